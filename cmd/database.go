@@ -6,6 +6,7 @@ import (
 	"github.com/mayswind/ezbookkeeping/pkg/datastore"
 	"github.com/mayswind/ezbookkeeping/pkg/log"
 	"github.com/mayswind/ezbookkeeping/pkg/models"
+	"github.com/mayswind/ezbookkeeping/pkg/services"
 )
 
 // Database represents the database command
@@ -116,5 +117,109 @@ func updateAllDatabaseTablesStructure() error {
 
 	log.BootInfof("[database.updateAllDatabaseTablesStructure] transaction tag index table maintained successfully")
 
+	err = datastore.Container.UserDataStore.SyncStructs(new(models.TransactionPayee))
+
+	if err != nil {
+		return err
+	}
+
+	log.BootInfof("[database.updateAllDatabaseTablesStructure] transaction payee table maintained successfully")
+
+	err = datastore.Container.UserDataStore.SyncStructs(new(models.TransactionRule))
+
+	if err != nil {
+		return err
+	}
+
+	log.BootInfof("[database.updateAllDatabaseTablesStructure] transaction rule table maintained successfully")
+
+	err = datastore.Container.UserDataStore.SyncStructs(new(models.TransactionSplit))
+
+	if err != nil {
+		return err
+	}
+
+	log.BootInfof("[database.updateAllDatabaseTablesStructure] transaction split table maintained successfully")
+
+	err = datastore.Container.UserDataStore.SyncStructs(new(models.TransactionSchedule))
+
+	if err != nil {
+		return err
+	}
+
+	log.BootInfof("[database.updateAllDatabaseTablesStructure] transaction schedule table maintained successfully")
+
+	err = datastore.Container.UserDataStore.SyncStructs(new(models.TransactionTemplate))
+
+	if err != nil {
+		return err
+	}
+
+	log.BootInfof("[database.updateAllDatabaseTablesStructure] transaction template table maintained successfully")
+
+	err = datastore.Container.UserDataStore.SyncStructs(new(models.TransactionAttachment))
+
+	if err != nil {
+		return err
+	}
+
+	log.BootInfof("[database.updateAllDatabaseTablesStructure] transaction attachment table maintained successfully")
+
+	err = datastore.Container.UserDataStore.SyncStructs(new(models.TransactionHistory))
+
+	if err != nil {
+		return err
+	}
+
+	log.BootInfof("[database.updateAllDatabaseTablesStructure] transaction history table maintained successfully")
+
+	err = datastore.Container.UserDataStore.SyncStructs(new(models.InvestmentSecurity))
+
+	if err != nil {
+		return err
+	}
+
+	log.BootInfof("[database.updateAllDatabaseTablesStructure] investment security table maintained successfully")
+
+	err = datastore.Container.UserDataStore.SyncStructs(new(models.InvestmentSecurityPrice))
+
+	if err != nil {
+		return err
+	}
+
+	log.BootInfof("[database.updateAllDatabaseTablesStructure] investment security price table maintained successfully")
+
+	err = datastore.Container.UserDataStore.SyncStructs(new(models.InvestmentTransaction))
+
+	if err != nil {
+		return err
+	}
+
+	log.BootInfof("[database.updateAllDatabaseTablesStructure] investment transaction table maintained successfully")
+
+	err = datastore.Container.UserDataStore.SyncStructs(new(models.Budget))
+
+	if err != nil {
+		return err
+	}
+
+	log.BootInfof("[database.updateAllDatabaseTablesStructure] budget table maintained successfully")
+
+	err = datastore.Container.UserDataStore.SyncStructs(new(models.SavingsGoal))
+
+	if err != nil {
+		return err
+	}
+
+	log.BootInfof("[database.updateAllDatabaseTablesStructure] savings goal table maintained successfully")
+
+	err = datastore.Container.UserDataStore.CreateFullTextIndex(services.TransactionCommentFullTextIndex)
+
+	if err != nil {
+		log.BootWarnf("[database.updateAllDatabaseTablesStructure] transaction comment full-text index cannot be created and keyword search would not use it, because %s", err.Error())
+	} else {
+		log.BootInfof("[database.updateAllDatabaseTablesStructure] transaction comment full-text index maintained successfully")
+	}
+
 	return nil
 }
@@ -10,6 +10,7 @@ import (
 	"github.com/mayswind/ezbookkeeping/pkg/exchangerates"
 	"github.com/mayswind/ezbookkeeping/pkg/log"
 	"github.com/mayswind/ezbookkeeping/pkg/settings"
+	"github.com/mayswind/ezbookkeeping/pkg/storage"
 	"github.com/mayswind/ezbookkeeping/pkg/utils"
 	"github.com/mayswind/ezbookkeeping/pkg/uuid"
 )
@@ -73,6 +74,13 @@ func initializeSystem(c *cli.Context) (*settings.Config, error) {
 		return nil, err
 	}
 
+	err = storage.InitializeObjectStorage(config)
+
+	if err != nil {
+		log.BootErrorf("[initializer.initializeSystem] initializes object storage failed, because %s", err.Error())
+		return nil, err
+	}
+
 	cfgJson, _ := json.Marshal(getConfigWithoutSensitiveData(config))
 	log.BootInfof("[initializer.initializeSystem] has loaded configuration %s", cfgJson)
 
@@ -151,6 +151,19 @@ var UserData = &cli.Command{
 				},
 			},
 		},
+		{
+			Name:   "transaction-history",
+			Usage:  "Dump user all transaction change histories (audit log)",
+			Action: dumpUserTransactionHistories,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:     "username",
+					Aliases:  []string{"n"},
+					Required: true,
+					Usage:    "Specific user name",
+				},
+			},
+		},
 		{
 			Name:   "transaction-export",
 			Usage:  "Export user all transactions to csv file",
@@ -170,6 +183,31 @@ var UserData = &cli.Command{
 				},
 			},
 		},
+		{
+			Name:   "transaction-tag-merge",
+			Usage:  "Merge user transaction tags into another tag",
+			Action: mergeUserTransactionTags,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:     "username",
+					Aliases:  []string{"n"},
+					Required: true,
+					Usage:    "Specific user name",
+				},
+				&cli.StringSliceFlag{
+					Name:     "from",
+					Aliases:  []string{"f"},
+					Required: true,
+					Usage:    "Specific source tag names which would be deleted after merging",
+				},
+				&cli.StringFlag{
+					Name:     "to",
+					Aliases:  []string{"t"},
+					Required: true,
+					Usage:    "Specific target tag name",
+				},
+			},
+		},
 	},
 }
 
@@ -391,6 +429,55 @@ func exportUserTransaction(c *cli.Context) error {
 	return nil
 }
 
+func mergeUserTransactionTags(c *cli.Context) error {
+	_, err := initializeSystem(c)
+
+	if err != nil {
+		return err
+	}
+
+	username := c.String("username")
+	fromTagNames := c.StringSlice("from")
+	toTagName := c.String("to")
+
+	err = clis.UserData.MergeTransactionTags(c, username, fromTagNames, toTagName)
+
+	if err != nil {
+		log.BootErrorf("[user_data.mergeUserTransactionTags] error occurs when merging user transaction tags")
+		return err
+	}
+
+	log.BootInfof("[user_data.mergeUserTransactionTags] %d tags of user \"%s\" have been merged into tag \"%s\"", len(fromTagNames), username, toTagName)
+
+	return nil
+}
+
+func dumpUserTransactionHistories(c *cli.Context) error {
+	_, err := initializeSystem(c)
+
+	if err != nil {
+		return err
+	}
+
+	username := c.String("username")
+	histories, err := clis.UserData.GetTransactionHistories(c, username)
+
+	if err != nil {
+		log.BootErrorf("[user_data.dumpUserTransactionHistories] error occurs when getting user transaction histories")
+		return err
+	}
+
+	for i := 0; i < len(histories); i++ {
+		printTransactionHistoryInfo(histories[i])
+
+		if i < len(histories)-1 {
+			fmt.Printf("---\n")
+		}
+	}
+
+	return nil
+}
+
 func printUserInfo(user *models.User) {
 	fmt.Printf("[Uid] %d\n", user.Uid)
 	fmt.Printf("[Username] %s\n", user.Username)
@@ -423,3 +510,21 @@ func printTokenInfo(token *models.TokenRecord) {
 	fmt.Printf("[ExpiredAt] %s (%d)\n", utils.FormatUnixTimeToLongDateTimeInServerTimezone(token.ExpiredUnixTime), token.ExpiredUnixTime)
 	fmt.Printf("[UserAgent] %s\n", token.UserAgent)
 }
+
+func printTransactionHistoryInfo(history *models.TransactionHistory) {
+	fmt.Printf("[HistoryId] %d\n", history.HistoryId)
+	fmt.Printf("[TransactionId] %d\n", history.TransactionId)
+	fmt.Printf("[Operation] %s\n", history.Operation)
+	fmt.Printf("[UserTokenId] %d\n", history.UserTokenId)
+	fmt.Printf("[RequestId] %s\n", history.RequestId)
+
+	if history.BeforeData != "" {
+		fmt.Printf("[Before] %s\n", history.BeforeData)
+	}
+
+	if history.AfterData != "" {
+		fmt.Printf("[After] %s\n", history.AfterData)
+	}
+
+	fmt.Printf("[CreatedAt] %s (%d)\n", utils.FormatUnixTimeToLongDateTimeInServerTimezone(history.CreatedUnixTime), history.CreatedUnixTime)
+}
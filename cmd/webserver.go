@@ -12,6 +12,7 @@ import (
 
 	"github.com/mayswind/ezbookkeeping/pkg/api"
 	"github.com/mayswind/ezbookkeeping/pkg/core"
+	"github.com/mayswind/ezbookkeeping/pkg/cron"
 	"github.com/mayswind/ezbookkeeping/pkg/errs"
 	"github.com/mayswind/ezbookkeeping/pkg/log"
 	"github.com/mayswind/ezbookkeeping/pkg/middlewares"
@@ -67,6 +68,15 @@ func startWebServer(c *cli.Context) error {
 
 	log.BootInfof("[server.startWebServer] %s%s", serverInfo, uuidServerInfo)
 
+	err = cron.InitializeCronJobSchedulerContainer(config)
+
+	if err != nil {
+		log.BootErrorf("[server.startWebServer] initializes cron job scheduler failed, because %s", err.Error())
+		return err
+	}
+
+	cron.Container.StartAllJobs()
+
 	if config.Mode == settings.MODE_PRODUCTION {
 		gin.SetMode(gin.ReleaseMode)
 	}
@@ -160,6 +170,13 @@ func startWebServer(c *cli.Context) error {
 			}
 		}
 
+		attachmentRoute := apiRoute.Group("/attachments")
+		attachmentRoute.Use(bindMiddleware(middlewares.HeaderInQueryString))
+		attachmentRoute.Use(bindMiddleware(middlewares.JWTAuthorizationByQueryString))
+		{
+			attachmentRoute.GET("/download", bindFile(api.TransactionAttachments.TransactionAttachmentDownloadHandler))
+		}
+
 		apiRoute.GET("/logout.json", bindApi(api.Tokens.TokenRevokeCurrentHandler))
 
 		apiV1Route := apiRoute.Group("/v1")
@@ -195,6 +212,15 @@ func startWebServer(c *cli.Context) error {
 			apiV1Route.POST("/accounts/hide.json", bindApi(api.Accounts.AccountHideHandler))
 			apiV1Route.POST("/accounts/move.json", bindApi(api.Accounts.AccountMoveHandler))
 			apiV1Route.POST("/accounts/delete.json", bindApi(api.Accounts.AccountDeleteHandler))
+			apiV1Route.POST("/accounts/close.json", bindApi(api.Accounts.AccountCloseHandler))
+			apiV1Route.POST("/accounts/merge.json", bindApi(api.Accounts.AccountMergeHandler))
+			apiV1Route.GET("/accounts/reconcile/preview.json", bindApi(api.Accounts.AccountReconcilePreviewHandler))
+			apiV1Route.POST("/accounts/reconcile.json", bindApi(api.Accounts.AccountReconcileHandler))
+			apiV1Route.GET("/accounts/credit_card/statements.json", bindApi(api.Accounts.AccountCreditCardStatementListHandler))
+			apiV1Route.POST("/accounts/loan/modify.json", bindApi(api.Accounts.AccountLoanSettingsModifyHandler))
+			apiV1Route.GET("/accounts/loan/schedule.json", bindApi(api.Accounts.AccountLoanScheduleHandler))
+			apiV1Route.POST("/accounts/loan/payment.json", bindApi(api.Accounts.AccountLoanPaymentHandler))
+			apiV1Route.GET("/accounts/balance_trends.json", bindApi(api.Accounts.AccountBalanceTrendsHandler))
 
 			// Transactions
 			apiV1Route.GET("/transactions/count.json", bindApi(api.Transactions.TransactionCountHandler))
@@ -203,10 +229,44 @@ func startWebServer(c *cli.Context) error {
 			apiV1Route.GET("/transactions/statistics.json", bindApi(api.Transactions.TransactionStatisticsHandler))
 			apiV1Route.GET("/transactions/amounts.json", bindApi(api.Transactions.TransactionAmountsHandler))
 			apiV1Route.GET("/transactions/amounts/by_month.json", bindApi(api.Transactions.TransactionMonthAmountsHandler))
+			apiV1Route.GET("/transactions/search.json", bindApi(api.Transactions.TransactionSearchHandler))
 			apiV1Route.GET("/transactions/get.json", bindApi(api.Transactions.TransactionGetHandler))
 			apiV1Route.POST("/transactions/add.json", bindApi(api.Transactions.TransactionCreateHandler))
 			apiV1Route.POST("/transactions/modify.json", bindApi(api.Transactions.TransactionModifyHandler))
 			apiV1Route.POST("/transactions/delete.json", bindApi(api.Transactions.TransactionDeleteHandler))
+			apiV1Route.POST("/transactions/batch/add.json", bindApi(api.Transactions.TransactionBatchCreateHandler))
+			apiV1Route.POST("/transactions/batch/modify.json", bindApi(api.Transactions.TransactionBatchModifyHandler))
+			apiV1Route.POST("/transactions/batch/delete.json", bindApi(api.Transactions.TransactionBatchDeleteHandler))
+			apiV1Route.POST("/transactions/batch/reassign_category.json", bindApi(api.Transactions.TransactionReassignCategoryHandler))
+			apiV1Route.POST("/transactions/reconcile_state/modify.json", bindApi(api.Transactions.TransactionReconcileStateModifyHandler))
+
+			// Transaction Histories
+			apiV1Route.GET("/transactions/history/list.json", bindApi(api.TransactionHistories.TransactionHistoryListHandler))
+
+			// Transaction Trash
+			apiV1Route.GET("/transactions/trash/list.json", bindApi(api.TransactionTrash.TransactionTrashListHandler))
+			apiV1Route.POST("/transactions/trash/restore.json", bindApi(api.TransactionTrash.TransactionRestoreHandler))
+			apiV1Route.POST("/transactions/trash/purge.json", bindApi(api.TransactionTrash.TransactionPurgeHandler))
+
+			// Transaction Schedules
+			apiV1Route.GET("/transactions/schedules/list.json", bindApi(api.TransactionSchedules.TransactionScheduleListHandler))
+			apiV1Route.GET("/transactions/schedules/get.json", bindApi(api.TransactionSchedules.TransactionScheduleGetHandler))
+			apiV1Route.POST("/transactions/schedules/add.json", bindApi(api.TransactionSchedules.TransactionScheduleCreateHandler))
+			apiV1Route.POST("/transactions/schedules/modify.json", bindApi(api.TransactionSchedules.TransactionScheduleModifyHandler))
+			apiV1Route.POST("/transactions/schedules/delete.json", bindApi(api.TransactionSchedules.TransactionScheduleDeleteHandler))
+
+			// Transaction Templates
+			apiV1Route.GET("/transactions/templates/list.json", bindApi(api.TransactionTemplates.TransactionTemplateListHandler))
+			apiV1Route.GET("/transactions/templates/get.json", bindApi(api.TransactionTemplates.TransactionTemplateGetHandler))
+			apiV1Route.POST("/transactions/templates/add.json", bindApi(api.TransactionTemplates.TransactionTemplateCreateHandler))
+			apiV1Route.POST("/transactions/templates/modify.json", bindApi(api.TransactionTemplates.TransactionTemplateModifyHandler))
+			apiV1Route.POST("/transactions/templates/delete.json", bindApi(api.TransactionTemplates.TransactionTemplateDeleteHandler))
+			apiV1Route.POST("/transactions/templates/apply.json", bindApi(api.Transactions.TransactionCreateByTemplateHandler))
+
+			// Transaction Attachments
+			apiV1Route.GET("/transactions/attachments/list.json", bindApi(api.TransactionAttachments.TransactionAttachmentListHandler))
+			apiV1Route.POST("/transactions/attachments/upload.json", bindApi(api.TransactionAttachments.TransactionAttachmentUploadHandler))
+			apiV1Route.POST("/transactions/attachments/delete.json", bindApi(api.TransactionAttachments.TransactionAttachmentDeleteHandler))
 
 			// Transaction Categories
 			apiV1Route.GET("/transaction/categories/list.json", bindApi(api.TransactionCategories.CategoryListHandler))
@@ -216,6 +276,7 @@ func startWebServer(c *cli.Context) error {
 			apiV1Route.POST("/transaction/categories/modify.json", bindApi(api.TransactionCategories.CategoryModifyHandler))
 			apiV1Route.POST("/transaction/categories/hide.json", bindApi(api.TransactionCategories.CategoryHideHandler))
 			apiV1Route.POST("/transaction/categories/move.json", bindApi(api.TransactionCategories.CategoryMoveHandler))
+			apiV1Route.POST("/transaction/categories/merge.json", bindApi(api.TransactionCategories.CategoryMergeHandler))
 			apiV1Route.POST("/transaction/categories/delete.json", bindApi(api.TransactionCategories.CategoryDeleteHandler))
 
 			// Transaction Tags
@@ -225,8 +286,59 @@ func startWebServer(c *cli.Context) error {
 			apiV1Route.POST("/transaction/tags/modify.json", bindApi(api.TransactionTags.TagModifyHandler))
 			apiV1Route.POST("/transaction/tags/hide.json", bindApi(api.TransactionTags.TagHideHandler))
 			apiV1Route.POST("/transaction/tags/move.json", bindApi(api.TransactionTags.TagMoveHandler))
+			apiV1Route.POST("/transaction/tags/merge.json", bindApi(api.TransactionTags.TagMergeHandler))
 			apiV1Route.POST("/transaction/tags/delete.json", bindApi(api.TransactionTags.TagDeleteHandler))
 
+			// Transaction Payees
+			apiV1Route.GET("/transaction/payees/list.json", bindApi(api.TransactionPayees.PayeeListHandler))
+			apiV1Route.GET("/transaction/payees/get.json", bindApi(api.TransactionPayees.PayeeGetHandler))
+			apiV1Route.GET("/transaction/payees/autocomplete.json", bindApi(api.TransactionPayees.PayeeAutocompleteHandler))
+			apiV1Route.GET("/transaction/payees/suggestion.json", bindApi(api.TransactionPayees.PayeeSuggestionHandler))
+			apiV1Route.POST("/transaction/payees/add.json", bindApi(api.TransactionPayees.PayeeCreateHandler))
+			apiV1Route.POST("/transaction/payees/modify.json", bindApi(api.TransactionPayees.PayeeModifyHandler))
+			apiV1Route.POST("/transaction/payees/hide.json", bindApi(api.TransactionPayees.PayeeHideHandler))
+			apiV1Route.POST("/transaction/payees/merge.json", bindApi(api.TransactionPayees.PayeeMergeHandler))
+			apiV1Route.POST("/transaction/payees/delete.json", bindApi(api.TransactionPayees.PayeeDeleteHandler))
+
+			// Transaction Rules
+			apiV1Route.GET("/transaction/rules/list.json", bindApi(api.TransactionRules.RuleListHandler))
+			apiV1Route.GET("/transaction/rules/get.json", bindApi(api.TransactionRules.RuleGetHandler))
+			apiV1Route.POST("/transaction/rules/add.json", bindApi(api.TransactionRules.RuleCreateHandler))
+			apiV1Route.POST("/transaction/rules/modify.json", bindApi(api.TransactionRules.RuleModifyHandler))
+			apiV1Route.POST("/transaction/rules/delete.json", bindApi(api.TransactionRules.RuleDeleteHandler))
+			apiV1Route.POST("/transaction/rules/apply.json", bindApi(api.TransactionRules.RuleApplyHandler))
+
+			// Investments
+			apiV1Route.GET("/investment/securities/list.json", bindApi(api.Investments.SecurityListHandler))
+			apiV1Route.GET("/investment/securities/get.json", bindApi(api.Investments.SecurityGetHandler))
+			apiV1Route.POST("/investment/securities/add.json", bindApi(api.Investments.SecurityCreateHandler))
+			apiV1Route.POST("/investment/securities/modify.json", bindApi(api.Investments.SecurityModifyHandler))
+			apiV1Route.POST("/investment/securities/delete.json", bindApi(api.Investments.SecurityDeleteHandler))
+			apiV1Route.GET("/investment/prices/list.json", bindApi(api.Investments.PriceListHandler))
+			apiV1Route.POST("/investment/prices/add.json", bindApi(api.Investments.PriceCreateHandler))
+			apiV1Route.POST("/investment/prices/import.json", bindApi(api.Investments.PriceImportHandler))
+			apiV1Route.POST("/investment/prices/update.json", bindApi(api.Investments.PriceUpdateHandler))
+			apiV1Route.POST("/investment/prices/delete.json", bindApi(api.Investments.PriceDeleteHandler))
+			apiV1Route.GET("/investment/transactions/list.json", bindApi(api.Investments.InvestmentTransactionListHandler))
+			apiV1Route.POST("/investment/transactions/add.json", bindApi(api.Investments.InvestmentTransactionCreateHandler))
+			apiV1Route.POST("/investment/transactions/modify.json", bindApi(api.Investments.InvestmentTransactionModifyHandler))
+			apiV1Route.POST("/investment/transactions/delete.json", bindApi(api.Investments.InvestmentTransactionDeleteHandler))
+			apiV1Route.GET("/investment/holdings/list.json", bindApi(api.Investments.HoldingListHandler))
+
+			// Budgets
+			apiV1Route.GET("/budgets/list.json", bindApi(api.Budgets.BudgetListHandler))
+			apiV1Route.POST("/budgets/add.json", bindApi(api.Budgets.BudgetCreateHandler))
+			apiV1Route.POST("/budgets/modify.json", bindApi(api.Budgets.BudgetModifyHandler))
+			apiV1Route.POST("/budgets/delete.json", bindApi(api.Budgets.BudgetDeleteHandler))
+			apiV1Route.GET("/budgets/status.json", bindApi(api.Budgets.BudgetStatusHandler))
+
+			// Savings Goals
+			apiV1Route.GET("/savings_goals/list.json", bindApi(api.SavingsGoals.SavingsGoalListHandler))
+			apiV1Route.GET("/savings_goals/get.json", bindApi(api.SavingsGoals.SavingsGoalGetHandler))
+			apiV1Route.POST("/savings_goals/add.json", bindApi(api.SavingsGoals.SavingsGoalCreateHandler))
+			apiV1Route.POST("/savings_goals/modify.json", bindApi(api.SavingsGoals.SavingsGoalModifyHandler))
+			apiV1Route.POST("/savings_goals/delete.json", bindApi(api.SavingsGoals.SavingsGoalDeleteHandler))
+
 			// Exchange Rates
 			apiV1Route.GET("/exchange_rates/latest.json", bindApi(api.ExchangeRates.LatestExchangeRateHandler))
 		}
@@ -286,3 +398,16 @@ func bindCsv(fn core.DataHandlerFunc) gin.HandlerFunc {
 		}
 	}
 }
+
+func bindFile(fn core.FileHandlerFunc) gin.HandlerFunc {
+	return func(ginCtx *gin.Context) {
+		c := core.WrapContext(ginCtx)
+		result, contentType, fileName, err := fn(c)
+
+		if err != nil {
+			utils.PrintDataErrorResult(c, "text/text", err)
+		} else {
+			utils.PrintDataSuccessResult(c, contentType, fileName, result)
+		}
+	}
+}
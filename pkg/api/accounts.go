@@ -2,24 +2,34 @@ package api
 
 import (
 	"sort"
+	"time"
 
 	"github.com/mayswind/ezbookkeeping/pkg/core"
 	"github.com/mayswind/ezbookkeeping/pkg/errs"
+	"github.com/mayswind/ezbookkeeping/pkg/exchangerates"
 	"github.com/mayswind/ezbookkeeping/pkg/log"
 	"github.com/mayswind/ezbookkeeping/pkg/models"
 	"github.com/mayswind/ezbookkeeping/pkg/services"
+	"github.com/mayswind/ezbookkeeping/pkg/settings"
+	"github.com/mayswind/ezbookkeeping/pkg/utils"
 	"github.com/mayswind/ezbookkeeping/pkg/validators"
 )
 
+const defaultCreditCardStatementCount = 12
+
 // AccountsApi represents account api
 type AccountsApi struct {
-	accounts *services.AccountService
+	accounts     *services.AccountService
+	transactions *services.TransactionService
+	users        *services.UserService
 }
 
 // Initialize an account api singleton instance
 var (
 	Accounts = &AccountsApi{
-		accounts: services.Accounts,
+		accounts:     services.Accounts,
+		transactions: services.Transactions,
+		users:        services.Users,
 	}
 )
 
@@ -41,11 +51,19 @@ func (a *AccountsApi) AccountListHandler(c *core.Context) (interface{}, *errs.Er
 		return nil, errs.ErrOperationFailed
 	}
 
+	accountPendingAmounts, err := a.transactions.GetAccountsPendingAmounts(uid)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[accounts.AccountListHandler] failed to get pending amounts of all accounts for user \"uid:%d\", because %s", uid, err.Error())
+		return nil, errs.ErrOperationFailed
+	}
+
 	userAllAccountResps := make([]*models.AccountInfoResponse, len(accounts))
 	userAllAccountRespMap := make(map[int64]*models.AccountInfoResponse)
 
 	for i := 0; i < len(accounts); i++ {
 		userAllAccountResps[i] = accounts[i].ToAccountInfoResponse()
+		userAllAccountResps[i].ProjectedBalance += accountPendingAmounts[accounts[i].AccountId]
 		userAllAccountRespMap[userAllAccountResps[i].Id] = userAllAccountResps[i]
 	}
 
@@ -101,10 +119,18 @@ func (a *AccountsApi) AccountGetHandler(c *core.Context) (interface{}, *errs.Err
 		return nil, errs.ErrOperationFailed
 	}
 
+	accountPendingAmounts, err := a.transactions.GetAccountsPendingAmounts(uid)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[accounts.AccountGetHandler] failed to get pending amounts of all accounts for user \"uid:%d\", because %s", uid, err.Error())
+		return nil, errs.ErrOperationFailed
+	}
+
 	accountRespMap := make(map[int64]*models.AccountInfoResponse)
 
 	for i := 0; i < len(accountAndSubAccounts); i++ {
 		accountResp := accountAndSubAccounts[i].ToAccountInfoResponse()
+		accountResp.ProjectedBalance += accountPendingAmounts[accountResp.Id]
 		accountRespMap[accountResp.Id] = accountResp
 	}
 
@@ -116,7 +142,7 @@ func (a *AccountsApi) AccountGetHandler(c *core.Context) (interface{}, *errs.Err
 
 	for i := 0; i < len(accountAndSubAccounts); i++ {
 		if accountAndSubAccounts[i].ParentAccountId == accountResp.Id {
-			subAccountResp := accountAndSubAccounts[i].ToAccountInfoResponse()
+			subAccountResp := accountRespMap[accountAndSubAccounts[i].AccountId]
 			accountResp.SubAccounts = append(accountResp.SubAccounts, subAccountResp)
 		}
 	}
@@ -146,6 +172,11 @@ func (a *AccountsApi) AccountCreateHandler(c *core.Context) (interface{}, *errs.
 			log.WarnfWithRequestId(c, "[accounts.AccountCreateHandler] account cannot set currency placeholder")
 			return nil, errs.ErrAccountCurrencyInvalid
 		}
+
+		if accountCreateReq.Category != models.ACCOUNT_CATEGORY_CREDIT_CARD && (accountCreateReq.StatementDay != 0 || accountCreateReq.PaymentDueDay != 0 || accountCreateReq.CreditLimit != 0) {
+			log.WarnfWithRequestId(c, "[accounts.AccountCreateHandler] account which is not credit card cannot set credit card settings")
+			return nil, errs.ErrAccountCannotSetCreditCardSettings
+		}
 	} else if accountCreateReq.Type == models.ACCOUNT_TYPE_MULTI_SUB_ACCOUNTS {
 		if len(accountCreateReq.SubAccounts) < 1 {
 			log.WarnfWithRequestId(c, "[accounts.AccountCreateHandler] account does not have any sub accounts")
@@ -162,6 +193,11 @@ func (a *AccountsApi) AccountCreateHandler(c *core.Context) (interface{}, *errs.
 			return nil, errs.ErrParentAccountCannotSetBalance
 		}
 
+		if accountCreateReq.StatementDay != 0 || accountCreateReq.PaymentDueDay != 0 || accountCreateReq.CreditLimit != 0 {
+			log.WarnfWithRequestId(c, "[accounts.AccountCreateHandler] parent account cannot set credit card settings")
+			return nil, errs.ErrAccountCannotSetCreditCardSettings
+		}
+
 		for i := 0; i < len(accountCreateReq.SubAccounts); i++ {
 			subAccount := accountCreateReq.SubAccounts[i]
 
@@ -179,6 +215,11 @@ func (a *AccountsApi) AccountCreateHandler(c *core.Context) (interface{}, *errs.
 				log.WarnfWithRequestId(c, "[accounts.AccountCreateHandler] sub account cannot set currency placeholder")
 				return nil, errs.ErrAccountCurrencyInvalid
 			}
+
+			if subAccount.Category != models.ACCOUNT_CATEGORY_CREDIT_CARD && (subAccount.StatementDay != 0 || subAccount.PaymentDueDay != 0 || subAccount.CreditLimit != 0) {
+				log.WarnfWithRequestId(c, "[accounts.AccountCreateHandler] sub account which is not credit card cannot set credit card settings")
+				return nil, errs.ErrAccountCannotSetCreditCardSettings
+			}
 		}
 	} else {
 		log.WarnfWithRequestId(c, "[accounts.AccountCreateHandler] account type invalid, type is %d", accountCreateReq.Type)
@@ -246,6 +287,11 @@ func (a *AccountsApi) AccountModifyHandler(c *core.Context) (interface{}, *errs.
 		return nil, errs.ErrCannotAddOrDeleteSubAccountsWhenModify
 	}
 
+	if !a.isCreditCardSettingsValid(&accountModifyReq, accountMap[accountModifyReq.Id]) {
+		log.WarnfWithRequestId(c, "[accounts.AccountModifyHandler] account \"id:%d\" cannot set credit card settings", accountModifyReq.Id)
+		return nil, errs.ErrAccountCannotSetCreditCardSettings
+	}
+
 	anythingUpdate := false
 	var toUpdateAccounts []*models.Account
 
@@ -263,6 +309,11 @@ func (a *AccountsApi) AccountModifyHandler(c *core.Context) (interface{}, *errs.
 			return nil, errs.ErrAccountNotFound
 		}
 
+		if !a.isCreditCardSettingsValid(subAccountReq, accountMap[subAccountReq.Id]) {
+			log.WarnfWithRequestId(c, "[accounts.AccountModifyHandler] sub account \"id:%d\" cannot set credit card settings", subAccountReq.Id)
+			return nil, errs.ErrAccountCannotSetCreditCardSettings
+		}
+
 		toUpdateSubAccount := a.getToUpdateAccount(uid, subAccountReq, accountMap[subAccountReq.Id])
 
 		if toUpdateSubAccount != nil {
@@ -403,18 +454,536 @@ func (a *AccountsApi) AccountDeleteHandler(c *core.Context) (interface{}, *errs.
 	return true, nil
 }
 
+// AccountCloseHandler transfers the remaining balance of an existed account into another account and hides it by request parameters for current user
+func (a *AccountsApi) AccountCloseHandler(c *core.Context) (interface{}, *errs.Error) {
+	var accountCloseReq models.AccountCloseRequest
+	err := c.ShouldBindJSON(&accountCloseReq)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[accounts.AccountCloseHandler] parse request failed, because %s", err.Error())
+		return nil, errs.NewIncompleteOrIncorrectSubmissionError(err)
+	}
+
+	utcOffset, err := c.GetClientTimezoneOffset()
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[accounts.AccountCloseHandler] cannot get client timezone offset, because %s", err.Error())
+		return nil, errs.ErrClientTimezoneOffsetInvalid
+	}
+
+	uid := c.GetCurrentUid()
+	transaction, err := a.transactions.CloseAccount(uid, accountCloseReq.Id, accountCloseReq.ToAccountId, accountCloseReq.CategoryId, accountCloseReq.ToAccountAmount, utcOffset, models.NewTransactionOperator(c))
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[accounts.AccountCloseHandler] failed to close account \"id:%d\" for user \"uid:%d\", because %s", accountCloseReq.Id, uid, err.Error())
+		return nil, errs.Or(err, errs.ErrOperationFailed)
+	}
+
+	if transaction != nil {
+		log.InfofWithRequestId(c, "[accounts.AccountCloseHandler] user \"uid:%d\" has closed account \"id:%d\" and transferred remaining balance by transaction \"id:%d\"", uid, accountCloseReq.Id, transaction.TransactionId)
+	} else {
+		log.InfofWithRequestId(c, "[accounts.AccountCloseHandler] user \"uid:%d\" has closed account \"id:%d\"", uid, accountCloseReq.Id)
+	}
+
+	return true, nil
+}
+
+// AccountMergeHandler moves all transactions of an existed account into another account and deletes it by request parameters for current user
+func (a *AccountsApi) AccountMergeHandler(c *core.Context) (interface{}, *errs.Error) {
+	var accountMergeReq models.AccountMergeRequest
+	err := c.ShouldBindJSON(&accountMergeReq)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[accounts.AccountMergeHandler] parse request failed, because %s", err.Error())
+		return nil, errs.NewIncompleteOrIncorrectSubmissionError(err)
+	}
+
+	uid := c.GetCurrentUid()
+	err = a.transactions.MergeAccounts(uid, accountMergeReq.FromId, accountMergeReq.ToId, models.NewTransactionOperator(c))
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[accounts.AccountMergeHandler] failed to merge account \"id:%d\" into account \"id:%d\" for user \"uid:%d\", because %s", accountMergeReq.FromId, accountMergeReq.ToId, uid, err.Error())
+		return nil, errs.Or(err, errs.ErrOperationFailed)
+	}
+
+	log.InfofWithRequestId(c, "[accounts.AccountMergeHandler] user \"uid:%d\" has merged account \"id:%d\" into account \"id:%d\"", uid, accountMergeReq.FromId, accountMergeReq.ToId)
+	return true, nil
+}
+
+// AccountReconcilePreviewHandler returns the difference between statement ending balance and cleared balance of account for current user
+func (a *AccountsApi) AccountReconcilePreviewHandler(c *core.Context) (interface{}, *errs.Error) {
+	var accountReconcilePreviewReq models.AccountReconcilePreviewRequest
+	err := c.ShouldBindQuery(&accountReconcilePreviewReq)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[accounts.AccountReconcilePreviewHandler] parse request failed, because %s", err.Error())
+		return nil, errs.NewIncompleteOrIncorrectSubmissionError(err)
+	}
+
+	uid := c.GetCurrentUid()
+	account, err := a.accounts.GetAccountByAccountId(uid, accountReconcilePreviewReq.Id)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[accounts.AccountReconcilePreviewHandler] failed to get account \"id:%d\" for user \"uid:%d\", because %s", accountReconcilePreviewReq.Id, uid, err.Error())
+		return nil, errs.Or(err, errs.ErrOperationFailed)
+	}
+
+	if account.Type == models.ACCOUNT_TYPE_MULTI_SUB_ACCOUNTS {
+		return nil, errs.ErrCannotReconcileParentAccount
+	}
+
+	maxTransactionTime := utils.GetMaxTransactionTimeFromUnixTime(accountReconcilePreviewReq.EndTime)
+	summary, err := a.transactions.GetAccountReconcileSummary(uid, account.AccountId, maxTransactionTime)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[accounts.AccountReconcilePreviewHandler] failed to get reconcile summary of account \"id:%d\" for user \"uid:%d\", because %s", accountReconcilePreviewReq.Id, uid, err.Error())
+		return nil, errs.Or(err, errs.ErrOperationFailed)
+	}
+
+	return summary.ToAccountReconcileResponse(account.AccountId, accountReconcilePreviewReq.EndTime, accountReconcilePreviewReq.EndingBalance), nil
+}
+
+// AccountReconcileHandler marks all cleared transactions of account until statement end time as reconciled for current user
+func (a *AccountsApi) AccountReconcileHandler(c *core.Context) (interface{}, *errs.Error) {
+	var accountReconcileReq models.AccountReconcileRequest
+	err := c.ShouldBindJSON(&accountReconcileReq)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[accounts.AccountReconcileHandler] parse request failed, because %s", err.Error())
+		return nil, errs.NewIncompleteOrIncorrectSubmissionError(err)
+	}
+
+	uid := c.GetCurrentUid()
+	maxTransactionTime := utils.GetMaxTransactionTimeFromUnixTime(accountReconcileReq.EndTime)
+	summary, err := a.transactions.ReconcileTransactions(uid, accountReconcileReq.Id, maxTransactionTime, accountReconcileReq.EndingBalance, accountReconcileReq.Force, models.NewTransactionOperator(c))
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[accounts.AccountReconcileHandler] failed to reconcile account \"id:%d\" for user \"uid:%d\", because %s", accountReconcileReq.Id, uid, err.Error())
+		return nil, errs.Or(err, errs.ErrOperationFailed)
+	}
+
+	log.InfofWithRequestId(c, "[accounts.AccountReconcileHandler] user \"uid:%d\" has reconciled account \"id:%d\"", uid, accountReconcileReq.Id)
+
+	return summary.ToAccountReconcileResponse(accountReconcileReq.Id, accountReconcileReq.EndTime, accountReconcileReq.EndingBalance), nil
+}
+
+// AccountCreditCardStatementListHandler returns the recent statement cycles of credit card account for current user
+func (a *AccountsApi) AccountCreditCardStatementListHandler(c *core.Context) (interface{}, *errs.Error) {
+	var statementListReq models.AccountCreditCardStatementListRequest
+	err := c.ShouldBindQuery(&statementListReq)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[accounts.AccountCreditCardStatementListHandler] parse request failed, because %s", err.Error())
+		return nil, errs.NewIncompleteOrIncorrectSubmissionError(err)
+	}
+
+	utcOffset, err := c.GetClientTimezoneOffset()
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[accounts.AccountCreditCardStatementListHandler] cannot get client timezone offset, because %s", err.Error())
+		return nil, errs.ErrClientTimezoneOffsetInvalid
+	}
+
+	uid := c.GetCurrentUid()
+	account, err := a.accounts.GetAccountByAccountId(uid, statementListReq.Id)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[accounts.AccountCreditCardStatementListHandler] failed to get account \"id:%d\" for user \"uid:%d\", because %s", statementListReq.Id, uid, err.Error())
+		return nil, errs.Or(err, errs.ErrOperationFailed)
+	}
+
+	if account.Type != models.ACCOUNT_TYPE_SINGLE_ACCOUNT || account.Category != models.ACCOUNT_CATEGORY_CREDIT_CARD || account.StatementDay <= 0 {
+		return nil, errs.ErrAccountStatementDayNotSet
+	}
+
+	count := statementListReq.Count
+
+	if count <= 0 {
+		count = defaultCreditCardStatementCount
+	}
+
+	statements, err := a.transactions.GetCreditCardStatements(uid, account, count, utcOffset)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[accounts.AccountCreditCardStatementListHandler] failed to get statements of account \"id:%d\" for user \"uid:%d\", because %s", statementListReq.Id, uid, err.Error())
+		return nil, errs.Or(err, errs.ErrOperationFailed)
+	}
+
+	statementListResp := &models.AccountCreditCardStatementListResponse{
+		AccountId:      account.AccountId,
+		StatementDay:   account.StatementDay,
+		PaymentDueDay:  account.PaymentDueDay,
+		CreditLimit:    account.CreditLimit,
+		CurrentBalance: account.Balance,
+		Statements:     make([]*models.AccountCreditCardStatementResponse, len(statements)),
+	}
+
+	if account.CreditLimit > 0 {
+		statementListResp.RemainingCredit = account.CreditLimit + account.Balance
+	}
+
+	for i := 0; i < len(statements); i++ {
+		statementListResp.Statements[i] = statements[i].ToAccountCreditCardStatementResponse(account.CreditLimit)
+	}
+
+	return statementListResp, nil
+}
+
+// AccountLoanSettingsModifyHandler saves the loan terms of an existed debt account by request parameters for current user
+func (a *AccountsApi) AccountLoanSettingsModifyHandler(c *core.Context) (interface{}, *errs.Error) {
+	var loanSettingsModifyReq models.AccountLoanSettingsModifyRequest
+	err := c.ShouldBindJSON(&loanSettingsModifyReq)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[accounts.AccountLoanSettingsModifyHandler] parse request failed, because %s", err.Error())
+		return nil, errs.NewIncompleteOrIncorrectSubmissionError(err)
+	}
+
+	uid := c.GetCurrentUid()
+	account, err := a.accounts.GetAccountByAccountId(uid, loanSettingsModifyReq.Id)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[accounts.AccountLoanSettingsModifyHandler] failed to get account \"id:%d\" for user \"uid:%d\", because %s", loanSettingsModifyReq.Id, uid, err.Error())
+		return nil, errs.Or(err, errs.ErrOperationFailed)
+	}
+
+	if account.Type != models.ACCOUNT_TYPE_SINGLE_ACCOUNT || account.Category != models.ACCOUNT_CATEGORY_DEBT {
+		log.WarnfWithRequestId(c, "[accounts.AccountLoanSettingsModifyHandler] account \"id:%d\" cannot set loan settings", loanSettingsModifyReq.Id)
+		return nil, errs.ErrAccountCannotSetLoanSettings
+	}
+
+	newAccount := &models.Account{
+		AccountId:              account.AccountId,
+		Uid:                    uid,
+		LoanPrincipal:          loanSettingsModifyReq.Principal,
+		LoanAnnualInterestRate: loanSettingsModifyReq.AnnualInterestRate,
+		LoanTermCount:          loanSettingsModifyReq.TermCount,
+		LoanStartUnixTime:      loanSettingsModifyReq.StartTime,
+		LoanPaymentFrequency:   loanSettingsModifyReq.PaymentFrequency,
+	}
+
+	isClearSettings := newAccount.LoanPrincipal == 0 && newAccount.LoanAnnualInterestRate == 0 && newAccount.LoanTermCount == 0 && newAccount.LoanStartUnixTime == 0 && newAccount.LoanPaymentFrequency == models.ACCOUNT_LOAN_PAYMENT_FREQUENCY_NONE
+
+	if !isClearSettings && !newAccount.HasLoanSettings() {
+		log.WarnfWithRequestId(c, "[accounts.AccountLoanSettingsModifyHandler] loan settings of account \"id:%d\" are incomplete", loanSettingsModifyReq.Id)
+		return nil, errs.ErrAccountLoanSettingsInvalid
+	}
+
+	if newAccount.LoanPrincipal == account.LoanPrincipal &&
+		newAccount.LoanAnnualInterestRate == account.LoanAnnualInterestRate &&
+		newAccount.LoanTermCount == account.LoanTermCount &&
+		newAccount.LoanStartUnixTime == account.LoanStartUnixTime &&
+		newAccount.LoanPaymentFrequency == account.LoanPaymentFrequency {
+		return nil, errs.ErrNothingWillBeUpdated
+	}
+
+	err = a.accounts.ModifyAccountLoanSettings(uid, newAccount)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[accounts.AccountLoanSettingsModifyHandler] failed to update loan settings of account \"id:%d\" for user \"uid:%d\", because %s", loanSettingsModifyReq.Id, uid, err.Error())
+		return nil, errs.Or(err, errs.ErrOperationFailed)
+	}
+
+	log.InfofWithRequestId(c, "[accounts.AccountLoanSettingsModifyHandler] user \"uid:%d\" has updated loan settings of account \"id:%d\" successfully", uid, loanSettingsModifyReq.Id)
+
+	account.LoanPrincipal = newAccount.LoanPrincipal
+	account.LoanAnnualInterestRate = newAccount.LoanAnnualInterestRate
+	account.LoanTermCount = newAccount.LoanTermCount
+	account.LoanStartUnixTime = newAccount.LoanStartUnixTime
+	account.LoanPaymentFrequency = newAccount.LoanPaymentFrequency
+	account.UpdatedUnixTime = newAccount.UpdatedUnixTime
+
+	return account.ToAccountInfoResponse(), nil
+}
+
+// AccountLoanScheduleHandler returns the amortization schedule of debt account for current user
+func (a *AccountsApi) AccountLoanScheduleHandler(c *core.Context) (interface{}, *errs.Error) {
+	var loanScheduleReq models.AccountLoanScheduleRequest
+	err := c.ShouldBindQuery(&loanScheduleReq)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[accounts.AccountLoanScheduleHandler] parse request failed, because %s", err.Error())
+		return nil, errs.NewIncompleteOrIncorrectSubmissionError(err)
+	}
+
+	utcOffset, err := c.GetClientTimezoneOffset()
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[accounts.AccountLoanScheduleHandler] cannot get client timezone offset, because %s", err.Error())
+		return nil, errs.ErrClientTimezoneOffsetInvalid
+	}
+
+	uid := c.GetCurrentUid()
+	account, err := a.accounts.GetAccountByAccountId(uid, loanScheduleReq.Id)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[accounts.AccountLoanScheduleHandler] failed to get account \"id:%d\" for user \"uid:%d\", because %s", loanScheduleReq.Id, uid, err.Error())
+		return nil, errs.Or(err, errs.ErrOperationFailed)
+	}
+
+	if account.Type != models.ACCOUNT_TYPE_SINGLE_ACCOUNT || account.Category != models.ACCOUNT_CATEGORY_DEBT || !account.HasLoanSettings() {
+		return nil, errs.ErrAccountLoanSettingsNotSet
+	}
+
+	return account.ToAccountLoanScheduleResponse(time.FixedZone("Client Timezone", int(utcOffset)*60)), nil
+}
+
+// AccountLoanPaymentHandler records a loan payment as a principal transfer and an interest expense by request parameters for current user
+func (a *AccountsApi) AccountLoanPaymentHandler(c *core.Context) (interface{}, *errs.Error) {
+	var loanPaymentReq models.AccountLoanPaymentRequest
+	err := c.ShouldBindJSON(&loanPaymentReq)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[accounts.AccountLoanPaymentHandler] parse request failed, because %s", err.Error())
+		return nil, errs.NewIncompleteOrIncorrectSubmissionError(err)
+	}
+
+	if loanPaymentReq.PrincipalAmount+loanPaymentReq.InterestAmount <= 0 {
+		return nil, errs.ErrAccountLoanPaymentAmountInvalid
+	}
+
+	uid := c.GetCurrentUid()
+	principalTransaction, interestTransaction, err := a.transactions.CreateLoanPayment(uid, loanPaymentReq.Id, loanPaymentReq.SourceAccountId, loanPaymentReq.TransferCategoryId, loanPaymentReq.InterestCategoryId, loanPaymentReq.PrincipalAmount, loanPaymentReq.InterestAmount, loanPaymentReq.Time, loanPaymentReq.UtcOffset, loanPaymentReq.Comment, models.NewTransactionOperator(c))
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[accounts.AccountLoanPaymentHandler] failed to record loan payment of account \"id:%d\" for user \"uid:%d\", because %s", loanPaymentReq.Id, uid, err.Error())
+		return nil, errs.Or(err, errs.ErrOperationFailed)
+	}
+
+	log.InfofWithRequestId(c, "[accounts.AccountLoanPaymentHandler] user \"uid:%d\" has recorded loan payment of account \"id:%d\"", uid, loanPaymentReq.Id)
+
+	loanPaymentResp := &models.AccountLoanPaymentResponse{}
+
+	if principalTransaction != nil {
+		loanPaymentResp.PrincipalTransaction = principalTransaction.ToTransactionInfoResponse(nil, true)
+	}
+
+	if interestTransaction != nil {
+		loanPaymentResp.InterestTransaction = interestTransaction.ToTransactionInfoResponse(nil, true)
+	}
+
+	return loanPaymentResp, nil
+}
+
+// AccountBalanceTrendsHandler returns the balance of every account and total net worth at the end of every period for current user,
+// the total amounts of all periods are converted to default currency by the latest exchange rates, not the exchange rates at that time
+func (a *AccountsApi) AccountBalanceTrendsHandler(c *core.Context) (interface{}, *errs.Error) {
+	var balanceTrendsReq models.AccountBalanceTrendsRequest
+	err := c.ShouldBindQuery(&balanceTrendsReq)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[accounts.AccountBalanceTrendsHandler] parse request failed, because %s", err.Error())
+		return nil, errs.NewIncompleteOrIncorrectSubmissionError(err)
+	}
+
+	utcOffset, err := c.GetClientTimezoneOffset()
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[accounts.AccountBalanceTrendsHandler] cannot get client timezone offset, because %s", err.Error())
+		return nil, errs.ErrClientTimezoneOffsetInvalid
+	}
+
+	uid := c.GetCurrentUid()
+	user, err := a.users.GetUserById(uid)
+
+	if err != nil {
+		if !errs.IsCustomError(err) {
+			log.ErrorfWithRequestId(c, "[accounts.AccountBalanceTrendsHandler] failed to get user, because %s", err.Error())
+		}
+
+		return nil, errs.ErrUserNotFound
+	}
+
+	now := time.Now().Unix()
+	endTime := balanceTrendsReq.EndTime
+
+	if endTime <= 0 || endTime > now {
+		endTime = now
+	}
+
+	if balanceTrendsReq.StartTime > endTime {
+		return nil, errs.ErrParameterInvalid
+	}
+
+	dataPointTimes := a.getBalanceTrendsDataPointTimes(balanceTrendsReq.StartTime, endTime, balanceTrendsReq.IntervalType, user.FirstDayOfWeek, utcOffset)
+
+	if len(dataPointTimes) > models.MaxAccountBalanceTrendsDataPointCount {
+		return nil, errs.ErrTooManyAccountBalanceTrendsDataPoints
+	}
+
+	accounts, err := a.accounts.GetAllAccountsByUid(uid)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[accounts.AccountBalanceTrendsHandler] failed to get all accounts for user \"uid:%d\", because %s", uid, err.Error())
+		return nil, errs.ErrOperationFailed
+	}
+
+	accountsBalances, err := a.transactions.GetAccountsBalancesByTimes(uid, dataPointTimes)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[accounts.AccountBalanceTrendsHandler] failed to get accounts balances for user \"uid:%d\", because %s", uid, err.Error())
+		return nil, errs.Or(err, errs.ErrOperationFailed)
+	}
+
+	var exchangeRates *models.LatestExchangeRateResponse
+	totalAssetsByCurrency := make(map[string][]int64)
+	totalLiabilitiesByCurrency := make(map[string][]int64)
+
+	balanceTrendsResp := &models.AccountBalanceTrendsResponse{
+		Currency:   user.DefaultCurrency,
+		DataPoints: make([]*models.AccountBalanceTrendsDataPointResponse, len(dataPointTimes)),
+		Accounts:   make([]*models.AccountBalanceTrendsAccountResponse, 0, len(accounts)),
+	}
+
+	for i := 0; i < len(accounts); i++ {
+		account := accounts[i]
+
+		if account.Type == models.ACCOUNT_TYPE_MULTI_SUB_ACCOUNTS {
+			continue
+		}
+
+		balances, exists := accountsBalances[account.AccountId]
+
+		if !exists {
+			balances = make([]int64, len(dataPointTimes))
+		}
+
+		if exists && account.Currency != user.DefaultCurrency && exchangeRates == nil {
+			exchangeRates, err = exchangerates.Container.GetLatestExchangeRates(c, uid, settings.Container.Current)
+
+			if err != nil {
+				log.ErrorfWithRequestId(c, "[accounts.AccountBalanceTrendsHandler] failed to get latest exchange rates for user \"uid:%d\", because %s", uid, err.Error())
+				return nil, errs.Or(err, errs.ErrFailedToRequestRemoteApi)
+			}
+		}
+
+		totalAmountsByCurrency := totalAssetsByCurrency
+
+		if account.IsLiability() {
+			totalAmountsByCurrency = totalLiabilitiesByCurrency
+		}
+
+		totalAmounts, exists := totalAmountsByCurrency[account.Currency]
+
+		if !exists {
+			totalAmounts = make([]int64, len(dataPointTimes))
+			totalAmountsByCurrency[account.Currency] = totalAmounts
+		}
+
+		for j := 0; j < len(balances); j++ {
+			totalAmounts[j] += balances[j]
+		}
+
+		balanceTrendsResp.Accounts = append(balanceTrendsResp.Accounts, &models.AccountBalanceTrendsAccountResponse{
+			AccountId: account.AccountId,
+			Currency:  account.Currency,
+			Balances:  balances,
+		})
+	}
+
+	totalAssets, err := a.getTotalAmountsInDefaultCurrency(totalAssetsByCurrency, len(dataPointTimes), user.DefaultCurrency, exchangeRates)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[accounts.AccountBalanceTrendsHandler] failed to convert total assets for user \"uid:%d\", because %s", uid, err.Error())
+		return nil, errs.Or(err, errs.ErrOperationFailed)
+	}
+
+	totalLiabilities, err := a.getTotalAmountsInDefaultCurrency(totalLiabilitiesByCurrency, len(dataPointTimes), user.DefaultCurrency, exchangeRates)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[accounts.AccountBalanceTrendsHandler] failed to convert total liabilities for user \"uid:%d\", because %s", uid, err.Error())
+		return nil, errs.Or(err, errs.ErrOperationFailed)
+	}
+
+	for i := 0; i < len(dataPointTimes); i++ {
+		balanceTrendsResp.DataPoints[i] = &models.AccountBalanceTrendsDataPointResponse{
+			Time:             dataPointTimes[i],
+			TotalAssets:      totalAssets[i],
+			TotalLiabilities: totalLiabilities[i],
+			NetWorth:         totalAssets[i] + totalLiabilities[i],
+		}
+	}
+
+	return balanceTrendsResp, nil
+}
+
+func (a *AccountsApi) getBalanceTrendsDataPointTimes(startTime int64, endTime int64, intervalType models.AccountBalanceTrendsIntervalType, firstDayOfWeek models.WeekDay, utcOffset int16) []int64 {
+	timezone := time.FixedZone("Client Timezone", int(utcOffset)*60)
+	startDateTime := time.Unix(startTime, 0).In(timezone)
+	periodStartTime := time.Date(startDateTime.Year(), startDateTime.Month(), startDateTime.Day(), 0, 0, 0, 0, timezone)
+
+	if intervalType == models.ACCOUNT_BALANCE_TRENDS_INTERVAL_WEEK {
+		periodStartTime = periodStartTime.AddDate(0, 0, -((int(periodStartTime.Weekday()) - int(firstDayOfWeek) + 7) % 7))
+	} else if intervalType == models.ACCOUNT_BALANCE_TRENDS_INTERVAL_MONTH {
+		periodStartTime = periodStartTime.AddDate(0, 0, 1-periodStartTime.Day())
+	}
+
+	var dataPointTimes []int64
+
+	for len(dataPointTimes) <= models.MaxAccountBalanceTrendsDataPointCount {
+		if intervalType == models.ACCOUNT_BALANCE_TRENDS_INTERVAL_WEEK {
+			periodStartTime = periodStartTime.AddDate(0, 0, 7)
+		} else if intervalType == models.ACCOUNT_BALANCE_TRENDS_INTERVAL_MONTH {
+			periodStartTime = periodStartTime.AddDate(0, 1, 0)
+		} else {
+			periodStartTime = periodStartTime.AddDate(0, 0, 1)
+		}
+
+		periodEndTime := periodStartTime.Unix() - 1
+
+		if periodEndTime >= endTime {
+			dataPointTimes = append(dataPointTimes, endTime)
+			break
+		}
+
+		dataPointTimes = append(dataPointTimes, periodEndTime)
+	}
+
+	return dataPointTimes
+}
+
+func (a *AccountsApi) getTotalAmountsInDefaultCurrency(totalAmountsByCurrency map[string][]int64, dataPointCount int, defaultCurrency string, exchangeRates *models.LatestExchangeRateResponse) ([]int64, error) {
+	totalAmounts := make([]int64, dataPointCount)
+
+	for currency, amounts := range totalAmountsByCurrency {
+		for i := 0; i < len(amounts); i++ {
+			if amounts[i] == 0 {
+				continue
+			}
+
+			if currency == defaultCurrency {
+				totalAmounts[i] += amounts[i]
+				continue
+			}
+
+			amount, err := exchangeRates.ExchangeAmount(amounts[i], currency, defaultCurrency)
+
+			if err != nil {
+				return nil, err
+			}
+
+			totalAmounts[i] += amount
+		}
+	}
+
+	return totalAmounts, nil
+}
+
 func (a *AccountsApi) createNewAccountModel(uid int64, accountCreateReq *models.AccountCreateRequest, order int) *models.Account {
 	return &models.Account{
-		Uid:          uid,
-		Name:         accountCreateReq.Name,
-		DisplayOrder: order,
-		Category:     accountCreateReq.Category,
-		Type:         accountCreateReq.Type,
-		Icon:         accountCreateReq.Icon,
-		Color:        accountCreateReq.Color,
-		Currency:     accountCreateReq.Currency,
-		Balance:      accountCreateReq.Balance,
-		Comment:      accountCreateReq.Comment,
+		Uid:           uid,
+		Name:          accountCreateReq.Name,
+		DisplayOrder:  order,
+		Category:      accountCreateReq.Category,
+		Type:          accountCreateReq.Type,
+		Icon:          accountCreateReq.Icon,
+		Color:         accountCreateReq.Color,
+		Currency:      accountCreateReq.Currency,
+		Balance:       accountCreateReq.Balance,
+		Comment:       accountCreateReq.Comment,
+		StatementDay:  accountCreateReq.StatementDay,
+		PaymentDueDay: accountCreateReq.PaymentDueDay,
+		CreditLimit:   accountCreateReq.CreditLimit,
 	}
 }
 
@@ -434,14 +1003,17 @@ func (a *AccountsApi) createSubAccountModels(uid int64, accountCreateReq *models
 
 func (a *AccountsApi) getToUpdateAccount(uid int64, accountModifyReq *models.AccountModifyRequest, oldAccount *models.Account) *models.Account {
 	newAccount := &models.Account{
-		AccountId: oldAccount.AccountId,
-		Uid:       uid,
-		Name:      accountModifyReq.Name,
-		Category:  accountModifyReq.Category,
-		Icon:      accountModifyReq.Icon,
-		Color:     accountModifyReq.Color,
-		Comment:   accountModifyReq.Comment,
-		Hidden:    accountModifyReq.Hidden,
+		AccountId:     oldAccount.AccountId,
+		Uid:           uid,
+		Name:          accountModifyReq.Name,
+		Category:      accountModifyReq.Category,
+		Icon:          accountModifyReq.Icon,
+		Color:         accountModifyReq.Color,
+		Comment:       accountModifyReq.Comment,
+		Hidden:        accountModifyReq.Hidden,
+		StatementDay:  accountModifyReq.StatementDay,
+		PaymentDueDay: accountModifyReq.PaymentDueDay,
+		CreditLimit:   accountModifyReq.CreditLimit,
 	}
 
 	if newAccount.Name != oldAccount.Name ||
@@ -449,9 +1021,20 @@ func (a *AccountsApi) getToUpdateAccount(uid int64, accountModifyReq *models.Acc
 		newAccount.Icon != oldAccount.Icon ||
 		newAccount.Color != oldAccount.Color ||
 		newAccount.Comment != oldAccount.Comment ||
-		newAccount.Hidden != oldAccount.Hidden {
+		newAccount.Hidden != oldAccount.Hidden ||
+		newAccount.StatementDay != oldAccount.StatementDay ||
+		newAccount.PaymentDueDay != oldAccount.PaymentDueDay ||
+		newAccount.CreditLimit != oldAccount.CreditLimit {
 		return newAccount
 	}
 
 	return nil
 }
+
+func (a *AccountsApi) isCreditCardSettingsValid(accountModifyReq *models.AccountModifyRequest, oldAccount *models.Account) bool {
+	if accountModifyReq.StatementDay == 0 && accountModifyReq.PaymentDueDay == 0 && accountModifyReq.CreditLimit == 0 {
+		return true
+	}
+
+	return oldAccount.Type == models.ACCOUNT_TYPE_SINGLE_ACCOUNT && accountModifyReq.Category == models.ACCOUNT_CATEGORY_CREDIT_CARD
+}
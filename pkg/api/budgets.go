@@ -0,0 +1,302 @@
+package api
+
+import (
+	"time"
+
+	"github.com/mayswind/ezbookkeeping/pkg/core"
+	"github.com/mayswind/ezbookkeeping/pkg/errs"
+	"github.com/mayswind/ezbookkeeping/pkg/exchangerates"
+	"github.com/mayswind/ezbookkeeping/pkg/log"
+	"github.com/mayswind/ezbookkeeping/pkg/models"
+	"github.com/mayswind/ezbookkeeping/pkg/services"
+	"github.com/mayswind/ezbookkeeping/pkg/settings"
+)
+
+// BudgetsApi represents budget api
+type BudgetsApi struct {
+	budgets      *services.BudgetService
+	accounts     *services.AccountService
+	categories   *services.TransactionCategoryService
+	transactions *services.TransactionService
+	users        *services.UserService
+}
+
+// Initialize a budget api singleton instance
+var (
+	Budgets = &BudgetsApi{
+		budgets:      services.Budgets,
+		accounts:     services.Accounts,
+		categories:   services.TransactionCategories,
+		transactions: services.Transactions,
+		users:        services.Users,
+	}
+)
+
+// BudgetListHandler returns budget list of current user in specified month
+func (a *BudgetsApi) BudgetListHandler(c *core.Context) (interface{}, *errs.Error) {
+	var budgetListReq models.BudgetListRequest
+	err := c.ShouldBindQuery(&budgetListReq)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[budgets.BudgetListHandler] parse request failed, because %s", err.Error())
+		return nil, errs.NewIncompleteOrIncorrectSubmissionError(err)
+	}
+
+	uid := c.GetCurrentUid()
+	budgets, err := a.budgets.GetBudgetsByMonth(uid, models.GetBudgetMonth(budgetListReq.Year, budgetListReq.Month))
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[budgets.BudgetListHandler] failed to get budgets for user \"uid:%d\", because %s", uid, err.Error())
+		return nil, errs.ErrOperationFailed
+	}
+
+	budgetResps := make([]*models.BudgetInfoResponse, len(budgets))
+
+	for i := 0; i < len(budgets); i++ {
+		budgetResps[i] = budgets[i].ToBudgetInfoResponse()
+	}
+
+	return budgetResps, nil
+}
+
+// BudgetCreateHandler saves a new budget by request parameters for current user
+func (a *BudgetsApi) BudgetCreateHandler(c *core.Context) (interface{}, *errs.Error) {
+	var budgetCreateReq models.BudgetCreateRequest
+	err := c.ShouldBindJSON(&budgetCreateReq)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[budgets.BudgetCreateHandler] parse request failed, because %s", err.Error())
+		return nil, errs.NewIncompleteOrIncorrectSubmissionError(err)
+	}
+
+	uid := c.GetCurrentUid()
+
+	budget := &models.Budget{
+		Uid:        uid,
+		Month:      models.GetBudgetMonth(budgetCreateReq.Year, budgetCreateReq.Month),
+		CategoryId: budgetCreateReq.CategoryId,
+		Amount:     budgetCreateReq.Amount,
+		Rollover:   budgetCreateReq.Rollover,
+	}
+
+	err = a.budgets.CreateBudget(budget)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[budgets.BudgetCreateHandler] failed to create budget \"id:%d\" for user \"uid:%d\", because %s", budget.BudgetId, uid, err.Error())
+		return nil, errs.Or(err, errs.ErrOperationFailed)
+	}
+
+	log.InfofWithRequestId(c, "[budgets.BudgetCreateHandler] user \"uid:%d\" has created a new budget \"id:%d\" successfully", uid, budget.BudgetId)
+
+	return budget.ToBudgetInfoResponse(), nil
+}
+
+// BudgetModifyHandler saves an existed budget by request parameters for current user
+func (a *BudgetsApi) BudgetModifyHandler(c *core.Context) (interface{}, *errs.Error) {
+	var budgetModifyReq models.BudgetModifyRequest
+	err := c.ShouldBindJSON(&budgetModifyReq)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[budgets.BudgetModifyHandler] parse request failed, because %s", err.Error())
+		return nil, errs.NewIncompleteOrIncorrectSubmissionError(err)
+	}
+
+	uid := c.GetCurrentUid()
+	budget, err := a.budgets.GetBudgetByBudgetId(uid, budgetModifyReq.Id)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[budgets.BudgetModifyHandler] failed to get budget \"id:%d\" for user \"uid:%d\", because %s", budgetModifyReq.Id, uid, err.Error())
+		return nil, errs.Or(err, errs.ErrOperationFailed)
+	}
+
+	if budget.Amount == budgetModifyReq.Amount && budget.Rollover == budgetModifyReq.Rollover {
+		return nil, errs.ErrNothingWillBeUpdated
+	}
+
+	budget.Amount = budgetModifyReq.Amount
+	budget.Rollover = budgetModifyReq.Rollover
+
+	err = a.budgets.ModifyBudget(budget)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[budgets.BudgetModifyHandler] failed to update budget \"id:%d\" for user \"uid:%d\", because %s", budgetModifyReq.Id, uid, err.Error())
+		return nil, errs.Or(err, errs.ErrOperationFailed)
+	}
+
+	log.InfofWithRequestId(c, "[budgets.BudgetModifyHandler] user \"uid:%d\" has updated budget \"id:%d\" successfully", uid, budgetModifyReq.Id)
+
+	return budget.ToBudgetInfoResponse(), nil
+}
+
+// BudgetDeleteHandler deletes an existed budget by request parameters for current user
+func (a *BudgetsApi) BudgetDeleteHandler(c *core.Context) (interface{}, *errs.Error) {
+	var budgetDeleteReq models.BudgetDeleteRequest
+	err := c.ShouldBindJSON(&budgetDeleteReq)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[budgets.BudgetDeleteHandler] parse request failed, because %s", err.Error())
+		return nil, errs.NewIncompleteOrIncorrectSubmissionError(err)
+	}
+
+	uid := c.GetCurrentUid()
+	err = a.budgets.DeleteBudget(uid, budgetDeleteReq.Id)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[budgets.BudgetDeleteHandler] failed to delete budget \"id:%d\" for user \"uid:%d\", because %s", budgetDeleteReq.Id, uid, err.Error())
+		return nil, errs.Or(err, errs.ErrOperationFailed)
+	}
+
+	log.InfofWithRequestId(c, "[budgets.BudgetDeleteHandler] user \"uid:%d\" has deleted budget \"id:%d\"", uid, budgetDeleteReq.Id)
+	return true, nil
+}
+
+// BudgetStatusHandler returns the planned, spent and remaining amounts in default currency of every budget in specified month for current user
+func (a *BudgetsApi) BudgetStatusHandler(c *core.Context) (interface{}, *errs.Error) {
+	var budgetStatusReq models.BudgetStatusRequest
+	err := c.ShouldBindQuery(&budgetStatusReq)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[budgets.BudgetStatusHandler] parse request failed, because %s", err.Error())
+		return nil, errs.NewIncompleteOrIncorrectSubmissionError(err)
+	}
+
+	utcOffset, err := c.GetClientTimezoneOffset()
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[budgets.BudgetStatusHandler] cannot get client timezone offset, because %s", err.Error())
+		return nil, errs.ErrClientTimezoneOffsetInvalid
+	}
+
+	uid := c.GetCurrentUid()
+	user, err := a.users.GetUserById(uid)
+
+	if err != nil {
+		if !errs.IsCustomError(err) {
+			log.ErrorfWithRequestId(c, "[budgets.BudgetStatusHandler] failed to get user, because %s", err.Error())
+		}
+
+		return nil, errs.ErrUserNotFound
+	}
+
+	month := models.GetBudgetMonth(budgetStatusReq.Year, budgetStatusReq.Month)
+	budgets, err := a.budgets.GetBudgetsByMonth(uid, month)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[budgets.BudgetStatusHandler] failed to get budgets for user \"uid:%d\", because %s", uid, err.Error())
+		return nil, errs.ErrOperationFailed
+	}
+
+	budgetStatusResp := &models.BudgetStatusResponse{
+		Year:     budgetStatusReq.Year,
+		Month:    budgetStatusReq.Month,
+		Currency: user.DefaultCurrency,
+		Items:    make([]*models.BudgetStatusItemResponse, len(budgets)),
+	}
+
+	if len(budgets) < 1 {
+		return budgetStatusResp, nil
+	}
+
+	// Find the consecutive previous budgets which roll their remaining amounts into the next month
+	rolloverBudgets, err := a.budgets.GetRolloverBudgets(uid, budgets)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[budgets.BudgetStatusHandler] failed to get previous budgets for user \"uid:%d\", because %s", uid, err.Error())
+		return nil, errs.ErrOperationFailed
+	}
+
+	months := make(map[int]bool)
+	months[month] = true
+
+	for i := 0; i < len(rolloverBudgets); i++ {
+		for j := 0; j < len(rolloverBudgets[i]); j++ {
+			months[rolloverBudgets[i][j].Month] = true
+		}
+	}
+
+	// Calculate the spent amount of every category in every month
+	accounts, err := a.accounts.GetAllAccountsByUid(uid)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[budgets.BudgetStatusHandler] failed to get accounts for user \"uid:%d\", because %s", uid, err.Error())
+		return nil, errs.ErrOperationFailed
+	}
+
+	categories, err := a.categories.GetAllCategoriesByUid(uid, models.CATEGORY_TYPE_EXPENSE, -1)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[budgets.BudgetStatusHandler] failed to get categories for user \"uid:%d\", because %s", uid, err.Error())
+		return nil, errs.ErrOperationFailed
+	}
+
+	accountMap := a.accounts.GetAccountMapByList(accounts)
+	categoryMap := a.categories.GetCategoryMapByList(categories)
+	timezone := time.FixedZone("Client Timezone", int(utcOffset)*60)
+	spentAmounts := make(map[int]map[int64]int64, len(months))
+	var exchangeRates *models.LatestExchangeRateResponse
+
+	for budgetMonth := range months {
+		startTime := time.Date(budgetMonth/100, time.Month(budgetMonth%100), 1, 0, 0, 0, 0, timezone)
+		endTime := startTime.AddDate(0, 1, 0)
+		totalAmounts, err := a.transactions.GetAccountsAndCategoriesTotalIncomeAndExpense(uid, startTime.Unix(), endTime.Unix()-1, nil, false)
+
+		if err != nil {
+			log.ErrorfWithRequestId(c, "[budgets.BudgetStatusHandler] failed to get categories total expense for user \"uid:%d\", because %s", uid, err.Error())
+			return nil, errs.ErrOperationFailed
+		}
+
+		categorySpentAmounts := make(map[int64]int64)
+
+		for i := 0; i < len(totalAmounts); i++ {
+			totalAmount := totalAmounts[i]
+			category, exists := categoryMap[totalAmount.CategoryId]
+
+			if !exists || totalAmount.Amount == 0 {
+				continue
+			}
+
+			account, exists := accountMap[totalAmount.AccountId]
+
+			if !exists {
+				continue
+			}
+
+			amount := totalAmount.Amount
+
+			if account.Currency != user.DefaultCurrency {
+				if exchangeRates == nil {
+					exchangeRates, err = exchangerates.Container.GetLatestExchangeRates(c, uid, settings.Container.Current)
+
+					if err != nil {
+						log.ErrorfWithRequestId(c, "[budgets.BudgetStatusHandler] failed to get latest exchange rates for user \"uid:%d\", because %s", uid, err.Error())
+						return nil, errs.Or(err, errs.ErrOperationFailed)
+					}
+				}
+
+				amount, err = exchangeRates.ExchangeAmount(amount, account.Currency, user.DefaultCurrency)
+
+				if err != nil {
+					log.WarnfWithRequestId(c, "[budgets.BudgetStatusHandler] cannot exchange amount from \"%s\" to \"%s\" for user \"uid:%d\", because %s", account.Currency, user.DefaultCurrency, uid, err.Error())
+					return nil, errs.Or(err, errs.ErrOperationFailed)
+				}
+			}
+
+			categorySpentAmounts[category.CategoryId] += amount
+
+			if category.ParentCategoryId != models.LevelOneTransactionParentId {
+				categorySpentAmounts[category.ParentCategoryId] += amount
+			}
+		}
+
+		spentAmounts[budgetMonth] = categorySpentAmounts
+	}
+
+	// Roll the remaining amounts of previous budgets into current month
+	for i := 0; i < len(budgets); i++ {
+		budget := budgets[i]
+		rolloverAmount := a.budgets.GetRolloverAmount(rolloverBudgets[i], spentAmounts)
+		budgetStatusResp.Items[i] = budget.ToBudgetStatusItemResponse(rolloverAmount, spentAmounts[month][budget.CategoryId])
+	}
+
+	return budgetStatusResp, nil
+}
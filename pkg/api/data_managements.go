@@ -26,6 +26,13 @@ type DataManagementsApi struct {
 	transactions *services.TransactionService
 	categories   *services.TransactionCategoryService
 	tags         *services.TransactionTagService
+	payees       *services.TransactionPayeeService
+	rules        *services.TransactionRuleService
+	investments  *services.InvestmentService
+	budgets      *services.BudgetService
+	savingsGoals *services.SavingsGoalService
+	splits       *services.TransactionSplitService
+	templates    *services.TransactionTemplateService
 }
 
 // Initialize a data management api singleton instance
@@ -38,6 +45,13 @@ var (
 		transactions: services.Transactions,
 		categories:   services.TransactionCategories,
 		tags:         services.TransactionTags,
+		payees:       services.TransactionPayees,
+		rules:        services.TransactionRules,
+		investments:  services.Investments,
+		budgets:      services.Budgets,
+		savingsGoals: services.SavingsGoals,
+		splits:       services.TransactionSplits,
+		templates:    services.TransactionTemplates,
 	}
 )
 
@@ -95,6 +109,13 @@ func (a *DataManagementsApi) ExportDataHandler(c *core.Context) ([]byte, string,
 		return nil, "", errs.ErrOperationFailed
 	}
 
+	splits, err := a.splits.GetAllSplitsOfAllTransactions(uid)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[data_managements.ExportDataHandler] failed to get transaction splits for user \"uid:%d\", because %s", uid, err.Error())
+		return nil, "", errs.ErrOperationFailed
+	}
+
 	accountMap := a.accounts.GetAccountMapByList(accounts)
 	categoryMap := a.categories.GetCategoryMapByList(categories)
 	tagMap := a.tags.GetTagMapByList(tags)
@@ -106,7 +127,7 @@ func (a *DataManagementsApi) ExportDataHandler(c *core.Context) ([]byte, string,
 		return nil, "", errs.ErrOperationFailed
 	}
 
-	result, err := a.exporter.ToExportedContent(uid, timezone, allTransactions, accountMap, categoryMap, tagMap, tagIndexs)
+	result, err := a.exporter.ToExportedContent(uid, timezone, allTransactions, accountMap, categoryMap, tagMap, tagIndexs, splits)
 
 	if err != nil {
 		log.ErrorfWithRequestId(c, "[data_managements.ExportDataHandler] failed to get csv format exported data for \"uid:%d\", because %s", uid, err.Error())
@@ -143,13 +164,20 @@ func (a *DataManagementsApi) ClearDataHandler(c *core.Context) (interface{}, *er
 		return nil, errs.ErrUserPasswordWrong
 	}
 
-	err = a.transactions.DeleteAllTransactions(uid)
+	err = a.transactions.DeleteAllTransactions(uid, models.NewTransactionOperator(c))
 
 	if err != nil {
 		log.ErrorfWithRequestId(c, "[data_managements.ClearDataHandler] failed to delete all transactions, because %s", err.Error())
 		return nil, errs.ErrOperationFailed
 	}
 
+	err = a.templates.DeleteAllTemplates(uid)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[data_managements.ClearDataHandler] failed to delete all transaction templates, because %s", err.Error())
+		return nil, errs.ErrOperationFailed
+	}
+
 	err = a.categories.DeleteAllCategories(uid)
 
 	if err != nil {
@@ -164,6 +192,41 @@ func (a *DataManagementsApi) ClearDataHandler(c *core.Context) (interface{}, *er
 		return nil, errs.ErrOperationFailed
 	}
 
+	err = a.payees.DeleteAllPayees(uid)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[data_managements.ClearDataHandler] failed to delete all transaction payees, because %s", err.Error())
+		return nil, errs.ErrOperationFailed
+	}
+
+	err = a.rules.DeleteAllRules(uid)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[data_managements.ClearDataHandler] failed to delete all transaction rules, because %s", err.Error())
+		return nil, errs.ErrOperationFailed
+	}
+
+	err = a.investments.DeleteAllInvestmentData(uid)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[data_managements.ClearDataHandler] failed to delete all investment data, because %s", err.Error())
+		return nil, errs.ErrOperationFailed
+	}
+
+	err = a.budgets.DeleteAllBudgets(uid)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[data_managements.ClearDataHandler] failed to delete all budgets, because %s", err.Error())
+		return nil, errs.ErrOperationFailed
+	}
+
+	err = a.savingsGoals.DeleteAllGoals(uid)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[data_managements.ClearDataHandler] failed to delete all savings goals, because %s", err.Error())
+		return nil, errs.ErrOperationFailed
+	}
+
 	log.InfofWithRequestId(c, "[data_managements.ClearDataHandler] user \"uid:%d\" has cleared all data", uid)
 	return true, nil
 }
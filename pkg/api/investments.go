@@ -0,0 +1,652 @@
+package api
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/mayswind/ezbookkeeping/pkg/core"
+	"github.com/mayswind/ezbookkeeping/pkg/errs"
+	"github.com/mayswind/ezbookkeeping/pkg/log"
+	"github.com/mayswind/ezbookkeeping/pkg/models"
+	"github.com/mayswind/ezbookkeeping/pkg/securityprices"
+	"github.com/mayswind/ezbookkeeping/pkg/services"
+	"github.com/mayswind/ezbookkeeping/pkg/utils"
+)
+
+const maxSecurityPriceFileSize = 1048576 // 1MB
+
+// InvestmentsApi represents investment api
+type InvestmentsApi struct {
+	investments  *services.InvestmentService
+	accounts     *services.AccountService
+	transactions *services.TransactionService
+}
+
+// Initialize an investment api singleton instance
+var (
+	Investments = &InvestmentsApi{
+		investments:  services.Investments,
+		accounts:     services.Accounts,
+		transactions: services.Transactions,
+	}
+)
+
+// SecurityListHandler returns investment security list of current user
+func (a *InvestmentsApi) SecurityListHandler(c *core.Context) (interface{}, *errs.Error) {
+	uid := c.GetCurrentUid()
+	securities, err := a.investments.GetAllSecuritiesByUid(uid)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[investments.SecurityListHandler] failed to get securities for user \"uid:%d\", because %s", uid, err.Error())
+		return nil, errs.ErrOperationFailed
+	}
+
+	securityResps := make(models.InvestmentSecurityInfoResponseSlice, len(securities))
+
+	for i := 0; i < len(securities); i++ {
+		securityResps[i] = securities[i].ToInvestmentSecurityInfoResponse()
+	}
+
+	sort.Sort(securityResps)
+
+	return securityResps, nil
+}
+
+// SecurityGetHandler returns one specific investment security of current user
+func (a *InvestmentsApi) SecurityGetHandler(c *core.Context) (interface{}, *errs.Error) {
+	var securityGetReq models.InvestmentSecurityGetRequest
+	err := c.ShouldBindQuery(&securityGetReq)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[investments.SecurityGetHandler] parse request failed, because %s", err.Error())
+		return nil, errs.NewIncompleteOrIncorrectSubmissionError(err)
+	}
+
+	uid := c.GetCurrentUid()
+	security, err := a.investments.GetSecurityBySecurityId(uid, securityGetReq.Id)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[investments.SecurityGetHandler] failed to get security \"id:%d\" for user \"uid:%d\", because %s", securityGetReq.Id, uid, err.Error())
+		return nil, errs.Or(err, errs.ErrOperationFailed)
+	}
+
+	return security.ToInvestmentSecurityInfoResponse(), nil
+}
+
+// SecurityCreateHandler saves a new investment security by request parameters for current user
+func (a *InvestmentsApi) SecurityCreateHandler(c *core.Context) (interface{}, *errs.Error) {
+	var securityCreateReq models.InvestmentSecurityCreateRequest
+	err := c.ShouldBindJSON(&securityCreateReq)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[investments.SecurityCreateHandler] parse request failed, because %s", err.Error())
+		return nil, errs.NewIncompleteOrIncorrectSubmissionError(err)
+	}
+
+	uid := c.GetCurrentUid()
+	security := &models.InvestmentSecurity{
+		Uid:      uid,
+		Symbol:   securityCreateReq.Symbol,
+		Name:     securityCreateReq.Name,
+		Currency: securityCreateReq.Currency,
+	}
+
+	err = a.investments.CreateSecurity(security)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[investments.SecurityCreateHandler] failed to create security \"id:%d\" for user \"uid:%d\", because %s", security.SecurityId, uid, err.Error())
+		return nil, errs.Or(err, errs.ErrOperationFailed)
+	}
+
+	log.InfofWithRequestId(c, "[investments.SecurityCreateHandler] user \"uid:%d\" has created a new security \"id:%d\" successfully", uid, security.SecurityId)
+
+	return security.ToInvestmentSecurityInfoResponse(), nil
+}
+
+// SecurityModifyHandler saves an existed investment security by request parameters for current user
+func (a *InvestmentsApi) SecurityModifyHandler(c *core.Context) (interface{}, *errs.Error) {
+	var securityModifyReq models.InvestmentSecurityModifyRequest
+	err := c.ShouldBindJSON(&securityModifyReq)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[investments.SecurityModifyHandler] parse request failed, because %s", err.Error())
+		return nil, errs.NewIncompleteOrIncorrectSubmissionError(err)
+	}
+
+	uid := c.GetCurrentUid()
+	security, err := a.investments.GetSecurityBySecurityId(uid, securityModifyReq.Id)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[investments.SecurityModifyHandler] failed to get security \"id:%d\" for user \"uid:%d\", because %s", securityModifyReq.Id, uid, err.Error())
+		return nil, errs.Or(err, errs.ErrOperationFailed)
+	}
+
+	newSecurity := &models.InvestmentSecurity{
+		SecurityId: security.SecurityId,
+		Uid:        uid,
+		Symbol:     securityModifyReq.Symbol,
+		Name:       securityModifyReq.Name,
+		Currency:   securityModifyReq.Currency,
+	}
+
+	if newSecurity.Symbol == security.Symbol && newSecurity.Name == security.Name && newSecurity.Currency == security.Currency {
+		return nil, errs.ErrNothingWillBeUpdated
+	}
+
+	err = a.investments.ModifySecurity(newSecurity, newSecurity.Symbol != security.Symbol, newSecurity.Currency != security.Currency)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[investments.SecurityModifyHandler] failed to update security \"id:%d\" for user \"uid:%d\", because %s", securityModifyReq.Id, uid, err.Error())
+		return nil, errs.Or(err, errs.ErrOperationFailed)
+	}
+
+	log.InfofWithRequestId(c, "[investments.SecurityModifyHandler] user \"uid:%d\" has updated security \"id:%d\" successfully", uid, securityModifyReq.Id)
+
+	return newSecurity.ToInvestmentSecurityInfoResponse(), nil
+}
+
+// SecurityDeleteHandler deletes an existed investment security by request parameters for current user
+func (a *InvestmentsApi) SecurityDeleteHandler(c *core.Context) (interface{}, *errs.Error) {
+	var securityDeleteReq models.InvestmentSecurityDeleteRequest
+	err := c.ShouldBindJSON(&securityDeleteReq)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[investments.SecurityDeleteHandler] parse request failed, because %s", err.Error())
+		return nil, errs.NewIncompleteOrIncorrectSubmissionError(err)
+	}
+
+	uid := c.GetCurrentUid()
+	err = a.investments.DeleteSecurity(uid, securityDeleteReq.Id)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[investments.SecurityDeleteHandler] failed to delete security \"id:%d\" for user \"uid:%d\", because %s", securityDeleteReq.Id, uid, err.Error())
+		return nil, errs.Or(err, errs.ErrOperationFailed)
+	}
+
+	log.InfofWithRequestId(c, "[investments.SecurityDeleteHandler] user \"uid:%d\" has deleted security \"id:%d\"", uid, securityDeleteReq.Id)
+	return true, nil
+}
+
+// PriceListHandler returns the latest prices of one specific investment security of current user
+func (a *InvestmentsApi) PriceListHandler(c *core.Context) (interface{}, *errs.Error) {
+	var priceListReq models.InvestmentSecurityPriceListRequest
+	err := c.ShouldBindQuery(&priceListReq)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[investments.PriceListHandler] parse request failed, because %s", err.Error())
+		return nil, errs.NewIncompleteOrIncorrectSubmissionError(err)
+	}
+
+	uid := c.GetCurrentUid()
+	prices, err := a.investments.GetPricesBySecurityId(uid, priceListReq.SecurityId, priceListReq.Count)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[investments.PriceListHandler] failed to get prices of security \"id:%d\" for user \"uid:%d\", because %s", priceListReq.SecurityId, uid, err.Error())
+		return nil, errs.Or(err, errs.ErrOperationFailed)
+	}
+
+	priceResps := make([]*models.InvestmentSecurityPriceInfoResponse, len(prices))
+
+	for i := 0; i < len(prices); i++ {
+		priceResps[i] = prices[i].ToInvestmentSecurityPriceInfoResponse()
+	}
+
+	return priceResps, nil
+}
+
+// PriceCreateHandler saves a new investment security price by request parameters for current user
+func (a *InvestmentsApi) PriceCreateHandler(c *core.Context) (interface{}, *errs.Error) {
+	var priceCreateReq models.InvestmentSecurityPriceCreateRequest
+	err := c.ShouldBindJSON(&priceCreateReq)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[investments.PriceCreateHandler] parse request failed, because %s", err.Error())
+		return nil, errs.NewIncompleteOrIncorrectSubmissionError(err)
+	}
+
+	uid := c.GetCurrentUid()
+	price := &models.InvestmentSecurityPrice{
+		SecurityId:    priceCreateReq.SecurityId,
+		PriceUnixTime: priceCreateReq.Time,
+		Price:         priceCreateReq.Price,
+	}
+
+	err = a.investments.SavePrices(uid, []*models.InvestmentSecurityPrice{price})
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[investments.PriceCreateHandler] failed to save price of security \"id:%d\" for user \"uid:%d\", because %s", priceCreateReq.SecurityId, uid, err.Error())
+		return nil, errs.Or(err, errs.ErrOperationFailed)
+	}
+
+	log.InfofWithRequestId(c, "[investments.PriceCreateHandler] user \"uid:%d\" has saved a new price \"id:%d\" of security \"id:%d\" successfully", uid, price.PriceId, price.SecurityId)
+
+	return price.ToInvestmentSecurityPriceInfoResponse(), nil
+}
+
+// PriceImportHandler saves the investment security prices in uploaded csv file (symbol, date and price in every line) for current user
+func (a *InvestmentsApi) PriceImportHandler(c *core.Context) (interface{}, *errs.Error) {
+	utcOffset, err := c.GetClientTimezoneOffset()
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[investments.PriceImportHandler] cannot get client timezone offset, because %s", err.Error())
+		return nil, errs.ErrClientTimezoneOffsetInvalid
+	}
+
+	fileHeader, err := c.FormFile("file")
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[investments.PriceImportHandler] failed to get uploaded file, because %s", err.Error())
+		return nil, errs.ErrInvestmentSecurityPriceFileIsEmpty
+	}
+
+	if fileHeader.Size < 1 {
+		return nil, errs.ErrInvestmentSecurityPriceFileIsEmpty
+	}
+
+	if fileHeader.Size > maxSecurityPriceFileSize {
+		return nil, errs.ErrInvestmentSecurityPriceFileInvalid
+	}
+
+	uid := c.GetCurrentUid()
+	file, err := fileHeader.Open()
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[investments.PriceImportHandler] failed to open uploaded file for user \"uid:%d\", because %s", uid, err.Error())
+		return nil, errs.ErrOperationFailed
+	}
+
+	defer file.Close()
+
+	securities, err := a.investments.GetAllSecuritiesByUid(uid)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[investments.PriceImportHandler] failed to get securities for user \"uid:%d\", because %s", uid, err.Error())
+		return nil, errs.ErrOperationFailed
+	}
+
+	securityIdsBySymbol := make(map[string]int64, len(securities))
+
+	for i := 0; i < len(securities); i++ {
+		securityIdsBySymbol[securities[i].Symbol] = securities[i].SecurityId
+	}
+
+	prices, err := a.parsePriceCsvFile(file, securityIdsBySymbol, utcOffset)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[investments.PriceImportHandler] failed to parse uploaded file for user \"uid:%d\", because %s", uid, err.Error())
+		return nil, errs.Or(err, errs.ErrInvestmentSecurityPriceFileInvalid)
+	}
+
+	if len(prices) < 1 {
+		return nil, errs.ErrInvestmentSecurityPriceFileIsEmpty
+	}
+
+	err = a.investments.SavePrices(uid, prices)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[investments.PriceImportHandler] failed to save prices for user \"uid:%d\", because %s", uid, err.Error())
+		return nil, errs.Or(err, errs.ErrOperationFailed)
+	}
+
+	log.InfofWithRequestId(c, "[investments.PriceImportHandler] user \"uid:%d\" has imported %d prices successfully", uid, len(prices))
+
+	return &models.InvestmentSecurityPriceImportResponse{
+		ImportedCount: len(prices),
+	}, nil
+}
+
+// PriceUpdateHandler saves the latest prices of all investment securities from the price data source for current user
+func (a *InvestmentsApi) PriceUpdateHandler(c *core.Context) (interface{}, *errs.Error) {
+	uid := c.GetCurrentUid()
+	securities, err := a.investments.GetAllSecuritiesByUid(uid)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[investments.PriceUpdateHandler] failed to get securities for user \"uid:%d\", because %s", uid, err.Error())
+		return nil, errs.ErrOperationFailed
+	}
+
+	if len(securities) < 1 {
+		return &models.InvestmentSecurityPriceImportResponse{}, nil
+	}
+
+	prices, err := securityprices.Container.GetLatestPrices(c, securities)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[investments.PriceUpdateHandler] failed to get latest prices for user \"uid:%d\", because %s", uid, err.Error())
+		return nil, errs.Or(err, errs.ErrFailedToRequestRemoteApi)
+	}
+
+	if len(prices) > 0 {
+		err = a.investments.SavePrices(uid, prices)
+
+		if err != nil {
+			log.ErrorfWithRequestId(c, "[investments.PriceUpdateHandler] failed to save prices for user \"uid:%d\", because %s", uid, err.Error())
+			return nil, errs.Or(err, errs.ErrOperationFailed)
+		}
+	}
+
+	log.InfofWithRequestId(c, "[investments.PriceUpdateHandler] user \"uid:%d\" has updated %d prices successfully", uid, len(prices))
+
+	return &models.InvestmentSecurityPriceImportResponse{
+		ImportedCount: len(prices),
+	}, nil
+}
+
+// PriceDeleteHandler deletes an existed investment security price by request parameters for current user
+func (a *InvestmentsApi) PriceDeleteHandler(c *core.Context) (interface{}, *errs.Error) {
+	var priceDeleteReq models.InvestmentSecurityPriceDeleteRequest
+	err := c.ShouldBindJSON(&priceDeleteReq)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[investments.PriceDeleteHandler] parse request failed, because %s", err.Error())
+		return nil, errs.NewIncompleteOrIncorrectSubmissionError(err)
+	}
+
+	uid := c.GetCurrentUid()
+	err = a.investments.DeletePrice(uid, priceDeleteReq.Id)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[investments.PriceDeleteHandler] failed to delete price \"id:%d\" for user \"uid:%d\", because %s", priceDeleteReq.Id, uid, err.Error())
+		return nil, errs.Or(err, errs.ErrOperationFailed)
+	}
+
+	log.InfofWithRequestId(c, "[investments.PriceDeleteHandler] user \"uid:%d\" has deleted price \"id:%d\"", uid, priceDeleteReq.Id)
+	return true, nil
+}
+
+// InvestmentTransactionListHandler returns investment transaction list of one specific account of current user
+func (a *InvestmentsApi) InvestmentTransactionListHandler(c *core.Context) (interface{}, *errs.Error) {
+	var transactionListReq models.InvestmentTransactionListRequest
+	err := c.ShouldBindQuery(&transactionListReq)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[investments.InvestmentTransactionListHandler] parse request failed, because %s", err.Error())
+		return nil, errs.NewIncompleteOrIncorrectSubmissionError(err)
+	}
+
+	uid := c.GetCurrentUid()
+	transactions, err := a.investments.GetTransactionsByAccountId(uid, transactionListReq.AccountId, transactionListReq.SecurityId)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[investments.InvestmentTransactionListHandler] failed to get investment transactions of account \"id:%d\" for user \"uid:%d\", because %s", transactionListReq.AccountId, uid, err.Error())
+		return nil, errs.Or(err, errs.ErrOperationFailed)
+	}
+
+	transactionResps := make([]*models.InvestmentTransactionInfoResponse, len(transactions))
+
+	for i := 0; i < len(transactions); i++ {
+		transactionResps[i] = transactions[i].ToInvestmentTransactionInfoResponse()
+	}
+
+	return transactionResps, nil
+}
+
+// InvestmentTransactionCreateHandler saves a new investment transaction by request parameters for current user
+func (a *InvestmentsApi) InvestmentTransactionCreateHandler(c *core.Context) (interface{}, *errs.Error) {
+	var transactionCreateReq models.InvestmentTransactionCreateRequest
+	err := c.ShouldBindJSON(&transactionCreateReq)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[investments.InvestmentTransactionCreateHandler] parse request failed, because %s", err.Error())
+		return nil, errs.NewIncompleteOrIncorrectSubmissionError(err)
+	}
+
+	uid := c.GetCurrentUid()
+	transaction := &models.InvestmentTransaction{
+		Uid:           uid,
+		AccountId:     transactionCreateReq.AccountId,
+		SecurityId:    transactionCreateReq.SecurityId,
+		Type:          transactionCreateReq.Type,
+		TradeUnixTime: transactionCreateReq.Time,
+		Quantity:      transactionCreateReq.Quantity,
+		Amount:        transactionCreateReq.Amount,
+		Comment:       transactionCreateReq.Comment,
+	}
+
+	err = a.investments.CreateTransaction(transaction, transactionCreateReq.CategoryId, transactionCreateReq.UtcOffset, models.NewTransactionOperator(c))
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[investments.InvestmentTransactionCreateHandler] failed to create investment transaction \"id:%d\" for user \"uid:%d\", because %s", transaction.TransactionId, uid, err.Error())
+		return nil, errs.Or(err, errs.ErrOperationFailed)
+	}
+
+	log.InfofWithRequestId(c, "[investments.InvestmentTransactionCreateHandler] user \"uid:%d\" has created a new investment transaction \"id:%d\" successfully", uid, transaction.TransactionId)
+
+	return transaction.ToInvestmentTransactionInfoResponse(), nil
+}
+
+// InvestmentTransactionModifyHandler saves an existed investment transaction by request parameters for current user
+func (a *InvestmentsApi) InvestmentTransactionModifyHandler(c *core.Context) (interface{}, *errs.Error) {
+	var transactionModifyReq models.InvestmentTransactionModifyRequest
+	err := c.ShouldBindJSON(&transactionModifyReq)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[investments.InvestmentTransactionModifyHandler] parse request failed, because %s", err.Error())
+		return nil, errs.NewIncompleteOrIncorrectSubmissionError(err)
+	}
+
+	uid := c.GetCurrentUid()
+	transaction, err := a.investments.GetTransactionByTransactionId(uid, transactionModifyReq.Id)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[investments.InvestmentTransactionModifyHandler] failed to get investment transaction \"id:%d\" for user \"uid:%d\", because %s", transactionModifyReq.Id, uid, err.Error())
+		return nil, errs.Or(err, errs.ErrOperationFailed)
+	}
+
+	newTransaction := &models.InvestmentTransaction{
+		TransactionId: transaction.TransactionId,
+		Uid:           uid,
+		AccountId:     transaction.AccountId,
+		SecurityId:    transaction.SecurityId,
+		Type:          transactionModifyReq.Type,
+		TradeUnixTime: transactionModifyReq.Time,
+		Quantity:      transactionModifyReq.Quantity,
+		Amount:        transactionModifyReq.Amount,
+		Comment:       transactionModifyReq.Comment,
+	}
+
+	if newTransaction.Type == transaction.Type &&
+		newTransaction.TradeUnixTime == transaction.TradeUnixTime &&
+		newTransaction.Quantity == transaction.Quantity &&
+		newTransaction.Amount == transaction.Amount &&
+		newTransaction.Comment == transaction.Comment &&
+		transaction.CashTransactionId > 0 {
+		cashTransaction, err := a.transactions.GetTransactionByTransactionId(uid, transaction.CashTransactionId)
+
+		if err == nil && cashTransaction.CategoryId == transactionModifyReq.CategoryId && cashTransaction.TimezoneUtcOffset == transactionModifyReq.UtcOffset {
+			return nil, errs.ErrNothingWillBeUpdated
+		}
+	}
+
+	err = a.investments.ModifyTransaction(newTransaction, transactionModifyReq.CategoryId, transactionModifyReq.UtcOffset, models.NewTransactionOperator(c))
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[investments.InvestmentTransactionModifyHandler] failed to update investment transaction \"id:%d\" for user \"uid:%d\", because %s", transactionModifyReq.Id, uid, err.Error())
+		return nil, errs.Or(err, errs.ErrOperationFailed)
+	}
+
+	log.InfofWithRequestId(c, "[investments.InvestmentTransactionModifyHandler] user \"uid:%d\" has updated investment transaction \"id:%d\" successfully", uid, transactionModifyReq.Id)
+
+	return newTransaction.ToInvestmentTransactionInfoResponse(), nil
+}
+
+// InvestmentTransactionDeleteHandler deletes an existed investment transaction by request parameters for current user
+func (a *InvestmentsApi) InvestmentTransactionDeleteHandler(c *core.Context) (interface{}, *errs.Error) {
+	var transactionDeleteReq models.InvestmentTransactionDeleteRequest
+	err := c.ShouldBindJSON(&transactionDeleteReq)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[investments.InvestmentTransactionDeleteHandler] parse request failed, because %s", err.Error())
+		return nil, errs.NewIncompleteOrIncorrectSubmissionError(err)
+	}
+
+	uid := c.GetCurrentUid()
+	err = a.investments.DeleteTransaction(uid, transactionDeleteReq.Id, models.NewTransactionOperator(c))
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[investments.InvestmentTransactionDeleteHandler] failed to delete investment transaction \"id:%d\" for user \"uid:%d\", because %s", transactionDeleteReq.Id, uid, err.Error())
+		return nil, errs.Or(err, errs.ErrOperationFailed)
+	}
+
+	log.InfofWithRequestId(c, "[investments.InvestmentTransactionDeleteHandler] user \"uid:%d\" has deleted investment transaction \"id:%d\"", uid, transactionDeleteReq.Id)
+	return true, nil
+}
+
+// HoldingListHandler returns market value, unrealized gain and realized gain of every investment account (or one specific account) of current user
+func (a *InvestmentsApi) HoldingListHandler(c *core.Context) (interface{}, *errs.Error) {
+	var holdingListReq models.InvestmentHoldingListRequest
+	err := c.ShouldBindQuery(&holdingListReq)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[investments.HoldingListHandler] parse request failed, because %s", err.Error())
+		return nil, errs.NewIncompleteOrIncorrectSubmissionError(err)
+	}
+
+	uid := c.GetCurrentUid()
+	accounts, err := a.accounts.GetAllAccountsByUid(uid)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[investments.HoldingListHandler] failed to get all accounts for user \"uid:%d\", because %s", uid, err.Error())
+		return nil, errs.ErrOperationFailed
+	}
+
+	var investmentAccounts []*models.Account
+
+	for i := 0; i < len(accounts); i++ {
+		account := accounts[i]
+
+		if account.Category != models.ACCOUNT_CATEGORY_INVESTMENT || account.Type != models.ACCOUNT_TYPE_SINGLE_ACCOUNT {
+			continue
+		}
+
+		if holdingListReq.AccountId > 0 && account.AccountId != holdingListReq.AccountId {
+			continue
+		}
+
+		investmentAccounts = append(investmentAccounts, account)
+	}
+
+	if holdingListReq.AccountId > 0 && len(investmentAccounts) < 1 {
+		return nil, errs.ErrInvestmentAccountInvalid
+	}
+
+	accountIds := make([]int64, len(investmentAccounts))
+
+	for i := 0; i < len(investmentAccounts); i++ {
+		accountIds[i] = investmentAccounts[i].AccountId
+	}
+
+	accountsHoldings, err := a.investments.GetAccountsHoldings(uid, accountIds)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[investments.HoldingListHandler] failed to get holdings for user \"uid:%d\", because %s", uid, err.Error())
+		return nil, errs.Or(err, errs.ErrOperationFailed)
+	}
+
+	securities, err := a.investments.GetAllSecuritiesByUid(uid)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[investments.HoldingListHandler] failed to get securities for user \"uid:%d\", because %s", uid, err.Error())
+		return nil, errs.ErrOperationFailed
+	}
+
+	securityMap := a.investments.GetSecurityMapByList(securities)
+	securityIds := make([]int64, len(securities))
+
+	for i := 0; i < len(securities); i++ {
+		securityIds[i] = securities[i].SecurityId
+	}
+
+	latestPrices, err := a.investments.GetLatestPricesBySecurityIds(uid, securityIds)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[investments.HoldingListHandler] failed to get latest prices for user \"uid:%d\", because %s", uid, err.Error())
+		return nil, errs.ErrOperationFailed
+	}
+
+	accountHoldingsResps := make([]*models.InvestmentAccountHoldingsResponse, len(investmentAccounts))
+
+	for i := 0; i < len(investmentAccounts); i++ {
+		account := investmentAccounts[i]
+		holdings := accountsHoldings[account.AccountId]
+
+		accountHoldingsResp := &models.InvestmentAccountHoldingsResponse{
+			AccountId: account.AccountId,
+			Currency:  account.Currency,
+			Holdings:  make([]*models.InvestmentHoldingResponse, len(holdings)),
+		}
+
+		for j := 0; j < len(holdings); j++ {
+			holding := holdings[j]
+			holdingResp := holding.ToInvestmentHoldingResponse(securityMap[holding.SecurityId], latestPrices[holding.SecurityId])
+
+			accountHoldingsResp.MarketValue += holdingResp.MarketValue
+			accountHoldingsResp.CostBasis += holdingResp.CostBasis
+			accountHoldingsResp.UnrealizedGain += holdingResp.UnrealizedGain
+			accountHoldingsResp.RealizedGain += holdingResp.RealizedGain
+			accountHoldingsResp.DividendIncome += holdingResp.DividendIncome
+			accountHoldingsResp.Holdings[j] = holdingResp
+		}
+
+		accountHoldingsResps[i] = accountHoldingsResp
+	}
+
+	return accountHoldingsResps, nil
+}
+
+func (a *InvestmentsApi) parsePriceCsvFile(reader io.Reader, securityIdsBySymbol map[string]int64, utcOffset int16) ([]*models.InvestmentSecurityPrice, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = 3
+	csvReader.TrimLeadingSpace = true
+
+	var prices []*models.InvestmentSecurityPrice
+
+	for lineIndex := 0; ; lineIndex++ {
+		items, err := csvReader.Read()
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if lineIndex == 0 && strings.EqualFold(items[0], "symbol") {
+			continue
+		}
+
+		securityId, exists := securityIdsBySymbol[items[0]]
+
+		if !exists {
+			return nil, errs.ErrInvestmentSecurityNotFound
+		}
+
+		priceTime, err := utils.ParseFromShortDateTime(fmt.Sprintf("%s 0:0:0", items[1]), utcOffset)
+
+		if err != nil {
+			return nil, err
+		}
+
+		price, err := utils.StringToAmount(items[2])
+
+		if err != nil {
+			return nil, err
+		}
+
+		if price < 0 {
+			return nil, errs.ErrInvestmentSecurityPriceFileInvalid
+		}
+
+		prices = append(prices, &models.InvestmentSecurityPrice{
+			SecurityId:    securityId,
+			PriceUnixTime: priceTime.Unix(),
+			Price:         price,
+		})
+	}
+
+	return prices, nil
+}
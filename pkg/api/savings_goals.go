@@ -0,0 +1,290 @@
+package api
+
+import (
+	"time"
+
+	"github.com/mayswind/ezbookkeeping/pkg/core"
+	"github.com/mayswind/ezbookkeeping/pkg/errs"
+	"github.com/mayswind/ezbookkeeping/pkg/exchangerates"
+	"github.com/mayswind/ezbookkeeping/pkg/log"
+	"github.com/mayswind/ezbookkeeping/pkg/models"
+	"github.com/mayswind/ezbookkeeping/pkg/services"
+	"github.com/mayswind/ezbookkeeping/pkg/settings"
+	"github.com/mayswind/ezbookkeeping/pkg/utils"
+)
+
+// SavingsGoalsApi represents savings goal api
+type SavingsGoalsApi struct {
+	goals    *services.SavingsGoalService
+	accounts *services.AccountService
+}
+
+// Initialize a savings goal api singleton instance
+var (
+	SavingsGoals = &SavingsGoalsApi{
+		goals:    services.SavingsGoals,
+		accounts: services.Accounts,
+	}
+)
+
+// SavingsGoalListHandler returns savings goal list with progress of current user
+func (a *SavingsGoalsApi) SavingsGoalListHandler(c *core.Context) (interface{}, *errs.Error) {
+	utcOffset, err := c.GetClientTimezoneOffset()
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[savings_goals.SavingsGoalListHandler] cannot get client timezone offset, because %s", err.Error())
+		return nil, errs.ErrClientTimezoneOffsetInvalid
+	}
+
+	uid := c.GetCurrentUid()
+	goals, err := a.goals.GetAllGoalsByUid(uid)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[savings_goals.SavingsGoalListHandler] failed to get savings goals for user \"uid:%d\", because %s", uid, err.Error())
+		return nil, errs.ErrOperationFailed
+	}
+
+	accounts, err := a.accounts.GetAllAccountsByUid(uid)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[savings_goals.SavingsGoalListHandler] failed to get accounts for user \"uid:%d\", because %s", uid, err.Error())
+		return nil, errs.ErrOperationFailed
+	}
+
+	accountMap := a.accounts.GetAccountMapByList(accounts)
+	now := time.Now().In(time.FixedZone("Client Timezone", int(utcOffset)*60))
+	goalResps := make([]*models.SavingsGoalInfoResponse, len(goals))
+	var exchangeRates *models.LatestExchangeRateResponse
+
+	for i := 0; i < len(goals); i++ {
+		currentAmount, errResp := a.getCurrentAmount(c, uid, goals[i], accountMap, &exchangeRates)
+
+		if errResp != nil {
+			return nil, errResp
+		}
+
+		goalResps[i] = goals[i].ToSavingsGoalInfoResponse(currentAmount, now)
+	}
+
+	return goalResps, nil
+}
+
+// SavingsGoalGetHandler returns one specific savings goal with progress of current user
+func (a *SavingsGoalsApi) SavingsGoalGetHandler(c *core.Context) (interface{}, *errs.Error) {
+	var goalGetReq models.SavingsGoalGetRequest
+	err := c.ShouldBindQuery(&goalGetReq)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[savings_goals.SavingsGoalGetHandler] parse request failed, because %s", err.Error())
+		return nil, errs.NewIncompleteOrIncorrectSubmissionError(err)
+	}
+
+	utcOffset, err := c.GetClientTimezoneOffset()
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[savings_goals.SavingsGoalGetHandler] cannot get client timezone offset, because %s", err.Error())
+		return nil, errs.ErrClientTimezoneOffsetInvalid
+	}
+
+	uid := c.GetCurrentUid()
+	goal, err := a.goals.GetGoalByGoalId(uid, goalGetReq.Id)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[savings_goals.SavingsGoalGetHandler] failed to get savings goal \"id:%d\" for user \"uid:%d\", because %s", goalGetReq.Id, uid, err.Error())
+		return nil, errs.Or(err, errs.ErrOperationFailed)
+	}
+
+	return a.getSavingsGoalInfoResponse(c, uid, goal, utcOffset)
+}
+
+// SavingsGoalCreateHandler saves a new savings goal by request parameters for current user
+func (a *SavingsGoalsApi) SavingsGoalCreateHandler(c *core.Context) (interface{}, *errs.Error) {
+	var goalCreateReq models.SavingsGoalCreateRequest
+	err := c.ShouldBindJSON(&goalCreateReq)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[savings_goals.SavingsGoalCreateHandler] parse request failed, because %s", err.Error())
+		return nil, errs.NewIncompleteOrIncorrectSubmissionError(err)
+	}
+
+	utcOffset, err := c.GetClientTimezoneOffset()
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[savings_goals.SavingsGoalCreateHandler] cannot get client timezone offset, because %s", err.Error())
+		return nil, errs.ErrClientTimezoneOffsetInvalid
+	}
+
+	accountIds, err := utils.StringArrayToInt64Array(goalCreateReq.AccountIds)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[savings_goals.SavingsGoalCreateHandler] parse account ids failed, because %s", err.Error())
+		return nil, errs.ErrSavingsGoalAccountIdInvalid
+	}
+
+	uid := c.GetCurrentUid()
+
+	goal := &models.SavingsGoal{
+		Uid:            uid,
+		Name:           goalCreateReq.Name,
+		Currency:       goalCreateReq.Currency,
+		TargetAmount:   goalCreateReq.TargetAmount,
+		TargetUnixTime: goalCreateReq.TargetTime,
+		Comment:        goalCreateReq.Comment,
+	}
+
+	err = a.goals.CreateGoal(goal, accountIds)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[savings_goals.SavingsGoalCreateHandler] failed to create savings goal \"id:%d\" for user \"uid:%d\", because %s", goal.GoalId, uid, err.Error())
+		return nil, errs.Or(err, errs.ErrOperationFailed)
+	}
+
+	log.InfofWithRequestId(c, "[savings_goals.SavingsGoalCreateHandler] user \"uid:%d\" has created a new savings goal \"id:%d\" successfully", uid, goal.GoalId)
+
+	return a.getSavingsGoalInfoResponse(c, uid, goal, utcOffset)
+}
+
+// SavingsGoalModifyHandler saves an existed savings goal by request parameters for current user
+func (a *SavingsGoalsApi) SavingsGoalModifyHandler(c *core.Context) (interface{}, *errs.Error) {
+	var goalModifyReq models.SavingsGoalModifyRequest
+	err := c.ShouldBindJSON(&goalModifyReq)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[savings_goals.SavingsGoalModifyHandler] parse request failed, because %s", err.Error())
+		return nil, errs.NewIncompleteOrIncorrectSubmissionError(err)
+	}
+
+	utcOffset, err := c.GetClientTimezoneOffset()
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[savings_goals.SavingsGoalModifyHandler] cannot get client timezone offset, because %s", err.Error())
+		return nil, errs.ErrClientTimezoneOffsetInvalid
+	}
+
+	accountIds, err := utils.StringArrayToInt64Array(goalModifyReq.AccountIds)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[savings_goals.SavingsGoalModifyHandler] parse account ids failed, because %s", err.Error())
+		return nil, errs.ErrSavingsGoalAccountIdInvalid
+	}
+
+	uid := c.GetCurrentUid()
+	goal, err := a.goals.GetGoalByGoalId(uid, goalModifyReq.Id)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[savings_goals.SavingsGoalModifyHandler] failed to get savings goal \"id:%d\" for user \"uid:%d\", because %s", goalModifyReq.Id, uid, err.Error())
+		return nil, errs.Or(err, errs.ErrOperationFailed)
+	}
+
+	newGoal := &models.SavingsGoal{
+		GoalId:         goal.GoalId,
+		Uid:            uid,
+		Name:           goalModifyReq.Name,
+		Currency:       goalModifyReq.Currency,
+		TargetAmount:   goalModifyReq.TargetAmount,
+		TargetUnixTime: goalModifyReq.TargetTime,
+		Comment:        goalModifyReq.Comment,
+	}
+
+	err = a.goals.ModifyGoal(newGoal, accountIds)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[savings_goals.SavingsGoalModifyHandler] failed to update savings goal \"id:%d\" for user \"uid:%d\", because %s", goalModifyReq.Id, uid, err.Error())
+		return nil, errs.Or(err, errs.ErrOperationFailed)
+	}
+
+	log.InfofWithRequestId(c, "[savings_goals.SavingsGoalModifyHandler] user \"uid:%d\" has updated savings goal \"id:%d\" successfully", uid, goalModifyReq.Id)
+
+	return a.getSavingsGoalInfoResponse(c, uid, newGoal, utcOffset)
+}
+
+// SavingsGoalDeleteHandler deletes an existed savings goal by request parameters for current user
+func (a *SavingsGoalsApi) SavingsGoalDeleteHandler(c *core.Context) (interface{}, *errs.Error) {
+	var goalDeleteReq models.SavingsGoalDeleteRequest
+	err := c.ShouldBindJSON(&goalDeleteReq)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[savings_goals.SavingsGoalDeleteHandler] parse request failed, because %s", err.Error())
+		return nil, errs.NewIncompleteOrIncorrectSubmissionError(err)
+	}
+
+	uid := c.GetCurrentUid()
+	err = a.goals.DeleteGoal(uid, goalDeleteReq.Id)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[savings_goals.SavingsGoalDeleteHandler] failed to delete savings goal \"id:%d\" for user \"uid:%d\", because %s", goalDeleteReq.Id, uid, err.Error())
+		return nil, errs.Or(err, errs.ErrOperationFailed)
+	}
+
+	log.InfofWithRequestId(c, "[savings_goals.SavingsGoalDeleteHandler] user \"uid:%d\" has deleted savings goal \"id:%d\"", uid, goalDeleteReq.Id)
+	return true, nil
+}
+
+func (a *SavingsGoalsApi) getSavingsGoalInfoResponse(c *core.Context, uid int64, goal *models.SavingsGoal, utcOffset int16) (*models.SavingsGoalInfoResponse, *errs.Error) {
+	accountIds, err := utils.StringArrayToInt64Array(goal.GetAccountIdStrings())
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[savings_goals.getSavingsGoalInfoResponse] failed to parse account ids of savings goal \"id:%d\" for user \"uid:%d\", because %s", goal.GoalId, uid, err.Error())
+		return nil, errs.ErrOperationFailed
+	}
+
+	accountMap, err := a.accounts.GetAccountsByAccountIds(uid, accountIds)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[savings_goals.getSavingsGoalInfoResponse] failed to get accounts of savings goal \"id:%d\" for user \"uid:%d\", because %s", goal.GoalId, uid, err.Error())
+		return nil, errs.ErrOperationFailed
+	}
+
+	var exchangeRates *models.LatestExchangeRateResponse
+	currentAmount, errResp := a.getCurrentAmount(c, uid, goal, accountMap, &exchangeRates)
+
+	if errResp != nil {
+		return nil, errResp
+	}
+
+	return goal.ToSavingsGoalInfoResponse(currentAmount, time.Now().In(time.FixedZone("Client Timezone", int(utcOffset)*60))), nil
+}
+
+func (a *SavingsGoalsApi) getCurrentAmount(c *core.Context, uid int64, goal *models.SavingsGoal, accountMap map[int64]*models.Account, exchangeRates **models.LatestExchangeRateResponse) (int64, *errs.Error) {
+	accountIds, err := utils.StringArrayToInt64Array(goal.GetAccountIdStrings())
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[savings_goals.getCurrentAmount] failed to parse account ids of savings goal \"id:%d\" for user \"uid:%d\", because %s", goal.GoalId, uid, err.Error())
+		return 0, errs.ErrOperationFailed
+	}
+
+	currentAmount := int64(0)
+
+	for i := 0; i < len(accountIds); i++ {
+		account, exists := accountMap[accountIds[i]]
+
+		if !exists || account.Balance == 0 {
+			continue
+		}
+
+		if account.Currency == goal.Currency {
+			currentAmount += account.Balance
+			continue
+		}
+
+		if *exchangeRates == nil {
+			*exchangeRates, err = exchangerates.Container.GetLatestExchangeRates(c, uid, settings.Container.Current)
+
+			if err != nil {
+				log.ErrorfWithRequestId(c, "[savings_goals.getCurrentAmount] failed to get latest exchange rates for user \"uid:%d\", because %s", uid, err.Error())
+				return 0, errs.Or(err, errs.ErrOperationFailed)
+			}
+		}
+
+		amount, err := (*exchangeRates).ExchangeAmount(account.Balance, account.Currency, goal.Currency)
+
+		if err != nil {
+			log.WarnfWithRequestId(c, "[savings_goals.getCurrentAmount] cannot exchange amount from \"%s\" to \"%s\" for user \"uid:%d\", because %s", account.Currency, goal.Currency, uid, err.Error())
+			return 0, errs.Or(err, errs.ErrOperationFailed)
+		}
+
+		currentAmount += amount
+	}
+
+	return currentAmount, nil
+}
@@ -0,0 +1,212 @@
+package api
+
+import (
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/mayswind/ezbookkeeping/pkg/core"
+	"github.com/mayswind/ezbookkeeping/pkg/errs"
+	"github.com/mayswind/ezbookkeeping/pkg/log"
+	"github.com/mayswind/ezbookkeeping/pkg/models"
+	"github.com/mayswind/ezbookkeeping/pkg/services"
+	"github.com/mayswind/ezbookkeeping/pkg/settings"
+)
+
+const maxAttachmentFileNameLength = 255
+
+var allowedAttachmentContentTypes = map[string]bool{
+	"image/jpeg":      true,
+	"image/png":       true,
+	"image/gif":       true,
+	"image/webp":      true,
+	"application/pdf": true,
+}
+
+// TransactionAttachmentsApi represents transaction attachment api
+type TransactionAttachmentsApi struct {
+	attachments *services.TransactionAttachmentService
+}
+
+// Initialize a transaction attachment api singleton instance
+var (
+	TransactionAttachments = &TransactionAttachmentsApi{
+		attachments: services.TransactionAttachments,
+	}
+)
+
+// TransactionAttachmentListHandler returns attachment list of one specific transaction of current user
+func (a *TransactionAttachmentsApi) TransactionAttachmentListHandler(c *core.Context) (interface{}, *errs.Error) {
+	var attachmentListReq models.TransactionAttachmentListRequest
+	err := c.ShouldBindQuery(&attachmentListReq)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[transaction_attachments.TransactionAttachmentListHandler] parse request failed, because %s", err.Error())
+		return nil, errs.NewIncompleteOrIncorrectSubmissionError(err)
+	}
+
+	uid := c.GetCurrentUid()
+	attachments, err := a.attachments.GetAllAttachmentsByTransactionId(uid, attachmentListReq.TransactionId)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[transaction_attachments.TransactionAttachmentListHandler] failed to get attachments of transaction \"id:%d\" for user \"uid:%d\", because %s", attachmentListReq.TransactionId, uid, err.Error())
+		return nil, errs.Or(err, errs.ErrOperationFailed)
+	}
+
+	attachmentResps := make([]*models.TransactionAttachmentInfoResponse, len(attachments))
+
+	for i := 0; i < len(attachments); i++ {
+		attachmentResps[i] = attachments[i].ToTransactionAttachmentInfoResponse()
+	}
+
+	return attachmentResps, nil
+}
+
+// TransactionAttachmentUploadHandler saves a new attachment of one specific transaction by request parameters for current user
+func (a *TransactionAttachmentsApi) TransactionAttachmentUploadHandler(c *core.Context) (interface{}, *errs.Error) {
+	var attachmentUploadReq models.TransactionAttachmentUploadRequest
+	err := c.ShouldBind(&attachmentUploadReq)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[transaction_attachments.TransactionAttachmentUploadHandler] parse request failed, because %s", err.Error())
+		return nil, errs.NewIncompleteOrIncorrectSubmissionError(err)
+	}
+
+	fileHeader, err := c.FormFile("file")
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[transaction_attachments.TransactionAttachmentUploadHandler] failed to get uploaded file, because %s", err.Error())
+		return nil, errs.ErrTransactionAttachmentFileIsEmpty
+	}
+
+	if fileHeader.Size < 1 {
+		return nil, errs.ErrTransactionAttachmentFileIsEmpty
+	}
+
+	if fileHeader.Size > int64(settings.Container.Current.MaxAttachmentFileSize) {
+		return nil, errs.ErrTransactionAttachmentFileTooLarge
+	}
+
+	uid := c.GetCurrentUid()
+	file, err := fileHeader.Open()
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[transaction_attachments.TransactionAttachmentUploadHandler] failed to open uploaded file for user \"uid:%d\", because %s", uid, err.Error())
+		return nil, errs.ErrOperationFailed
+	}
+
+	defer file.Close()
+
+	header := make([]byte, 512)
+	headerLength, err := io.ReadFull(file, header)
+
+	if err != nil && err != io.ErrUnexpectedEOF {
+		log.ErrorfWithRequestId(c, "[transaction_attachments.TransactionAttachmentUploadHandler] failed to read uploaded file for user \"uid:%d\", because %s", uid, err.Error())
+		return nil, errs.ErrOperationFailed
+	}
+
+	contentType := http.DetectContentType(header[:headerLength])
+
+	if !allowedAttachmentContentTypes[contentType] {
+		log.WarnfWithRequestId(c, "[transaction_attachments.TransactionAttachmentUploadHandler] the content type \"%s\" of uploaded file is not supported", contentType)
+		return nil, errs.ErrTransactionAttachmentFileTypeInvalid
+	}
+
+	if _, err = file.Seek(0, io.SeekStart); err != nil {
+		log.ErrorfWithRequestId(c, "[transaction_attachments.TransactionAttachmentUploadHandler] failed to read uploaded file for user \"uid:%d\", because %s", uid, err.Error())
+		return nil, errs.ErrOperationFailed
+	}
+
+	attachment := &models.TransactionAttachment{
+		Uid:           uid,
+		TransactionId: attachmentUploadReq.TransactionId,
+		FileName:      a.getSafeFileName(fileHeader.Filename),
+		ContentType:   contentType,
+		Size:          fileHeader.Size,
+	}
+
+	err = a.attachments.CreateAttachment(attachment, file)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[transaction_attachments.TransactionAttachmentUploadHandler] failed to create attachment of transaction \"id:%d\" for user \"uid:%d\", because %s", attachment.TransactionId, uid, err.Error())
+		return nil, errs.Or(err, errs.ErrOperationFailed)
+	}
+
+	log.InfofWithRequestId(c, "[transaction_attachments.TransactionAttachmentUploadHandler] user \"uid:%d\" has uploaded a new attachment \"id:%d\" to transaction \"id:%d\" successfully", uid, attachment.AttachmentId, attachment.TransactionId)
+
+	attachmentResp := attachment.ToTransactionAttachmentInfoResponse()
+
+	return attachmentResp, nil
+}
+
+// TransactionAttachmentDownloadHandler returns the file content of one specific transaction attachment of current user
+func (a *TransactionAttachmentsApi) TransactionAttachmentDownloadHandler(c *core.Context) ([]byte, string, string, *errs.Error) {
+	var attachmentGetReq models.TransactionAttachmentGetRequest
+	err := c.ShouldBindQuery(&attachmentGetReq)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[transaction_attachments.TransactionAttachmentDownloadHandler] parse request failed, because %s", err.Error())
+		return nil, "", "", errs.NewIncompleteOrIncorrectSubmissionError(err)
+	}
+
+	uid := c.GetCurrentUid()
+	attachment, err := a.attachments.GetAttachmentByAttachmentId(uid, attachmentGetReq.Id)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[transaction_attachments.TransactionAttachmentDownloadHandler] failed to get attachment \"id:%d\" for user \"uid:%d\", because %s", attachmentGetReq.Id, uid, err.Error())
+		return nil, "", "", errs.Or(err, errs.ErrOperationFailed)
+	}
+
+	content, err := a.attachments.GetAttachmentContent(attachment)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[transaction_attachments.TransactionAttachmentDownloadHandler] failed to read content of attachment \"id:%d\" for user \"uid:%d\", because %s", attachmentGetReq.Id, uid, err.Error())
+		return nil, "", "", errs.ErrObjectStorageOperationFailed
+	}
+
+	return content, attachment.ContentType, attachment.FileName, nil
+}
+
+// TransactionAttachmentDeleteHandler deletes an existed transaction attachment by request parameters for current user
+func (a *TransactionAttachmentsApi) TransactionAttachmentDeleteHandler(c *core.Context) (interface{}, *errs.Error) {
+	var attachmentDeleteReq models.TransactionAttachmentDeleteRequest
+	err := c.ShouldBindJSON(&attachmentDeleteReq)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[transaction_attachments.TransactionAttachmentDeleteHandler] parse request failed, because %s", err.Error())
+		return nil, errs.NewIncompleteOrIncorrectSubmissionError(err)
+	}
+
+	uid := c.GetCurrentUid()
+	err = a.attachments.DeleteAttachment(uid, attachmentDeleteReq.Id)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[transaction_attachments.TransactionAttachmentDeleteHandler] failed to delete attachment \"id:%d\" for user \"uid:%d\", because %s", attachmentDeleteReq.Id, uid, err.Error())
+		return nil, errs.Or(err, errs.ErrOperationFailed)
+	}
+
+	log.InfofWithRequestId(c, "[transaction_attachments.TransactionAttachmentDeleteHandler] user \"uid:%d\" has deleted attachment \"id:%d\"", uid, attachmentDeleteReq.Id)
+	return true, nil
+}
+
+func (a *TransactionAttachmentsApi) getSafeFileName(fileName string) string {
+	fileName = filepath.Base(strings.ReplaceAll(fileName, "\\", "/"))
+	fileName = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f || r == '"' || r == ';' {
+			return -1
+		}
+
+		return r
+	}, fileName)
+
+	if fileName == "" || fileName == "." || fileName == "/" {
+		fileName = "attachment"
+	}
+
+	if runes := []rune(fileName); len(runes) > maxAttachmentFileNameLength {
+		fileName = string(runes[len(runes)-maxAttachmentFileNameLength:])
+	}
+
+	return fileName
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/mayswind/ezbookkeeping/pkg/log"
 	"github.com/mayswind/ezbookkeeping/pkg/models"
 	"github.com/mayswind/ezbookkeeping/pkg/services"
+	"github.com/mayswind/ezbookkeeping/pkg/utils"
 )
 
 // TransactionCategoriesApi represents transaction category api
@@ -303,6 +304,35 @@ func (a *TransactionCategoriesApi) CategoryMoveHandler(c *core.Context) (interfa
 	return true, nil
 }
 
+// CategoryMergeHandler merges existed transaction categories into another one by request parameters for current user
+func (a *TransactionCategoriesApi) CategoryMergeHandler(c *core.Context) (interface{}, *errs.Error) {
+	var categoryMergeReq models.TransactionCategoryMergeRequest
+	err := c.ShouldBindJSON(&categoryMergeReq)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[transaction_categories.CategoryMergeHandler] parse request failed, because %s", err.Error())
+		return nil, errs.NewIncompleteOrIncorrectSubmissionError(err)
+	}
+
+	fromCategoryIds, err := utils.StringArrayToInt64Array(categoryMergeReq.FromIds)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[transaction_categories.CategoryMergeHandler] parse category ids failed, because %s", err.Error())
+		return nil, errs.ErrTransactionCategoryIdInvalid
+	}
+
+	uid := c.GetCurrentUid()
+	err = a.categories.MergeCategories(uid, fromCategoryIds, categoryMergeReq.ToId, models.NewTransactionOperator(c))
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[transaction_categories.CategoryMergeHandler] failed to merge categories into category \"id:%d\" for user \"uid:%d\", because %s", categoryMergeReq.ToId, uid, err.Error())
+		return nil, errs.Or(err, errs.ErrOperationFailed)
+	}
+
+	log.InfofWithRequestId(c, "[transaction_categories.CategoryMergeHandler] user \"uid:%d\" has merged %d categories into category \"id:%d\"", uid, len(fromCategoryIds), categoryMergeReq.ToId)
+	return true, nil
+}
+
 // CategoryDeleteHandler deletes an existed transaction category by request parameters for current user
 func (a *TransactionCategoriesApi) CategoryDeleteHandler(c *core.Context) (interface{}, *errs.Error) {
 	var categoryDeleteReq models.TransactionCategoryDeleteRequest
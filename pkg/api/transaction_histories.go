@@ -0,0 +1,48 @@
+package api
+
+import (
+	"github.com/mayswind/ezbookkeeping/pkg/core"
+	"github.com/mayswind/ezbookkeeping/pkg/errs"
+	"github.com/mayswind/ezbookkeeping/pkg/log"
+	"github.com/mayswind/ezbookkeeping/pkg/models"
+	"github.com/mayswind/ezbookkeeping/pkg/services"
+)
+
+// TransactionHistoriesApi represents transaction history api
+type TransactionHistoriesApi struct {
+	histories *services.TransactionHistoryService
+}
+
+// Initialize a transaction history api singleton instance
+var (
+	TransactionHistories = &TransactionHistoriesApi{
+		histories: services.TransactionHistories,
+	}
+)
+
+// TransactionHistoryListHandler returns change history of one specific transaction of current user
+func (a *TransactionHistoriesApi) TransactionHistoryListHandler(c *core.Context) (interface{}, *errs.Error) {
+	var historyListReq models.TransactionHistoryListRequest
+	err := c.ShouldBindQuery(&historyListReq)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[transaction_histories.TransactionHistoryListHandler] parse request failed, because %s", err.Error())
+		return nil, errs.NewIncompleteOrIncorrectSubmissionError(err)
+	}
+
+	uid := c.GetCurrentUid()
+	histories, err := a.histories.GetAllHistoriesByTransactionId(uid, historyListReq.Id)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[transaction_histories.TransactionHistoryListHandler] failed to get history of transaction \"id:%d\" for user \"uid:%d\", because %s", historyListReq.Id, uid, err.Error())
+		return nil, errs.Or(err, errs.ErrOperationFailed)
+	}
+
+	historyResps := make([]*models.TransactionHistoryInfoResponse, len(histories))
+
+	for i := 0; i < len(histories); i++ {
+		historyResps[i] = histories[i].ToTransactionHistoryInfoResponse()
+	}
+
+	return historyResps, nil
+}
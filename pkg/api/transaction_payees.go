@@ -0,0 +1,291 @@
+package api
+
+import (
+	"sort"
+
+	"github.com/mayswind/ezbookkeeping/pkg/core"
+	"github.com/mayswind/ezbookkeeping/pkg/errs"
+	"github.com/mayswind/ezbookkeeping/pkg/log"
+	"github.com/mayswind/ezbookkeeping/pkg/models"
+	"github.com/mayswind/ezbookkeeping/pkg/services"
+	"github.com/mayswind/ezbookkeeping/pkg/utils"
+)
+
+// TransactionPayeesApi represents transaction payee api
+type TransactionPayeesApi struct {
+	payees *services.TransactionPayeeService
+}
+
+// Initialize a transaction payee api singleton instance
+var (
+	TransactionPayees = &TransactionPayeesApi{
+		payees: services.TransactionPayees,
+	}
+)
+
+// PayeeListHandler returns transaction payee list of current user
+func (a *TransactionPayeesApi) PayeeListHandler(c *core.Context) (interface{}, *errs.Error) {
+	uid := c.GetCurrentUid()
+	payees, err := a.payees.GetAllPayeesByUid(uid)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[transaction_payees.PayeeListHandler] failed to get payees for user \"uid:%d\", because %s", uid, err.Error())
+		return nil, errs.ErrOperationFailed
+	}
+
+	payeeResps := make(models.TransactionPayeeInfoResponseSlice, len(payees))
+
+	for i := 0; i < len(payees); i++ {
+		payeeResps[i] = payees[i].ToTransactionPayeeInfoResponse()
+	}
+
+	sort.Sort(payeeResps)
+
+	return payeeResps, nil
+}
+
+// PayeeGetHandler returns one specific transaction payee of current user
+func (a *TransactionPayeesApi) PayeeGetHandler(c *core.Context) (interface{}, *errs.Error) {
+	var payeeGetReq models.TransactionPayeeGetRequest
+	err := c.ShouldBindQuery(&payeeGetReq)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[transaction_payees.PayeeGetHandler] parse request failed, because %s", err.Error())
+		return nil, errs.NewIncompleteOrIncorrectSubmissionError(err)
+	}
+
+	uid := c.GetCurrentUid()
+	payee, err := a.payees.GetPayeeByPayeeId(uid, payeeGetReq.Id)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[transaction_payees.PayeeGetHandler] failed to get payee \"id:%d\" for user \"uid:%d\", because %s", payeeGetReq.Id, uid, err.Error())
+		return nil, errs.Or(err, errs.ErrOperationFailed)
+	}
+
+	payeeResp := payee.ToTransactionPayeeInfoResponse()
+
+	return payeeResp, nil
+}
+
+// PayeeAutocompleteHandler returns visible transaction payees of current user whose name starts with given prefix
+func (a *TransactionPayeesApi) PayeeAutocompleteHandler(c *core.Context) (interface{}, *errs.Error) {
+	var payeeAutocompleteReq models.TransactionPayeeAutocompleteRequest
+	err := c.ShouldBindQuery(&payeeAutocompleteReq)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[transaction_payees.PayeeAutocompleteHandler] parse request failed, because %s", err.Error())
+		return nil, errs.NewIncompleteOrIncorrectSubmissionError(err)
+	}
+
+	uid := c.GetCurrentUid()
+	payees, err := a.payees.GetPayeesByNamePrefix(uid, payeeAutocompleteReq.Prefix, payeeAutocompleteReq.Count)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[transaction_payees.PayeeAutocompleteHandler] failed to get payees by prefix \"%s\" for user \"uid:%d\", because %s", payeeAutocompleteReq.Prefix, uid, err.Error())
+		return nil, errs.ErrOperationFailed
+	}
+
+	payeeResps := make(models.TransactionPayeeInfoResponseSlice, len(payees))
+
+	for i := 0; i < len(payees); i++ {
+		payeeResps[i] = payees[i].ToTransactionPayeeInfoResponse()
+	}
+
+	return payeeResps, nil
+}
+
+// PayeeSuggestionHandler returns the category and account used in the latest transaction of one specific transaction payee of current user
+func (a *TransactionPayeesApi) PayeeSuggestionHandler(c *core.Context) (interface{}, *errs.Error) {
+	var payeeSuggestionReq models.TransactionPayeeSuggestionRequest
+	err := c.ShouldBindQuery(&payeeSuggestionReq)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[transaction_payees.PayeeSuggestionHandler] parse request failed, because %s", err.Error())
+		return nil, errs.NewIncompleteOrIncorrectSubmissionError(err)
+	}
+
+	uid := c.GetCurrentUid()
+	payee, err := a.payees.GetPayeeByPayeeId(uid, payeeSuggestionReq.Id)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[transaction_payees.PayeeSuggestionHandler] failed to get payee \"id:%d\" for user \"uid:%d\", because %s", payeeSuggestionReq.Id, uid, err.Error())
+		return nil, errs.Or(err, errs.ErrOperationFailed)
+	}
+
+	transaction, err := a.payees.GetLastUsedTransactionOfPayee(uid, payee.PayeeId)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[transaction_payees.PayeeSuggestionHandler] failed to get last used transaction of payee \"id:%d\" for user \"uid:%d\", because %s", payee.PayeeId, uid, err.Error())
+		return nil, errs.ErrOperationFailed
+	}
+
+	suggestionResp := &models.TransactionPayeeSuggestionResponse{
+		PayeeId: payee.PayeeId,
+	}
+
+	if transaction != nil {
+		if transaction.Type == models.TRANSACTION_DB_TYPE_INCOME {
+			suggestionResp.Type = models.TRANSACTION_TYPE_INCOME
+		} else if transaction.Type == models.TRANSACTION_DB_TYPE_EXPENSE {
+			suggestionResp.Type = models.TRANSACTION_TYPE_EXPENSE
+		}
+
+		suggestionResp.CategoryId = transaction.CategoryId
+		suggestionResp.AccountId = transaction.AccountId
+	}
+
+	return suggestionResp, nil
+}
+
+// PayeeCreateHandler saves a new transaction payee by request parameters for current user
+func (a *TransactionPayeesApi) PayeeCreateHandler(c *core.Context) (interface{}, *errs.Error) {
+	var payeeCreateReq models.TransactionPayeeCreateRequest
+	err := c.ShouldBindJSON(&payeeCreateReq)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[transaction_payees.PayeeCreateHandler] parse request failed, because %s", err.Error())
+		return nil, errs.NewIncompleteOrIncorrectSubmissionError(err)
+	}
+
+	uid := c.GetCurrentUid()
+	payee := a.createNewPayeeModel(uid, &payeeCreateReq)
+
+	err = a.payees.CreatePayee(payee)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[transaction_payees.PayeeCreateHandler] failed to create payee \"id:%d\" for user \"uid:%d\", because %s", payee.PayeeId, uid, err.Error())
+		return nil, errs.Or(err, errs.ErrOperationFailed)
+	}
+
+	log.InfofWithRequestId(c, "[transaction_payees.PayeeCreateHandler] user \"uid:%d\" has created a new payee \"id:%d\" successfully", uid, payee.PayeeId)
+
+	payeeResp := payee.ToTransactionPayeeInfoResponse()
+
+	return payeeResp, nil
+}
+
+// PayeeModifyHandler saves an existed transaction payee by request parameters for current user
+func (a *TransactionPayeesApi) PayeeModifyHandler(c *core.Context) (interface{}, *errs.Error) {
+	var payeeModifyReq models.TransactionPayeeModifyRequest
+	err := c.ShouldBindJSON(&payeeModifyReq)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[transaction_payees.PayeeModifyHandler] parse request failed, because %s", err.Error())
+		return nil, errs.NewIncompleteOrIncorrectSubmissionError(err)
+	}
+
+	uid := c.GetCurrentUid()
+	payee, err := a.payees.GetPayeeByPayeeId(uid, payeeModifyReq.Id)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[transaction_payees.PayeeModifyHandler] failed to get payee \"id:%d\" for user \"uid:%d\", because %s", payeeModifyReq.Id, uid, err.Error())
+		return nil, errs.Or(err, errs.ErrOperationFailed)
+	}
+
+	newPayee := &models.TransactionPayee{
+		PayeeId: payee.PayeeId,
+		Uid:     uid,
+		Name:    payeeModifyReq.Name,
+		Comment: payeeModifyReq.Comment,
+	}
+
+	if newPayee.Name == payee.Name && newPayee.Comment == payee.Comment {
+		return nil, errs.ErrNothingWillBeUpdated
+	}
+
+	err = a.payees.ModifyPayee(newPayee, newPayee.Name != payee.Name)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[transaction_payees.PayeeModifyHandler] failed to update payee \"id:%d\" for user \"uid:%d\", because %s", payeeModifyReq.Id, uid, err.Error())
+		return nil, errs.Or(err, errs.ErrOperationFailed)
+	}
+
+	log.InfofWithRequestId(c, "[transaction_payees.PayeeModifyHandler] user \"uid:%d\" has updated payee \"id:%d\" successfully", uid, payeeModifyReq.Id)
+
+	payee.Name = newPayee.Name
+	payee.Comment = newPayee.Comment
+	payeeResp := payee.ToTransactionPayeeInfoResponse()
+
+	return payeeResp, nil
+}
+
+// PayeeHideHandler hides a transaction payee by request parameters for current user
+func (a *TransactionPayeesApi) PayeeHideHandler(c *core.Context) (interface{}, *errs.Error) {
+	var payeeHideReq models.TransactionPayeeHideRequest
+	err := c.ShouldBindJSON(&payeeHideReq)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[transaction_payees.PayeeHideHandler] parse request failed, because %s", err.Error())
+		return nil, errs.NewIncompleteOrIncorrectSubmissionError(err)
+	}
+
+	uid := c.GetCurrentUid()
+	err = a.payees.HidePayee(uid, []int64{payeeHideReq.Id}, payeeHideReq.Hidden)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[transaction_payees.PayeeHideHandler] failed to hide payee \"id:%d\" for user \"uid:%d\", because %s", payeeHideReq.Id, uid, err.Error())
+		return nil, errs.Or(err, errs.ErrOperationFailed)
+	}
+
+	log.InfofWithRequestId(c, "[transaction_payees.PayeeHideHandler] user \"uid:%d\" has hidden payee \"id:%d\"", uid, payeeHideReq.Id)
+	return true, nil
+}
+
+// PayeeMergeHandler merges existed transaction payees into another one by request parameters for current user
+func (a *TransactionPayeesApi) PayeeMergeHandler(c *core.Context) (interface{}, *errs.Error) {
+	var payeeMergeReq models.TransactionPayeeMergeRequest
+	err := c.ShouldBindJSON(&payeeMergeReq)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[transaction_payees.PayeeMergeHandler] parse request failed, because %s", err.Error())
+		return nil, errs.NewIncompleteOrIncorrectSubmissionError(err)
+	}
+
+	fromPayeeIds, err := utils.StringArrayToInt64Array(payeeMergeReq.FromIds)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[transaction_payees.PayeeMergeHandler] parse payee ids failed, because %s", err.Error())
+		return nil, errs.ErrTransactionPayeeIdInvalid
+	}
+
+	uid := c.GetCurrentUid()
+	err = a.payees.MergePayees(uid, fromPayeeIds, payeeMergeReq.ToId, models.NewTransactionOperator(c))
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[transaction_payees.PayeeMergeHandler] failed to merge payees into payee \"id:%d\" for user \"uid:%d\", because %s", payeeMergeReq.ToId, uid, err.Error())
+		return nil, errs.Or(err, errs.ErrOperationFailed)
+	}
+
+	log.InfofWithRequestId(c, "[transaction_payees.PayeeMergeHandler] user \"uid:%d\" has merged %d payees into payee \"id:%d\"", uid, len(fromPayeeIds), payeeMergeReq.ToId)
+	return true, nil
+}
+
+// PayeeDeleteHandler deletes an existed transaction payee by request parameters for current user
+func (a *TransactionPayeesApi) PayeeDeleteHandler(c *core.Context) (interface{}, *errs.Error) {
+	var payeeDeleteReq models.TransactionPayeeDeleteRequest
+	err := c.ShouldBindJSON(&payeeDeleteReq)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[transaction_payees.PayeeDeleteHandler] parse request failed, because %s", err.Error())
+		return nil, errs.NewIncompleteOrIncorrectSubmissionError(err)
+	}
+
+	uid := c.GetCurrentUid()
+	err = a.payees.DeletePayee(uid, payeeDeleteReq.Id)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[transaction_payees.PayeeDeleteHandler] failed to delete payee \"id:%d\" for user \"uid:%d\", because %s", payeeDeleteReq.Id, uid, err.Error())
+		return nil, errs.Or(err, errs.ErrOperationFailed)
+	}
+
+	log.InfofWithRequestId(c, "[transaction_payees.PayeeDeleteHandler] user \"uid:%d\" has deleted payee \"id:%d\"", uid, payeeDeleteReq.Id)
+	return true, nil
+}
+
+func (a *TransactionPayeesApi) createNewPayeeModel(uid int64, payeeCreateReq *models.TransactionPayeeCreateRequest) *models.TransactionPayee {
+	return &models.TransactionPayee{
+		Uid:     uid,
+		Name:    payeeCreateReq.Name,
+		Comment: payeeCreateReq.Comment,
+	}
+}
@@ -0,0 +1,417 @@
+package api
+
+import (
+	"sort"
+
+	"github.com/mayswind/ezbookkeeping/pkg/core"
+	"github.com/mayswind/ezbookkeeping/pkg/errs"
+	"github.com/mayswind/ezbookkeeping/pkg/log"
+	"github.com/mayswind/ezbookkeeping/pkg/models"
+	"github.com/mayswind/ezbookkeeping/pkg/services"
+	"github.com/mayswind/ezbookkeeping/pkg/utils"
+)
+
+const pageCountForApplyTransactionRules = 1000
+
+// TransactionRulesApi represents transaction rule api
+type TransactionRulesApi struct {
+	rules             *services.TransactionRuleService
+	transactions      *services.TransactionService
+	transactionTags   *services.TransactionTagService
+	transactionSplits *services.TransactionSplitService
+	accounts          *services.AccountService
+	users             *services.UserService
+}
+
+// Initialize a transaction rule api singleton instance
+var (
+	TransactionRules = &TransactionRulesApi{
+		rules:             services.TransactionRules,
+		transactions:      services.Transactions,
+		transactionTags:   services.TransactionTags,
+		transactionSplits: services.TransactionSplits,
+		accounts:          services.Accounts,
+		users:             services.Users,
+	}
+)
+
+// RuleListHandler returns transaction rule list of current user
+func (a *TransactionRulesApi) RuleListHandler(c *core.Context) (interface{}, *errs.Error) {
+	uid := c.GetCurrentUid()
+	rules, err := a.rules.GetAllRulesByUid(uid)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[transaction_rules.RuleListHandler] failed to get rules for user \"uid:%d\", because %s", uid, err.Error())
+		return nil, errs.ErrOperationFailed
+	}
+
+	ruleResps := make(models.TransactionRuleInfoResponseSlice, len(rules))
+
+	for i := 0; i < len(rules); i++ {
+		ruleResps[i] = rules[i].ToTransactionRuleInfoResponse()
+	}
+
+	sort.Stable(ruleResps)
+
+	return ruleResps, nil
+}
+
+// RuleGetHandler returns one specific transaction rule of current user
+func (a *TransactionRulesApi) RuleGetHandler(c *core.Context) (interface{}, *errs.Error) {
+	var ruleGetReq models.TransactionRuleGetRequest
+	err := c.ShouldBindQuery(&ruleGetReq)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[transaction_rules.RuleGetHandler] parse request failed, because %s", err.Error())
+		return nil, errs.NewIncompleteOrIncorrectSubmissionError(err)
+	}
+
+	uid := c.GetCurrentUid()
+	rule, err := a.rules.GetRuleByRuleId(uid, ruleGetReq.Id)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[transaction_rules.RuleGetHandler] failed to get rule \"id:%d\" for user \"uid:%d\", because %s", ruleGetReq.Id, uid, err.Error())
+		return nil, errs.Or(err, errs.ErrOperationFailed)
+	}
+
+	ruleResp := rule.ToTransactionRuleInfoResponse()
+
+	return ruleResp, nil
+}
+
+// RuleCreateHandler saves a new transaction rule by request parameters for current user
+func (a *TransactionRulesApi) RuleCreateHandler(c *core.Context) (interface{}, *errs.Error) {
+	var ruleCreateReq models.TransactionRuleCreateRequest
+	err := c.ShouldBindJSON(&ruleCreateReq)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[transaction_rules.RuleCreateHandler] parse request failed, because %s", err.Error())
+		return nil, errs.NewIncompleteOrIncorrectSubmissionError(err)
+	}
+
+	tagIds, err := utils.StringArrayToInt64Array(ruleCreateReq.ActionTagIds)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[transaction_rules.RuleCreateHandler] parse tag ids failed, because %s", err.Error())
+		return nil, errs.ErrTransactionTagIdInvalid
+	}
+
+	conditionType, errResp := a.getRuleConditionDbType(ruleCreateReq.ConditionType)
+
+	if errResp != nil {
+		log.WarnfWithRequestId(c, "[transaction_rules.RuleCreateHandler] rule condition type is invalid")
+		return nil, errResp
+	}
+
+	uid := c.GetCurrentUid()
+	rule := &models.TransactionRule{
+		Uid:                uid,
+		Name:               ruleCreateReq.Name,
+		Priority:           ruleCreateReq.Priority,
+		Disabled:           ruleCreateReq.Disabled,
+		ConditionType:      conditionType,
+		ConditionComment:   ruleCreateReq.ConditionComment,
+		ConditionPayeeId:   ruleCreateReq.ConditionPayeeId,
+		ConditionAccountId: ruleCreateReq.ConditionAccountId,
+		ConditionMinAmount: ruleCreateReq.ConditionMinAmount,
+		ConditionMaxAmount: ruleCreateReq.ConditionMaxAmount,
+		ActionCategoryId:   ruleCreateReq.ActionCategoryId,
+		ActionHideAmount:   ruleCreateReq.ActionHideAmount,
+	}
+
+	err = a.rules.CreateRule(rule, tagIds)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[transaction_rules.RuleCreateHandler] failed to create rule \"id:%d\" for user \"uid:%d\", because %s", rule.RuleId, uid, err.Error())
+		return nil, errs.Or(err, errs.ErrOperationFailed)
+	}
+
+	log.InfofWithRequestId(c, "[transaction_rules.RuleCreateHandler] user \"uid:%d\" has created a new rule \"id:%d\" successfully", uid, rule.RuleId)
+
+	ruleResp := rule.ToTransactionRuleInfoResponse()
+
+	return ruleResp, nil
+}
+
+// RuleModifyHandler saves an existed transaction rule by request parameters for current user
+func (a *TransactionRulesApi) RuleModifyHandler(c *core.Context) (interface{}, *errs.Error) {
+	var ruleModifyReq models.TransactionRuleModifyRequest
+	err := c.ShouldBindJSON(&ruleModifyReq)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[transaction_rules.RuleModifyHandler] parse request failed, because %s", err.Error())
+		return nil, errs.NewIncompleteOrIncorrectSubmissionError(err)
+	}
+
+	tagIds, err := utils.StringArrayToInt64Array(ruleModifyReq.ActionTagIds)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[transaction_rules.RuleModifyHandler] parse tag ids failed, because %s", err.Error())
+		return nil, errs.ErrTransactionTagIdInvalid
+	}
+
+	conditionType, errResp := a.getRuleConditionDbType(ruleModifyReq.ConditionType)
+
+	if errResp != nil {
+		log.WarnfWithRequestId(c, "[transaction_rules.RuleModifyHandler] rule condition type is invalid")
+		return nil, errResp
+	}
+
+	uid := c.GetCurrentUid()
+	rule, err := a.rules.GetRuleByRuleId(uid, ruleModifyReq.Id)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[transaction_rules.RuleModifyHandler] failed to get rule \"id:%d\" for user \"uid:%d\", because %s", ruleModifyReq.Id, uid, err.Error())
+		return nil, errs.Or(err, errs.ErrOperationFailed)
+	}
+
+	newRule := &models.TransactionRule{
+		RuleId:             rule.RuleId,
+		Uid:                uid,
+		Name:               ruleModifyReq.Name,
+		Priority:           ruleModifyReq.Priority,
+		Disabled:           ruleModifyReq.Disabled,
+		ConditionType:      conditionType,
+		ConditionComment:   ruleModifyReq.ConditionComment,
+		ConditionPayeeId:   ruleModifyReq.ConditionPayeeId,
+		ConditionAccountId: ruleModifyReq.ConditionAccountId,
+		ConditionMinAmount: ruleModifyReq.ConditionMinAmount,
+		ConditionMaxAmount: ruleModifyReq.ConditionMaxAmount,
+		ActionCategoryId:   ruleModifyReq.ActionCategoryId,
+		ActionHideAmount:   ruleModifyReq.ActionHideAmount,
+	}
+
+	err = a.rules.ModifyRule(newRule, tagIds)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[transaction_rules.RuleModifyHandler] failed to update rule \"id:%d\" for user \"uid:%d\", because %s", ruleModifyReq.Id, uid, err.Error())
+		return nil, errs.Or(err, errs.ErrOperationFailed)
+	}
+
+	log.InfofWithRequestId(c, "[transaction_rules.RuleModifyHandler] user \"uid:%d\" has updated rule \"id:%d\" successfully", uid, ruleModifyReq.Id)
+
+	newRule.CreatedUnixTime = rule.CreatedUnixTime
+	ruleResp := newRule.ToTransactionRuleInfoResponse()
+
+	return ruleResp, nil
+}
+
+// RuleDeleteHandler deletes an existed transaction rule by request parameters for current user
+func (a *TransactionRulesApi) RuleDeleteHandler(c *core.Context) (interface{}, *errs.Error) {
+	var ruleDeleteReq models.TransactionRuleDeleteRequest
+	err := c.ShouldBindJSON(&ruleDeleteReq)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[transaction_rules.RuleDeleteHandler] parse request failed, because %s", err.Error())
+		return nil, errs.NewIncompleteOrIncorrectSubmissionError(err)
+	}
+
+	uid := c.GetCurrentUid()
+	err = a.rules.DeleteRule(uid, ruleDeleteReq.Id)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[transaction_rules.RuleDeleteHandler] failed to delete rule \"id:%d\" for user \"uid:%d\", because %s", ruleDeleteReq.Id, uid, err.Error())
+		return nil, errs.Or(err, errs.ErrOperationFailed)
+	}
+
+	log.InfofWithRequestId(c, "[transaction_rules.RuleDeleteHandler] user \"uid:%d\" has deleted rule \"id:%d\"", uid, ruleDeleteReq.Id)
+	return true, nil
+}
+
+// RuleApplyHandler re-runs transaction rules over existed transactions of current user, and only returns the changes if it is a dry run
+func (a *TransactionRulesApi) RuleApplyHandler(c *core.Context) (interface{}, *errs.Error) {
+	var ruleApplyReq models.TransactionRuleApplyRequest
+	err := c.ShouldBindJSON(&ruleApplyReq)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[transaction_rules.RuleApplyHandler] parse request failed, because %s", err.Error())
+		return nil, errs.NewIncompleteOrIncorrectSubmissionError(err)
+	}
+
+	ruleIds, err := utils.StringArrayToInt64Array(ruleApplyReq.RuleIds)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[transaction_rules.RuleApplyHandler] parse rule ids failed, because %s", err.Error())
+		return nil, errs.ErrTransactionRuleIdInvalid
+	}
+
+	uid := c.GetCurrentUid()
+	user, err := a.users.GetUserById(uid)
+
+	if err != nil {
+		if !errs.IsCustomError(err) {
+			log.ErrorfWithRequestId(c, "[transaction_rules.RuleApplyHandler] failed to get user, because %s", err.Error())
+		}
+
+		return nil, errs.ErrUserNotFound
+	}
+
+	var rules []*models.TransactionRule
+
+	if len(ruleIds) > 0 {
+		rules, err = a.rules.GetRulesByRuleIds(uid, ruleIds)
+	} else {
+		rules, err = a.rules.GetAllEnabledRulesByUid(uid)
+	}
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[transaction_rules.RuleApplyHandler] failed to get rules for user \"uid:%d\", because %s", uid, err.Error())
+		return nil, errs.Or(err, errs.ErrOperationFailed)
+	}
+
+	accounts, err := a.accounts.GetAllAccountsByUid(uid)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[transaction_rules.RuleApplyHandler] failed to get all accounts for user \"uid:%d\", because %s", uid, err.Error())
+		return nil, errs.ErrOperationFailed
+	}
+
+	accountMap := a.accounts.GetAccountMapByList(accounts)
+
+	applyResp := &models.TransactionRuleApplyResponse{
+		DryRun: ruleApplyReq.DryRun,
+		Items:  make([]*models.TransactionRuleApplyResponseItem, 0),
+	}
+
+	if len(rules) < 1 {
+		return applyResp, nil
+	}
+
+	var batchItems []*models.TransactionBatchItem
+	maxTransactionTime := int64(0)
+	minTransactionTime := int64(0)
+
+	if ruleApplyReq.EndTime > 0 {
+		maxTransactionTime = utils.GetMaxTransactionTimeFromUnixTime(ruleApplyReq.EndTime)
+	}
+
+	if ruleApplyReq.StartTime > 0 {
+		minTransactionTime = utils.GetMinTransactionTimeFromUnixTime(ruleApplyReq.StartTime)
+	}
+
+	for {
+		transactions, err := a.transactions.GetTransactionsByMaxTime(uid, maxTransactionTime, minTransactionTime, 0, nil, nil, nil, nil, models.TRANSACTION_TAG_FILTER_HAS_ANY, "", pageCountForApplyTransactionRules, true)
+
+		if err != nil {
+			log.ErrorfWithRequestId(c, "[transaction_rules.RuleApplyHandler] failed to get transactions for user \"uid:%d\", because %s", uid, err.Error())
+			return nil, errs.ErrOperationFailed
+		}
+
+		if len(transactions) < 1 {
+			break
+		}
+
+		transactionIds := make([]int64, len(transactions))
+
+		for i := 0; i < len(transactions); i++ {
+			transactionIds[i] = transactions[i].TransactionId
+		}
+
+		allTransactionTagIds, err := a.transactionTags.GetAllTagIdsOfTransactions(uid, transactionIds)
+
+		if err != nil {
+			log.ErrorfWithRequestId(c, "[transaction_rules.RuleApplyHandler] failed to get transactions tag ids for user \"uid:%d\", because %s", uid, err.Error())
+			return nil, errs.ErrOperationFailed
+		}
+
+		allTransactionSplits, err := a.transactionSplits.GetAllSplitsOfTransactions(uid, transactionIds)
+
+		if err != nil {
+			log.ErrorfWithRequestId(c, "[transaction_rules.RuleApplyHandler] failed to get transaction splits for user \"uid:%d\", because %s", uid, err.Error())
+			return nil, errs.ErrOperationFailed
+		}
+
+		// Both sides of transfer would be changed, so the transfer is skipped if its transfer in side has been reconciled
+		reconciledTransactionIds, err := a.transactions.GetTransferTransactionIdsWithReconciledRelatedTransaction(uid, transactions)
+
+		if err != nil {
+			log.ErrorfWithRequestId(c, "[transaction_rules.RuleApplyHandler] failed to get reconciled related transactions for user \"uid:%d\", because %s", uid, err.Error())
+			return nil, errs.ErrOperationFailed
+		}
+
+		for i := 0; i < len(transactions); i++ {
+			transaction := transactions[i]
+
+			if !transaction.IsEditableInBulk(user, accountMap) || reconciledTransactionIds[transaction.TransactionId] {
+				continue
+			}
+
+			tagIds := allTransactionTagIds[transaction.TransactionId]
+			splits := allTransactionSplits[transaction.TransactionId]
+
+			newTransaction := *transaction
+			newTagIds, matchedRuleIds := a.rules.ApplyRulesToTransaction(rules, &newTransaction, tagIds, len(splits) > 0)
+
+			if len(matchedRuleIds) < 1 {
+				continue
+			}
+
+			addTagIds := utils.Int64SliceMinus(newTagIds, tagIds)
+
+			if newTransaction.CategoryId == transaction.CategoryId && newTransaction.HideAmount == transaction.HideAmount && len(addTagIds) < 1 {
+				continue
+			}
+
+			applyRespItem := &models.TransactionRuleApplyResponseItem{
+				TransactionId:  transaction.TransactionId,
+				MatchedRuleIds: utils.Int64ArrayToStringArray(matchedRuleIds),
+			}
+
+			if newTransaction.CategoryId != transaction.CategoryId {
+				applyRespItem.CategoryId = newTransaction.CategoryId
+			}
+
+			if len(addTagIds) > 0 {
+				applyRespItem.AddTagIds = utils.Int64ArrayToStringArray(addTagIds)
+			}
+
+			if newTransaction.HideAmount != transaction.HideAmount {
+				applyRespItem.HideAmount = newTransaction.HideAmount
+			}
+
+			applyResp.Items = append(applyResp.Items, applyRespItem)
+
+			batchItems = append(batchItems, &models.TransactionBatchItem{
+				Transaction: &newTransaction,
+				AddTagIds:   addTagIds,
+				Splits:      splits,
+			})
+		}
+
+		if len(transactions) < pageCountForApplyTransactionRules {
+			break
+		}
+
+		maxTransactionTime = transactions[len(transactions)-1].TransactionTime - 1
+	}
+
+	if ruleApplyReq.DryRun || len(batchItems) < 1 {
+		return applyResp, nil
+	}
+
+	_, err = a.transactions.BatchModifyTransactions(uid, batchItems, models.NewTransactionOperator(c))
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[transaction_rules.RuleApplyHandler] failed to apply rules to transactions for user \"uid:%d\", because %s", uid, err.Error())
+		return nil, errs.Or(err, errs.ErrOperationFailed)
+	}
+
+	log.InfofWithRequestId(c, "[transaction_rules.RuleApplyHandler] user \"uid:%d\" has applied %d rules to %d transactions successfully", uid, len(rules), len(batchItems))
+	return applyResp, nil
+}
+
+func (a *TransactionRulesApi) getRuleConditionDbType(transactionType models.TransactionType) (models.TransactionDbType, *errs.Error) {
+	if transactionType == 0 {
+		return 0, nil
+	} else if transactionType == models.TRANSACTION_TYPE_MODIFY_BALANCE {
+		return models.TRANSACTION_DB_TYPE_MODIFY_BALANCE, nil
+	} else if transactionType == models.TRANSACTION_TYPE_EXPENSE {
+		return models.TRANSACTION_DB_TYPE_EXPENSE, nil
+	} else if transactionType == models.TRANSACTION_TYPE_INCOME {
+		return models.TRANSACTION_DB_TYPE_INCOME, nil
+	} else if transactionType == models.TRANSACTION_TYPE_TRANSFER {
+		return models.TRANSACTION_DB_TYPE_TRANSFER_OUT, nil
+	}
+
+	return 0, errs.ErrTransactionRuleTypeInvalid
+}
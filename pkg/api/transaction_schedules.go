@@ -0,0 +1,342 @@
+package api
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/mayswind/ezbookkeeping/pkg/core"
+	"github.com/mayswind/ezbookkeeping/pkg/errs"
+	"github.com/mayswind/ezbookkeeping/pkg/log"
+	"github.com/mayswind/ezbookkeeping/pkg/models"
+	"github.com/mayswind/ezbookkeeping/pkg/services"
+	"github.com/mayswind/ezbookkeeping/pkg/utils"
+)
+
+// TransactionSchedulesApi represents transaction schedule api
+type TransactionSchedulesApi struct {
+	schedules *services.TransactionScheduleService
+	users     *services.UserService
+}
+
+// Initialize a transaction schedule api singleton instance
+var (
+	TransactionSchedules = &TransactionSchedulesApi{
+		schedules: services.TransactionSchedules,
+		users:     services.Users,
+	}
+)
+
+// TransactionScheduleListHandler returns transaction schedule list of current user
+func (a *TransactionSchedulesApi) TransactionScheduleListHandler(c *core.Context) (interface{}, *errs.Error) {
+	uid := c.GetCurrentUid()
+	schedules, err := a.schedules.GetAllSchedulesByUid(uid)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[transaction_schedules.TransactionScheduleListHandler] failed to get transaction schedules for user \"uid:%d\", because %s", uid, err.Error())
+		return nil, errs.ErrOperationFailed
+	}
+
+	scheduleResps := make(models.TransactionScheduleInfoResponseSlice, 0, len(schedules))
+
+	for i := 0; i < len(schedules); i++ {
+		scheduleResp := schedules[i].ToTransactionScheduleInfoResponse()
+
+		if scheduleResp == nil {
+			log.WarnfWithRequestId(c, "[transaction_schedules.TransactionScheduleListHandler] transaction schedule \"id:%d\" of user \"uid:%d\" has invalid type", schedules[i].ScheduleId, uid)
+			continue
+		}
+
+		scheduleResps = append(scheduleResps, scheduleResp)
+	}
+
+	sort.Sort(scheduleResps)
+
+	return scheduleResps, nil
+}
+
+// TransactionScheduleGetHandler returns one specific transaction schedule of current user
+func (a *TransactionSchedulesApi) TransactionScheduleGetHandler(c *core.Context) (interface{}, *errs.Error) {
+	var scheduleGetReq models.TransactionScheduleGetRequest
+	err := c.ShouldBindQuery(&scheduleGetReq)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[transaction_schedules.TransactionScheduleGetHandler] parse request failed, because %s", err.Error())
+		return nil, errs.NewIncompleteOrIncorrectSubmissionError(err)
+	}
+
+	uid := c.GetCurrentUid()
+	schedule, err := a.schedules.GetScheduleByScheduleId(uid, scheduleGetReq.Id)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[transaction_schedules.TransactionScheduleGetHandler] failed to get transaction schedule \"id:%d\" for user \"uid:%d\", because %s", scheduleGetReq.Id, uid, err.Error())
+		return nil, errs.Or(err, errs.ErrOperationFailed)
+	}
+
+	scheduleResp := schedule.ToTransactionScheduleInfoResponse()
+
+	if scheduleResp == nil {
+		return nil, errs.ErrTransactionTypeInvalid
+	}
+
+	return scheduleResp, nil
+}
+
+// TransactionScheduleCreateHandler saves a new transaction schedule by request parameters for current user
+func (a *TransactionSchedulesApi) TransactionScheduleCreateHandler(c *core.Context) (interface{}, *errs.Error) {
+	var scheduleCreateReq models.TransactionScheduleCreateRequest
+	err := c.ShouldBindJSON(&scheduleCreateReq)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[transaction_schedules.TransactionScheduleCreateHandler] parse request failed, because %s", err.Error())
+		return nil, errs.NewIncompleteOrIncorrectSubmissionError(err)
+	}
+
+	tagIds, err := utils.StringArrayToInt64Array(scheduleCreateReq.TagIds)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[transaction_schedules.TransactionScheduleCreateHandler] parse tag ids failed, because %s", err.Error())
+		return nil, errs.ErrTransactionTagIdInvalid
+	}
+
+	if scheduleCreateReq.Type < models.TRANSACTION_TYPE_MODIFY_BALANCE || scheduleCreateReq.Type > models.TRANSACTION_TYPE_TRANSFER {
+		log.WarnfWithRequestId(c, "[transaction_schedules.TransactionScheduleCreateHandler] transaction type is invalid")
+		return nil, errs.ErrTransactionTypeInvalid
+	}
+
+	if scheduleCreateReq.Type == models.TRANSACTION_TYPE_MODIFY_BALANCE {
+		log.WarnfWithRequestId(c, "[transaction_schedules.TransactionScheduleCreateHandler] balance modification transaction cannot be scheduled")
+		return nil, errs.ErrBalanceModificationTransactionCannotBeScheduled
+	}
+
+	if scheduleCreateReq.Type != models.TRANSACTION_TYPE_TRANSFER && scheduleCreateReq.DestinationAccountId != 0 {
+		log.WarnfWithRequestId(c, "[transaction_schedules.TransactionScheduleCreateHandler] non-transfer transaction destination account cannot be set")
+		return nil, errs.ErrTransactionDestinationAccountCannotBeSet
+	} else if scheduleCreateReq.Type == models.TRANSACTION_TYPE_TRANSFER && scheduleCreateReq.SourceAccountId == scheduleCreateReq.DestinationAccountId {
+		log.WarnfWithRequestId(c, "[transaction_schedules.TransactionScheduleCreateHandler] transfer transaction source account must not be destination account")
+		return nil, errs.ErrTransactionSourceAndDestinationIdCannotBeEqual
+	}
+
+	if scheduleCreateReq.Type != models.TRANSACTION_TYPE_TRANSFER && scheduleCreateReq.DestinationAmount != 0 {
+		log.WarnfWithRequestId(c, "[transaction_schedules.TransactionScheduleCreateHandler] non-transfer transaction destination amount cannot be set")
+		return nil, errs.ErrTransactionDestinationAmountCannotBeSet
+	}
+
+	if scheduleCreateReq.Frequency < models.TRANSACTION_SCHEDULE_FREQUENCY_DAILY || scheduleCreateReq.Frequency > models.TRANSACTION_SCHEDULE_FREQUENCY_YEARLY {
+		log.WarnfWithRequestId(c, "[transaction_schedules.TransactionScheduleCreateHandler] transaction schedule frequency is invalid")
+		return nil, errs.ErrTransactionScheduleFrequencyInvalid
+	}
+
+	if scheduleCreateReq.EndTime > 0 && scheduleCreateReq.EndTime < scheduleCreateReq.StartTime {
+		log.WarnfWithRequestId(c, "[transaction_schedules.TransactionScheduleCreateHandler] transaction schedule end time is earlier than start time")
+		return nil, errs.ErrTransactionScheduleEndTimeInvalid
+	}
+
+	uid := c.GetCurrentUid()
+	user, err := a.users.GetUserById(uid)
+
+	if err != nil {
+		if !errs.IsCustomError(err) {
+			log.ErrorfWithRequestId(c, "[transaction_schedules.TransactionScheduleCreateHandler] failed to get user, because %s", err.Error())
+		}
+
+		return nil, errs.ErrUserNotFound
+	}
+
+	if !user.CanEditTransactionByTransactionTime(utils.GetMinTransactionTimeFromUnixTime(scheduleCreateReq.StartTime), scheduleCreateReq.UtcOffset) {
+		return nil, errs.ErrCannotCreateTransactionWithThisTransactionTime
+	}
+
+	schedule := a.createNewScheduleModel(uid, &scheduleCreateReq)
+
+	err = a.schedules.CreateSchedule(schedule, tagIds)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[transaction_schedules.TransactionScheduleCreateHandler] failed to create transaction schedule \"id:%d\" for user \"uid:%d\", because %s", schedule.ScheduleId, uid, err.Error())
+		return nil, errs.Or(err, errs.ErrOperationFailed)
+	}
+
+	log.InfofWithRequestId(c, "[transaction_schedules.TransactionScheduleCreateHandler] user \"uid:%d\" has created a new transaction schedule \"id:%d\" successfully", uid, schedule.ScheduleId)
+
+	scheduleResp := schedule.ToTransactionScheduleInfoResponse()
+
+	return scheduleResp, nil
+}
+
+// TransactionScheduleModifyHandler saves an existed transaction schedule by request parameters for current user
+func (a *TransactionSchedulesApi) TransactionScheduleModifyHandler(c *core.Context) (interface{}, *errs.Error) {
+	var scheduleModifyReq models.TransactionScheduleModifyRequest
+	err := c.ShouldBindJSON(&scheduleModifyReq)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[transaction_schedules.TransactionScheduleModifyHandler] parse request failed, because %s", err.Error())
+		return nil, errs.NewIncompleteOrIncorrectSubmissionError(err)
+	}
+
+	tagIds, err := utils.StringArrayToInt64Array(scheduleModifyReq.TagIds)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[transaction_schedules.TransactionScheduleModifyHandler] parse tag ids failed, because %s", err.Error())
+		return nil, errs.ErrTransactionTagIdInvalid
+	}
+
+	if scheduleModifyReq.Frequency < models.TRANSACTION_SCHEDULE_FREQUENCY_DAILY || scheduleModifyReq.Frequency > models.TRANSACTION_SCHEDULE_FREQUENCY_YEARLY {
+		log.WarnfWithRequestId(c, "[transaction_schedules.TransactionScheduleModifyHandler] transaction schedule frequency is invalid")
+		return nil, errs.ErrTransactionScheduleFrequencyInvalid
+	}
+
+	if scheduleModifyReq.EndTime > 0 && scheduleModifyReq.EndTime < scheduleModifyReq.StartTime {
+		log.WarnfWithRequestId(c, "[transaction_schedules.TransactionScheduleModifyHandler] transaction schedule end time is earlier than start time")
+		return nil, errs.ErrTransactionScheduleEndTimeInvalid
+	}
+
+	uid := c.GetCurrentUid()
+	user, err := a.users.GetUserById(uid)
+
+	if err != nil {
+		if !errs.IsCustomError(err) {
+			log.ErrorfWithRequestId(c, "[transaction_schedules.TransactionScheduleModifyHandler] failed to get user, because %s", err.Error())
+		}
+
+		return nil, errs.ErrUserNotFound
+	}
+
+	schedule, err := a.schedules.GetScheduleByScheduleId(uid, scheduleModifyReq.Id)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[transaction_schedules.TransactionScheduleModifyHandler] failed to get transaction schedule \"id:%d\" for user \"uid:%d\", because %s", scheduleModifyReq.Id, uid, err.Error())
+		return nil, errs.Or(err, errs.ErrOperationFailed)
+	}
+
+	if schedule.Type != models.TRANSACTION_DB_TYPE_TRANSFER_OUT && scheduleModifyReq.DestinationAccountId != 0 {
+		log.WarnfWithRequestId(c, "[transaction_schedules.TransactionScheduleModifyHandler] non-transfer transaction destination account cannot be set")
+		return nil, errs.ErrTransactionDestinationAccountCannotBeSet
+	} else if schedule.Type == models.TRANSACTION_DB_TYPE_TRANSFER_OUT && scheduleModifyReq.SourceAccountId == scheduleModifyReq.DestinationAccountId {
+		log.WarnfWithRequestId(c, "[transaction_schedules.TransactionScheduleModifyHandler] transfer transaction source account must not be destination account")
+		return nil, errs.ErrTransactionSourceAndDestinationIdCannotBeEqual
+	}
+
+	if schedule.Type != models.TRANSACTION_DB_TYPE_TRANSFER_OUT && scheduleModifyReq.DestinationAmount != 0 {
+		log.WarnfWithRequestId(c, "[transaction_schedules.TransactionScheduleModifyHandler] non-transfer transaction destination amount cannot be set")
+		return nil, errs.ErrTransactionDestinationAmountCannotBeSet
+	}
+
+	newSchedule := &models.TransactionSchedule{
+		ScheduleId:           schedule.ScheduleId,
+		Uid:                  uid,
+		Type:                 schedule.Type,
+		CategoryId:           scheduleModifyReq.CategoryId,
+		AccountId:            scheduleModifyReq.SourceAccountId,
+		RelatedAccountId:     scheduleModifyReq.DestinationAccountId,
+		Amount:               scheduleModifyReq.SourceAmount,
+		RelatedAccountAmount: scheduleModifyReq.DestinationAmount,
+		HideAmount:           scheduleModifyReq.HideAmount,
+		TagIds:               strings.Join(utils.Int64ArrayToStringArray(utils.ToUniqueInt64Slice(tagIds)), ","),
+		Comment:              scheduleModifyReq.Comment,
+		TimezoneUtcOffset:    scheduleModifyReq.UtcOffset,
+		Frequency:            scheduleModifyReq.Frequency,
+		FrequencyInterval:    scheduleModifyReq.Interval,
+		StartUnixTime:        scheduleModifyReq.StartTime,
+		EndUnixTime:          scheduleModifyReq.EndTime,
+		ExecutedCount:        schedule.ExecutedCount,
+	}
+
+	recurrenceChanged := newSchedule.TimezoneUtcOffset != schedule.TimezoneUtcOffset ||
+		newSchedule.Frequency != schedule.Frequency ||
+		newSchedule.FrequencyInterval != schedule.FrequencyInterval ||
+		newSchedule.StartUnixTime != schedule.StartUnixTime
+
+	if !recurrenceChanged &&
+		newSchedule.CategoryId == schedule.CategoryId &&
+		newSchedule.AccountId == schedule.AccountId &&
+		newSchedule.RelatedAccountId == schedule.RelatedAccountId &&
+		newSchedule.Amount == schedule.Amount &&
+		newSchedule.RelatedAccountAmount == schedule.RelatedAccountAmount &&
+		newSchedule.HideAmount == schedule.HideAmount &&
+		newSchedule.TagIds == schedule.TagIds &&
+		newSchedule.Comment == schedule.Comment &&
+		newSchedule.EndUnixTime == schedule.EndUnixTime {
+		return nil, errs.ErrNothingWillBeUpdated
+	}
+
+	if recurrenceChanged {
+		if !user.CanEditTransactionByTransactionTime(utils.GetMinTransactionTimeFromUnixTime(newSchedule.StartUnixTime), newSchedule.TimezoneUtcOffset) {
+			return nil, errs.ErrCannotModifyTransactionWithThisTransactionTime
+		}
+
+		// The occurrences which have been executed should not be created again, so the next occurrence is the first one after the last executed occurrence
+		if schedule.ExecutedCount > 0 {
+			newSchedule.ExecutedCount = newSchedule.GetOccurrenceCountUntilUnixTime(schedule.GetOccurrenceUnixTime(schedule.ExecutedCount - 1))
+		} else {
+			newSchedule.ExecutedCount = 0
+		}
+	}
+
+	err = a.schedules.ModifySchedule(newSchedule, schedule, tagIds)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[transaction_schedules.TransactionScheduleModifyHandler] failed to update transaction schedule \"id:%d\" for user \"uid:%d\", because %s", scheduleModifyReq.Id, uid, err.Error())
+		return nil, errs.Or(err, errs.ErrOperationFailed)
+	}
+
+	log.InfofWithRequestId(c, "[transaction_schedules.TransactionScheduleModifyHandler] user \"uid:%d\" has updated transaction schedule \"id:%d\" successfully", uid, scheduleModifyReq.Id)
+
+	newScheduleResp := newSchedule.ToTransactionScheduleInfoResponse()
+
+	return newScheduleResp, nil
+}
+
+// TransactionScheduleDeleteHandler deletes an existed transaction schedule by request parameters for current user
+func (a *TransactionSchedulesApi) TransactionScheduleDeleteHandler(c *core.Context) (interface{}, *errs.Error) {
+	var scheduleDeleteReq models.TransactionScheduleDeleteRequest
+	err := c.ShouldBindJSON(&scheduleDeleteReq)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[transaction_schedules.TransactionScheduleDeleteHandler] parse request failed, because %s", err.Error())
+		return nil, errs.NewIncompleteOrIncorrectSubmissionError(err)
+	}
+
+	uid := c.GetCurrentUid()
+	err = a.schedules.DeleteSchedule(uid, scheduleDeleteReq.Id)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[transaction_schedules.TransactionScheduleDeleteHandler] failed to delete transaction schedule \"id:%d\" for user \"uid:%d\", because %s", scheduleDeleteReq.Id, uid, err.Error())
+		return nil, errs.Or(err, errs.ErrOperationFailed)
+	}
+
+	log.InfofWithRequestId(c, "[transaction_schedules.TransactionScheduleDeleteHandler] user \"uid:%d\" has deleted transaction schedule \"id:%d\"", uid, scheduleDeleteReq.Id)
+	return true, nil
+}
+
+func (a *TransactionSchedulesApi) createNewScheduleModel(uid int64, scheduleCreateReq *models.TransactionScheduleCreateRequest) *models.TransactionSchedule {
+	var transactionDbType models.TransactionDbType
+
+	if scheduleCreateReq.Type == models.TRANSACTION_TYPE_EXPENSE {
+		transactionDbType = models.TRANSACTION_DB_TYPE_EXPENSE
+	} else if scheduleCreateReq.Type == models.TRANSACTION_TYPE_INCOME {
+		transactionDbType = models.TRANSACTION_DB_TYPE_INCOME
+	} else if scheduleCreateReq.Type == models.TRANSACTION_TYPE_TRANSFER {
+		transactionDbType = models.TRANSACTION_DB_TYPE_TRANSFER_OUT
+	}
+
+	schedule := &models.TransactionSchedule{
+		Uid:               uid,
+		Type:              transactionDbType,
+		CategoryId:        scheduleCreateReq.CategoryId,
+		AccountId:         scheduleCreateReq.SourceAccountId,
+		Amount:            scheduleCreateReq.SourceAmount,
+		HideAmount:        scheduleCreateReq.HideAmount,
+		Comment:           scheduleCreateReq.Comment,
+		TimezoneUtcOffset: scheduleCreateReq.UtcOffset,
+		Frequency:         scheduleCreateReq.Frequency,
+		FrequencyInterval: scheduleCreateReq.Interval,
+		StartUnixTime:     scheduleCreateReq.StartTime,
+		EndUnixTime:       scheduleCreateReq.EndTime,
+	}
+
+	if scheduleCreateReq.Type == models.TRANSACTION_TYPE_TRANSFER {
+		schedule.RelatedAccountId = scheduleCreateReq.DestinationAccountId
+		schedule.RelatedAccountAmount = scheduleCreateReq.DestinationAmount
+	}
+
+	return schedule
+}
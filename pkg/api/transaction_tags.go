@@ -8,6 +8,7 @@ import (
 	"github.com/mayswind/ezbookkeeping/pkg/log"
 	"github.com/mayswind/ezbookkeeping/pkg/models"
 	"github.com/mayswind/ezbookkeeping/pkg/services"
+	"github.com/mayswind/ezbookkeeping/pkg/utils"
 )
 
 // TransactionTagsApi represents transaction tag api
@@ -201,6 +202,35 @@ func (a *TransactionTagsApi) TagMoveHandler(c *core.Context) (interface{}, *errs
 	return true, nil
 }
 
+// TagMergeHandler merges existed transaction tags into another one by request parameters for current user
+func (a *TransactionTagsApi) TagMergeHandler(c *core.Context) (interface{}, *errs.Error) {
+	var tagMergeReq models.TransactionTagMergeRequest
+	err := c.ShouldBindJSON(&tagMergeReq)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[transaction_tags.TagMergeHandler] parse request failed, because %s", err.Error())
+		return nil, errs.NewIncompleteOrIncorrectSubmissionError(err)
+	}
+
+	fromTagIds, err := utils.StringArrayToInt64Array(tagMergeReq.FromIds)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[transaction_tags.TagMergeHandler] parse tag ids failed, because %s", err.Error())
+		return nil, errs.ErrTransactionTagIdInvalid
+	}
+
+	uid := c.GetCurrentUid()
+	err = a.tags.MergeTags(uid, fromTagIds, tagMergeReq.ToId)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[transaction_tags.TagMergeHandler] failed to merge tags into tag \"id:%d\" for user \"uid:%d\", because %s", tagMergeReq.ToId, uid, err.Error())
+		return nil, errs.Or(err, errs.ErrOperationFailed)
+	}
+
+	log.InfofWithRequestId(c, "[transaction_tags.TagMergeHandler] user \"uid:%d\" has merged %d tags into tag \"id:%d\"", uid, len(fromTagIds), tagMergeReq.ToId)
+	return true, nil
+}
+
 // TagDeleteHandler deletes an existed transaction tag by request parameters for current user
 func (a *TransactionTagsApi) TagDeleteHandler(c *core.Context) (interface{}, *errs.Error) {
 	var tagDeleteReq models.TransactionTagDeleteRequest
@@ -0,0 +1,264 @@
+package api
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/mayswind/ezbookkeeping/pkg/core"
+	"github.com/mayswind/ezbookkeeping/pkg/errs"
+	"github.com/mayswind/ezbookkeeping/pkg/log"
+	"github.com/mayswind/ezbookkeeping/pkg/models"
+	"github.com/mayswind/ezbookkeeping/pkg/services"
+	"github.com/mayswind/ezbookkeeping/pkg/utils"
+)
+
+// TransactionTemplatesApi represents transaction template api
+type TransactionTemplatesApi struct {
+	templates *services.TransactionTemplateService
+}
+
+// Initialize a transaction template api singleton instance
+var (
+	TransactionTemplates = &TransactionTemplatesApi{
+		templates: services.TransactionTemplates,
+	}
+)
+
+// TransactionTemplateListHandler returns transaction template list of current user
+func (a *TransactionTemplatesApi) TransactionTemplateListHandler(c *core.Context) (interface{}, *errs.Error) {
+	uid := c.GetCurrentUid()
+	templates, err := a.templates.GetAllTemplatesByUid(uid)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[transaction_templates.TransactionTemplateListHandler] failed to get transaction templates for user \"uid:%d\", because %s", uid, err.Error())
+		return nil, errs.ErrOperationFailed
+	}
+
+	templateResps := make(models.TransactionTemplateInfoResponseSlice, len(templates))
+
+	for i := 0; i < len(templates); i++ {
+		templateResps[i] = templates[i].ToTransactionTemplateInfoResponse()
+	}
+
+	sort.Sort(templateResps)
+
+	return templateResps, nil
+}
+
+// TransactionTemplateGetHandler returns one specific transaction template of current user
+func (a *TransactionTemplatesApi) TransactionTemplateGetHandler(c *core.Context) (interface{}, *errs.Error) {
+	var templateGetReq models.TransactionTemplateGetRequest
+	err := c.ShouldBindQuery(&templateGetReq)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[transaction_templates.TransactionTemplateGetHandler] parse request failed, because %s", err.Error())
+		return nil, errs.NewIncompleteOrIncorrectSubmissionError(err)
+	}
+
+	uid := c.GetCurrentUid()
+	template, err := a.templates.GetTemplateByTemplateId(uid, templateGetReq.Id)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[transaction_templates.TransactionTemplateGetHandler] failed to get transaction template \"id:%d\" for user \"uid:%d\", because %s", templateGetReq.Id, uid, err.Error())
+		return nil, errs.Or(err, errs.ErrOperationFailed)
+	}
+
+	templateResp := template.ToTransactionTemplateInfoResponse()
+
+	return templateResp, nil
+}
+
+// TransactionTemplateCreateHandler saves a new transaction template by request parameters for current user
+func (a *TransactionTemplatesApi) TransactionTemplateCreateHandler(c *core.Context) (interface{}, *errs.Error) {
+	var templateCreateReq models.TransactionTemplateCreateRequest
+	err := c.ShouldBindJSON(&templateCreateReq)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[transaction_templates.TransactionTemplateCreateHandler] parse request failed, because %s", err.Error())
+		return nil, errs.NewIncompleteOrIncorrectSubmissionError(err)
+	}
+
+	tagIds, err := utils.StringArrayToInt64Array(templateCreateReq.TagIds)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[transaction_templates.TransactionTemplateCreateHandler] parse tag ids failed, because %s", err.Error())
+		return nil, errs.ErrTransactionTagIdInvalid
+	}
+
+	if templateCreateReq.Type < models.TRANSACTION_TYPE_MODIFY_BALANCE || templateCreateReq.Type > models.TRANSACTION_TYPE_TRANSFER {
+		log.WarnfWithRequestId(c, "[transaction_templates.TransactionTemplateCreateHandler] transaction type is invalid")
+		return nil, errs.ErrTransactionTypeInvalid
+	}
+
+	if templateCreateReq.Type == models.TRANSACTION_TYPE_MODIFY_BALANCE && templateCreateReq.CategoryId > 0 {
+		log.WarnfWithRequestId(c, "[transaction_templates.TransactionTemplateCreateHandler] balance modification transaction cannot set category id")
+		return nil, errs.ErrBalanceModificationTransactionCannotSetCategory
+	}
+
+	if templateCreateReq.Type != models.TRANSACTION_TYPE_TRANSFER && templateCreateReq.DestinationAccountId != 0 {
+		log.WarnfWithRequestId(c, "[transaction_templates.TransactionTemplateCreateHandler] non-transfer transaction destination account cannot be set")
+		return nil, errs.ErrTransactionDestinationAccountCannotBeSet
+	} else if templateCreateReq.Type == models.TRANSACTION_TYPE_TRANSFER && templateCreateReq.SourceAccountId == templateCreateReq.DestinationAccountId {
+		log.WarnfWithRequestId(c, "[transaction_templates.TransactionTemplateCreateHandler] transfer transaction source account must not be destination account")
+		return nil, errs.ErrTransactionSourceAndDestinationIdCannotBeEqual
+	}
+
+	if templateCreateReq.Type != models.TRANSACTION_TYPE_TRANSFER && templateCreateReq.DestinationAmount != 0 {
+		log.WarnfWithRequestId(c, "[transaction_templates.TransactionTemplateCreateHandler] non-transfer transaction destination amount cannot be set")
+		return nil, errs.ErrTransactionDestinationAmountCannotBeSet
+	}
+
+	uid := c.GetCurrentUid()
+	template := a.createNewTemplateModel(uid, &templateCreateReq)
+
+	err = a.templates.CreateTemplate(template, tagIds)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[transaction_templates.TransactionTemplateCreateHandler] failed to create transaction template \"id:%d\" for user \"uid:%d\", because %s", template.TemplateId, uid, err.Error())
+		return nil, errs.Or(err, errs.ErrOperationFailed)
+	}
+
+	log.InfofWithRequestId(c, "[transaction_templates.TransactionTemplateCreateHandler] user \"uid:%d\" has created a new transaction template \"id:%d\" successfully", uid, template.TemplateId)
+
+	templateResp := template.ToTransactionTemplateInfoResponse()
+
+	return templateResp, nil
+}
+
+// TransactionTemplateModifyHandler saves an existed transaction template by request parameters for current user
+func (a *TransactionTemplatesApi) TransactionTemplateModifyHandler(c *core.Context) (interface{}, *errs.Error) {
+	var templateModifyReq models.TransactionTemplateModifyRequest
+	err := c.ShouldBindJSON(&templateModifyReq)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[transaction_templates.TransactionTemplateModifyHandler] parse request failed, because %s", err.Error())
+		return nil, errs.NewIncompleteOrIncorrectSubmissionError(err)
+	}
+
+	tagIds, err := utils.StringArrayToInt64Array(templateModifyReq.TagIds)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[transaction_templates.TransactionTemplateModifyHandler] parse tag ids failed, because %s", err.Error())
+		return nil, errs.ErrTransactionTagIdInvalid
+	}
+
+	uid := c.GetCurrentUid()
+	template, err := a.templates.GetTemplateByTemplateId(uid, templateModifyReq.Id)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[transaction_templates.TransactionTemplateModifyHandler] failed to get transaction template \"id:%d\" for user \"uid:%d\", because %s", templateModifyReq.Id, uid, err.Error())
+		return nil, errs.Or(err, errs.ErrOperationFailed)
+	}
+
+	if template.Type == models.TRANSACTION_DB_TYPE_MODIFY_BALANCE && templateModifyReq.CategoryId > 0 {
+		log.WarnfWithRequestId(c, "[transaction_templates.TransactionTemplateModifyHandler] balance modification transaction cannot set category id")
+		return nil, errs.ErrBalanceModificationTransactionCannotSetCategory
+	}
+
+	if template.Type != models.TRANSACTION_DB_TYPE_TRANSFER_OUT && templateModifyReq.DestinationAccountId != 0 {
+		log.WarnfWithRequestId(c, "[transaction_templates.TransactionTemplateModifyHandler] non-transfer transaction destination account cannot be set")
+		return nil, errs.ErrTransactionDestinationAccountCannotBeSet
+	} else if template.Type == models.TRANSACTION_DB_TYPE_TRANSFER_OUT && templateModifyReq.SourceAccountId == templateModifyReq.DestinationAccountId {
+		log.WarnfWithRequestId(c, "[transaction_templates.TransactionTemplateModifyHandler] transfer transaction source account must not be destination account")
+		return nil, errs.ErrTransactionSourceAndDestinationIdCannotBeEqual
+	}
+
+	if template.Type != models.TRANSACTION_DB_TYPE_TRANSFER_OUT && templateModifyReq.DestinationAmount != 0 {
+		log.WarnfWithRequestId(c, "[transaction_templates.TransactionTemplateModifyHandler] non-transfer transaction destination amount cannot be set")
+		return nil, errs.ErrTransactionDestinationAmountCannotBeSet
+	}
+
+	newTemplate := &models.TransactionTemplate{
+		TemplateId:           template.TemplateId,
+		Uid:                  uid,
+		Name:                 templateModifyReq.Name,
+		Type:                 template.Type,
+		CategoryId:           templateModifyReq.CategoryId,
+		AccountId:            templateModifyReq.SourceAccountId,
+		RelatedAccountId:     templateModifyReq.DestinationAccountId,
+		Amount:               templateModifyReq.SourceAmount,
+		RelatedAccountAmount: templateModifyReq.DestinationAmount,
+		HideAmount:           templateModifyReq.HideAmount,
+		TagIds:               strings.Join(utils.Int64ArrayToStringArray(utils.ToUniqueInt64Slice(tagIds)), ","),
+		Comment:              templateModifyReq.Comment,
+	}
+
+	if newTemplate.Name == template.Name &&
+		newTemplate.CategoryId == template.CategoryId &&
+		newTemplate.AccountId == template.AccountId &&
+		newTemplate.RelatedAccountId == template.RelatedAccountId &&
+		newTemplate.Amount == template.Amount &&
+		newTemplate.RelatedAccountAmount == template.RelatedAccountAmount &&
+		newTemplate.HideAmount == template.HideAmount &&
+		newTemplate.TagIds == template.TagIds &&
+		newTemplate.Comment == template.Comment {
+		return nil, errs.ErrNothingWillBeUpdated
+	}
+
+	err = a.templates.ModifyTemplate(newTemplate, tagIds)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[transaction_templates.TransactionTemplateModifyHandler] failed to update transaction template \"id:%d\" for user \"uid:%d\", because %s", templateModifyReq.Id, uid, err.Error())
+		return nil, errs.Or(err, errs.ErrOperationFailed)
+	}
+
+	log.InfofWithRequestId(c, "[transaction_templates.TransactionTemplateModifyHandler] user \"uid:%d\" has updated transaction template \"id:%d\" successfully", uid, templateModifyReq.Id)
+
+	newTemplateResp := newTemplate.ToTransactionTemplateInfoResponse()
+
+	return newTemplateResp, nil
+}
+
+// TransactionTemplateDeleteHandler deletes an existed transaction template by request parameters for current user
+func (a *TransactionTemplatesApi) TransactionTemplateDeleteHandler(c *core.Context) (interface{}, *errs.Error) {
+	var templateDeleteReq models.TransactionTemplateDeleteRequest
+	err := c.ShouldBindJSON(&templateDeleteReq)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[transaction_templates.TransactionTemplateDeleteHandler] parse request failed, because %s", err.Error())
+		return nil, errs.NewIncompleteOrIncorrectSubmissionError(err)
+	}
+
+	uid := c.GetCurrentUid()
+	err = a.templates.DeleteTemplate(uid, templateDeleteReq.Id)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[transaction_templates.TransactionTemplateDeleteHandler] failed to delete transaction template \"id:%d\" for user \"uid:%d\", because %s", templateDeleteReq.Id, uid, err.Error())
+		return nil, errs.Or(err, errs.ErrOperationFailed)
+	}
+
+	log.InfofWithRequestId(c, "[transaction_templates.TransactionTemplateDeleteHandler] user \"uid:%d\" has deleted transaction template \"id:%d\"", uid, templateDeleteReq.Id)
+	return true, nil
+}
+
+func (a *TransactionTemplatesApi) createNewTemplateModel(uid int64, templateCreateReq *models.TransactionTemplateCreateRequest) *models.TransactionTemplate {
+	var transactionDbType models.TransactionDbType
+
+	if templateCreateReq.Type == models.TRANSACTION_TYPE_MODIFY_BALANCE {
+		transactionDbType = models.TRANSACTION_DB_TYPE_MODIFY_BALANCE
+	} else if templateCreateReq.Type == models.TRANSACTION_TYPE_EXPENSE {
+		transactionDbType = models.TRANSACTION_DB_TYPE_EXPENSE
+	} else if templateCreateReq.Type == models.TRANSACTION_TYPE_INCOME {
+		transactionDbType = models.TRANSACTION_DB_TYPE_INCOME
+	} else if templateCreateReq.Type == models.TRANSACTION_TYPE_TRANSFER {
+		transactionDbType = models.TRANSACTION_DB_TYPE_TRANSFER_OUT
+	}
+
+	template := &models.TransactionTemplate{
+		Uid:        uid,
+		Name:       templateCreateReq.Name,
+		Type:       transactionDbType,
+		CategoryId: templateCreateReq.CategoryId,
+		AccountId:  templateCreateReq.SourceAccountId,
+		Amount:     templateCreateReq.SourceAmount,
+		HideAmount: templateCreateReq.HideAmount,
+		Comment:    templateCreateReq.Comment,
+	}
+
+	if templateCreateReq.Type == models.TRANSACTION_TYPE_TRANSFER {
+		template.RelatedAccountId = templateCreateReq.DestinationAccountId
+		template.RelatedAccountAmount = templateCreateReq.DestinationAmount
+	}
+
+	return template
+}
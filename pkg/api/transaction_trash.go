@@ -0,0 +1,180 @@
+package api
+
+import (
+	"github.com/mayswind/ezbookkeeping/pkg/core"
+	"github.com/mayswind/ezbookkeeping/pkg/errs"
+	"github.com/mayswind/ezbookkeeping/pkg/log"
+	"github.com/mayswind/ezbookkeeping/pkg/models"
+	"github.com/mayswind/ezbookkeeping/pkg/services"
+	"github.com/mayswind/ezbookkeeping/pkg/utils"
+)
+
+// TransactionTrashApi represents transaction trash api
+type TransactionTrashApi struct {
+	transactions          *services.TransactionService
+	transactionCategories *services.TransactionCategoryService
+	accounts              *services.AccountService
+	users                 *services.UserService
+}
+
+// Initialize a transaction trash api singleton instance
+var (
+	TransactionTrash = &TransactionTrashApi{
+		transactions:          services.Transactions,
+		transactionCategories: services.TransactionCategories,
+		accounts:              services.Accounts,
+		users:                 services.Users,
+	}
+)
+
+// TransactionTrashListHandler returns deleted transaction list in trash of current user
+func (a *TransactionTrashApi) TransactionTrashListHandler(c *core.Context) (interface{}, *errs.Error) {
+	var trashListReq models.TransactionTrashListRequest
+	err := c.ShouldBindQuery(&trashListReq)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[transaction_trash.TransactionTrashListHandler] parse request failed, because %s", err.Error())
+		return nil, errs.NewIncompleteOrIncorrectSubmissionError(err)
+	}
+
+	uid := c.GetCurrentUid()
+	transactions, err := a.transactions.GetDeletedTransactionsByPage(uid, trashListReq.Page, trashListReq.Count)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[transaction_trash.TransactionTrashListHandler] failed to get deleted transactions for user \"uid:%d\", because %s", uid, err.Error())
+		return nil, errs.ErrOperationFailed
+	}
+
+	totalCount, err := a.transactions.GetDeletedTransactionCount(uid)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[transaction_trash.TransactionTrashListHandler] failed to get deleted transaction count for user \"uid:%d\", because %s", uid, err.Error())
+		return nil, errs.ErrOperationFailed
+	}
+
+	accountIds := make([]int64, 0, len(transactions)*2)
+	categoryIds := make([]int64, 0, len(transactions))
+
+	for i := 0; i < len(transactions); i++ {
+		accountIds = append(accountIds, transactions[i].AccountId)
+
+		if transactions[i].Type == models.TRANSACTION_DB_TYPE_TRANSFER_OUT {
+			accountIds = append(accountIds, transactions[i].RelatedAccountId)
+		}
+
+		categoryIds = append(categoryIds, transactions[i].CategoryId)
+	}
+
+	allAccounts, err := a.accounts.GetAccountsByAccountIds(uid, utils.ToUniqueInt64Slice(accountIds))
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[transaction_trash.TransactionTrashListHandler] failed to get accounts for user \"uid:%d\", because %s", uid, err.Error())
+		return nil, errs.ErrOperationFailed
+	}
+
+	categoryMap, err := a.transactionCategories.GetCategoriesByCategoryIds(uid, utils.ToUniqueInt64Slice(categoryIds))
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[transaction_trash.TransactionTrashListHandler] failed to get transactions categories for user \"uid:%d\", because %s", uid, err.Error())
+		return nil, errs.ErrOperationFailed
+	}
+
+	transactionResps := make(models.TransactionInfoResponseSlice, len(transactions))
+
+	for i := 0; i < len(transactions); i++ {
+		transaction := transactions[i]
+		transactionResps[i] = transaction.ToTransactionInfoResponse(nil, false)
+		transactionResps[i].DeletedTime = transaction.DeletedUnixTime
+
+		if sourceAccount := allAccounts[transaction.AccountId]; sourceAccount != nil {
+			transactionResps[i].SourceAccount = sourceAccount.ToAccountInfoResponse()
+		}
+
+		if destinationAccount := allAccounts[transaction.RelatedAccountId]; destinationAccount != nil && transaction.Type == models.TRANSACTION_DB_TYPE_TRANSFER_OUT {
+			transactionResps[i].DestinationAccount = destinationAccount.ToAccountInfoResponse()
+		}
+
+		if category := categoryMap[transaction.CategoryId]; category != nil {
+			transactionResps[i].Category = category.ToTransactionCategoryInfoResponse()
+		}
+	}
+
+	trashResps := &models.TransactionInfoPageWrapperResponse2{
+		Items:      transactionResps,
+		TotalCount: totalCount,
+	}
+
+	return trashResps, nil
+}
+
+// TransactionRestoreHandler restores a deleted transaction in trash by request parameters for current user
+func (a *TransactionTrashApi) TransactionRestoreHandler(c *core.Context) (interface{}, *errs.Error) {
+	var transactionRestoreReq models.TransactionRestoreRequest
+	err := c.ShouldBindJSON(&transactionRestoreReq)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[transaction_trash.TransactionRestoreHandler] parse request failed, because %s", err.Error())
+		return nil, errs.NewIncompleteOrIncorrectSubmissionError(err)
+	}
+
+	utcOffset, err := c.GetClientTimezoneOffset()
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[transaction_trash.TransactionRestoreHandler] cannot get client timezone offset, because %s", err.Error())
+		return nil, errs.ErrClientTimezoneOffsetInvalid
+	}
+
+	uid := c.GetCurrentUid()
+	user, err := a.users.GetUserById(uid)
+
+	if err != nil {
+		if !errs.IsCustomError(err) {
+			log.ErrorfWithRequestId(c, "[transaction_trash.TransactionRestoreHandler] failed to get user, because %s", err.Error())
+		}
+
+		return nil, errs.ErrUserNotFound
+	}
+
+	transaction, err := a.transactions.GetDeletedTransactionByTransactionId(uid, transactionRestoreReq.Id)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[transaction_trash.TransactionRestoreHandler] failed to get deleted transaction \"id:%d\" for user \"uid:%d\", because %s", transactionRestoreReq.Id, uid, err.Error())
+		return nil, errs.Or(err, errs.ErrOperationFailed)
+	}
+
+	if !user.CanEditTransactionByTransactionTime(transaction.TransactionTime, utcOffset) {
+		return nil, errs.ErrCannotRestoreTransactionWithThisTransactionTime
+	}
+
+	err = a.transactions.RestoreTransaction(uid, transactionRestoreReq.Id, models.NewTransactionOperator(c))
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[transaction_trash.TransactionRestoreHandler] failed to restore transaction \"id:%d\" for user \"uid:%d\", because %s", transactionRestoreReq.Id, uid, err.Error())
+		return nil, errs.Or(err, errs.ErrOperationFailed)
+	}
+
+	log.InfofWithRequestId(c, "[transaction_trash.TransactionRestoreHandler] user \"uid:%d\" has restored transaction \"id:%d\"", uid, transactionRestoreReq.Id)
+	return true, nil
+}
+
+// TransactionPurgeHandler removes a deleted transaction in trash permanently by request parameters for current user
+func (a *TransactionTrashApi) TransactionPurgeHandler(c *core.Context) (interface{}, *errs.Error) {
+	var transactionPurgeReq models.TransactionPurgeRequest
+	err := c.ShouldBindJSON(&transactionPurgeReq)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[transaction_trash.TransactionPurgeHandler] parse request failed, because %s", err.Error())
+		return nil, errs.NewIncompleteOrIncorrectSubmissionError(err)
+	}
+
+	uid := c.GetCurrentUid()
+	err = a.transactions.PurgeTransaction(uid, transactionPurgeReq.Id, models.NewTransactionOperator(c))
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[transaction_trash.TransactionPurgeHandler] failed to purge transaction \"id:%d\" for user \"uid:%d\", because %s", transactionPurgeReq.Id, uid, err.Error())
+		return nil, errs.Or(err, errs.ErrOperationFailed)
+	}
+
+	log.InfofWithRequestId(c, "[transaction_trash.TransactionPurgeHandler] user \"uid:%d\" has purged transaction \"id:%d\"", uid, transactionPurgeReq.Id)
+	return true, nil
+}
@@ -1,8 +1,10 @@
 package api
 
 import (
+	"math"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/mayswind/ezbookkeeping/pkg/core"
 	"github.com/mayswind/ezbookkeeping/pkg/errs"
@@ -19,6 +21,10 @@ type TransactionsApi struct {
 	transactions          *services.TransactionService
 	transactionCategories *services.TransactionCategoryService
 	transactionTags       *services.TransactionTagService
+	transactionPayees     *services.TransactionPayeeService
+	transactionTemplates  *services.TransactionTemplateService
+	transactionSplits     *services.TransactionSplitService
+	transactionRules      *services.TransactionRuleService
 	accounts              *services.AccountService
 	users                 *services.UserService
 }
@@ -29,6 +35,10 @@ var (
 		transactions:          services.Transactions,
 		transactionCategories: services.TransactionCategories,
 		transactionTags:       services.TransactionTags,
+		transactionPayees:     services.TransactionPayees,
+		transactionTemplates:  services.TransactionTemplates,
+		transactionSplits:     services.TransactionSplits,
+		transactionRules:      services.TransactionRules,
 		accounts:              services.Accounts,
 		users:                 services.Users,
 	}
@@ -46,15 +56,19 @@ func (a *TransactionsApi) TransactionCountHandler(c *core.Context) (interface{},
 
 	uid := c.GetCurrentUid()
 
-	allCategoryIds, err := a.getCategoryAndSubCategoryIds(transactionCountReq.CategoryId, uid)
+	allCategoryIds, allAccountIds, allPayeeIds, allTagIds, errResp := a.getTransactionFilterIds(c, uid, transactionCountReq.CategoryId, transactionCountReq.CategoryIds, transactionCountReq.AccountId, transactionCountReq.AccountIds, transactionCountReq.PayeeIds, transactionCountReq.TagIds)
+
+	if errResp != nil {
+		return nil, errResp
+	}
+
+	totalCount, err := a.transactions.GetTransactionCount(uid, transactionCountReq.MaxTime, transactionCountReq.MinTime, transactionCountReq.Type, allCategoryIds, allAccountIds, allPayeeIds, allTagIds, transactionCountReq.TagFilterType, transactionCountReq.Keyword)
 
 	if err != nil {
-		log.WarnfWithRequestId(c, "[transactions.TransactionCountHandler] get transaction category error, because %s", err.Error())
+		log.ErrorfWithRequestId(c, "[transactions.TransactionCountHandler] failed to get transaction count for user \"uid:%d\", because %s", uid, err.Error())
 		return nil, errs.ErrOperationFailed
 	}
 
-	totalCount, err := a.transactions.GetTransactionCount(uid, transactionCountReq.MaxTime, transactionCountReq.MinTime, transactionCountReq.Type, allCategoryIds, transactionCountReq.AccountId, transactionCountReq.Keyword)
-
 	countResp := &models.TransactionCountResponse{
 		TotalCount: totalCount,
 	}
@@ -90,14 +104,13 @@ func (a *TransactionsApi) TransactionListHandler(c *core.Context) (interface{},
 		return nil, errs.ErrUserNotFound
 	}
 
-	allCategoryIds, err := a.getCategoryAndSubCategoryIds(transactionListReq.CategoryId, uid)
+	allCategoryIds, allAccountIds, allPayeeIds, allTagIds, errResp := a.getTransactionFilterIds(c, uid, transactionListReq.CategoryId, transactionListReq.CategoryIds, transactionListReq.AccountId, transactionListReq.AccountIds, transactionListReq.PayeeIds, transactionListReq.TagIds)
 
-	if err != nil {
-		log.WarnfWithRequestId(c, "[transactions.TransactionListHandler] get transaction category error, because %s", err.Error())
-		return nil, errs.ErrOperationFailed
+	if errResp != nil {
+		return nil, errResp
 	}
 
-	transactions, err := a.transactions.GetTransactionsByMaxTime(uid, transactionListReq.MaxTime, transactionListReq.MinTime, transactionListReq.Type, allCategoryIds, transactionListReq.AccountId, transactionListReq.Keyword, transactionListReq.Count+1, true)
+	transactions, err := a.transactions.GetTransactionsByMaxTime(uid, transactionListReq.MaxTime, transactionListReq.MinTime, transactionListReq.Type, allCategoryIds, allAccountIds, allPayeeIds, allTagIds, transactionListReq.TagFilterType, transactionListReq.Keyword, transactionListReq.Count+1, true)
 
 	if err != nil {
 		log.ErrorfWithRequestId(c, "[transactions.TransactionListHandler] failed to get transactions earlier than \"%d\" for user \"uid:%d\", because %s", transactionListReq.MaxTime, uid, err.Error())
@@ -131,6 +144,80 @@ func (a *TransactionsApi) TransactionListHandler(c *core.Context) (interface{},
 	return transactionResps, nil
 }
 
+// TransactionSearchHandler returns transaction list of current user matched search conditions
+func (a *TransactionsApi) TransactionSearchHandler(c *core.Context) (interface{}, *errs.Error) {
+	var transactionSearchReq models.TransactionSearchRequest
+	err := c.ShouldBindQuery(&transactionSearchReq)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[transactions.TransactionSearchHandler] parse request failed, because %s", err.Error())
+		return nil, errs.NewIncompleteOrIncorrectSubmissionError(err)
+	}
+
+	utcOffset, err := c.GetClientTimezoneOffset()
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[transactions.TransactionSearchHandler] cannot get client timezone offset, because %s", err.Error())
+		return nil, errs.ErrClientTimezoneOffsetInvalid
+	}
+
+	uid := c.GetCurrentUid()
+	user, err := a.users.GetUserById(uid)
+
+	if err != nil {
+		if !errs.IsCustomError(err) {
+			log.ErrorfWithRequestId(c, "[transactions.TransactionSearchHandler] failed to get user, because %s", err.Error())
+		}
+
+		return nil, errs.ErrUserNotFound
+	}
+
+	filter, noResult, errResp := a.getTransactionSearchFilter(c, uid, utcOffset, &transactionSearchReq)
+
+	if errResp != nil {
+		return nil, errResp
+	}
+
+	if noResult {
+		return &models.TransactionInfoPageWrapperResponse{
+			Items: make(models.TransactionInfoResponseSlice, 0),
+		}, nil
+	}
+
+	transactions, err := a.transactions.SearchTransactions(uid, filter, transactionSearchReq.Count+1)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[transactions.TransactionSearchHandler] failed to search transactions earlier than \"%d\" for user \"uid:%d\", because %s", transactionSearchReq.MaxTime, uid, err.Error())
+		return nil, errs.ErrOperationFailed
+	}
+
+	hasMore := false
+	var nextTimeSequenceId *int64
+
+	if len(transactions) > transactionSearchReq.Count {
+		hasMore = true
+		nextTimeSequenceId = &transactions[transactionSearchReq.Count].TransactionTime
+		transactions = transactions[:transactionSearchReq.Count]
+	}
+
+	transactionResult, err := a.getTransactionListResult(c, user, transactions, utcOffset, transactionSearchReq.TrimAccount, transactionSearchReq.TrimCategory, transactionSearchReq.TrimTag)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[transactions.TransactionSearchHandler] failed to assemble transaction result for user \"uid:%d\", because %s", uid, err.Error())
+		return nil, errs.Or(err, errs.ErrOperationFailed)
+	}
+
+	transactionResps := &models.TransactionInfoPageWrapperResponse{
+		Items: transactionResult,
+	}
+
+	if hasMore {
+		transactionResps.NextTimeSequenceId = nextTimeSequenceId
+	}
+
+	return transactionResps, nil
+}
+
 // TransactionMonthListHandler returns transaction list of current user by month
 func (a *TransactionsApi) TransactionMonthListHandler(c *core.Context) (interface{}, *errs.Error) {
 	var transactionListReq models.TransactionListInMonthByPageRequest
@@ -159,21 +246,20 @@ func (a *TransactionsApi) TransactionMonthListHandler(c *core.Context) (interfac
 		return nil, errs.ErrUserNotFound
 	}
 
-	allCategoryIds, err := a.getCategoryAndSubCategoryIds(transactionListReq.CategoryId, uid)
+	allCategoryIds, allAccountIds, allPayeeIds, allTagIds, errResp := a.getTransactionFilterIds(c, uid, transactionListReq.CategoryId, transactionListReq.CategoryIds, transactionListReq.AccountId, transactionListReq.AccountIds, transactionListReq.PayeeIds, transactionListReq.TagIds)
 
-	if err != nil {
-		log.WarnfWithRequestId(c, "[transactions.TransactionMonthListHandler] get transaction category error, because %s", err.Error())
-		return nil, errs.ErrOperationFailed
+	if errResp != nil {
+		return nil, errResp
 	}
 
-	transactions, err := a.transactions.GetTransactionsInMonthByPage(uid, transactionListReq.Year, transactionListReq.Month, transactionListReq.Type, allCategoryIds, transactionListReq.AccountId, transactionListReq.Keyword, transactionListReq.Page, transactionListReq.Count, utcOffset)
+	transactions, err := a.transactions.GetTransactionsInMonthByPage(uid, transactionListReq.Year, transactionListReq.Month, transactionListReq.Type, allCategoryIds, allAccountIds, allPayeeIds, allTagIds, transactionListReq.TagFilterType, transactionListReq.Keyword, transactionListReq.Page, transactionListReq.Count, utcOffset)
 
 	if err != nil {
 		log.ErrorfWithRequestId(c, "[transactions.TransactionMonthListHandler] failed to get transactions in month \"%d-%d\" for user \"uid:%d\", because %s", transactionListReq.Year, transactionListReq.Month, uid, err.Error())
 		return nil, errs.ErrOperationFailed
 	}
 
-	totalCount, err := a.transactions.GetMonthTransactionCount(uid, transactionListReq.Year, transactionListReq.Month, transactionListReq.Type, allCategoryIds, transactionListReq.AccountId, transactionListReq.Keyword, utcOffset)
+	totalCount, err := a.transactions.GetMonthTransactionCount(uid, transactionListReq.Year, transactionListReq.Month, transactionListReq.Type, allCategoryIds, allAccountIds, allPayeeIds, allTagIds, transactionListReq.TagFilterType, transactionListReq.Keyword, utcOffset)
 
 	if err != nil {
 		log.ErrorfWithRequestId(c, "[transactions.TransactionMonthListHandler] failed to get transaction count in month \"%d-%d\" for user \"uid:%d\", because %s", transactionListReq.Year, transactionListReq.Month, uid, err.Error())
@@ -205,8 +291,25 @@ func (a *TransactionsApi) TransactionStatisticsHandler(c *core.Context) (interfa
 		return nil, errs.NewIncompleteOrIncorrectSubmissionError(err)
 	}
 
+	payeeIds, err := a.getFilterIds(0, statisticReq.PayeeIds)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[transactions.TransactionStatisticsHandler] parse payee ids failed, because %s", err.Error())
+		return nil, errs.ErrTransactionPayeeIdInvalid
+	}
+
 	uid := c.GetCurrentUid()
-	totalAmounts, err := a.transactions.GetAccountsAndCategoriesTotalIncomeAndExpense(uid, statisticReq.StartTime, statisticReq.EndTime)
+
+	if statisticReq.GroupByTag {
+		return a.getTransactionStatisticsGroupByTag(c, uid, &statisticReq, payeeIds)
+	}
+
+	totalAmounts, err := a.transactions.GetAccountsAndCategoriesTotalIncomeAndExpense(uid, statisticReq.StartTime, statisticReq.EndTime, payeeIds, statisticReq.GroupByPayee)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[transactions.TransactionStatisticsHandler] failed to get accounts and categories total income and expense for user \"uid:%d\", because %s", uid, err.Error())
+		return nil, errs.ErrOperationFailed
+	}
 
 	statisticResp := &models.TransactionStatisticResponse{
 		StartTime: statisticReq.StartTime,
@@ -220,6 +323,7 @@ func (a *TransactionsApi) TransactionStatisticsHandler(c *core.Context) (interfa
 		statisticResp.Items[i] = &models.TransactionStatisticResponseItem{
 			CategoryId:  totalAmountItem.CategoryId,
 			AccountId:   totalAmountItem.AccountId,
+			PayeeId:     totalAmountItem.PayeeId,
 			TotalAmount: totalAmountItem.Amount,
 		}
 	}
@@ -512,6 +616,13 @@ func (a *TransactionsApi) TransactionGetHandler(c *core.Context) (interface{}, *
 		return nil, errs.ErrOperationFailed
 	}
 
+	allTransactionSplits, err := a.transactionSplits.GetAllSplitsOfTransactions(uid, []int64{transaction.TransactionId})
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[transactions.TransactionGetHandler] failed to get transaction splits for user \"uid:%d\", because %s", uid, err.Error())
+		return nil, errs.ErrOperationFailed
+	}
+
 	var category *models.TransactionCategory
 	var tagMap map[int64]*models.TransactionTag
 
@@ -536,6 +647,7 @@ func (a *TransactionsApi) TransactionGetHandler(c *core.Context) (interface{}, *
 	transactionEditable := transaction.IsEditable(user, utcOffset, accountMap[transaction.AccountId], accountMap[transaction.RelatedAccountId])
 	transactionTagIds := allTransactionTagIds[transaction.TransactionId]
 	transactionResp := transaction.ToTransactionInfoResponse(transactionTagIds, transactionEditable)
+	transactionResp.Splits = a.getTransactionSplitInfoResponses(allTransactionSplits[transaction.TransactionId])
 
 	if !transactionGetReq.TrimAccount {
 		if sourceAccount := accountMap[transaction.AccountId]; sourceAccount != nil {
@@ -570,34 +682,44 @@ func (a *TransactionsApi) TransactionCreateHandler(c *core.Context) (interface{}
 		return nil, errs.NewIncompleteOrIncorrectSubmissionError(err)
 	}
 
-	tagIds, err := utils.StringArrayToInt64Array(transactionCreateReq.TagIds)
+	return a.createTransaction(c, &transactionCreateReq)
+}
+
+// TransactionCreateByTemplateHandler saves a new transaction by the given transaction template for current user
+func (a *TransactionsApi) TransactionCreateByTemplateHandler(c *core.Context) (interface{}, *errs.Error) {
+	var templateApplyReq models.TransactionTemplateApplyRequest
+	err := c.ShouldBindJSON(&templateApplyReq)
 
 	if err != nil {
-		log.WarnfWithRequestId(c, "[transactions.TransactionCreateHandler] parse tag ids failed, because %s", err.Error())
-		return nil, errs.ErrTransactionTagIdInvalid
+		log.WarnfWithRequestId(c, "[transactions.TransactionCreateByTemplateHandler] parse request failed, because %s", err.Error())
+		return nil, errs.NewIncompleteOrIncorrectSubmissionError(err)
 	}
 
-	if transactionCreateReq.Type < models.TRANSACTION_TYPE_MODIFY_BALANCE || transactionCreateReq.Type > models.TRANSACTION_TYPE_TRANSFER {
-		log.WarnfWithRequestId(c, "[transactions.TransactionCreateHandler] transaction type is invalid")
-		return nil, errs.ErrTransactionTypeInvalid
-	}
+	uid := c.GetCurrentUid()
+	template, err := a.transactionTemplates.GetTemplateByTemplateId(uid, templateApplyReq.Id)
 
-	if transactionCreateReq.Type == models.TRANSACTION_TYPE_MODIFY_BALANCE && transactionCreateReq.CategoryId > 0 {
-		log.WarnfWithRequestId(c, "[transactions.TransactionCreateHandler] balance modification transaction cannot set category id")
-		return nil, errs.ErrBalanceModificationTransactionCannotSetCategory
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[transactions.TransactionCreateByTemplateHandler] failed to get transaction template \"id:%d\" for user \"uid:%d\", because %s", templateApplyReq.Id, uid, err.Error())
+		return nil, errs.Or(err, errs.ErrOperationFailed)
 	}
 
-	if transactionCreateReq.Type != models.TRANSACTION_TYPE_TRANSFER && transactionCreateReq.DestinationAccountId != 0 {
-		log.WarnfWithRequestId(c, "[transactions.TransactionCreateHandler] non-transfer transaction destination account cannot be set")
-		return nil, errs.ErrTransactionDestinationAccountCannotBeSet
-	} else if transactionCreateReq.Type == models.TRANSACTION_TYPE_TRANSFER && transactionCreateReq.SourceAccountId == transactionCreateReq.DestinationAccountId {
-		log.WarnfWithRequestId(c, "[transactions.TransactionCreateHandler] transfer transaction source account must not be destination account")
-		return nil, errs.ErrTransactionSourceAndDestinationIdCannotBeEqual
+	transactionUnixTime := templateApplyReq.Time
+
+	if transactionUnixTime <= 0 {
+		transactionUnixTime = time.Now().Unix()
 	}
 
-	if transactionCreateReq.Type != models.TRANSACTION_TYPE_TRANSFER && transactionCreateReq.DestinationAmount != 0 {
-		log.WarnfWithRequestId(c, "[transactions.TransactionCreateHandler] non-transfer transaction destination amount cannot be set")
-		return nil, errs.ErrTransactionDestinationAmountCannotBeSet
+	return a.createTransaction(c, template.ToTransactionCreateRequest(transactionUnixTime, templateApplyReq.UtcOffset))
+}
+
+// TransactionModifyHandler saves an existed transaction by request parameters for current user
+func (a *TransactionsApi) TransactionModifyHandler(c *core.Context) (interface{}, *errs.Error) {
+	var transactionModifyReq models.TransactionModifyRequest
+	err := c.ShouldBindJSON(&transactionModifyReq)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[transactions.TransactionModifyHandler] parse request failed, because %s", err.Error())
+		return nil, errs.NewIncompleteOrIncorrectSubmissionError(err)
 	}
 
 	uid := c.GetCurrentUid()
@@ -605,48 +727,48 @@ func (a *TransactionsApi) TransactionCreateHandler(c *core.Context) (interface{}
 
 	if err != nil {
 		if !errs.IsCustomError(err) {
-			log.ErrorfWithRequestId(c, "[transactions.TransactionCreateHandler] failed to get user, because %s", err.Error())
+			log.ErrorfWithRequestId(c, "[transactions.TransactionModifyHandler] failed to get user, because %s", err.Error())
 		}
 
 		return nil, errs.ErrUserNotFound
 	}
 
-	transaction := a.createNewTransactionModel(uid, &transactionCreateReq)
-	transactionEditable := user.CanEditTransactionByTransactionTime(transaction.TransactionTime, transactionCreateReq.UtcOffset)
+	newTransaction, tagIds, addTransactionTagIds, removeTransactionTagIds, newTransactionSplits, errResp := a.getModifiedTransactionModels(c, user, &transactionModifyReq)
 
-	if !transactionEditable {
-		return nil, errs.ErrCannotCreateTransactionWithThisTransactionTime
+	if errResp != nil {
+		return nil, errResp
 	}
 
-	err = a.transactions.CreateTransaction(transaction, tagIds)
+	err = a.transactions.ModifyTransaction(newTransaction, addTransactionTagIds, removeTransactionTagIds, newTransactionSplits, models.NewTransactionOperator(c))
 
 	if err != nil {
-		log.ErrorfWithRequestId(c, "[transactions.TransactionCreateHandler] failed to create transaction \"id:%d\" for user \"uid:%d\", because %s", transaction.TransactionId, uid, err.Error())
+		log.ErrorfWithRequestId(c, "[transactions.TransactionModifyHandler] failed to update transaction \"id:%d\" for user \"uid:%d\", because %s", transactionModifyReq.Id, uid, err.Error())
 		return nil, errs.Or(err, errs.ErrOperationFailed)
 	}
 
-	log.InfofWithRequestId(c, "[transactions.TransactionCreateHandler] user \"uid:%d\" has created a new transaction \"id:%d\" successfully", uid, transaction.TransactionId)
+	log.InfofWithRequestId(c, "[transactions.TransactionModifyHandler] user \"uid:%d\" has updated transaction \"id:%d\" successfully", uid, transactionModifyReq.Id)
 
-	transactionResp := transaction.ToTransactionInfoResponse(tagIds, transactionEditable)
+	newTransactionResp := newTransaction.ToTransactionInfoResponse(tagIds, true)
+	newTransactionResp.Splits = a.getTransactionSplitInfoResponses(newTransactionSplits)
 
-	return transactionResp, nil
+	return newTransactionResp, nil
 }
 
-// TransactionModifyHandler saves an existed transaction by request parameters for current user
-func (a *TransactionsApi) TransactionModifyHandler(c *core.Context) (interface{}, *errs.Error) {
-	var transactionModifyReq models.TransactionModifyRequest
-	err := c.ShouldBindJSON(&transactionModifyReq)
+// TransactionDeleteHandler deletes an existed transaction by request parameters for current user
+func (a *TransactionsApi) TransactionDeleteHandler(c *core.Context) (interface{}, *errs.Error) {
+	var transactionDeleteReq models.TransactionDeleteRequest
+	err := c.ShouldBindJSON(&transactionDeleteReq)
 
 	if err != nil {
-		log.WarnfWithRequestId(c, "[transactions.TransactionModifyHandler] parse request failed, because %s", err.Error())
+		log.WarnfWithRequestId(c, "[transactions.TransactionDeleteHandler] parse request failed, because %s", err.Error())
 		return nil, errs.NewIncompleteOrIncorrectSubmissionError(err)
 	}
 
-	tagIds, err := utils.StringArrayToInt64Array(transactionModifyReq.TagIds)
+	utcOffset, err := c.GetClientTimezoneOffset()
 
 	if err != nil {
-		log.WarnfWithRequestId(c, "[transactions.TransactionModifyHandler] parse tag ids failed, because %s", err.Error())
-		return nil, errs.ErrTransactionTagIdInvalid
+		log.WarnfWithRequestId(c, "[transactions.TransactionDeleteHandler] cannot get client timezone offset, because %s", err.Error())
+		return nil, errs.ErrClientTimezoneOffsetInvalid
 	}
 
 	uid := c.GetCurrentUid()
@@ -654,111 +776,195 @@ func (a *TransactionsApi) TransactionModifyHandler(c *core.Context) (interface{}
 
 	if err != nil {
 		if !errs.IsCustomError(err) {
-			log.ErrorfWithRequestId(c, "[transactions.TransactionModifyHandler] failed to get user, because %s", err.Error())
+			log.ErrorfWithRequestId(c, "[transactions.TransactionDeleteHandler] failed to get user, because %s", err.Error())
 		}
 
 		return nil, errs.ErrUserNotFound
 	}
 
-	transaction, err := a.transactions.GetTransactionByTransactionId(uid, transactionModifyReq.Id)
+	errResp := a.checkTransactionCanBeDeleted(c, user, utcOffset, &transactionDeleteReq)
+
+	if errResp != nil {
+		return nil, errResp
+	}
+
+	err = a.transactions.DeleteTransaction(uid, transactionDeleteReq.Id, models.NewTransactionOperator(c))
 
 	if err != nil {
-		log.ErrorfWithRequestId(c, "[transactions.TransactionModifyHandler] failed to get transaction \"id:%d\" for user \"uid:%d\", because %s", transactionModifyReq.Id, uid, err.Error())
+		log.ErrorfWithRequestId(c, "[transactions.TransactionDeleteHandler] failed to delete transaction \"id:%d\" for user \"uid:%d\", because %s", transactionDeleteReq.Id, uid, err.Error())
 		return nil, errs.Or(err, errs.ErrOperationFailed)
 	}
 
-	if transaction.Type == models.TRANSACTION_DB_TYPE_TRANSFER_IN {
-		log.WarnfWithRequestId(c, "[transactions.TransactionModifyHandler] cannot modify transaction \"id:%d\" for user \"uid:%d\", because transaction type is transfer in", transactionModifyReq.Id, uid)
-		return nil, errs.ErrTransactionTypeInvalid
+	log.InfofWithRequestId(c, "[transactions.TransactionDeleteHandler] user \"uid:%d\" has deleted transaction \"id:%d\"", uid, transactionDeleteReq.Id)
+	return true, nil
+}
+
+// TransactionBatchCreateHandler saves new transactions in one batch by request parameters for current user
+func (a *TransactionsApi) TransactionBatchCreateHandler(c *core.Context) (interface{}, *errs.Error) {
+	var transactionBatchCreateReq models.TransactionBatchCreateRequest
+	err := c.ShouldBindJSON(&transactionBatchCreateReq)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[transactions.TransactionBatchCreateHandler] parse request failed, because %s", err.Error())
+		return nil, errs.NewIncompleteOrIncorrectSubmissionError(err)
 	}
 
-	allTransactionTagIds, err := a.transactionTags.GetAllTagIdsOfTransactions(uid, []int64{transaction.TransactionId})
+	uid := c.GetCurrentUid()
+	user, err := a.users.GetUserById(uid)
+
+	if err != nil {
+		if !errs.IsCustomError(err) {
+			log.ErrorfWithRequestId(c, "[transactions.TransactionBatchCreateHandler] failed to get user, because %s", err.Error())
+		}
+
+		return nil, errs.ErrUserNotFound
+	}
+
+	batchResp := models.NewTransactionBatchResponse(len(transactionBatchCreateReq.Transactions))
+	batchItems := make([]*models.TransactionBatchItem, len(transactionBatchCreateReq.Transactions))
+
+	rules, err := a.transactionRules.GetAllEnabledRulesByUid(uid)
 
 	if err != nil {
-		log.ErrorfWithRequestId(c, "[transactions.TransactionModifyHandler] failed to get transactions tag ids for user \"uid:%d\", because %s", uid, err.Error())
+		log.ErrorfWithRequestId(c, "[transactions.TransactionBatchCreateHandler] failed to get transaction rules for user \"uid:%d\", because %s", uid, err.Error())
 		return nil, errs.ErrOperationFailed
 	}
 
-	transactionTagIds := allTransactionTagIds[transaction.TransactionId]
+	for i := 0; i < len(transactionBatchCreateReq.Transactions); i++ {
+		transaction, tagIds, splits, errResp := a.getNewTransactionModels(c, user, transactionBatchCreateReq.Transactions[i])
 
-	if transactionTagIds == nil {
-		transactionTagIds = make([]int64, 0, 0)
+		if errResp != nil {
+			batchResp.SetItemError(i, errResp)
+			continue
+		}
+
+		tagIds, _ = a.transactionRules.ApplyRulesToTransaction(rules, transaction, tagIds, len(splits) > 0)
+
+		batchItems[i] = &models.TransactionBatchItem{
+			Transaction: transaction,
+			AddTagIds:   tagIds,
+			Splits:      splits,
+		}
 	}
 
-	newTransaction := &models.Transaction{
-		TransactionId:     transaction.TransactionId,
-		Uid:               uid,
-		CategoryId:        transactionModifyReq.CategoryId,
-		TransactionTime:   utils.GetMinTransactionTimeFromUnixTime(transactionModifyReq.Time),
-		TimezoneUtcOffset: transactionModifyReq.UtcOffset,
-		AccountId:         transactionModifyReq.SourceAccountId,
-		Amount:            transactionModifyReq.SourceAmount,
-		HideAmount:        transactionModifyReq.HideAmount,
-		Comment:           transactionModifyReq.Comment,
+	if batchResp.HasError() {
+		log.WarnfWithRequestId(c, "[transactions.TransactionBatchCreateHandler] cannot create transactions for user \"uid:%d\", because some items are invalid", uid)
+		return batchResp, nil
 	}
 
-	if transaction.Type == models.TRANSACTION_DB_TYPE_TRANSFER_OUT {
-		newTransaction.RelatedAccountId = transactionModifyReq.DestinationAccountId
-		newTransaction.RelatedAccountAmount = transactionModifyReq.DestinationAmount
+	failedIndex, err := a.transactions.BatchCreateTransactions(uid, batchItems, models.NewTransactionOperator(c))
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[transactions.TransactionBatchCreateHandler] failed to create transactions for user \"uid:%d\", because %s", uid, err.Error())
+
+		if failedIndex < 0 {
+			return nil, errs.Or(err, errs.ErrOperationFailed)
+		}
+
+		batchResp.SetItemError(failedIndex, errs.Or(err, errs.ErrOperationFailed))
+		return batchResp, nil
 	}
 
-	if newTransaction.CategoryId == transaction.CategoryId &&
-		utils.GetUnixTimeFromTransactionTime(newTransaction.TransactionTime) == utils.GetUnixTimeFromTransactionTime(transaction.TransactionTime) &&
-		newTransaction.TimezoneUtcOffset == transaction.TimezoneUtcOffset &&
-		newTransaction.AccountId == transaction.AccountId &&
-		newTransaction.Amount == transaction.Amount &&
-		(transaction.Type != models.TRANSACTION_DB_TYPE_TRANSFER_OUT || newTransaction.RelatedAccountId == transaction.RelatedAccountId) &&
-		(transaction.Type != models.TRANSACTION_DB_TYPE_TRANSFER_OUT || newTransaction.RelatedAccountAmount == transaction.RelatedAccountAmount) &&
-		newTransaction.HideAmount == transaction.HideAmount &&
-		newTransaction.Comment == transaction.Comment &&
-		utils.Int64SliceEquals(tagIds, transactionTagIds) {
-		return nil, errs.ErrNothingWillBeUpdated
+	for i := 0; i < len(batchItems); i++ {
+		batchResp.SetItemSuccess(i, batchItems[i].Transaction.TransactionId)
 	}
 
-	var addTransactionTagIds []int64
-	var removeTransactionTagIds []int64
+	batchResp.Committed = true
 
-	if !utils.Int64SliceEquals(tagIds, transactionTagIds) {
-		removeTransactionTagIds = transactionTagIds
-		addTransactionTagIds = tagIds
+	log.InfofWithRequestId(c, "[transactions.TransactionBatchCreateHandler] user \"uid:%d\" has created %d transactions successfully", uid, len(batchItems))
+	return batchResp, nil
+}
+
+// TransactionBatchModifyHandler saves existed transactions in one batch by request parameters for current user
+func (a *TransactionsApi) TransactionBatchModifyHandler(c *core.Context) (interface{}, *errs.Error) {
+	var transactionBatchModifyReq models.TransactionBatchModifyRequest
+	err := c.ShouldBindJSON(&transactionBatchModifyReq)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[transactions.TransactionBatchModifyHandler] parse request failed, because %s", err.Error())
+		return nil, errs.NewIncompleteOrIncorrectSubmissionError(err)
 	}
 
-	transactionEditable := user.CanEditTransactionByTransactionTime(transaction.TransactionTime, transaction.TimezoneUtcOffset)
-	newTransactionEditable := user.CanEditTransactionByTransactionTime(newTransaction.TransactionTime, transactionModifyReq.UtcOffset)
+	uid := c.GetCurrentUid()
+	user, err := a.users.GetUserById(uid)
 
-	if !transactionEditable || !newTransactionEditable {
-		return nil, errs.ErrCannotModifyTransactionWithThisTransactionTime
+	if err != nil {
+		if !errs.IsCustomError(err) {
+			log.ErrorfWithRequestId(c, "[transactions.TransactionBatchModifyHandler] failed to get user, because %s", err.Error())
+		}
+
+		return nil, errs.ErrUserNotFound
+	}
+
+	batchResp := models.NewTransactionBatchResponse(len(transactionBatchModifyReq.Transactions))
+	batchItems := make([]*models.TransactionBatchItem, 0, len(transactionBatchModifyReq.Transactions))
+	batchItemIndexes := make([]int, 0, len(transactionBatchModifyReq.Transactions))
+
+	for i := 0; i < len(transactionBatchModifyReq.Transactions); i++ {
+		transactionModifyReq := transactionBatchModifyReq.Transactions[i]
+		newTransaction, _, addTransactionTagIds, removeTransactionTagIds, newTransactionSplits, errResp := a.getModifiedTransactionModels(c, user, transactionModifyReq)
+
+		if errResp == errs.ErrNothingWillBeUpdated {
+			batchResp.SetItemSuccess(i, transactionModifyReq.Id)
+			continue
+		} else if errResp != nil {
+			batchResp.SetItemError(i, errResp)
+			continue
+		}
+
+		batchItems = append(batchItems, &models.TransactionBatchItem{
+			Transaction:  newTransaction,
+			AddTagIds:    addTransactionTagIds,
+			RemoveTagIds: removeTransactionTagIds,
+			Splits:       newTransactionSplits,
+		})
+		batchItemIndexes = append(batchItemIndexes, i)
+	}
+
+	if batchResp.HasError() {
+		log.WarnfWithRequestId(c, "[transactions.TransactionBatchModifyHandler] cannot update transactions for user \"uid:%d\", because some items are invalid", uid)
+		batchResp.ClearSuccessItems()
+		return batchResp, nil
 	}
 
-	err = a.transactions.ModifyTransaction(newTransaction, addTransactionTagIds, removeTransactionTagIds)
+	failedIndex, err := a.transactions.BatchModifyTransactions(uid, batchItems, models.NewTransactionOperator(c))
 
 	if err != nil {
-		log.ErrorfWithRequestId(c, "[transactions.TransactionModifyHandler] failed to update transaction \"id:%d\" for user \"uid:%d\", because %s", transactionModifyReq.Id, uid, err.Error())
-		return nil, errs.Or(err, errs.ErrOperationFailed)
+		log.ErrorfWithRequestId(c, "[transactions.TransactionBatchModifyHandler] failed to update transactions for user \"uid:%d\", because %s", uid, err.Error())
+
+		if failedIndex < 0 {
+			return nil, errs.Or(err, errs.ErrOperationFailed)
+		}
+
+		batchResp.ClearSuccessItems()
+		batchResp.SetItemError(batchItemIndexes[failedIndex], errs.Or(err, errs.ErrOperationFailed))
+		return batchResp, nil
 	}
 
-	log.InfofWithRequestId(c, "[transactions.TransactionModifyHandler] user \"uid:%d\" has updated transaction \"id:%d\" successfully", uid, transactionModifyReq.Id)
+	for i := 0; i < len(batchItems); i++ {
+		batchResp.SetItemSuccess(batchItemIndexes[i], batchItems[i].Transaction.TransactionId)
+	}
 
-	newTransaction.Type = transaction.Type
-	newTransactionResp := newTransaction.ToTransactionInfoResponse(tagIds, transactionEditable)
+	batchResp.Committed = true
 
-	return newTransactionResp, nil
+	log.InfofWithRequestId(c, "[transactions.TransactionBatchModifyHandler] user \"uid:%d\" has updated %d transactions successfully", uid, len(batchItems))
+	return batchResp, nil
 }
 
-// TransactionDeleteHandler deletes an existed transaction by request parameters for current user
-func (a *TransactionsApi) TransactionDeleteHandler(c *core.Context) (interface{}, *errs.Error) {
-	var transactionDeleteReq models.TransactionDeleteRequest
-	err := c.ShouldBindJSON(&transactionDeleteReq)
+// TransactionBatchDeleteHandler deletes existed transactions in one batch by request parameters for current user
+func (a *TransactionsApi) TransactionBatchDeleteHandler(c *core.Context) (interface{}, *errs.Error) {
+	var transactionBatchDeleteReq models.TransactionBatchDeleteRequest
+	err := c.ShouldBindJSON(&transactionBatchDeleteReq)
 
 	if err != nil {
-		log.WarnfWithRequestId(c, "[transactions.TransactionDeleteHandler] parse request failed, because %s", err.Error())
+		log.WarnfWithRequestId(c, "[transactions.TransactionBatchDeleteHandler] parse request failed, because %s", err.Error())
 		return nil, errs.NewIncompleteOrIncorrectSubmissionError(err)
 	}
 
 	utcOffset, err := c.GetClientTimezoneOffset()
 
 	if err != nil {
-		log.WarnfWithRequestId(c, "[transactions.TransactionDeleteHandler] cannot get client timezone offset, because %s", err.Error())
+		log.WarnfWithRequestId(c, "[transactions.TransactionBatchDeleteHandler] cannot get client timezone offset, because %s", err.Error())
 		return nil, errs.ErrClientTimezoneOffsetInvalid
 	}
 
@@ -767,85 +973,775 @@ func (a *TransactionsApi) TransactionDeleteHandler(c *core.Context) (interface{}
 
 	if err != nil {
 		if !errs.IsCustomError(err) {
-			log.ErrorfWithRequestId(c, "[transactions.TransactionDeleteHandler] failed to get user, because %s", err.Error())
+			log.ErrorfWithRequestId(c, "[transactions.TransactionBatchDeleteHandler] failed to get user, because %s", err.Error())
 		}
 
 		return nil, errs.ErrUserNotFound
 	}
 
-	transaction, err := a.transactions.GetTransactionByTransactionId(uid, transactionDeleteReq.Id)
+	batchResp := models.NewTransactionBatchResponse(len(transactionBatchDeleteReq.Transactions))
+	transactionIds := make([]int64, len(transactionBatchDeleteReq.Transactions))
 
-	if err != nil {
-		log.ErrorfWithRequestId(c, "[transactions.TransactionDeleteHandler] failed to get transaction \"id:%d\" for user \"uid:%d\", because %s", transactionDeleteReq.Id, uid, err.Error())
-		return nil, errs.Or(err, errs.ErrOperationFailed)
-	}
+	for i := 0; i < len(transactionBatchDeleteReq.Transactions); i++ {
+		transactionDeleteReq := transactionBatchDeleteReq.Transactions[i]
+		errResp := a.checkTransactionCanBeDeleted(c, user, utcOffset, transactionDeleteReq)
 
-	if transaction.Type == models.TRANSACTION_DB_TYPE_TRANSFER_IN {
-		log.WarnfWithRequestId(c, "[transactions.TransactionDeleteHandler] cannot delete transaction \"id:%d\" for user \"uid:%d\", because transaction type is transfer in", transactionDeleteReq.Id, uid)
-		return nil, errs.ErrTransactionTypeInvalid
-	}
+		if errResp != nil {
+			batchResp.SetItemError(i, errResp)
+			continue
+		}
 
-	transactionEditable := user.CanEditTransactionByTransactionTime(transaction.TransactionTime, utcOffset)
+		transactionIds[i] = transactionDeleteReq.Id
+	}
 
-	if !transactionEditable {
-		return nil, errs.ErrCannotDeleteTransactionWithThisTransactionTime
+	if batchResp.HasError() {
+		log.WarnfWithRequestId(c, "[transactions.TransactionBatchDeleteHandler] cannot delete transactions for user \"uid:%d\", because some items are invalid", uid)
+		return batchResp, nil
 	}
 
-	err = a.transactions.DeleteTransaction(uid, transactionDeleteReq.Id)
+	failedIndex, err := a.transactions.BatchDeleteTransactions(uid, transactionIds, models.NewTransactionOperator(c))
 
 	if err != nil {
-		log.ErrorfWithRequestId(c, "[transactions.TransactionDeleteHandler] failed to delete transaction \"id:%d\" for user \"uid:%d\", because %s", transactionDeleteReq.Id, uid, err.Error())
-		return nil, errs.Or(err, errs.ErrOperationFailed)
+		log.ErrorfWithRequestId(c, "[transactions.TransactionBatchDeleteHandler] failed to delete transactions for user \"uid:%d\", because %s", uid, err.Error())
+
+		if failedIndex < 0 {
+			return nil, errs.Or(err, errs.ErrOperationFailed)
+		}
+
+		batchResp.SetItemError(failedIndex, errs.Or(err, errs.ErrOperationFailed))
+		return batchResp, nil
 	}
 
-	log.InfofWithRequestId(c, "[transactions.TransactionDeleteHandler] user \"uid:%d\" has deleted transaction \"id:%d\"", uid, transactionDeleteReq.Id)
-	return true, nil
-}
+	for i := 0; i < len(transactionIds); i++ {
+		batchResp.SetItemSuccess(i, transactionIds[i])
+	}
 
-func (a *TransactionsApi) filterTransactions(c *core.Context, uid int64, transactions []*models.Transaction, accountMap map[int64]*models.Account) []*models.Transaction {
-	finalTransactions := make([]*models.Transaction, 0, len(transactions))
+	batchResp.Committed = true
 
-	for i := 0; i < len(transactions); i++ {
-		transaction := transactions[i]
+	log.InfofWithRequestId(c, "[transactions.TransactionBatchDeleteHandler] user \"uid:%d\" has deleted %d transactions", uid, len(transactionIds))
+	return batchResp, nil
+}
 
-		if _, exists := accountMap[transaction.AccountId]; !exists {
-			log.WarnfWithRequestId(c, "[transactions.filterTransactions] account of transaction \"id:%d\" does not exist for user \"uid:%d\"", transaction.TransactionId, uid)
+// TransactionReassignCategoryHandler moves all filtered transactions of current user to the given category
+func (a *TransactionsApi) TransactionReassignCategoryHandler(c *core.Context) (interface{}, *errs.Error) {
+	var transactionReassignCategoryReq models.TransactionReassignCategoryRequest
+	err := c.ShouldBindJSON(&transactionReassignCategoryReq)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[transactions.TransactionReassignCategoryHandler] parse request failed, because %s", err.Error())
+		return nil, errs.NewIncompleteOrIncorrectSubmissionError(err)
+	}
+
+	uid := c.GetCurrentUid()
+	user, err := a.users.GetUserById(uid)
+
+	if err != nil {
+		if !errs.IsCustomError(err) {
+			log.ErrorfWithRequestId(c, "[transactions.TransactionReassignCategoryHandler] failed to get user, because %s", err.Error())
+		}
+
+		return nil, errs.ErrUserNotFound
+	}
+
+	toCategory, err := a.transactionCategories.GetCategoryByCategoryId(uid, transactionReassignCategoryReq.ToCategoryId)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[transactions.TransactionReassignCategoryHandler] failed to get category \"id:%d\" for user \"uid:%d\", because %s", transactionReassignCategoryReq.ToCategoryId, uid, err.Error())
+		return nil, errs.Or(err, errs.ErrOperationFailed)
+	}
+
+	if toCategory.ParentCategoryId == models.LevelOneTransactionParentId {
+		log.WarnfWithRequestId(c, "[transactions.TransactionReassignCategoryHandler] category \"id:%d\" is a primary category", toCategory.CategoryId)
+		return nil, errs.ErrCannotUsePrimaryCategoryForTransaction
+	}
+
+	var transactionType models.TransactionDbType
+
+	if toCategory.Type == models.CATEGORY_TYPE_INCOME {
+		transactionType = models.TRANSACTION_DB_TYPE_INCOME
+	} else if toCategory.Type == models.CATEGORY_TYPE_EXPENSE {
+		transactionType = models.TRANSACTION_DB_TYPE_EXPENSE
+	} else if toCategory.Type == models.CATEGORY_TYPE_TRANSFER {
+		transactionType = models.TRANSACTION_DB_TYPE_TRANSFER_OUT
+	} else {
+		return nil, errs.ErrTransactionCategoryTypeInvalid
+	}
+
+	allCategoryIds, allAccountIds, allPayeeIds, allTagIds, errResp := a.getTransactionFilterIds(c, uid, 0, transactionReassignCategoryReq.CategoryIds, 0, transactionReassignCategoryReq.AccountIds, transactionReassignCategoryReq.PayeeIds, transactionReassignCategoryReq.TagIds)
+
+	if errResp != nil {
+		return nil, errResp
+	}
+
+	accounts, err := a.accounts.GetAllAccountsByUid(uid)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[transactions.TransactionReassignCategoryHandler] failed to get all accounts for user \"uid:%d\", because %s", uid, err.Error())
+		return nil, errs.ErrOperationFailed
+	}
+
+	accountMap := a.accounts.GetAccountMapByList(accounts)
+	reassignResp := &models.TransactionReassignCategoryResponse{}
+
+	var batchItems []*models.TransactionBatchItem
+	maxTransactionTime := int64(0)
+	minTransactionTime := int64(0)
+
+	if transactionReassignCategoryReq.EndTime > 0 {
+		maxTransactionTime = utils.GetMaxTransactionTimeFromUnixTime(transactionReassignCategoryReq.EndTime)
+	}
+
+	if transactionReassignCategoryReq.StartTime > 0 {
+		minTransactionTime = utils.GetMinTransactionTimeFromUnixTime(transactionReassignCategoryReq.StartTime)
+	}
+
+	for {
+		transactions, err := a.transactions.GetTransactionsByMaxTime(uid, maxTransactionTime, minTransactionTime, transactionType, allCategoryIds, allAccountIds, allPayeeIds, allTagIds, transactionReassignCategoryReq.TagFilterType, transactionReassignCategoryReq.Keyword, pageCountForLoadTransactionAmounts, true)
+
+		if err != nil {
+			log.ErrorfWithRequestId(c, "[transactions.TransactionReassignCategoryHandler] failed to get transactions for user \"uid:%d\", because %s", uid, err.Error())
+			return nil, errs.ErrOperationFailed
+		}
+
+		if len(transactions) < 1 {
+			break
+		}
+
+		transactionIds := make([]int64, len(transactions))
+
+		for i := 0; i < len(transactions); i++ {
+			transactionIds[i] = transactions[i].TransactionId
+		}
+
+		allTransactionSplits, err := a.transactionSplits.GetAllSplitsOfTransactions(uid, transactionIds)
+
+		if err != nil {
+			log.ErrorfWithRequestId(c, "[transactions.TransactionReassignCategoryHandler] failed to get transaction splits for user \"uid:%d\", because %s", uid, err.Error())
+			return nil, errs.ErrOperationFailed
+		}
+
+		// Both sides of transfer would be changed, so the transfer is skipped if its transfer in side has been reconciled
+		reconciledTransactionIds, err := a.transactions.GetTransferTransactionIdsWithReconciledRelatedTransaction(uid, transactions)
+
+		if err != nil {
+			log.ErrorfWithRequestId(c, "[transactions.TransactionReassignCategoryHandler] failed to get reconciled related transactions for user \"uid:%d\", because %s", uid, err.Error())
+			return nil, errs.ErrOperationFailed
+		}
+
+		for i := 0; i < len(transactions); i++ {
+			transaction := transactions[i]
+
+			if transaction.CategoryId == toCategory.CategoryId {
+				continue
+			}
+
+			// The category of split transaction is decided by its splits, so it would not be changed here
+			if len(allTransactionSplits[transaction.TransactionId]) > 0 || !transaction.IsEditableInBulk(user, accountMap) || reconciledTransactionIds[transaction.TransactionId] {
+				reassignResp.SkippedCount++
+				continue
+			}
+
+			newTransaction := *transaction
+			newTransaction.CategoryId = toCategory.CategoryId
+
+			batchItems = append(batchItems, &models.TransactionBatchItem{
+				Transaction: &newTransaction,
+			})
+		}
+
+		if len(transactions) < pageCountForLoadTransactionAmounts {
+			break
+		}
+
+		maxTransactionTime = transactions[len(transactions)-1].TransactionTime - 1
+	}
+
+	if len(batchItems) < 1 {
+		return reassignResp, nil
+	}
+
+	_, err = a.transactions.BatchModifyTransactions(uid, batchItems, models.NewTransactionOperator(c))
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[transactions.TransactionReassignCategoryHandler] failed to reassign category of transactions for user \"uid:%d\", because %s", uid, err.Error())
+		return nil, errs.Or(err, errs.ErrOperationFailed)
+	}
+
+	reassignResp.UpdatedCount = len(batchItems)
+
+	log.InfofWithRequestId(c, "[transactions.TransactionReassignCategoryHandler] user \"uid:%d\" has moved %d transactions to category \"id:%d\"", uid, reassignResp.UpdatedCount, toCategory.CategoryId)
+	return reassignResp, nil
+}
+
+// TransactionReconcileStateModifyHandler saves the reconcile state of an existed transaction by request parameters for current user
+func (a *TransactionsApi) TransactionReconcileStateModifyHandler(c *core.Context) (interface{}, *errs.Error) {
+	var transactionReconcileStateModifyReq models.TransactionReconcileStateModifyRequest
+	err := c.ShouldBindJSON(&transactionReconcileStateModifyReq)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[transactions.TransactionReconcileStateModifyHandler] parse request failed, because %s", err.Error())
+		return nil, errs.NewIncompleteOrIncorrectSubmissionError(err)
+	}
+
+	uid := c.GetCurrentUid()
+	user, err := a.users.GetUserById(uid)
+
+	if err != nil {
+		if !errs.IsCustomError(err) {
+			log.ErrorfWithRequestId(c, "[transactions.TransactionReconcileStateModifyHandler] failed to get user, because %s", err.Error())
+		}
+
+		return nil, errs.ErrUserNotFound
+	}
+
+	transaction, err := a.transactions.GetTransactionByTransactionId(uid, transactionReconcileStateModifyReq.Id)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[transactions.TransactionReconcileStateModifyHandler] failed to get transaction \"id:%d\" for user \"uid:%d\", because %s", transactionReconcileStateModifyReq.Id, uid, err.Error())
+		return nil, errs.Or(err, errs.ErrOperationFailed)
+	}
+
+	if !user.CanEditTransactionByTransactionTime(transaction.TransactionTime, transaction.TimezoneUtcOffset) {
+		return nil, errs.ErrCannotModifyTransactionWithThisTransactionTime
+	}
+
+	if transaction.ReconcileState == models.TRANSACTION_RECONCILE_STATE_RECONCILED && !transactionReconcileStateModifyReq.Force {
+		return nil, errs.ErrCannotModifyReconciledTransaction
+	}
+
+	err = a.transactions.ModifyTransactionReconcileState(uid, transactionReconcileStateModifyReq.Id, transactionReconcileStateModifyReq.State, models.NewTransactionOperator(c))
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[transactions.TransactionReconcileStateModifyHandler] failed to update reconcile state of transaction \"id:%d\" for user \"uid:%d\", because %s", transactionReconcileStateModifyReq.Id, uid, err.Error())
+		return nil, errs.Or(err, errs.ErrOperationFailed)
+	}
+
+	log.InfofWithRequestId(c, "[transactions.TransactionReconcileStateModifyHandler] user \"uid:%d\" has updated reconcile state of transaction \"id:%d\" to \"%s\"", uid, transactionReconcileStateModifyReq.Id, transactionReconcileStateModifyReq.State)
+	return true, nil
+}
+
+func (a *TransactionsApi) checkTransactionCanBeDeleted(c *core.Context, user *models.User, utcOffset int16, transactionDeleteReq *models.TransactionDeleteRequest) *errs.Error {
+	transaction, err := a.transactions.GetTransactionByTransactionId(user.Uid, transactionDeleteReq.Id)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[transactions.checkTransactionCanBeDeleted] failed to get transaction \"id:%d\" for user \"uid:%d\", because %s", transactionDeleteReq.Id, user.Uid, err.Error())
+		return errs.Or(err, errs.ErrOperationFailed)
+	}
+
+	if transaction.Type == models.TRANSACTION_DB_TYPE_TRANSFER_IN {
+		log.WarnfWithRequestId(c, "[transactions.checkTransactionCanBeDeleted] cannot delete transaction \"id:%d\" for user \"uid:%d\", because transaction type is transfer in", transactionDeleteReq.Id, user.Uid)
+		return errs.ErrTransactionTypeInvalid
+	}
+
+	transactionEditable := user.CanEditTransactionByTransactionTime(transaction.TransactionTime, utcOffset)
+
+	if !transactionEditable {
+		return errs.ErrCannotDeleteTransactionWithThisTransactionTime
+	}
+
+	reconciled, err := a.isTransactionReconciled(user.Uid, transaction)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[transactions.checkTransactionCanBeDeleted] failed to get related transaction of transaction \"id:%d\" for user \"uid:%d\", because %s", transactionDeleteReq.Id, user.Uid, err.Error())
+		return errs.Or(err, errs.ErrOperationFailed)
+	}
+
+	if reconciled && !transactionDeleteReq.Force {
+		return errs.ErrCannotDeleteReconciledTransaction
+	}
+
+	return nil
+}
+
+// isTransactionReconciled returns whether either side of the transaction has been reconciled, because both sides of transfer would be changed together
+func (a *TransactionsApi) isTransactionReconciled(uid int64, transaction *models.Transaction) (bool, error) {
+	if transaction.ReconcileState == models.TRANSACTION_RECONCILE_STATE_RECONCILED {
+		return true, nil
+	}
+
+	reconciledTransactionIds, err := a.transactions.GetTransferTransactionIdsWithReconciledRelatedTransaction(uid, []*models.Transaction{transaction})
+
+	if err != nil {
+		return false, err
+	}
+
+	return reconciledTransactionIds[transaction.TransactionId], nil
+}
+
+func (a *TransactionsApi) filterTransactions(c *core.Context, uid int64, transactions []*models.Transaction, accountMap map[int64]*models.Account) []*models.Transaction {
+	finalTransactions := make([]*models.Transaction, 0, len(transactions))
+
+	for i := 0; i < len(transactions); i++ {
+		transaction := transactions[i]
+
+		if _, exists := accountMap[transaction.AccountId]; !exists {
+			log.WarnfWithRequestId(c, "[transactions.filterTransactions] account of transaction \"id:%d\" does not exist for user \"uid:%d\"", transaction.TransactionId, uid)
+			continue
+		}
+
+		if transaction.Type == models.TRANSACTION_DB_TYPE_TRANSFER_IN || transaction.Type == models.TRANSACTION_DB_TYPE_TRANSFER_OUT {
+			if _, exists := accountMap[transaction.RelatedAccountId]; !exists {
+				log.WarnfWithRequestId(c, "[transactions.filterTransactions] related account of transaction \"id:%d\" does not exist for user \"uid:%d\"", transaction.TransactionId, uid)
+				continue
+			}
+		}
+
+		finalTransactions = append(finalTransactions, transaction)
+	}
+
+	return finalTransactions
+}
+
+func (a *TransactionsApi) getCategoryAndSubCategoryIds(categoryIds []int64, uid int64) ([]int64, error) {
+	var allCategoryIds []int64
+
+	if len(categoryIds) < 1 {
+		return allCategoryIds, nil
+	}
+
+	allCategories, err := a.transactionCategories.GetAllCategoriesByUid(uid, 0, -1)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for i := 0; i < len(categoryIds); i++ {
+		categoryId := categoryIds[i]
+		hasSubCategories := false
+
+		for j := 0; j < len(allCategories); j++ {
+			if allCategories[j].ParentCategoryId == categoryId {
+				allCategoryIds = append(allCategoryIds, allCategories[j].CategoryId)
+				hasSubCategories = true
+			}
+		}
+
+		if !hasSubCategories {
+			allCategoryIds = append(allCategoryIds, categoryId)
+		}
+	}
+
+	return utils.ToUniqueInt64Slice(allCategoryIds), nil
+}
+
+func (a *TransactionsApi) getFilterIds(id int64, ids string) ([]int64, error) {
+	var allIds []int64
+
+	if id > 0 {
+		allIds = append(allIds, id)
+	}
+
+	if ids == "" {
+		return allIds, nil
+	}
+
+	idStrs := strings.Split(ids, ",")
+
+	for i := 0; i < len(idStrs); i++ {
+		idStr := strings.TrimSpace(idStrs[i])
+
+		if idStr == "" {
 			continue
 		}
 
-		if transaction.Type == models.TRANSACTION_DB_TYPE_TRANSFER_IN || transaction.Type == models.TRANSACTION_DB_TYPE_TRANSFER_OUT {
-			if _, exists := accountMap[transaction.RelatedAccountId]; !exists {
-				log.WarnfWithRequestId(c, "[transactions.filterTransactions] related account of transaction \"id:%d\" does not exist for user \"uid:%d\"", transaction.TransactionId, uid)
-				continue
-			}
-		}
+		id, err := utils.StringToInt64(idStr)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if id <= 0 {
+			return nil, errs.ErrIncompleteOrIncorrectSubmission
+		}
+
+		allIds = append(allIds, id)
+	}
+
+	return utils.ToUniqueInt64Slice(allIds), nil
+}
+
+func (a *TransactionsApi) getTransactionFilterIds(c *core.Context, uid int64, categoryId int64, categoryIds string, accountId int64, accountIds string, payeeIds string, tagIds string) ([]int64, []int64, []int64, []int64, *errs.Error) {
+	filterCategoryIds, err := a.getFilterIds(categoryId, categoryIds)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[transactions.getTransactionFilterIds] parse category ids failed, because %s", err.Error())
+		return nil, nil, nil, nil, errs.ErrTransactionCategoryIdInvalid
+	}
+
+	allCategoryIds, err := a.getCategoryAndSubCategoryIds(filterCategoryIds, uid)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[transactions.getTransactionFilterIds] get transaction category error, because %s", err.Error())
+		return nil, nil, nil, nil, errs.ErrOperationFailed
+	}
+
+	allAccountIds, err := a.getFilterIds(accountId, accountIds)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[transactions.getTransactionFilterIds] parse account ids failed, because %s", err.Error())
+		return nil, nil, nil, nil, errs.ErrAccountIdInvalid
+	}
+
+	allPayeeIds, err := a.getFilterIds(0, payeeIds)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[transactions.getTransactionFilterIds] parse payee ids failed, because %s", err.Error())
+		return nil, nil, nil, nil, errs.ErrTransactionPayeeIdInvalid
+	}
+
+	allTagIds, err := a.getFilterIds(0, tagIds)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[transactions.getTransactionFilterIds] parse tag ids failed, because %s", err.Error())
+		return nil, nil, nil, nil, errs.ErrTransactionTagIdInvalid
+	}
+
+	return allCategoryIds, allAccountIds, allPayeeIds, allTagIds, nil
+}
+
+func (a *TransactionsApi) getTransactionSearchFilter(c *core.Context, uid int64, utcOffset int16, transactionSearchReq *models.TransactionSearchRequest) (*models.TransactionSearchFilter, bool, *errs.Error) {
+	categoryIds, accountIds, payeeIds, tagIds, errResp := a.getTransactionFilterIds(c, uid, 0, transactionSearchReq.CategoryIds, 0, transactionSearchReq.AccountIds, transactionSearchReq.PayeeIds, transactionSearchReq.TagIds)
+
+	if errResp != nil {
+		return nil, false, errResp
+	}
+
+	filter := &models.TransactionSearchFilter{
+		MaxTransactionTime: transactionSearchReq.MaxTime,
+		MinTransactionTime: transactionSearchReq.MinTime,
+		Type:               transactionSearchReq.Type,
+		CategoryIds:        categoryIds,
+		AccountIds:         accountIds,
+		PayeeIds:           payeeIds,
+		TagIds:             tagIds,
+		TagFilterType:      transactionSearchReq.TagFilterType,
+		MinAmount:          transactionSearchReq.MinAmount,
+		MaxAmount:          transactionSearchReq.MaxAmount,
+	}
+
+	if transactionSearchReq.Keyword != "" {
+		filter.Keywords = append(filter.Keywords, transactionSearchReq.Keyword)
+	}
+
+	if transactionSearchReq.Query == "" {
+		return filter, false, nil
+	}
+
+	terms, err := utils.ParseSearchQuery(transactionSearchReq.Query)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[transactions.getTransactionSearchFilter] parse search query \"%s\" failed, because %s", transactionSearchReq.Query, err.Error())
+		return nil, false, errs.ErrTransactionSearchQueryInvalid
+	}
+
+	var allAccounts []*models.Account
+	var allCategories []*models.TransactionCategory
+	var allTags []*models.TransactionTag
+	var allPayees []*models.TransactionPayee
+	noResult := false
+
+	for i := 0; i < len(terms); i++ {
+		term := terms[i]
+
+		switch term.Field {
+		case "amount":
+			err = a.applyAmountSearchTerm(filter, term)
+		case "date":
+			err = a.applyDateSearchTerm(filter, term, utcOffset)
+		case "type":
+			var matched bool
+			matched, err = a.applyTypeSearchTerm(filter, term)
+			noResult = noResult || !matched
+		case "account":
+			if allAccounts == nil {
+				allAccounts, err = a.accounts.GetAllAccountsByUid(uid)
+
+				if err != nil {
+					log.ErrorfWithRequestId(c, "[transactions.getTransactionSearchFilter] failed to get accounts for user \"uid:%d\", because %s", uid, err.Error())
+					return nil, false, errs.ErrOperationFailed
+				}
+			}
+
+			var matchedAccountIds []int64
+
+			for j := 0; j < len(allAccounts); j++ {
+				if strings.EqualFold(allAccounts[j].Name, term.Value) {
+					matchedAccountIds = append(matchedAccountIds, allAccounts[j].AccountId)
+				}
+			}
+
+			for j := 0; j < len(allAccounts); j++ {
+				for k := 0; k < len(matchedAccountIds); k++ {
+					if allAccounts[j].ParentAccountId == matchedAccountIds[k] {
+						matchedAccountIds = append(matchedAccountIds, allAccounts[j].AccountId)
+						break
+					}
+				}
+			}
+
+			if term.Negated {
+				filter.ExcludeAccountIds = append(filter.ExcludeAccountIds, matchedAccountIds...)
+			} else if len(matchedAccountIds) > 0 {
+				filter.AccountIds = utils.ToUniqueInt64Slice(append(filter.AccountIds, matchedAccountIds...))
+			} else {
+				noResult = true
+			}
+		case "category":
+			if allCategories == nil {
+				allCategories, err = a.transactionCategories.GetAllCategoriesByUid(uid, 0, -1)
+
+				if err != nil {
+					log.ErrorfWithRequestId(c, "[transactions.getTransactionSearchFilter] failed to get categories for user \"uid:%d\", because %s", uid, err.Error())
+					return nil, false, errs.ErrOperationFailed
+				}
+			}
+
+			var matchedCategoryIds []int64
+
+			for j := 0; j < len(allCategories); j++ {
+				if strings.EqualFold(allCategories[j].Name, term.Value) {
+					matchedCategoryIds = append(matchedCategoryIds, allCategories[j].CategoryId)
+				}
+			}
+
+			matchedCategoryIds, err = a.getCategoryAndSubCategoryIds(matchedCategoryIds, uid)
+
+			if err != nil {
+				log.ErrorfWithRequestId(c, "[transactions.getTransactionSearchFilter] failed to get sub categories for user \"uid:%d\", because %s", uid, err.Error())
+				return nil, false, errs.ErrOperationFailed
+			}
+
+			if term.Negated {
+				filter.ExcludeCategoryIds = append(filter.ExcludeCategoryIds, matchedCategoryIds...)
+			} else if len(matchedCategoryIds) > 0 {
+				filter.CategoryIds = utils.ToUniqueInt64Slice(append(filter.CategoryIds, matchedCategoryIds...))
+			} else {
+				noResult = true
+			}
+		case "tag":
+			if allTags == nil {
+				allTags, err = a.transactionTags.GetAllTagsByUid(uid)
+
+				if err != nil {
+					log.ErrorfWithRequestId(c, "[transactions.getTransactionSearchFilter] failed to get tags for user \"uid:%d\", because %s", uid, err.Error())
+					return nil, false, errs.ErrOperationFailed
+				}
+			}
+
+			var matchedTagId int64
+
+			for j := 0; j < len(allTags); j++ {
+				if strings.EqualFold(allTags[j].Name, term.Value) {
+					matchedTagId = allTags[j].TagId
+					break
+				}
+			}
+
+			if term.Negated {
+				if matchedTagId > 0 {
+					filter.ExcludeTagIds = append(filter.ExcludeTagIds, matchedTagId)
+				}
+			} else if matchedTagId > 0 {
+				// all tags in search query must be matched
+				filter.TagIds = utils.ToUniqueInt64Slice(append(filter.TagIds, matchedTagId))
+				filter.TagFilterType = models.TRANSACTION_TAG_FILTER_HAS_ALL
+			} else {
+				noResult = true
+			}
+		case "payee":
+			if allPayees == nil {
+				allPayees, err = a.transactionPayees.GetAllPayeesByUid(uid)
+
+				if err != nil {
+					log.ErrorfWithRequestId(c, "[transactions.getTransactionSearchFilter] failed to get payees for user \"uid:%d\", because %s", uid, err.Error())
+					return nil, false, errs.ErrOperationFailed
+				}
+			}
+
+			var matchedPayeeId int64
+
+			for j := 0; j < len(allPayees); j++ {
+				if strings.EqualFold(allPayees[j].Name, term.Value) {
+					matchedPayeeId = allPayees[j].PayeeId
+					break
+				}
+			}
+
+			if term.Negated {
+				if matchedPayeeId > 0 {
+					filter.ExcludePayeeIds = append(filter.ExcludePayeeIds, matchedPayeeId)
+				}
+			} else if matchedPayeeId > 0 {
+				filter.PayeeIds = utils.ToUniqueInt64Slice(append(filter.PayeeIds, matchedPayeeId))
+			} else {
+				noResult = true
+			}
+		default:
+			keyword := term.Value
+
+			if term.Field != "" && term.Field != "comment" {
+				keyword = term.Field + term.Operator + term.Value
+			}
+
+			if term.Negated {
+				filter.ExcludeKeywords = append(filter.ExcludeKeywords, keyword)
+			} else {
+				filter.Keywords = append(filter.Keywords, keyword)
+			}
+		}
+
+		if err != nil {
+			log.WarnfWithRequestId(c, "[transactions.getTransactionSearchFilter] search query term \"%s%s%s\" is invalid, because %s", term.Field, term.Operator, term.Value, err.Error())
+			return nil, false, errs.ErrTransactionSearchQueryInvalid
+		}
+	}
+
+	return filter, noResult, nil
+}
+
+func (a *TransactionsApi) applyAmountSearchTerm(filter *models.TransactionSearchFilter, term *utils.SearchQueryTerm) error {
+	if term.Negated {
+		return errs.ErrFormatInvalid
+	}
+
+	amount, err := utils.StringToAmount(term.Value)
+
+	if err != nil {
+		return err
+	}
+
+	var minAmount *int64
+	var maxAmount *int64
+
+	switch term.Operator {
+	case ":", "=":
+		minAmount = &amount
+		maxAmount = &amount
+	case ">":
+		amount = amount + 1
+		minAmount = &amount
+	case ">=":
+		minAmount = &amount
+	case "<":
+		amount = amount - 1
+		maxAmount = &amount
+	case "<=":
+		maxAmount = &amount
+	default:
+		return errs.ErrFormatInvalid
+	}
 
-		finalTransactions = append(finalTransactions, transaction)
+	if minAmount != nil && (filter.MinAmount == nil || *minAmount > *filter.MinAmount) {
+		filter.MinAmount = minAmount
 	}
 
-	return finalTransactions
+	if maxAmount != nil && (filter.MaxAmount == nil || *maxAmount < *filter.MaxAmount) {
+		filter.MaxAmount = maxAmount
+	}
+
+	return nil
 }
 
-func (a *TransactionsApi) getCategoryAndSubCategoryIds(categoryId int64, uid int64) ([]int64, error) {
-	var allCategoryIds []int64
+func (a *TransactionsApi) applyDateSearchTerm(filter *models.TransactionSearchFilter, term *utils.SearchQueryTerm, utcOffset int16) error {
+	if term.Negated {
+		return errs.ErrFormatInvalid
+	}
 
-	if categoryId > 0 {
-		allSubCategories, err := a.transactionCategories.GetAllCategoriesByUid(uid, 0, categoryId)
+	startTime, err := utils.ParseFromShortDateTime(term.Value+" 0:0:0", utcOffset)
 
-		if err != nil {
-			return nil, err
-		}
+	if err != nil {
+		return err
+	}
 
-		if len(allSubCategories) > 0 {
-			for i := 0; i < len(allSubCategories); i++ {
-				allCategoryIds = append(allCategoryIds, allSubCategories[i].CategoryId)
-			}
-		} else {
-			allCategoryIds = append(allCategoryIds, categoryId)
+	startUnixTime := startTime.Unix()
+	endUnixTime := startTime.AddDate(0, 0, 1).Unix() - 1
+
+	var minTransactionTime int64
+	var maxTransactionTime int64
+
+	switch term.Operator {
+	case ":", "=":
+		minTransactionTime = utils.GetMinTransactionTimeFromUnixTime(startUnixTime)
+		maxTransactionTime = utils.GetMaxTransactionTimeFromUnixTime(endUnixTime)
+	case ">":
+		minTransactionTime = utils.GetMinTransactionTimeFromUnixTime(endUnixTime + 1)
+	case ">=":
+		minTransactionTime = utils.GetMinTransactionTimeFromUnixTime(startUnixTime)
+	case "<":
+		maxTransactionTime = utils.GetMaxTransactionTimeFromUnixTime(startUnixTime - 1)
+	case "<=":
+		maxTransactionTime = utils.GetMaxTransactionTimeFromUnixTime(endUnixTime)
+	default:
+		return errs.ErrFormatInvalid
+	}
+
+	if minTransactionTime > 0 && minTransactionTime > filter.MinTransactionTime {
+		filter.MinTransactionTime = minTransactionTime
+	}
+
+	if maxTransactionTime > 0 && (filter.MaxTransactionTime <= 0 || maxTransactionTime < filter.MaxTransactionTime) {
+		filter.MaxTransactionTime = maxTransactionTime
+	}
+
+	return nil
+}
+
+func (a *TransactionsApi) applyTypeSearchTerm(filter *models.TransactionSearchFilter, term *utils.SearchQueryTerm) (bool, error) {
+	if term.Negated || (term.Operator != ":" && term.Operator != "=") {
+		return false, errs.ErrFormatInvalid
+	}
+
+	var transactionType models.TransactionDbType
+
+	switch strings.ToLower(term.Value) {
+	case "balance":
+		transactionType = models.TRANSACTION_DB_TYPE_MODIFY_BALANCE
+	case "income":
+		transactionType = models.TRANSACTION_DB_TYPE_INCOME
+	case "expense":
+		transactionType = models.TRANSACTION_DB_TYPE_EXPENSE
+	case "transfer":
+		transactionType = models.TRANSACTION_DB_TYPE_TRANSFER_OUT
+	default:
+		return false, errs.ErrFormatInvalid
+	}
+
+	if filter.Type != 0 && filter.Type != transactionType {
+		return false, nil
+	}
+
+	filter.Type = transactionType
+
+	return true, nil
+}
+
+func (a *TransactionsApi) getTransactionStatisticsGroupByTag(c *core.Context, uid int64, statisticReq *models.TransactionStatisticRequest, payeeIds []int64) (*models.TransactionStatisticResponse, *errs.Error) {
+	totalAmounts, err := a.transactions.GetAccountsCategoriesAndTagsTotalIncomeAndExpense(uid, statisticReq.StartTime, statisticReq.EndTime, payeeIds, statisticReq.GroupByPayee)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[transactions.getTransactionStatisticsGroupByTag] failed to get tags, accounts and categories total income and expense for user \"uid:%d\", because %s", uid, err.Error())
+		return nil, errs.ErrOperationFailed
+	}
+
+	statisticResp := &models.TransactionStatisticResponse{
+		StartTime: statisticReq.StartTime,
+		EndTime:   statisticReq.EndTime,
+	}
+
+	statisticResp.Items = make([]*models.TransactionStatisticResponseItem, len(totalAmounts))
+
+	for i := 0; i < len(totalAmounts); i++ {
+		totalAmountItem := totalAmounts[i]
+		statisticResp.Items[i] = &models.TransactionStatisticResponseItem{
+			CategoryId:  totalAmountItem.CategoryId,
+			AccountId:   totalAmountItem.AccountId,
+			PayeeId:     totalAmountItem.PayeeId,
+			TagId:       totalAmountItem.TagId,
+			TotalAmount: totalAmountItem.Amount,
 		}
 	}
 
-	return allCategoryIds, nil
+	return statisticResp, nil
 }
 
 func (a *TransactionsApi) getTransactionTagIds(allTransactionTagIds map[int64][]int64) []int64 {
@@ -913,6 +1809,13 @@ func (a *TransactionsApi) getTransactionListResult(c *core.Context, user *models
 		return nil, err
 	}
 
+	allTransactionSplits, err := a.transactionSplits.GetAllSplitsOfTransactions(uid, transactionIds)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[transactions.getTransactionListResult] failed to get transaction splits for user \"uid:%d\", because %s", uid, err.Error())
+		return nil, err
+	}
+
 	var categoryMap map[int64]*models.TransactionCategory
 	var tagMap map[int64]*models.TransactionTag
 
@@ -946,6 +1849,7 @@ func (a *TransactionsApi) getTransactionListResult(c *core.Context, user *models
 		transactionEditable := transaction.IsEditable(user, utcOffset, allAccounts[transaction.AccountId], allAccounts[transaction.RelatedAccountId])
 		transactionTagIds := allTransactionTagIds[transaction.TransactionId]
 		result[i] = transaction.ToTransactionInfoResponse(transactionTagIds, transactionEditable)
+		result[i].Splits = a.getTransactionSplitInfoResponses(allTransactionSplits[transaction.TransactionId])
 
 		if !trimAccount {
 			if sourceAccount := allAccounts[transaction.AccountId]; sourceAccount != nil {
@@ -973,6 +1877,233 @@ func (a *TransactionsApi) getTransactionListResult(c *core.Context, user *models
 	return result, nil
 }
 
+func (a *TransactionsApi) createTransaction(c *core.Context, transactionCreateReq *models.TransactionCreateRequest) (interface{}, *errs.Error) {
+	uid := c.GetCurrentUid()
+	user, err := a.users.GetUserById(uid)
+
+	if err != nil {
+		if !errs.IsCustomError(err) {
+			log.ErrorfWithRequestId(c, "[transactions.createTransaction] failed to get user, because %s", err.Error())
+		}
+
+		return nil, errs.ErrUserNotFound
+	}
+
+	transaction, tagIds, splits, errResp := a.getNewTransactionModels(c, user, transactionCreateReq)
+
+	if errResp != nil {
+		return nil, errResp
+	}
+
+	rules, err := a.transactionRules.GetAllEnabledRulesByUid(uid)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[transactions.createTransaction] failed to get transaction rules for user \"uid:%d\", because %s", uid, err.Error())
+		return nil, errs.ErrOperationFailed
+	}
+
+	tagIds, _ = a.transactionRules.ApplyRulesToTransaction(rules, transaction, tagIds, len(splits) > 0)
+
+	err = a.transactions.CreateTransaction(transaction, tagIds, splits, models.NewTransactionOperator(c))
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[transactions.createTransaction] failed to create transaction \"id:%d\" for user \"uid:%d\", because %s", transaction.TransactionId, uid, err.Error())
+		return nil, errs.Or(err, errs.ErrOperationFailed)
+	}
+
+	log.InfofWithRequestId(c, "[transactions.createTransaction] user \"uid:%d\" has created a new transaction \"id:%d\" successfully", uid, transaction.TransactionId)
+
+	transactionResp := transaction.ToTransactionInfoResponse(tagIds, true)
+	transactionResp.Splits = a.getTransactionSplitInfoResponses(splits)
+
+	return transactionResp, nil
+}
+
+func (a *TransactionsApi) getNewTransactionModels(c *core.Context, user *models.User, transactionCreateReq *models.TransactionCreateRequest) (*models.Transaction, []int64, []*models.TransactionSplit, *errs.Error) {
+	tagIds, err := utils.StringArrayToInt64Array(transactionCreateReq.TagIds)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[transactions.getNewTransactionModels] parse tag ids failed, because %s", err.Error())
+		return nil, nil, nil, errs.ErrTransactionTagIdInvalid
+	}
+
+	if transactionCreateReq.Type < models.TRANSACTION_TYPE_MODIFY_BALANCE || transactionCreateReq.Type > models.TRANSACTION_TYPE_TRANSFER {
+		log.WarnfWithRequestId(c, "[transactions.getNewTransactionModels] transaction type is invalid")
+		return nil, nil, nil, errs.ErrTransactionTypeInvalid
+	}
+
+	if transactionCreateReq.Type == models.TRANSACTION_TYPE_MODIFY_BALANCE && transactionCreateReq.CategoryId > 0 {
+		log.WarnfWithRequestId(c, "[transactions.getNewTransactionModels] balance modification transaction cannot set category id")
+		return nil, nil, nil, errs.ErrBalanceModificationTransactionCannotSetCategory
+	}
+
+	if transactionCreateReq.Type != models.TRANSACTION_TYPE_TRANSFER && transactionCreateReq.DestinationAccountId != 0 {
+		log.WarnfWithRequestId(c, "[transactions.getNewTransactionModels] non-transfer transaction destination account cannot be set")
+		return nil, nil, nil, errs.ErrTransactionDestinationAccountCannotBeSet
+	} else if transactionCreateReq.Type == models.TRANSACTION_TYPE_TRANSFER && transactionCreateReq.SourceAccountId == transactionCreateReq.DestinationAccountId {
+		log.WarnfWithRequestId(c, "[transactions.getNewTransactionModels] transfer transaction source account must not be destination account")
+		return nil, nil, nil, errs.ErrTransactionSourceAndDestinationIdCannotBeEqual
+	}
+
+	if transactionCreateReq.Type != models.TRANSACTION_TYPE_TRANSFER && transactionCreateReq.DestinationAmount != 0 {
+		log.WarnfWithRequestId(c, "[transactions.getNewTransactionModels] non-transfer transaction destination amount cannot be set")
+		return nil, nil, nil, errs.ErrTransactionDestinationAmountCannotBeSet
+	}
+
+	if transactionCreateReq.Type != models.TRANSACTION_TYPE_INCOME && transactionCreateReq.Type != models.TRANSACTION_TYPE_EXPENSE && len(transactionCreateReq.Splits) > 0 {
+		log.WarnfWithRequestId(c, "[transactions.getNewTransactionModels] only income or expense transaction can be split")
+		return nil, nil, nil, errs.ErrTransactionCannotBeSplit
+	}
+
+	transaction := a.createNewTransactionModel(user.Uid, transactionCreateReq)
+	splits := a.createNewTransactionSplitModels(transactionCreateReq.Splits)
+
+	if errResp := a.setTransactionOriginalAmount(c, transaction, transactionCreateReq.OriginalCurrency, transactionCreateReq.OriginalAmount, transactionCreateReq.ExchangeRate); errResp != nil {
+		return nil, nil, nil, errResp
+	}
+
+	if !user.CanEditTransactionByTransactionTime(transaction.TransactionTime, transactionCreateReq.UtcOffset) {
+		return nil, nil, nil, errs.ErrCannotCreateTransactionWithThisTransactionTime
+	}
+
+	return transaction, tagIds, splits, nil
+}
+
+func (a *TransactionsApi) getModifiedTransactionModels(c *core.Context, user *models.User, transactionModifyReq *models.TransactionModifyRequest) (*models.Transaction, []int64, []int64, []int64, []*models.TransactionSplit, *errs.Error) {
+	tagIds, err := utils.StringArrayToInt64Array(transactionModifyReq.TagIds)
+
+	if err != nil {
+		log.WarnfWithRequestId(c, "[transactions.getModifiedTransactionModels] parse tag ids failed, because %s", err.Error())
+		return nil, nil, nil, nil, nil, errs.ErrTransactionTagIdInvalid
+	}
+
+	uid := user.Uid
+
+	transaction, err := a.transactions.GetTransactionByTransactionId(uid, transactionModifyReq.Id)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[transactions.getModifiedTransactionModels] failed to get transaction \"id:%d\" for user \"uid:%d\", because %s", transactionModifyReq.Id, uid, err.Error())
+		return nil, nil, nil, nil, nil, errs.Or(err, errs.ErrOperationFailed)
+	}
+
+	if transaction.Type == models.TRANSACTION_DB_TYPE_TRANSFER_IN {
+		log.WarnfWithRequestId(c, "[transactions.getModifiedTransactionModels] cannot modify transaction \"id:%d\" for user \"uid:%d\", because transaction type is transfer in", transactionModifyReq.Id, uid)
+		return nil, nil, nil, nil, nil, errs.ErrTransactionTypeInvalid
+	}
+
+	allTransactionTagIds, err := a.transactionTags.GetAllTagIdsOfTransactions(uid, []int64{transaction.TransactionId})
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[transactions.getModifiedTransactionModels] failed to get transactions tag ids for user \"uid:%d\", because %s", uid, err.Error())
+		return nil, nil, nil, nil, nil, errs.ErrOperationFailed
+	}
+
+	transactionTagIds := allTransactionTagIds[transaction.TransactionId]
+
+	if transactionTagIds == nil {
+		transactionTagIds = make([]int64, 0, 0)
+	}
+
+	if transaction.Type != models.TRANSACTION_DB_TYPE_INCOME && transaction.Type != models.TRANSACTION_DB_TYPE_EXPENSE && len(transactionModifyReq.Splits) > 0 {
+		log.WarnfWithRequestId(c, "[transactions.getModifiedTransactionModels] only income or expense transaction can be split")
+		return nil, nil, nil, nil, nil, errs.ErrTransactionCannotBeSplit
+	}
+
+	allTransactionSplits, err := a.transactionSplits.GetAllSplitsOfTransactions(uid, []int64{transaction.TransactionId})
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[transactions.getModifiedTransactionModels] failed to get transaction splits for user \"uid:%d\", because %s", uid, err.Error())
+		return nil, nil, nil, nil, nil, errs.ErrOperationFailed
+	}
+
+	if transactionModifyReq.ClearSplits && len(transactionModifyReq.Splits) > 0 {
+		log.WarnfWithRequestId(c, "[transactions.getModifiedTransactionModels] cannot clear splits and set new splits at the same time")
+		return nil, nil, nil, nil, nil, errs.ErrTransactionSplitsConflict
+	}
+
+	transactionSplits := allTransactionSplits[transaction.TransactionId]
+	newTransactionSplits := a.createNewTransactionSplitModels(transactionModifyReq.Splits)
+
+	// The existed splits are kept if the request does not contain any split, they are only removed when clearing splits explicitly
+	if len(newTransactionSplits) < 1 && !transactionModifyReq.ClearSplits {
+		newTransactionSplits = a.copyTransactionSplitModels(transactionSplits)
+	}
+
+	if len(newTransactionSplits) > 0 {
+		transactionModifyReq.CategoryId = newTransactionSplits[0].CategoryId
+	}
+
+	newTransaction := &models.Transaction{
+		TransactionId:     transaction.TransactionId,
+		Uid:               uid,
+		Type:              transaction.Type,
+		CategoryId:        transactionModifyReq.CategoryId,
+		TransactionTime:   utils.GetMinTransactionTimeFromUnixTime(transactionModifyReq.Time),
+		TimezoneUtcOffset: transactionModifyReq.UtcOffset,
+		AccountId:         transactionModifyReq.SourceAccountId,
+		PayeeId:           transactionModifyReq.PayeeId,
+		Amount:            transactionModifyReq.SourceAmount,
+		HideAmount:        transactionModifyReq.HideAmount,
+		Comment:           transactionModifyReq.Comment,
+		ReconcileState:    transaction.ReconcileState,
+	}
+
+	if transaction.Type == models.TRANSACTION_DB_TYPE_TRANSFER_OUT {
+		newTransaction.RelatedAccountId = transactionModifyReq.DestinationAccountId
+		newTransaction.RelatedAccountAmount = transactionModifyReq.DestinationAmount
+	}
+
+	if errResp := a.setTransactionOriginalAmount(c, newTransaction, transactionModifyReq.OriginalCurrency, transactionModifyReq.OriginalAmount, transactionModifyReq.ExchangeRate); errResp != nil {
+		return nil, nil, nil, nil, nil, errResp
+	}
+
+	if newTransaction.CategoryId == transaction.CategoryId &&
+		utils.GetUnixTimeFromTransactionTime(newTransaction.TransactionTime) == utils.GetUnixTimeFromTransactionTime(transaction.TransactionTime) &&
+		newTransaction.TimezoneUtcOffset == transaction.TimezoneUtcOffset &&
+		newTransaction.AccountId == transaction.AccountId &&
+		newTransaction.PayeeId == transaction.PayeeId &&
+		newTransaction.Amount == transaction.Amount &&
+		(transaction.Type != models.TRANSACTION_DB_TYPE_TRANSFER_OUT || newTransaction.RelatedAccountId == transaction.RelatedAccountId) &&
+		(transaction.Type != models.TRANSACTION_DB_TYPE_TRANSFER_OUT || newTransaction.RelatedAccountAmount == transaction.RelatedAccountAmount) &&
+		newTransaction.OriginalCurrency == transaction.OriginalCurrency &&
+		newTransaction.OriginalAmount == transaction.OriginalAmount &&
+		newTransaction.ExchangeRate == transaction.ExchangeRate &&
+		newTransaction.HideAmount == transaction.HideAmount &&
+		newTransaction.Comment == transaction.Comment &&
+		utils.Int64SliceEquals(tagIds, transactionTagIds) &&
+		a.isTransactionSplitsEqual(newTransactionSplits, transactionSplits) {
+		return nil, nil, nil, nil, nil, errs.ErrNothingWillBeUpdated
+	}
+
+	var addTransactionTagIds []int64
+	var removeTransactionTagIds []int64
+
+	if !utils.Int64SliceEquals(tagIds, transactionTagIds) {
+		removeTransactionTagIds = transactionTagIds
+		addTransactionTagIds = tagIds
+	}
+
+	transactionEditable := user.CanEditTransactionByTransactionTime(transaction.TransactionTime, transaction.TimezoneUtcOffset)
+	newTransactionEditable := user.CanEditTransactionByTransactionTime(newTransaction.TransactionTime, transactionModifyReq.UtcOffset)
+
+	if !transactionEditable || !newTransactionEditable {
+		return nil, nil, nil, nil, nil, errs.ErrCannotModifyTransactionWithThisTransactionTime
+	}
+
+	reconciled, err := a.isTransactionReconciled(uid, transaction)
+
+	if err != nil {
+		log.ErrorfWithRequestId(c, "[transactions.getModifiedTransactionModels] failed to get related transaction of transaction \"id:%d\" for user \"uid:%d\", because %s", transactionModifyReq.Id, uid, err.Error())
+		return nil, nil, nil, nil, nil, errs.Or(err, errs.ErrOperationFailed)
+	}
+
+	if reconciled && !transactionModifyReq.Force {
+		return nil, nil, nil, nil, nil, errs.ErrCannotModifyReconciledTransaction
+	}
+
+	return newTransaction, tagIds, addTransactionTagIds, removeTransactionTagIds, newTransactionSplits, nil
+}
+
 func (a *TransactionsApi) createNewTransactionModel(uid int64, transactionCreateReq *models.TransactionCreateRequest) *models.Transaction {
 	var transactionDbType models.TransactionDbType
 
@@ -993,6 +2124,7 @@ func (a *TransactionsApi) createNewTransactionModel(uid int64, transactionCreate
 		TransactionTime:   utils.GetMinTransactionTimeFromUnixTime(transactionCreateReq.Time),
 		TimezoneUtcOffset: transactionCreateReq.UtcOffset,
 		AccountId:         transactionCreateReq.SourceAccountId,
+		PayeeId:           transactionCreateReq.PayeeId,
 		Amount:            transactionCreateReq.SourceAmount,
 		HideAmount:        transactionCreateReq.HideAmount,
 		Comment:           transactionCreateReq.Comment,
@@ -1005,3 +2137,105 @@ func (a *TransactionsApi) createNewTransactionModel(uid int64, transactionCreate
 
 	return transaction
 }
+
+func (a *TransactionsApi) setTransactionOriginalAmount(c *core.Context, transaction *models.Transaction, originalCurrency string, originalAmount int64, exchangeRate string) *errs.Error {
+	if originalCurrency == "" {
+		if originalAmount != 0 || exchangeRate != "" {
+			log.WarnfWithRequestId(c, "[transactions.setTransactionOriginalAmount] original amount or exchange rate cannot be set without original currency")
+			return errs.ErrTransactionOriginalAmountInvalid
+		}
+
+		return nil
+	}
+
+	if transaction.Type != models.TRANSACTION_DB_TYPE_INCOME && transaction.Type != models.TRANSACTION_DB_TYPE_EXPENSE {
+		log.WarnfWithRequestId(c, "[transactions.setTransactionOriginalAmount] only income or expense transaction can have original amount")
+		return errs.ErrTransactionCannotHaveOriginalAmount
+	}
+
+	if originalAmount == 0 {
+		log.WarnfWithRequestId(c, "[transactions.setTransactionOriginalAmount] original amount cannot be zero")
+		return errs.ErrTransactionOriginalAmountInvalid
+	}
+
+	if exchangeRate == "" {
+		if transaction.Amount == 0 {
+			log.WarnfWithRequestId(c, "[transactions.setTransactionOriginalAmount] cannot calculate exchange rate when amount is zero")
+			return errs.ErrTransactionExchangeRateInvalid
+		}
+
+		rate := math.Round(float64(transaction.Amount)/float64(originalAmount)*1000000) / 1000000
+		exchangeRate = utils.Float64ToString(rate)
+	}
+
+	rate, err := utils.StringToFloat64(exchangeRate)
+
+	if err != nil || rate <= 0 || math.IsInf(rate, 0) {
+		log.WarnfWithRequestId(c, "[transactions.setTransactionOriginalAmount] exchange rate \"%s\" is invalid", exchangeRate)
+		return errs.ErrTransactionExchangeRateInvalid
+	}
+
+	transaction.OriginalCurrency = originalCurrency
+	transaction.OriginalAmount = originalAmount
+	transaction.ExchangeRate = exchangeRate
+
+	return nil
+}
+
+func (a *TransactionsApi) createNewTransactionSplitModels(splitReqs []*models.TransactionSplitRequest) []*models.TransactionSplit {
+	splits := make([]*models.TransactionSplit, len(splitReqs))
+
+	for i := 0; i < len(splitReqs); i++ {
+		splits[i] = &models.TransactionSplit{
+			CategoryId: splitReqs[i].CategoryId,
+			Amount:     splitReqs[i].Amount,
+			Comment:    splitReqs[i].Comment,
+		}
+	}
+
+	return splits
+}
+
+func (a *TransactionsApi) copyTransactionSplitModels(splits []*models.TransactionSplit) []*models.TransactionSplit {
+	newSplits := make([]*models.TransactionSplit, len(splits))
+
+	for i := 0; i < len(splits); i++ {
+		newSplits[i] = &models.TransactionSplit{
+			CategoryId: splits[i].CategoryId,
+			Amount:     splits[i].Amount,
+			Comment:    splits[i].Comment,
+		}
+	}
+
+	return newSplits
+}
+
+func (a *TransactionsApi) isTransactionSplitsEqual(splits []*models.TransactionSplit, otherSplits []*models.TransactionSplit) bool {
+	if len(splits) != len(otherSplits) {
+		return false
+	}
+
+	for i := 0; i < len(splits); i++ {
+		if splits[i].CategoryId != otherSplits[i].CategoryId ||
+			splits[i].Amount != otherSplits[i].Amount ||
+			splits[i].Comment != otherSplits[i].Comment {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (a *TransactionsApi) getTransactionSplitInfoResponses(splits []*models.TransactionSplit) []*models.TransactionSplitInfoResponse {
+	if len(splits) < 1 {
+		return nil
+	}
+
+	splitResps := make([]*models.TransactionSplitInfoResponse, len(splits))
+
+	for i := 0; i < len(splits); i++ {
+		splitResps[i] = splits[i].ToTransactionSplitInfoResponse()
+	}
+
+	return splitResps
+}
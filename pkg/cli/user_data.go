@@ -23,6 +23,9 @@ type UserDataCli struct {
 	transactions             *services.TransactionService
 	categories               *services.TransactionCategoryService
 	tags                     *services.TransactionTagService
+	splits                   *services.TransactionSplitService
+	attachments              *services.TransactionAttachmentService
+	histories                *services.TransactionHistoryService
 	users                    *services.UserService
 	twoFactorAuthorizations  *services.TwoFactorAuthorizationService
 	tokens                   *services.TokenService
@@ -36,6 +39,9 @@ var (
 		transactions:             services.Transactions,
 		categories:               services.TransactionCategories,
 		tags:                     services.TransactionTags,
+		splits:                   services.TransactionSplits,
+		attachments:              services.TransactionAttachments,
+		histories:                services.TransactionHistories,
 		users:                    services.Users,
 		twoFactorAuthorizations:  services.TwoFactorAuthorizations,
 		tokens:                   services.Tokens,
@@ -168,16 +174,54 @@ func (l *UserDataCli) DeleteUser(c *cli.Context, username string) error {
 		return errs.ErrUsernameIsEmpty
 	}
 
-	err := l.users.DeleteUser(username)
+	uid, err := l.getUserIdByUsername(c, username)
+
+	if err != nil {
+		log.BootErrorf("[user_data.DeleteUser] error occurs when getting user id by user name")
+		return err
+	}
+
+	err = l.users.DeleteUser(username)
 
 	if err != nil {
 		log.BootErrorf("[user_data.DeleteUser] failed to delete user by user name \"%s\", because %s", username, err.Error())
 		return err
 	}
 
+	err = l.attachments.DeleteAllAttachments(uid)
+
+	if err != nil {
+		log.BootErrorf("[user_data.DeleteUser] failed to delete transaction attachments of user \"%s\", because %s", username, err.Error())
+		return err
+	}
+
 	return nil
 }
 
+// GetTransactionHistories returns all transaction change histories of the specified user
+func (l *UserDataCli) GetTransactionHistories(c *cli.Context, username string) ([]*models.TransactionHistory, error) {
+	if username == "" {
+		log.BootErrorf("[user_data.GetTransactionHistories] user name is empty")
+		return nil, errs.ErrUsernameIsEmpty
+	}
+
+	uid, err := l.getUserIdByUsername(c, username)
+
+	if err != nil {
+		log.BootErrorf("[user_data.GetTransactionHistories] error occurs when getting user id by user name")
+		return nil, err
+	}
+
+	histories, err := l.histories.GetAllHistoriesByUid(uid)
+
+	if err != nil {
+		log.BootErrorf("[user_data.GetTransactionHistories] failed to get transaction histories of user \"%s\", because %s", username, err.Error())
+		return nil, err
+	}
+
+	return histories, nil
+}
+
 // ListUserTokens returns all tokens of the specified user
 func (l *UserDataCli) ListUserTokens(c *cli.Context, username string) ([]*models.TokenRecord, error) {
 	if username == "" {
@@ -417,7 +461,14 @@ func (l *UserDataCli) ExportTransaction(c *cli.Context, username string) ([]byte
 		return nil, err
 	}
 
-	result, err := l.ezBookKeepingCsvExporter.ToExportedContent(uid, time.Local, allTransactions, accountMap, categoryMap, tagMap, tagIndexs)
+	splits, err := l.splits.GetAllSplitsOfAllTransactions(uid)
+
+	if err != nil {
+		log.BootErrorf("[user_data.ExportTransaction] failed to get transaction splits for user \"%s\", because %s", username, err.Error())
+		return nil, err
+	}
+
+	result, err := l.ezBookKeepingCsvExporter.ToExportedContent(uid, time.Local, allTransactions, accountMap, categoryMap, tagMap, tagIndexs, splits)
 
 	if err != nil {
 		log.BootErrorf("[user_data.ExportTransaction] failed to get csv format exported data for \"%s\", because %s", username, err.Error())
@@ -427,6 +478,63 @@ func (l *UserDataCli) ExportTransaction(c *cli.Context, username string) ([]byte
 	return result, nil
 }
 
+// MergeTransactionTags merges the specified transaction tags of the specified user into another tag
+func (l *UserDataCli) MergeTransactionTags(c *cli.Context, username string, fromTagNames []string, toTagName string) error {
+	if username == "" {
+		log.BootErrorf("[user_data.MergeTransactionTags] user name is empty")
+		return errs.ErrUsernameIsEmpty
+	}
+
+	uid, err := l.getUserIdByUsername(c, username)
+
+	if err != nil {
+		log.BootErrorf("[user_data.MergeTransactionTags] error occurs when getting user id by user name")
+		return err
+	}
+
+	tags, err := l.tags.GetAllTagsByUid(uid)
+
+	if err != nil {
+		log.BootErrorf("[user_data.MergeTransactionTags] failed to get tags for user \"%s\", because %s", username, err.Error())
+		return err
+	}
+
+	tagIdsByName := make(map[string]int64, len(tags))
+
+	for i := 0; i < len(tags); i++ {
+		tagIdsByName[tags[i].Name] = tags[i].TagId
+	}
+
+	toTagId, exists := tagIdsByName[toTagName]
+
+	if !exists {
+		log.BootErrorf("[user_data.MergeTransactionTags] tag \"%s\" does not exist for user \"%s\"", toTagName, username)
+		return errs.ErrTransactionTagNotFound
+	}
+
+	fromTagIds := make([]int64, len(fromTagNames))
+
+	for i := 0; i < len(fromTagNames); i++ {
+		fromTagId, exists := tagIdsByName[fromTagNames[i]]
+
+		if !exists {
+			log.BootErrorf("[user_data.MergeTransactionTags] tag \"%s\" does not exist for user \"%s\"", fromTagNames[i], username)
+			return errs.ErrTransactionTagNotFound
+		}
+
+		fromTagIds[i] = fromTagId
+	}
+
+	err = l.tags.MergeTags(uid, fromTagIds, toTagId)
+
+	if err != nil {
+		log.BootErrorf("[user_data.MergeTransactionTags] failed to merge tags into tag \"%s\" for user \"%s\", because %s", toTagName, username, err.Error())
+		return err
+	}
+
+	return nil
+}
+
 func (l *UserDataCli) getUserIdByUsername(c *cli.Context, username string) (int64, error) {
 	user, err := l.GetUserByUsername(c, username)
 
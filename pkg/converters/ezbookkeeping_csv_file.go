@@ -14,11 +14,11 @@ type EzBookKeepingCSVFileExporter struct {
 	DataConverter
 }
 
-const csvHeaderLine = "Time,Timezone,Type,Category,Sub Category,Account,Account Currency,Amount,Account2,Account2 Currency,Account2 Amount,Tags,Comment\n"
-const csvDataLineFormat = "%s,%s,%s,%s,%s,%s,%s,%s,%s,%s,%s,%s,%s\n"
+const csvHeaderLine = "Time,Timezone,Type,Category,Sub Category,Account,Account Currency,Amount,Account2,Account2 Currency,Account2 Amount,Tags,Comment,Original Currency,Original Amount,Exchange Rate\n"
+const csvDataLineFormat = "%s,%s,%s,%s,%s,%s,%s,%s,%s,%s,%s,%s,%s,%s,%s,%s\n"
 
 // ToExportedContent returns the exported csv data
-func (e *EzBookKeepingCSVFileExporter) ToExportedContent(uid int64, timezone *time.Location, transactions []*models.Transaction, accountMap map[int64]*models.Account, categoryMap map[int64]*models.TransactionCategory, tagMap map[int64]*models.TransactionTag, allTagIndexs map[int64][]int64) ([]byte, error) {
+func (e *EzBookKeepingCSVFileExporter) ToExportedContent(uid int64, timezone *time.Location, transactions []*models.Transaction, accountMap map[int64]*models.Account, categoryMap map[int64]*models.TransactionCategory, tagMap map[int64]*models.TransactionTag, allTagIndexs map[int64][]int64, allSplits map[int64][]*models.TransactionSplit) ([]byte, error) {
 	var ret strings.Builder
 
 	ret.Grow(len(transactions) * 100)
@@ -50,10 +50,38 @@ func (e *EzBookKeepingCSVFileExporter) ToExportedContent(uid int64, timezone *ti
 			account2Amount = e.getDisplayAmount(transaction.RelatedAccountAmount)
 		}
 
+		originalCurrency := ""
+		originalAmount := ""
+		exchangeRate := ""
+
+		if transaction.OriginalCurrency != "" {
+			originalCurrency = transaction.OriginalCurrency
+			originalAmount = e.getDisplayAmount(transaction.OriginalAmount)
+			exchangeRate = transaction.ExchangeRate
+		}
+
 		tags := e.getTags(transaction.TransactionId, allTagIndexs, tagMap)
 		comment := e.getComment(transaction.Comment)
 
-		ret.WriteString(fmt.Sprintf(csvDataLineFormat, transactionTime, transactionTimezone, transactionType, category, subCategory, account, accountCurrency, amount, account2, account2Currency, account2Amount, tags, comment))
+		if splits, exists := allSplits[transaction.TransactionId]; exists && len(splits) > 0 {
+			for j := 0; j < len(splits); j++ {
+				split := splits[j]
+				category = e.getTransactionCategoryName(split.CategoryId, categoryMap)
+				subCategory = e.getTransactionSubCategoryName(split.CategoryId, categoryMap)
+				amount = e.getDisplayAmount(split.Amount)
+				splitComment := comment
+
+				if split.Comment != "" {
+					splitComment = e.getComment(split.Comment)
+				}
+
+				ret.WriteString(fmt.Sprintf(csvDataLineFormat, transactionTime, transactionTimezone, transactionType, category, subCategory, account, accountCurrency, amount, account2, account2Currency, account2Amount, tags, splitComment, originalCurrency, originalAmount, exchangeRate))
+			}
+
+			continue
+		}
+
+		ret.WriteString(fmt.Sprintf(csvDataLineFormat, transactionTime, transactionTimezone, transactionType, category, subCategory, account, accountCurrency, amount, account2, account2Currency, account2Amount, tags, comment, originalCurrency, originalAmount, exchangeRate))
 	}
 
 	return []byte(ret.String()), nil
@@ -10,3 +10,6 @@ type ApiHandlerFunc func(*Context) (interface{}, *errs.Error)
 
 // DataHandlerFunc represents the handler function that returns byte array
 type DataHandlerFunc func(*Context) ([]byte, string, *errs.Error)
+
+// FileHandlerFunc represents the handler function that returns file content, content type and file name
+type FileHandlerFunc func(*Context) ([]byte, string, string, *errs.Error)
@@ -0,0 +1,39 @@
+package cron
+
+import (
+	"github.com/mayswind/ezbookkeeping/pkg/log"
+	"github.com/mayswind/ezbookkeeping/pkg/settings"
+)
+
+// CronJobSchedulerContainer contains all cron jobs which run in background
+type CronJobSchedulerContainer struct {
+	jobs []*CronJob
+}
+
+// Initialize a cron job scheduler container singleton instance
+var (
+	Container = &CronJobSchedulerContainer{}
+)
+
+// InitializeCronJobSchedulerContainer initializes all cron jobs according to the config
+func InitializeCronJobSchedulerContainer(config *settings.Config) error {
+	Container.jobs = []*CronJob{
+		CreateScheduledTransactionsJob,
+		PostDuePendingTransactionsJob,
+	}
+
+	if config.TransactionTrashRetentionDays > 0 {
+		Container.jobs = append(Container.jobs, PurgeExpiredDeletedTransactionsJob)
+	}
+
+	return nil
+}
+
+// StartAllJobs starts all cron jobs in background
+func (c *CronJobSchedulerContainer) StartAllJobs() {
+	for i := 0; i < len(c.jobs); i++ {
+		job := c.jobs[i]
+		log.BootInfof("[cron_container.StartAllJobs] cron job \"%s\" will run every %s", job.Name, job.Interval)
+		go job.start()
+	}
+}
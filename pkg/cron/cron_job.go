@@ -0,0 +1,33 @@
+package cron
+
+import (
+	"time"
+
+	"github.com/mayswind/ezbookkeeping/pkg/log"
+)
+
+// CronJob represents a job which runs periodically in background
+type CronJob struct {
+	Name     string
+	Interval time.Duration
+	Run      func()
+}
+
+func (j *CronJob) start() {
+	ticker := time.NewTicker(j.Interval)
+
+	for {
+		j.runOnce()
+		<-ticker.C
+	}
+}
+
+func (j *CronJob) runOnce() {
+	defer func() {
+		if err := recover(); err != nil {
+			log.Errorf("[cron_job.runOnce] cron job \"%s\" panicked, because %s", j.Name, err)
+		}
+	}()
+
+	j.Run()
+}
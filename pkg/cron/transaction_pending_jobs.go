@@ -0,0 +1,48 @@
+package cron
+
+import (
+	"time"
+
+	"github.com/mayswind/ezbookkeeping/pkg/log"
+	"github.com/mayswind/ezbookkeeping/pkg/services"
+)
+
+const pageCountForPostDuePendingTransactions = 100
+
+// PostDuePendingTransactionsJob represents the cron job which posts all pending transactions whose transaction time has arrived to account balance
+var PostDuePendingTransactionsJob = &CronJob{
+	Name:     "PostDuePendingTransactions",
+	Interval: time.Minute,
+	Run:      postDuePendingTransactions,
+}
+
+func postDuePendingTransactions() {
+	for {
+		transactions, err := services.Transactions.GetAllDuePendingTransactions(pageCountForPostDuePendingTransactions)
+
+		if err != nil {
+			log.Errorf("[transaction_pending_jobs.postDuePendingTransactions] failed to get due pending transactions, because %s", err.Error())
+			return
+		}
+
+		postedCount := 0
+
+		for i := 0; i < len(transactions); i++ {
+			transaction := transactions[i]
+			err = services.Transactions.PostPendingTransaction(transaction.Uid, transaction.TransactionId)
+
+			if err != nil {
+				log.Errorf("[transaction_pending_jobs.postDuePendingTransactions] failed to post pending transaction \"id:%d\" of user \"uid:%d\", because %s", transaction.TransactionId, transaction.Uid, err.Error())
+				continue
+			}
+
+			postedCount++
+		}
+
+		if postedCount < 1 {
+			return
+		}
+
+		log.Infof("[transaction_pending_jobs.postDuePendingTransactions] %d due pending transactions have been posted", postedCount)
+	}
+}
@@ -0,0 +1,66 @@
+package cron
+
+import (
+	"time"
+
+	"github.com/mayswind/ezbookkeeping/pkg/errs"
+	"github.com/mayswind/ezbookkeeping/pkg/log"
+	"github.com/mayswind/ezbookkeeping/pkg/services"
+)
+
+const pageCountForCreateScheduledTransactions = 100
+
+// CreateScheduledTransactionsJob represents the cron job which creates transactions for all due transaction schedules
+var CreateScheduledTransactionsJob = &CronJob{
+	Name:     "CreateScheduledTransactions",
+	Interval: time.Minute,
+	Run:      createScheduledTransactions,
+}
+
+func createScheduledTransactions() {
+	for {
+		schedules, err := services.TransactionSchedules.GetAllDueSchedules(time.Now().Unix(), pageCountForCreateScheduledTransactions)
+
+		if err != nil {
+			log.Errorf("[transaction_schedule_jobs.createScheduledTransactions] failed to get due transaction schedules, because %s", err.Error())
+			return
+		}
+
+		processedCount := 0
+
+		for i := 0; i < len(schedules); i++ {
+			schedule := schedules[i]
+			transaction, err := services.TransactionSchedules.ExecuteSchedule(schedule)
+
+			if err == nil {
+				log.Infof("[transaction_schedule_jobs.createScheduledTransactions] transaction schedule \"id:%d\" of user \"uid:%d\" has created a new transaction \"id:%d\" successfully", schedule.ScheduleId, schedule.Uid, transaction.TransactionId)
+				processedCount++
+				continue
+			}
+
+			if err == errs.ErrTransactionScheduleHasBeenProcessed {
+				continue
+			}
+
+			if customErr, ok := err.(*errs.Error); !ok || customErr.Category != errs.CATEGORY_NORMAL {
+				log.Errorf("[transaction_schedule_jobs.createScheduledTransactions] failed to create transaction for transaction schedule \"id:%d\" of user \"uid:%d\", because %s", schedule.ScheduleId, schedule.Uid, err.Error())
+				continue
+			}
+
+			// the transaction schedule cannot be executed any more (e.g. account has been hidden), so skip this occurrence to avoid retrying forever
+			log.Warnf("[transaction_schedule_jobs.createScheduledTransactions] skip current occurrence of transaction schedule \"id:%d\" of user \"uid:%d\", because %s", schedule.ScheduleId, schedule.Uid, err.Error())
+			err = services.TransactionSchedules.SkipSchedule(schedule)
+
+			if err != nil {
+				log.Errorf("[transaction_schedule_jobs.createScheduledTransactions] failed to skip current occurrence of transaction schedule \"id:%d\" of user \"uid:%d\", because %s", schedule.ScheduleId, schedule.Uid, err.Error())
+				continue
+			}
+
+			processedCount++
+		}
+
+		if processedCount < 1 {
+			return
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package cron
+
+import (
+	"time"
+
+	"github.com/mayswind/ezbookkeeping/pkg/log"
+	"github.com/mayswind/ezbookkeeping/pkg/services"
+)
+
+const pageCountForPurgeExpiredDeletedTransactions = 100
+
+// PurgeExpiredDeletedTransactionsJob represents the cron job which purges all deleted transactions kept in trash longer than the retention period
+var PurgeExpiredDeletedTransactionsJob = &CronJob{
+	Name:     "PurgeExpiredDeletedTransactions",
+	Interval: time.Hour,
+	Run:      purgeExpiredDeletedTransactions,
+}
+
+func purgeExpiredDeletedTransactions() {
+	for {
+		transactions, err := services.Transactions.GetAllExpiredDeletedTransactions(pageCountForPurgeExpiredDeletedTransactions)
+
+		if err != nil {
+			log.Errorf("[transaction_trash_jobs.purgeExpiredDeletedTransactions] failed to get expired deleted transactions, because %s", err.Error())
+			return
+		}
+
+		purgedCount := 0
+
+		for i := 0; i < len(transactions); i++ {
+			transaction := transactions[i]
+			err = services.Transactions.PurgeTransaction(transaction.Uid, transaction.TransactionId, nil)
+
+			if err != nil {
+				log.Errorf("[transaction_trash_jobs.purgeExpiredDeletedTransactions] failed to purge deleted transaction \"id:%d\" of user \"uid:%d\", because %s", transaction.TransactionId, transaction.Uid, err.Error())
+				continue
+			}
+
+			purgedCount++
+		}
+
+		if purgedCount < 1 {
+			return
+		}
+
+		log.Infof("[transaction_trash_jobs.purgeExpiredDeletedTransactions] %d expired deleted transactions have been purged", purgedCount)
+	}
+}
@@ -1,10 +1,17 @@
 package datastore
 
-import "xorm.io/xorm"
+import (
+	"sync"
+
+	"xorm.io/xorm"
+
+	"github.com/mayswind/ezbookkeeping/pkg/errs"
+)
 
 // Database represents a database instance
 type Database struct {
 	*xorm.EngineGroup
+	fullTextIndexes sync.Map
 }
 
 // DoTransaction runs a new database transaction
@@ -27,3 +34,52 @@ func (db *Database) DoTransaction(fn func(sess *xorm.Session) error) (err error)
 
 	return nil
 }
+
+// GetFullTextIndex returns the full-text index by definition if it has been created in database, or returns nil
+func (db *Database) GetFullTextIndex(definition *FullTextIndexDefinition) FullTextIndex {
+	if value, exists := db.fullTextIndexes.Load(definition.IndexName); exists {
+		index, _ := value.(FullTextIndex)
+		return index
+	}
+
+	index := newFullTextIndex(db, definition)
+
+	if index != nil {
+		exists, err := index.exists(db)
+
+		if err != nil || !exists {
+			index = nil
+		}
+	}
+
+	db.fullTextIndexes.Store(definition.IndexName, index)
+
+	return index
+}
+
+// CreateFullTextIndex creates the full-text index by definition if it does not exist
+func (db *Database) CreateFullTextIndex(definition *FullTextIndexDefinition) error {
+	index := newFullTextIndex(db, definition)
+
+	if index == nil {
+		return errs.ErrDatabaseTypeInvalid
+	}
+
+	exists, err := index.exists(db)
+
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		err = index.create(db)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	db.fullTextIndexes.Delete(definition.IndexName)
+
+	return nil
+}
@@ -41,6 +41,21 @@ func (s *DataStore) SyncStructs(beans ...interface{}) error {
 	return err
 }
 
+// CreateFullTextIndex creates full-text index in all databases by full-text index definition
+func (s *DataStore) CreateFullTextIndex(definition *FullTextIndexDefinition) error {
+	var err error
+
+	for i := 0; i < len(s.databases); i++ {
+		err = s.databases[i].CreateFullTextIndex(definition)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return err
+}
+
 // NewDataStore returns a new data storage by a series of database
 func NewDataStore(databases ...*Database) (*DataStore, error) {
 	if len(databases) < 1 {
@@ -0,0 +1,63 @@
+package datastore
+
+import (
+	"strings"
+
+	"xorm.io/xorm"
+
+	"github.com/mayswind/ezbookkeeping/pkg/settings"
+)
+
+// FullTextIndexDefinition represents the definition of full-text index on a text column of database table
+type FullTextIndexDefinition struct {
+	IndexName  string
+	TableName  string
+	IdColumn   string
+	UidColumn  string
+	TextColumn string
+}
+
+// FullTextIndex represents a full-text index which is implemented by native feature of database
+type FullTextIndex interface {
+	// UpdateDocument saves the text of specified row to full-text index
+	UpdateDocument(sess *xorm.Session, uid int64, id int64, text string) error
+
+	// DeleteDocument deletes the text of specified row from full-text index
+	DeleteDocument(sess *xorm.Session, uid int64, id int64) error
+
+	// DeleteAllDocuments deletes the text of all rows of specified user from full-text index
+	DeleteAllDocuments(sess *xorm.Session, uid int64) error
+
+	// GetMatchCondition returns the condition which can be used in where clause of the indexed table to match the keyword
+	GetMatchCondition(keyword string) (string, []interface{})
+
+	create(db *Database) error
+	exists(db *Database) (bool, error)
+}
+
+var likeKeywordReplacer = strings.NewReplacer("\\", "\\\\", "%", "\\%", "_", "\\_")
+
+// getLikePattern returns the pattern which matches the text containing the keyword in LIKE operator, escaped by backslash
+func getLikePattern(keyword string) string {
+	return "%" + likeKeywordReplacer.Replace(keyword) + "%"
+}
+
+func newFullTextIndex(db *Database, definition *FullTextIndexDefinition) FullTextIndex {
+	switch db.DriverName() {
+	case settings.MySqlDbType:
+		return &mysqlFullTextIndex{
+			definition: definition,
+		}
+	case settings.PostgresDbType:
+		return &postgresFullTextIndex{
+			definition: definition,
+		}
+	case settings.Sqlite3DbType:
+		return &sqlite3FullTextIndex{
+			definition: definition,
+			quote:      db.Quote,
+		}
+	default:
+		return nil
+	}
+}
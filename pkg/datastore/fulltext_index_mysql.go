@@ -0,0 +1,58 @@
+package datastore
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"xorm.io/xorm"
+)
+
+// mysqlNgramTokenSize is the default value of ngram_token_size of MySQL
+const mysqlNgramTokenSize = 2
+
+// mysqlFullTextIndex represents the full-text index which is implemented by FULLTEXT index of MySQL
+type mysqlFullTextIndex struct {
+	definition *FullTextIndexDefinition
+}
+
+// UpdateDocument does nothing, because FULLTEXT index is maintained by MySQL
+func (i *mysqlFullTextIndex) UpdateDocument(sess *xorm.Session, uid int64, id int64, text string) error {
+	return nil
+}
+
+// DeleteDocument does nothing, because FULLTEXT index is maintained by MySQL
+func (i *mysqlFullTextIndex) DeleteDocument(sess *xorm.Session, uid int64, id int64) error {
+	return nil
+}
+
+// DeleteAllDocuments does nothing, because FULLTEXT index is maintained by MySQL
+func (i *mysqlFullTextIndex) DeleteAllDocuments(sess *xorm.Session, uid int64) error {
+	return nil
+}
+
+// GetMatchCondition returns the condition which matches the keyword as a phrase in boolean mode, or uses LIKE operator if the keyword is shorter than the ngram token
+func (i *mysqlFullTextIndex) GetMatchCondition(keyword string) (string, []interface{}) {
+	if utf8.RuneCountInString(keyword) < mysqlNgramTokenSize {
+		return fmt.Sprintf("`%s` LIKE ?", i.definition.TextColumn), []interface{}{getLikePattern(keyword)}
+	}
+
+	phrase := "\"" + strings.ReplaceAll(keyword, "\"", " ") + "\""
+	return fmt.Sprintf("MATCH(`%s`) AGAINST(? IN BOOLEAN MODE)", i.definition.TextColumn), []interface{}{phrase}
+}
+
+func (i *mysqlFullTextIndex) create(db *Database) error {
+	// ngram parser makes the index support CJK characters and partial word matching
+	_, err := db.Exec(fmt.Sprintf("ALTER TABLE `%s` ADD FULLTEXT INDEX `%s` (`%s`) WITH PARSER ngram", i.definition.TableName, i.definition.IndexName, i.definition.TextColumn))
+	return err
+}
+
+func (i *mysqlFullTextIndex) exists(db *Database) (bool, error) {
+	results, err := db.QueryString("SELECT COUNT(*) AS index_count FROM information_schema.statistics WHERE table_schema=DATABASE() AND table_name=? AND index_name=?", i.definition.TableName, i.definition.IndexName)
+
+	if err != nil {
+		return false, err
+	}
+
+	return len(results) > 0 && results[0]["index_count"] != "0", nil
+}
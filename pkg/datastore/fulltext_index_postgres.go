@@ -0,0 +1,63 @@
+package datastore
+
+import (
+	"fmt"
+
+	"xorm.io/xorm"
+)
+
+// postgresFullTextIndex represents the full-text index which is implemented by GIN index with trigram operator class of pg_trgm extension of PostgreSQL
+type postgresFullTextIndex struct {
+	definition *FullTextIndexDefinition
+}
+
+// UpdateDocument does nothing, because expression index is maintained by PostgreSQL
+func (i *postgresFullTextIndex) UpdateDocument(sess *xorm.Session, uid int64, id int64, text string) error {
+	return nil
+}
+
+// DeleteDocument does nothing, because expression index is maintained by PostgreSQL
+func (i *postgresFullTextIndex) DeleteDocument(sess *xorm.Session, uid int64, id int64) error {
+	return nil
+}
+
+// DeleteAllDocuments does nothing, because expression index is maintained by PostgreSQL
+func (i *postgresFullTextIndex) DeleteAllDocuments(sess *xorm.Session, uid int64) error {
+	return nil
+}
+
+// GetMatchCondition returns the condition which matches the text containing the keyword, trigram index would be used when the keyword has at least 3 characters
+func (i *postgresFullTextIndex) GetMatchCondition(keyword string) (string, []interface{}) {
+	return fmt.Sprintf("\"%s\" ILIKE ?", i.definition.TextColumn), []interface{}{getLikePattern(keyword)}
+}
+
+func (i *postgresFullTextIndex) create(db *Database) error {
+	return db.DoTransaction(func(sess *xorm.Session) error {
+		// trigram index supports CJK characters and partial word matching
+		_, err := sess.Exec("CREATE EXTENSION IF NOT EXISTS pg_trgm")
+
+		if err != nil {
+			return err
+		}
+
+		// an index with the same name but without trigram operator class may exist
+		_, err = sess.Exec(fmt.Sprintf("DROP INDEX IF EXISTS \"%s\"", i.definition.IndexName))
+
+		if err != nil {
+			return err
+		}
+
+		_, err = sess.Exec(fmt.Sprintf("CREATE INDEX \"%s\" ON \"%s\" USING GIN (\"%s\" gin_trgm_ops)", i.definition.IndexName, i.definition.TableName, i.definition.TextColumn))
+		return err
+	})
+}
+
+func (i *postgresFullTextIndex) exists(db *Database) (bool, error) {
+	results, err := db.QueryString("SELECT COUNT(*) AS index_count FROM pg_indexes WHERE tablename=? AND indexname=? AND indexdef LIKE '%gin_trgm_ops%'", i.definition.TableName, i.definition.IndexName)
+
+	if err != nil {
+		return false, err
+	}
+
+	return len(results) > 0 && results[0]["index_count"] != "0", nil
+}
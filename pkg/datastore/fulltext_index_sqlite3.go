@@ -0,0 +1,152 @@
+package datastore
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"xorm.io/xorm"
+)
+
+// sqlite3TrigramLength is the character count of each token saved in FTS5 virtual table
+const sqlite3TrigramLength = 3
+
+// sqlite3FullTextIndex represents the full-text index which is implemented by FTS5 virtual table of SQLite,
+// the binary must be built with "sqlite_fts5" tag to enable FTS5 extension.
+// The bundled SQLite does not have trigram tokenizer, so the text is saved as hex encoded trigrams to support CJK characters and partial word matching
+type sqlite3FullTextIndex struct {
+	definition *FullTextIndexDefinition
+	quote      func(string) string
+}
+
+// UpdateDocument saves the text of specified row to FTS5 virtual table
+func (i *sqlite3FullTextIndex) UpdateDocument(sess *xorm.Session, uid int64, id int64, text string) error {
+	_, err := sess.Exec(fmt.Sprintf("DELETE FROM %s WHERE rowid=?", i.quote(i.definition.IndexName)), id)
+
+	if err != nil {
+		return err
+	}
+
+	return i.insertDocument(sess, uid, id, text)
+}
+
+// DeleteDocument deletes the text of specified row from FTS5 virtual table
+func (i *sqlite3FullTextIndex) DeleteDocument(sess *xorm.Session, uid int64, id int64) error {
+	_, err := sess.Exec(fmt.Sprintf("DELETE FROM %s WHERE rowid=?", i.quote(i.definition.IndexName)), id)
+	return err
+}
+
+// DeleteAllDocuments deletes the text of all rows of specified user from FTS5 virtual table
+func (i *sqlite3FullTextIndex) DeleteAllDocuments(sess *xorm.Session, uid int64) error {
+	_, err := sess.Exec(fmt.Sprintf("DELETE FROM %s WHERE %s=?", i.quote(i.definition.IndexName), i.quote(i.definition.UidColumn)), uid)
+	return err
+}
+
+// GetMatchCondition returns the condition which matches the trigrams of the keyword as a phrase, or uses LIKE operator if the keyword is shorter than a trigram
+func (i *sqlite3FullTextIndex) GetMatchCondition(keyword string) (string, []interface{}) {
+	if utf8.RuneCountInString(keyword) < sqlite3TrigramLength {
+		return fmt.Sprintf("%s LIKE ? ESCAPE '\\'", i.quote(i.definition.TextColumn)), []interface{}{getLikePattern(keyword)}
+	}
+
+	phrase := "\"" + getSqlite3TrigramTokens(keyword) + "\""
+	return fmt.Sprintf("%s IN (SELECT rowid FROM %s WHERE %s MATCH ?)", i.quote(i.definition.IdColumn), i.quote(i.definition.IndexName), i.quote(i.definition.IndexName)), []interface{}{phrase}
+}
+
+func (i *sqlite3FullTextIndex) create(db *Database) error {
+	return db.DoTransaction(func(sess *xorm.Session) error {
+		// a virtual table with the same name which saves the original text may exist
+		_, err := sess.Exec(fmt.Sprintf("DROP TABLE IF EXISTS %s", i.quote(i.definition.IndexName)))
+
+		if err != nil {
+			return err
+		}
+
+		_, err = sess.Exec(fmt.Sprintf("CREATE VIRTUAL TABLE %s USING fts5(%s, %s UNINDEXED, tokenize='ascii')", i.quote(i.definition.IndexName), i.quote(i.definition.TextColumn), i.quote(i.definition.UidColumn)))
+
+		if err != nil {
+			return err
+		}
+
+		rows, err := sess.QueryString(fmt.Sprintf("SELECT %s AS id, %s AS uid, %s AS text FROM %s WHERE %s<>''",
+			i.quote(i.definition.IdColumn), i.quote(i.definition.UidColumn), i.quote(i.definition.TextColumn),
+			i.quote(i.definition.TableName), i.quote(i.definition.TextColumn)))
+
+		if err != nil {
+			return err
+		}
+
+		for j := 0; j < len(rows); j++ {
+			id, err := strconv.ParseInt(rows[j]["id"], 10, 64)
+
+			if err != nil {
+				return err
+			}
+
+			uid, err := strconv.ParseInt(rows[j]["uid"], 10, 64)
+
+			if err != nil {
+				return err
+			}
+
+			err = i.insertDocument(sess, uid, id, rows[j]["text"])
+
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (i *sqlite3FullTextIndex) exists(db *Database) (bool, error) {
+	results, err := db.QueryString("SELECT COUNT(*) AS index_count FROM sqlite_master WHERE type='table' AND name=? AND sql LIKE '%tokenize%'", i.definition.IndexName)
+
+	if err != nil {
+		return false, err
+	}
+
+	if len(results) < 1 || results[0]["index_count"] == "0" {
+		return false, nil
+	}
+
+	// querying the virtual table fails if current binary is built without FTS5 extension
+	_, err = db.QueryString(fmt.Sprintf("SELECT rowid FROM %s LIMIT 0", i.quote(i.definition.IndexName)))
+
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (i *sqlite3FullTextIndex) insertDocument(sess *xorm.Session, uid int64, id int64, text string) error {
+	tokens := getSqlite3TrigramTokens(text)
+
+	if tokens == "" {
+		return nil
+	}
+
+	_, err := sess.Exec(fmt.Sprintf("INSERT INTO %s (rowid, %s, %s) VALUES (?, ?, ?)", i.quote(i.definition.IndexName), i.quote(i.definition.TextColumn), i.quote(i.definition.UidColumn)), id, tokens, uid)
+
+	return err
+}
+
+// getSqlite3TrigramTokens returns all trigrams of the lowercase text in order, each trigram is encoded in hex so that it is a single token of ascii tokenizer
+func getSqlite3TrigramTokens(text string) string {
+	runes := []rune(strings.ToLower(text))
+
+	if len(runes) < sqlite3TrigramLength {
+		return ""
+	}
+
+	tokens := make([]string, 0, len(runes)-sqlite3TrigramLength+1)
+
+	for i := 0; i+sqlite3TrigramLength <= len(runes); i++ {
+		tokens = append(tokens, hex.EncodeToString([]byte(string(runes[i:i+sqlite3TrigramLength]))))
+	}
+
+	return strings.Join(tokens, " ")
+}
@@ -4,18 +4,37 @@ import "net/http"
 
 // Error codes related to accounts
 var (
-	ErrAccountIdInvalid                       = NewNormalError(NormalSubcategoryAccount, 0, http.StatusBadRequest, "account id is invalid")
-	ErrAccountNotFound                        = NewNormalError(NormalSubcategoryAccount, 1, http.StatusBadRequest, "account not found")
-	ErrAccountTypeInvalid                     = NewNormalError(NormalSubcategoryAccount, 2, http.StatusBadRequest, "account type is invalid")
-	ErrAccountCurrencyInvalid                 = NewNormalError(NormalSubcategoryAccount, 3, http.StatusBadRequest, "account currency is invalid")
-	ErrAccountHaveNoSubAccount                = NewNormalError(NormalSubcategoryAccount, 4, http.StatusBadRequest, "account must have at least one sub account")
-	ErrAccountCannotHaveSubAccounts           = NewNormalError(NormalSubcategoryAccount, 5, http.StatusBadRequest, "account cannot have sub accounts")
-	ErrParentAccountCannotSetCurrency         = NewNormalError(NormalSubcategoryAccount, 6, http.StatusBadRequest, "parent account cannot set currency")
-	ErrParentAccountCannotSetBalance          = NewNormalError(NormalSubcategoryAccount, 7, http.StatusBadRequest, "parent account cannot set balance")
-	ErrSubAccountCategoryNotEqualsToParent    = NewNormalError(NormalSubcategoryAccount, 8, http.StatusBadRequest, "sub account category not equals to parent")
-	ErrSubAccountTypeInvalid                  = NewNormalError(NormalSubcategoryAccount, 9, http.StatusBadRequest, "sub account type invalid")
-	ErrCannotAddOrDeleteSubAccountsWhenModify = NewNormalError(NormalSubcategoryAccount, 10, http.StatusBadRequest, "cannot add or delete sub accounts when modify account")
-	ErrSourceAccountNotFound                  = NewNormalError(NormalSubcategoryAccount, 11, http.StatusBadRequest, "source account not found")
-	ErrDestinationAccountNotFound             = NewNormalError(NormalSubcategoryAccount, 12, http.StatusBadRequest, "destination account not found")
-	ErrAccountInUseCannotBeDeleted            = NewNormalError(NormalSubcategoryAccount, 13, http.StatusBadRequest, "account is in use and cannot be deleted")
+	ErrAccountIdInvalid                           = NewNormalError(NormalSubcategoryAccount, 0, http.StatusBadRequest, "account id is invalid")
+	ErrAccountNotFound                            = NewNormalError(NormalSubcategoryAccount, 1, http.StatusBadRequest, "account not found")
+	ErrAccountTypeInvalid                         = NewNormalError(NormalSubcategoryAccount, 2, http.StatusBadRequest, "account type is invalid")
+	ErrAccountCurrencyInvalid                     = NewNormalError(NormalSubcategoryAccount, 3, http.StatusBadRequest, "account currency is invalid")
+	ErrAccountHaveNoSubAccount                    = NewNormalError(NormalSubcategoryAccount, 4, http.StatusBadRequest, "account must have at least one sub account")
+	ErrAccountCannotHaveSubAccounts               = NewNormalError(NormalSubcategoryAccount, 5, http.StatusBadRequest, "account cannot have sub accounts")
+	ErrParentAccountCannotSetCurrency             = NewNormalError(NormalSubcategoryAccount, 6, http.StatusBadRequest, "parent account cannot set currency")
+	ErrParentAccountCannotSetBalance              = NewNormalError(NormalSubcategoryAccount, 7, http.StatusBadRequest, "parent account cannot set balance")
+	ErrSubAccountCategoryNotEqualsToParent        = NewNormalError(NormalSubcategoryAccount, 8, http.StatusBadRequest, "sub account category not equals to parent")
+	ErrSubAccountTypeInvalid                      = NewNormalError(NormalSubcategoryAccount, 9, http.StatusBadRequest, "sub account type invalid")
+	ErrCannotAddOrDeleteSubAccountsWhenModify     = NewNormalError(NormalSubcategoryAccount, 10, http.StatusBadRequest, "cannot add or delete sub accounts when modify account")
+	ErrSourceAccountNotFound                      = NewNormalError(NormalSubcategoryAccount, 11, http.StatusBadRequest, "source account not found")
+	ErrDestinationAccountNotFound                 = NewNormalError(NormalSubcategoryAccount, 12, http.StatusBadRequest, "destination account not found")
+	ErrAccountInUseCannotBeDeleted                = NewNormalError(NormalSubcategoryAccount, 13, http.StatusBadRequest, "account is in use and cannot be deleted")
+	ErrCannotReconcileParentAccount               = NewNormalError(NormalSubcategoryAccount, 14, http.StatusBadRequest, "parent account cannot be reconciled")
+	ErrAccountReconcileBalanceNotMatched          = NewNormalError(NormalSubcategoryAccount, 15, http.StatusBadRequest, "statement ending balance does not match cleared balance")
+	ErrCannotMergeAccountIntoItself               = NewNormalError(NormalSubcategoryAccount, 16, http.StatusBadRequest, "cannot merge account into itself")
+	ErrCannotMergeParentAccount                   = NewNormalError(NormalSubcategoryAccount, 17, http.StatusBadRequest, "parent account cannot be merged")
+	ErrCannotMergeAccountsWithDifferentCurrency   = NewNormalError(NormalSubcategoryAccount, 18, http.StatusBadRequest, "cannot merge accounts with different currencies")
+	ErrCannotCloseParentAccount                   = NewNormalError(NormalSubcategoryAccount, 19, http.StatusBadRequest, "parent account cannot be closed")
+	ErrAccountCloseTransferAccountRequired        = NewNormalError(NormalSubcategoryAccount, 20, http.StatusBadRequest, "account to receive remaining balance is required")
+	ErrAccountCloseTransferAmountRequired         = NewNormalError(NormalSubcategoryAccount, 21, http.StatusBadRequest, "amount of account to receive remaining balance is required")
+	ErrAccountCannotSetCreditCardSettings         = NewNormalError(NormalSubcategoryAccount, 22, http.StatusBadRequest, "account cannot set credit card settings")
+	ErrAccountStatementDayNotSet                  = NewNormalError(NormalSubcategoryAccount, 23, http.StatusBadRequest, "account statement day is not set")
+	ErrTooManyAccountBalanceTrendsDataPoints      = NewNormalError(NormalSubcategoryAccount, 24, http.StatusBadRequest, "too many data points of account balance trends")
+	ErrAccountCurrencyExchangeRateNotFound        = NewNormalError(NormalSubcategoryAccount, 25, http.StatusBadRequest, "exchange rate of account currency is not found")
+	ErrAccountCannotSetLoanSettings               = NewNormalError(NormalSubcategoryAccount, 26, http.StatusBadRequest, "account cannot set loan settings")
+	ErrAccountLoanSettingsInvalid                 = NewNormalError(NormalSubcategoryAccount, 27, http.StatusBadRequest, "account loan settings are invalid")
+	ErrAccountLoanSettingsNotSet                  = NewNormalError(NormalSubcategoryAccount, 28, http.StatusBadRequest, "account loan settings are not set")
+	ErrAccountLoanPaymentAmountInvalid            = NewNormalError(NormalSubcategoryAccount, 29, http.StatusBadRequest, "loan payment amount is invalid")
+	ErrAccountLoanPaymentCurrencyNotEqual         = NewNormalError(NormalSubcategoryAccount, 30, http.StatusBadRequest, "currency of payment account is not equal to loan account")
+	ErrCannotMergeAccountsWithBalanceModification = NewNormalError(NormalSubcategoryAccount, 31, http.StatusBadRequest, "cannot merge accounts which both have balance modification transaction")
+	ErrCannotCloseAccountWithPendingTransactions  = NewNormalError(NormalSubcategoryAccount, 32, http.StatusBadRequest, "account with pending transactions cannot be closed")
 )
@@ -0,0 +1,11 @@
+package errs
+
+import "net/http"
+
+// Error codes related to budgets
+var (
+	ErrBudgetIdInvalid           = NewNormalError(NormalSubcategoryBudget, 0, http.StatusBadRequest, "budget id is invalid")
+	ErrBudgetNotFound            = NewNormalError(NormalSubcategoryBudget, 1, http.StatusBadRequest, "budget not found")
+	ErrBudgetAlreadyExists       = NewNormalError(NormalSubcategoryBudget, 2, http.StatusBadRequest, "budget of this category in this month already exists")
+	ErrBudgetCategoryTypeInvalid = NewNormalError(NormalSubcategoryBudget, 3, http.StatusBadRequest, "budget category must be expense category")
+)
@@ -14,6 +14,7 @@ const (
 	SystemSubcategoryDefault  = 0
 	SystemSubcategorySetting  = 1
 	SystemSubcategoryDatabase = 2
+	SystemSubcategoryStorage  = 3
 )
 
 // Sub categories of normal error
@@ -27,6 +28,14 @@ const (
 	NormalSubcategoryCategory       = 6
 	NormalSubcategoryTag            = 7
 	NormalSubcategoryDataManagement = 8
+	NormalSubcategorySchedule       = 9
+	NormalSubcategoryTemplate       = 10
+	NormalSubcategoryAttachment     = 11
+	NormalSubcategoryPayee          = 12
+	NormalSubcategoryRule           = 13
+	NormalSubcategoryInvestment     = 14
+	NormalSubcategoryBudget         = 15
+	NormalSubcategorySavingsGoal    = 16
 )
 
 // Error represents the specific error returned to user
@@ -0,0 +1,24 @@
+package errs
+
+import "net/http"
+
+// Error codes related to investments
+var (
+	ErrInvestmentSecurityIdInvalid                  = NewNormalError(NormalSubcategoryInvestment, 0, http.StatusBadRequest, "investment security id is invalid")
+	ErrInvestmentSecurityNotFound                   = NewNormalError(NormalSubcategoryInvestment, 1, http.StatusBadRequest, "investment security not found")
+	ErrInvestmentSecuritySymbolAlreadyExists        = NewNormalError(NormalSubcategoryInvestment, 2, http.StatusBadRequest, "investment security symbol already exists")
+	ErrInvestmentSecurityInUseCannotBeDeleted       = NewNormalError(NormalSubcategoryInvestment, 3, http.StatusBadRequest, "investment security is in use and cannot be deleted")
+	ErrInvestmentSecurityCurrencyCannotBeModified   = NewNormalError(NormalSubcategoryInvestment, 4, http.StatusBadRequest, "currency of investment security in use cannot be modified")
+	ErrInvestmentSecurityPriceIdInvalid             = NewNormalError(NormalSubcategoryInvestment, 5, http.StatusBadRequest, "investment security price id is invalid")
+	ErrInvestmentSecurityPriceNotFound              = NewNormalError(NormalSubcategoryInvestment, 6, http.StatusBadRequest, "investment security price not found")
+	ErrInvestmentSecurityPriceFileIsEmpty           = NewNormalError(NormalSubcategoryInvestment, 7, http.StatusBadRequest, "investment security price file is empty")
+	ErrInvestmentSecurityPriceFileInvalid           = NewNormalError(NormalSubcategoryInvestment, 8, http.StatusBadRequest, "investment security price file is invalid")
+	ErrInvestmentSecurityPriceDataSourceNotSet      = NewNormalError(NormalSubcategoryInvestment, 9, http.StatusBadRequest, "investment security price data source is not set")
+	ErrInvestmentTransactionIdInvalid               = NewNormalError(NormalSubcategoryInvestment, 10, http.StatusBadRequest, "investment transaction id is invalid")
+	ErrInvestmentTransactionNotFound                = NewNormalError(NormalSubcategoryInvestment, 11, http.StatusBadRequest, "investment transaction not found")
+	ErrInvestmentTransactionTypeInvalid             = NewNormalError(NormalSubcategoryInvestment, 12, http.StatusBadRequest, "investment transaction type is invalid")
+	ErrInvestmentTransactionQuantityInvalid         = NewNormalError(NormalSubcategoryInvestment, 13, http.StatusBadRequest, "investment transaction quantity is invalid")
+	ErrInvestmentAccountInvalid                     = NewNormalError(NormalSubcategoryInvestment, 14, http.StatusBadRequest, "account is not an investment account")
+	ErrInvestmentSecurityCurrencyNotEqualsToAccount = NewNormalError(NormalSubcategoryInvestment, 15, http.StatusBadRequest, "currency of investment security does not equal to account")
+	ErrInvestmentSellQuantityExceedsHolding         = NewNormalError(NormalSubcategoryInvestment, 16, http.StatusBadRequest, "sell quantity exceeds holding quantity")
+)
@@ -0,0 +1,11 @@
+package errs
+
+import "net/http"
+
+// Error codes related to savings goals
+var (
+	ErrSavingsGoalIdInvalid        = NewNormalError(NormalSubcategorySavingsGoal, 0, http.StatusBadRequest, "savings goal id is invalid")
+	ErrSavingsGoalNotFound         = NewNormalError(NormalSubcategorySavingsGoal, 1, http.StatusBadRequest, "savings goal not found")
+	ErrSavingsGoalAccountIdInvalid = NewNormalError(NormalSubcategorySavingsGoal, 2, http.StatusBadRequest, "savings goal account id is invalid")
+	ErrSavingsGoalAccountInvalid   = NewNormalError(NormalSubcategorySavingsGoal, 3, http.StatusBadRequest, "savings goal can only be linked to asset account")
+)
@@ -0,0 +1,11 @@
+package errs
+
+import (
+	"net/http"
+)
+
+// Error codes related to object storage
+var (
+	ErrObjectStoragePathInvalid     = NewSystemError(SystemSubcategoryStorage, 0, http.StatusInternalServerError, "object storage path is invalid")
+	ErrObjectStorageOperationFailed = NewSystemError(SystemSubcategoryStorage, 1, http.StatusInternalServerError, "object storage operation failed")
+)
@@ -21,4 +21,17 @@ var (
 	ErrCannotCreateTransactionWithThisTransactionTime      = NewNormalError(NormalSubcategoryTransaction, 14, http.StatusBadRequest, "cannot add transaction with this transaction time")
 	ErrCannotModifyTransactionWithThisTransactionTime      = NewNormalError(NormalSubcategoryTransaction, 15, http.StatusBadRequest, "cannot modify transaction with this transaction time")
 	ErrCannotDeleteTransactionWithThisTransactionTime      = NewNormalError(NormalSubcategoryTransaction, 16, http.StatusBadRequest, "cannot delete transaction with this transaction time")
+	ErrTransactionCannotBeSplit                            = NewNormalError(NormalSubcategoryTransaction, 17, http.StatusBadRequest, "only income or expense transaction can be split")
+	ErrTransactionSplitsTooFew                             = NewNormalError(NormalSubcategoryTransaction, 18, http.StatusBadRequest, "split transaction must have at least two splits")
+	ErrTransactionSplitsAmountNotEqual                     = NewNormalError(NormalSubcategoryTransaction, 19, http.StatusBadRequest, "total amount of splits must equal transaction amount")
+	ErrCannotRestoreTransactionInHiddenAccount             = NewNormalError(NormalSubcategoryTransaction, 20, http.StatusBadRequest, "cannot restore transaction in hidden account")
+	ErrCannotRestoreTransactionWithThisTransactionTime     = NewNormalError(NormalSubcategoryTransaction, 21, http.StatusBadRequest, "cannot restore transaction with this transaction time")
+	ErrCannotModifyReconciledTransaction                   = NewNormalError(NormalSubcategoryTransaction, 22, http.StatusBadRequest, "cannot modify reconciled transaction")
+	ErrCannotDeleteReconciledTransaction                   = NewNormalError(NormalSubcategoryTransaction, 23, http.StatusBadRequest, "cannot delete reconciled transaction")
+	ErrTransactionSearchQueryInvalid                       = NewNormalError(NormalSubcategoryTransaction, 24, http.StatusBadRequest, "transaction search query is invalid")
+	ErrTransactionCannotHaveOriginalAmount                 = NewNormalError(NormalSubcategoryTransaction, 25, http.StatusBadRequest, "only income or expense transaction can have original amount")
+	ErrTransactionOriginalAmountInvalid                    = NewNormalError(NormalSubcategoryTransaction, 26, http.StatusBadRequest, "transaction original amount is invalid")
+	ErrTransactionExchangeRateInvalid                      = NewNormalError(NormalSubcategoryTransaction, 27, http.StatusBadRequest, "transaction exchange rate is invalid")
+	ErrTransactionCannotHavePayee                          = NewNormalError(NormalSubcategoryTransaction, 28, http.StatusBadRequest, "only income or expense transaction can have payee")
+	ErrTransactionSplitsConflict                           = NewNormalError(NormalSubcategoryTransaction, 29, http.StatusBadRequest, "cannot clear splits and set new splits at the same time")
 )
@@ -0,0 +1,13 @@
+package errs
+
+import "net/http"
+
+// Error codes related to transaction payees
+var (
+	ErrTransactionPayeeIdInvalid             = NewNormalError(NormalSubcategoryPayee, 0, http.StatusBadRequest, "transaction payee id is invalid")
+	ErrTransactionPayeeNotFound              = NewNormalError(NormalSubcategoryPayee, 1, http.StatusBadRequest, "transaction payee not found")
+	ErrTransactionPayeeNameIsEmpty           = NewNormalError(NormalSubcategoryPayee, 2, http.StatusBadRequest, "transaction payee name is empty")
+	ErrTransactionPayeeNameAlreadyExists     = NewNormalError(NormalSubcategoryPayee, 3, http.StatusBadRequest, "transaction payee name already exists")
+	ErrTransactionPayeeInUseCannotBeDeleted  = NewNormalError(NormalSubcategoryPayee, 4, http.StatusBadRequest, "transaction payee is in use and cannot be deleted")
+	ErrCannotMergeTransactionPayeeIntoItself = NewNormalError(NormalSubcategoryPayee, 5, http.StatusBadRequest, "cannot merge transaction payee into itself")
+)
@@ -0,0 +1,14 @@
+package errs
+
+import "net/http"
+
+// Error codes related to transaction rules
+var (
+	ErrTransactionRuleIdInvalid                  = NewNormalError(NormalSubcategoryRule, 0, http.StatusBadRequest, "transaction rule id is invalid")
+	ErrTransactionRuleNotFound                   = NewNormalError(NormalSubcategoryRule, 1, http.StatusBadRequest, "transaction rule not found")
+	ErrTransactionRuleNoCondition                = NewNormalError(NormalSubcategoryRule, 2, http.StatusBadRequest, "transaction rule must have at least one condition")
+	ErrTransactionRuleNoAction                   = NewNormalError(NormalSubcategoryRule, 3, http.StatusBadRequest, "transaction rule must have at least one action")
+	ErrTransactionRuleCategoryActionRequiresType = NewNormalError(NormalSubcategoryRule, 4, http.StatusBadRequest, "transaction rule must have transaction type condition to set category")
+	ErrTransactionRuleAmountRangeInvalid         = NewNormalError(NormalSubcategoryRule, 5, http.StatusBadRequest, "transaction rule amount range is invalid")
+	ErrTransactionRuleTypeInvalid                = NewNormalError(NormalSubcategoryRule, 6, http.StatusBadRequest, "transaction rule transaction type is invalid")
+)
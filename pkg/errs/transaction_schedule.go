@@ -0,0 +1,13 @@
+package errs
+
+import "net/http"
+
+// Error codes related to transaction schedules
+var (
+	ErrTransactionScheduleIdInvalid                    = NewNormalError(NormalSubcategorySchedule, 0, http.StatusBadRequest, "transaction schedule id is invalid")
+	ErrTransactionScheduleNotFound                     = NewNormalError(NormalSubcategorySchedule, 1, http.StatusBadRequest, "transaction schedule not found")
+	ErrTransactionScheduleFrequencyInvalid             = NewNormalError(NormalSubcategorySchedule, 2, http.StatusBadRequest, "transaction schedule frequency is invalid")
+	ErrTransactionScheduleEndTimeInvalid               = NewNormalError(NormalSubcategorySchedule, 3, http.StatusBadRequest, "transaction schedule end time must be later than start time")
+	ErrBalanceModificationTransactionCannotBeScheduled = NewNormalError(NormalSubcategorySchedule, 4, http.StatusBadRequest, "balance modification transaction cannot be scheduled")
+	ErrTransactionScheduleHasBeenProcessed             = NewNormalError(NormalSubcategorySchedule, 5, http.StatusBadRequest, "transaction schedule has been processed")
+)
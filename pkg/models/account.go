@@ -1,5 +1,12 @@
 package models
 
+import (
+	"math"
+	"time"
+
+	"github.com/mayswind/ezbookkeeping/pkg/utils"
+)
+
 // LevelOneAccountParentId represents the parent id of level-one account
 const LevelOneAccountParentId = 0
 
@@ -37,6 +44,49 @@ var liabilityAccountCategory = map[AccountCategory]bool{
 	ACCOUNT_CATEGORY_INVESTMENT:  false,
 }
 
+// CreditCardMinimumPaymentPercent represents the percentage of statement balance which should be paid at least
+const CreditCardMinimumPaymentPercent = 10
+
+// CreditCardMinimumPaymentAmount represents the minimum amount which should be paid if statement balance is not zero
+const CreditCardMinimumPaymentAmount = 100
+
+// AccountLoanPaymentFrequency represents the payment frequency of loan account
+type AccountLoanPaymentFrequency byte
+
+// Account loan payment frequencies
+const (
+	ACCOUNT_LOAN_PAYMENT_FREQUENCY_NONE      AccountLoanPaymentFrequency = 0
+	ACCOUNT_LOAN_PAYMENT_FREQUENCY_WEEKLY    AccountLoanPaymentFrequency = 1
+	ACCOUNT_LOAN_PAYMENT_FREQUENCY_BIWEEKLY  AccountLoanPaymentFrequency = 2
+	ACCOUNT_LOAN_PAYMENT_FREQUENCY_MONTHLY   AccountLoanPaymentFrequency = 3
+	ACCOUNT_LOAN_PAYMENT_FREQUENCY_QUARTERLY AccountLoanPaymentFrequency = 4
+	ACCOUNT_LOAN_PAYMENT_FREQUENCY_YEARLY    AccountLoanPaymentFrequency = 5
+)
+
+var accountLoanPaymentCountPerYear = map[AccountLoanPaymentFrequency]int{
+	ACCOUNT_LOAN_PAYMENT_FREQUENCY_WEEKLY:    52,
+	ACCOUNT_LOAN_PAYMENT_FREQUENCY_BIWEEKLY:  26,
+	ACCOUNT_LOAN_PAYMENT_FREQUENCY_MONTHLY:   12,
+	ACCOUNT_LOAN_PAYMENT_FREQUENCY_QUARTERLY: 4,
+	ACCOUNT_LOAN_PAYMENT_FREQUENCY_YEARLY:    1,
+}
+
+// AccountLoanInterestRatePrecision represents the multiplier of annual interest rate percentage stored in database (e.g. 5.25% is stored as 52500)
+const AccountLoanInterestRatePrecision = 10000
+
+// AccountBalanceTrendsIntervalType represents the interval type of account balance trends
+type AccountBalanceTrendsIntervalType byte
+
+// Account balance trends interval types
+const (
+	ACCOUNT_BALANCE_TRENDS_INTERVAL_DAY   AccountBalanceTrendsIntervalType = 1
+	ACCOUNT_BALANCE_TRENDS_INTERVAL_WEEK  AccountBalanceTrendsIntervalType = 2
+	ACCOUNT_BALANCE_TRENDS_INTERVAL_MONTH AccountBalanceTrendsIntervalType = 3
+)
+
+// MaxAccountBalanceTrendsDataPointCount represents the maximum count of data points in account balance trends
+const MaxAccountBalanceTrendsDataPointCount = 1000
+
 // AccountType represents account type
 type AccountType byte
 
@@ -48,48 +98,62 @@ const (
 
 // Account represents account data stored in database
 type Account struct {
-	AccountId       int64           `xorm:"PK"`
-	Uid             int64           `xorm:"INDEX(IDX_account_uid_deleted_parent_account_id_order) NOT NULL"`
-	Deleted         bool            `xorm:"INDEX(IDX_account_uid_deleted_parent_account_id_order) NOT NULL"`
-	Category        AccountCategory `xorm:"NOT NULL"`
-	Type            AccountType     `xorm:"NOT NULL"`
-	ParentAccountId int64           `xorm:"INDEX(IDX_account_uid_deleted_parent_account_id_order) NOT NULL"`
-	Name            string          `xorm:"VARCHAR(32) NOT NULL"`
-	DisplayOrder    int             `xorm:"INDEX(IDX_account_uid_deleted_parent_account_id_order) NOT NULL"`
-	Icon            int64           `xorm:"NOT NULL"`
-	Color           string          `xorm:"VARCHAR(6) NOT NULL"`
-	Currency        string          `xorm:"VARCHAR(3) NOT NULL"`
-	Balance         int64           `xorm:"NOT NULL"`
-	Comment         string          `xorm:"VARCHAR(255) NOT NULL"`
-	Hidden          bool            `xorm:"NOT NULL"`
-	CreatedUnixTime int64
-	UpdatedUnixTime int64
-	DeletedUnixTime int64
+	AccountId              int64                       `xorm:"PK"`
+	Uid                    int64                       `xorm:"INDEX(IDX_account_uid_deleted_parent_account_id_order) NOT NULL"`
+	Deleted                bool                        `xorm:"INDEX(IDX_account_uid_deleted_parent_account_id_order) NOT NULL"`
+	Category               AccountCategory             `xorm:"NOT NULL"`
+	Type                   AccountType                 `xorm:"NOT NULL"`
+	ParentAccountId        int64                       `xorm:"INDEX(IDX_account_uid_deleted_parent_account_id_order) NOT NULL"`
+	Name                   string                      `xorm:"VARCHAR(32) NOT NULL"`
+	DisplayOrder           int                         `xorm:"INDEX(IDX_account_uid_deleted_parent_account_id_order) NOT NULL"`
+	Icon                   int64                       `xorm:"NOT NULL"`
+	Color                  string                      `xorm:"VARCHAR(6) NOT NULL"`
+	Currency               string                      `xorm:"VARCHAR(3) NOT NULL"`
+	Balance                int64                       `xorm:"NOT NULL"`
+	Comment                string                      `xorm:"VARCHAR(255) NOT NULL"`
+	Hidden                 bool                        `xorm:"NOT NULL"`
+	StatementDay           int                         `xorm:"NOT NULL DEFAULT 0"`
+	PaymentDueDay          int                         `xorm:"NOT NULL DEFAULT 0"`
+	CreditLimit            int64                       `xorm:"NOT NULL DEFAULT 0"`
+	LoanPrincipal          int64                       `xorm:"NOT NULL DEFAULT 0"`
+	LoanAnnualInterestRate int64                       `xorm:"NOT NULL DEFAULT 0"`
+	LoanTermCount          int                         `xorm:"NOT NULL DEFAULT 0"`
+	LoanStartUnixTime      int64                       `xorm:"NOT NULL DEFAULT 0"`
+	LoanPaymentFrequency   AccountLoanPaymentFrequency `xorm:"TINYINT NOT NULL DEFAULT 0"`
+	CreatedUnixTime        int64
+	UpdatedUnixTime        int64
+	DeletedUnixTime        int64
 }
 
 // AccountCreateRequest represents all parameters of account creation request
 type AccountCreateRequest struct {
-	Name        string                  `json:"name" binding:"required,notBlank,max=32"`
-	Category    AccountCategory         `json:"category" binding:"required"`
-	Type        AccountType             `json:"type" binding:"required"`
-	Icon        int64                   `json:"icon,string" binding:"required,min=1"`
-	Color       string                  `json:"color" binding:"required,len=6,validHexRGBColor"`
-	Currency    string                  `json:"currency" binding:"required,len=3,validCurrency"`
-	Balance     int64                   `json:"balance"`
-	Comment     string                  `json:"comment" binding:"max=255"`
-	SubAccounts []*AccountCreateRequest `json:"subAccounts" binding:"omitempty"`
+	Name          string                  `json:"name" binding:"required,notBlank,max=32"`
+	Category      AccountCategory         `json:"category" binding:"required"`
+	Type          AccountType             `json:"type" binding:"required"`
+	Icon          int64                   `json:"icon,string" binding:"required,min=1"`
+	Color         string                  `json:"color" binding:"required,len=6,validHexRGBColor"`
+	Currency      string                  `json:"currency" binding:"required,len=3,validCurrency"`
+	Balance       int64                   `json:"balance"`
+	Comment       string                  `json:"comment" binding:"max=255"`
+	StatementDay  int                     `json:"statementDay" binding:"min=0,max=31"`
+	PaymentDueDay int                     `json:"paymentDueDay" binding:"min=0,max=31"`
+	CreditLimit   int64                   `json:"creditLimit" binding:"min=0,max=99999999999"`
+	SubAccounts   []*AccountCreateRequest `json:"subAccounts" binding:"omitempty"`
 }
 
 // AccountModifyRequest represents all parameters of account modification request
 type AccountModifyRequest struct {
-	Id          int64                   `json:"id,string" binding:"required,min=1"`
-	Name        string                  `json:"name" binding:"required,notBlank,max=32"`
-	Category    AccountCategory         `json:"category" binding:"required"`
-	Icon        int64                   `json:"icon,string" binding:"min=1"`
-	Color       string                  `json:"color" binding:"required,len=6,validHexRGBColor"`
-	Comment     string                  `json:"comment" binding:"max=255"`
-	Hidden      bool                    `json:"hidden"`
-	SubAccounts []*AccountModifyRequest `json:"subAccounts" binding:"omitempty"`
+	Id            int64                   `json:"id,string" binding:"required,min=1"`
+	Name          string                  `json:"name" binding:"required,notBlank,max=32"`
+	Category      AccountCategory         `json:"category" binding:"required"`
+	Icon          int64                   `json:"icon,string" binding:"min=1"`
+	Color         string                  `json:"color" binding:"required,len=6,validHexRGBColor"`
+	Comment       string                  `json:"comment" binding:"max=255"`
+	Hidden        bool                    `json:"hidden"`
+	StatementDay  int                     `json:"statementDay" binding:"min=0,max=31"`
+	PaymentDueDay int                     `json:"paymentDueDay" binding:"min=0,max=31"`
+	CreditLimit   int64                   `json:"creditLimit" binding:"min=0,max=99999999999"`
+	SubAccounts   []*AccountModifyRequest `json:"subAccounts" binding:"omitempty"`
 }
 
 // AccountListRequest represents all parameters of account listing request
@@ -124,42 +188,384 @@ type AccountDeleteRequest struct {
 	Id int64 `json:"id,string" binding:"required,min=1"`
 }
 
+// AccountCloseRequest represents all parameters of account closing request
+type AccountCloseRequest struct {
+	Id              int64 `json:"id,string" binding:"required,min=1"`
+	ToAccountId     int64 `json:"toAccountId,string" binding:"min=0"`
+	CategoryId      int64 `json:"categoryId,string" binding:"min=0"`
+	ToAccountAmount int64 `json:"toAccountAmount" binding:"min=0,max=99999999999"`
+}
+
+// AccountMergeRequest represents all parameters of account merging request
+type AccountMergeRequest struct {
+	FromId int64 `json:"fromId,string" binding:"required,min=1"`
+	ToId   int64 `json:"toId,string" binding:"required,min=1"`
+}
+
+// AccountReconcilePreviewRequest represents all parameters of account reconciliation previewing request
+type AccountReconcilePreviewRequest struct {
+	Id            int64 `form:"id,string" binding:"required,min=1"`
+	EndTime       int64 `form:"end_time" binding:"required,min=1"`
+	EndingBalance int64 `form:"ending_balance" binding:"min=-99999999999,max=99999999999"`
+}
+
+// AccountReconcileRequest represents all parameters of account reconciliation request
+type AccountReconcileRequest struct {
+	Id            int64 `json:"id,string" binding:"required,min=1"`
+	EndTime       int64 `json:"endTime" binding:"required,min=1"`
+	EndingBalance int64 `json:"endingBalance" binding:"min=-99999999999,max=99999999999"`
+	Force         bool  `json:"force"`
+}
+
+// AccountReconcileSummary represents the cleared amounts of account until the statement end time
+type AccountReconcileSummary struct {
+	ClearedBalance  int64
+	ClearedCount    int64
+	UnclearedCount  int64
+	ReconciledCount int64
+}
+
+// AccountReconcileResponse represents a view-object of account reconciliation
+type AccountReconcileResponse struct {
+	AccountId       int64 `json:"accountId,string"`
+	EndTime         int64 `json:"endTime"`
+	EndingBalance   int64 `json:"endingBalance"`
+	ClearedBalance  int64 `json:"clearedBalance"`
+	Difference      int64 `json:"difference"`
+	ClearedCount    int64 `json:"clearedCount"`
+	UnclearedCount  int64 `json:"unclearedCount"`
+	ReconciledCount int64 `json:"reconciledCount"`
+}
+
+// AccountBalanceTrendsRequest represents all parameters of account balance trends request
+type AccountBalanceTrendsRequest struct {
+	StartTime    int64                            `form:"start_time" binding:"required,min=1"`
+	EndTime      int64                            `form:"end_time" binding:"min=0"`
+	IntervalType AccountBalanceTrendsIntervalType `form:"interval_type" binding:"required,min=1,max=3"`
+}
+
+// AccountCreditCardStatementListRequest represents all parameters of credit card statement listing request
+type AccountCreditCardStatementListRequest struct {
+	Id    int64 `form:"id,string" binding:"required,min=1"`
+	Count int   `form:"count" binding:"min=0,max=36"`
+}
+
+// AccountLoanSettingsModifyRequest represents all parameters of loan settings modification request
+type AccountLoanSettingsModifyRequest struct {
+	Id                 int64                       `json:"id,string" binding:"required,min=1"`
+	Principal          int64                       `json:"principal" binding:"min=0,max=99999999999"`
+	AnnualInterestRate int64                       `json:"annualInterestRate" binding:"min=0,max=1000000"`
+	TermCount          int                         `json:"termCount" binding:"min=0,max=1200"`
+	StartTime          int64                       `json:"startTime" binding:"min=0"`
+	PaymentFrequency   AccountLoanPaymentFrequency `json:"paymentFrequency" binding:"min=0,max=5"`
+}
+
+// AccountLoanScheduleRequest represents all parameters of loan amortization schedule request
+type AccountLoanScheduleRequest struct {
+	Id int64 `form:"id,string" binding:"required,min=1"`
+}
+
+// AccountLoanPaymentRequest represents all parameters of loan payment recording request
+type AccountLoanPaymentRequest struct {
+	Id                 int64  `json:"id,string" binding:"required,min=1"`
+	SourceAccountId    int64  `json:"sourceAccountId,string" binding:"required,min=1"`
+	TransferCategoryId int64  `json:"transferCategoryId,string" binding:"min=0"`
+	InterestCategoryId int64  `json:"interestCategoryId,string" binding:"min=0"`
+	PrincipalAmount    int64  `json:"principalAmount" binding:"min=0,max=99999999999"`
+	InterestAmount     int64  `json:"interestAmount" binding:"min=0,max=99999999999"`
+	Time               int64  `json:"time" binding:"required,min=1"`
+	UtcOffset          int16  `json:"utcOffset" binding:"min=-720,max=840"`
+	Comment            string `json:"comment" binding:"max=255"`
+}
+
+// AccountCreditCardStatement represents the amounts of credit card account in a statement cycle
+type AccountCreditCardStatement struct {
+	StartTime      int64
+	EndTime        int64
+	PaymentDueTime int64
+	ClosingBalance int64
+	PaidAmount     int64
+}
+
+// AccountCreditCardStatementResponse represents a view-object of credit card statement cycle
+type AccountCreditCardStatementResponse struct {
+	StartTime        int64 `json:"startTime"`
+	EndTime          int64 `json:"endTime"`
+	PaymentDueTime   int64 `json:"paymentDueTime"`
+	StatementBalance int64 `json:"statementBalance"`
+	MinimumPayment   int64 `json:"minimumPayment"`
+	PaidAmount       int64 `json:"paidAmount"`
+	UnpaidAmount     int64 `json:"unpaidAmount"`
+	RemainingCredit  int64 `json:"remainingCredit"`
+}
+
+// AccountCreditCardStatementListResponse represents a view-object of credit card statement cycles of account
+type AccountCreditCardStatementListResponse struct {
+	AccountId       int64                                 `json:"accountId,string"`
+	StatementDay    int                                   `json:"statementDay"`
+	PaymentDueDay   int                                   `json:"paymentDueDay"`
+	CreditLimit     int64                                 `json:"creditLimit"`
+	CurrentBalance  int64                                 `json:"currentBalance"`
+	RemainingCredit int64                                 `json:"remainingCredit"`
+	Statements      []*AccountCreditCardStatementResponse `json:"statements"`
+}
+
+// AccountLoanScheduleItemResponse represents a view-object of a payment in loan amortization schedule
+type AccountLoanScheduleItemResponse struct {
+	Period             int   `json:"period"`
+	PaymentTime        int64 `json:"paymentTime"`
+	Payment            int64 `json:"payment"`
+	Principal          int64 `json:"principal"`
+	Interest           int64 `json:"interest"`
+	RemainingPrincipal int64 `json:"remainingPrincipal"`
+}
+
+// AccountLoanScheduleResponse represents a view-object of loan amortization schedule of account
+type AccountLoanScheduleResponse struct {
+	AccountId            int64                              `json:"accountId,string"`
+	Principal            int64                              `json:"principal"`
+	AnnualInterestRate   int64                              `json:"annualInterestRate"`
+	TermCount            int                                `json:"termCount"`
+	StartTime            int64                              `json:"startTime"`
+	PaymentFrequency     AccountLoanPaymentFrequency        `json:"paymentFrequency"`
+	TotalPayment         int64                              `json:"totalPayment"`
+	TotalInterest        int64                              `json:"totalInterest"`
+	OutstandingPrincipal int64                              `json:"outstandingPrincipal"`
+	Payments             []*AccountLoanScheduleItemResponse `json:"payments"`
+}
+
+// AccountLoanPaymentResponse represents a view-object of the transactions created by loan payment
+type AccountLoanPaymentResponse struct {
+	PrincipalTransaction *TransactionInfoResponse `json:"principalTransaction,omitempty"`
+	InterestTransaction  *TransactionInfoResponse `json:"interestTransaction,omitempty"`
+}
+
+// AccountBalanceTrendsResponse represents a view-object of account balance and net worth time series
+type AccountBalanceTrendsResponse struct {
+	Currency   string                                   `json:"currency"`
+	DataPoints []*AccountBalanceTrendsDataPointResponse `json:"dataPoints"`
+	Accounts   []*AccountBalanceTrendsAccountResponse   `json:"accounts"`
+}
+
+// AccountBalanceTrendsDataPointResponse represents the total amounts in default currency at the end of a period
+type AccountBalanceTrendsDataPointResponse struct {
+	Time             int64 `json:"time"`
+	TotalAssets      int64 `json:"totalAssets"`
+	TotalLiabilities int64 `json:"totalLiabilities"`
+	NetWorth         int64 `json:"netWorth"`
+}
+
+// AccountBalanceTrendsAccountResponse represents the balances of an account at the end of every period
+type AccountBalanceTrendsAccountResponse struct {
+	AccountId int64   `json:"accountId,string"`
+	Currency  string  `json:"currency"`
+	Balances  []int64 `json:"balances"`
+}
+
 // AccountInfoResponse represents a view-object of account
 type AccountInfoResponse struct {
-	Id           int64                    `json:"id,string"`
-	Name         string                   `json:"name"`
-	ParentId     int64                    `json:"parentId,string"`
-	Category     AccountCategory          `json:"category"`
-	Type         AccountType              `json:"type"`
-	Icon         int64                    `json:"icon,string"`
-	Color        string                   `json:"color"`
-	Currency     string                   `json:"currency"`
-	Balance      int64                    `json:"balance"`
-	Comment      string                   `json:"comment"`
-	DisplayOrder int                      `json:"displayOrder"`
-	IsAsset      bool                     `json:"isAsset,omitempty"`
-	IsLiability  bool                     `json:"isLiability,omitempty"`
-	Hidden       bool                     `json:"hidden"`
-	SubAccounts  AccountInfoResponseSlice `json:"subAccounts,omitempty"`
+	Id                     int64                       `json:"id,string"`
+	Name                   string                      `json:"name"`
+	ParentId               int64                       `json:"parentId,string"`
+	Category               AccountCategory             `json:"category"`
+	Type                   AccountType                 `json:"type"`
+	Icon                   int64                       `json:"icon,string"`
+	Color                  string                      `json:"color"`
+	Currency               string                      `json:"currency"`
+	Balance                int64                       `json:"balance"`
+	ProjectedBalance       int64                       `json:"projectedBalance"`
+	Comment                string                      `json:"comment"`
+	DisplayOrder           int                         `json:"displayOrder"`
+	IsAsset                bool                        `json:"isAsset,omitempty"`
+	IsLiability            bool                        `json:"isLiability,omitempty"`
+	Hidden                 bool                        `json:"hidden"`
+	StatementDay           int                         `json:"statementDay,omitempty"`
+	PaymentDueDay          int                         `json:"paymentDueDay,omitempty"`
+	CreditLimit            int64                       `json:"creditLimit,omitempty"`
+	LoanPrincipal          int64                       `json:"loanPrincipal,omitempty"`
+	LoanAnnualInterestRate int64                       `json:"loanAnnualInterestRate,omitempty"`
+	LoanTermCount          int                         `json:"loanTermCount,omitempty"`
+	LoanStartTime          int64                       `json:"loanStartTime,omitempty"`
+	LoanPaymentFrequency   AccountLoanPaymentFrequency `json:"loanPaymentFrequency,omitempty"`
+	SubAccounts            AccountInfoResponseSlice    `json:"subAccounts,omitempty"`
 }
 
 // ToAccountInfoResponse returns a view-object according to database model
 func (a *Account) ToAccountInfoResponse() *AccountInfoResponse {
 	return &AccountInfoResponse{
-		Id:           a.AccountId,
-		Name:         a.Name,
-		ParentId:     a.ParentAccountId,
-		Category:     a.Category,
-		Type:         a.Type,
-		Icon:         a.Icon,
-		Color:        a.Color,
-		Currency:     a.Currency,
-		Balance:      a.Balance,
-		Comment:      a.Comment,
-		DisplayOrder: a.DisplayOrder,
-		IsAsset:      assetAccountCategory[a.Category],
-		IsLiability:  liabilityAccountCategory[a.Category],
-		Hidden:       a.Hidden,
+		Id:                     a.AccountId,
+		Name:                   a.Name,
+		ParentId:               a.ParentAccountId,
+		Category:               a.Category,
+		Type:                   a.Type,
+		Icon:                   a.Icon,
+		Color:                  a.Color,
+		Currency:               a.Currency,
+		Balance:                a.Balance,
+		ProjectedBalance:       a.Balance,
+		Comment:                a.Comment,
+		DisplayOrder:           a.DisplayOrder,
+		IsAsset:                assetAccountCategory[a.Category],
+		IsLiability:            liabilityAccountCategory[a.Category],
+		Hidden:                 a.Hidden,
+		StatementDay:           a.StatementDay,
+		PaymentDueDay:          a.PaymentDueDay,
+		CreditLimit:            a.CreditLimit,
+		LoanPrincipal:          a.LoanPrincipal,
+		LoanAnnualInterestRate: a.LoanAnnualInterestRate,
+		LoanTermCount:          a.LoanTermCount,
+		LoanStartTime:          a.LoanStartUnixTime,
+		LoanPaymentFrequency:   a.LoanPaymentFrequency,
+	}
+}
+
+// ToAccountReconcileResponse returns a view-object according to reconcile summary and statement
+func (s *AccountReconcileSummary) ToAccountReconcileResponse(accountId int64, endTime int64, endingBalance int64) *AccountReconcileResponse {
+	return &AccountReconcileResponse{
+		AccountId:       accountId,
+		EndTime:         endTime,
+		EndingBalance:   endingBalance,
+		ClearedBalance:  s.ClearedBalance,
+		Difference:      endingBalance - s.ClearedBalance,
+		ClearedCount:    s.ClearedCount,
+		UnclearedCount:  s.UnclearedCount,
+		ReconciledCount: s.ReconciledCount,
+	}
+}
+
+// IsAsset returns whether the account is an asset account
+func (a *Account) IsAsset() bool {
+	return assetAccountCategory[a.Category]
+}
+
+// IsLiability returns whether the account is a liability account
+func (a *Account) IsLiability() bool {
+	return liabilityAccountCategory[a.Category]
+}
+
+// HasCreditCardSettings returns whether any credit card setting of account is set
+func (a *Account) HasCreditCardSettings() bool {
+	return a.StatementDay > 0 || a.PaymentDueDay > 0 || a.CreditLimit > 0
+}
+
+// HasLoanSettings returns whether the loan terms of account are all set
+func (a *Account) HasLoanSettings() bool {
+	return a.LoanPrincipal > 0 && a.LoanTermCount > 0 && a.LoanStartUnixTime > 0 && accountLoanPaymentCountPerYear[a.LoanPaymentFrequency] > 0
+}
+
+// ToAccountLoanScheduleResponse returns a view-object of the amortization schedule calculated from loan terms of account
+func (a *Account) ToAccountLoanScheduleResponse(timezone *time.Location) *AccountLoanScheduleResponse {
+	periodicRate := float64(a.LoanAnnualInterestRate) / AccountLoanInterestRatePrecision / 100 / float64(accountLoanPaymentCountPerYear[a.LoanPaymentFrequency])
+	periodicPayment := int64(0)
+
+	if periodicRate > 0 {
+		periodicPayment = int64(math.Round(float64(a.LoanPrincipal) * periodicRate / (1 - math.Pow(1+periodicRate, -float64(a.LoanTermCount)))))
+	} else {
+		periodicPayment = int64(math.Ceil(float64(a.LoanPrincipal) / float64(a.LoanTermCount)))
+	}
+
+	startTime := time.Unix(a.LoanStartUnixTime, 0).In(timezone)
+	remainingPrincipal := a.LoanPrincipal
+	payments := make([]*AccountLoanScheduleItemResponse, 0, a.LoanTermCount)
+	totalPayment := int64(0)
+	totalInterest := int64(0)
+
+	for i := 1; i <= a.LoanTermCount && remainingPrincipal > 0; i++ {
+		interest := int64(math.Round(float64(remainingPrincipal) * periodicRate))
+		principal := periodicPayment - interest
+
+		if principal > remainingPrincipal || i == a.LoanTermCount {
+			principal = remainingPrincipal
+		}
+
+		remainingPrincipal -= principal
+		totalPayment += principal + interest
+		totalInterest += interest
+
+		payments = append(payments, &AccountLoanScheduleItemResponse{
+			Period:             i,
+			PaymentTime:        a.getLoanPaymentTime(startTime, i).Unix(),
+			Payment:            principal + interest,
+			Principal:          principal,
+			Interest:           interest,
+			RemainingPrincipal: remainingPrincipal,
+		})
+	}
+
+	outstandingPrincipal := int64(0)
+
+	if a.Balance < 0 {
+		outstandingPrincipal = -a.Balance
+	}
+
+	return &AccountLoanScheduleResponse{
+		AccountId:            a.AccountId,
+		Principal:            a.LoanPrincipal,
+		AnnualInterestRate:   a.LoanAnnualInterestRate,
+		TermCount:            a.LoanTermCount,
+		StartTime:            a.LoanStartUnixTime,
+		PaymentFrequency:     a.LoanPaymentFrequency,
+		TotalPayment:         totalPayment,
+		TotalInterest:        totalInterest,
+		OutstandingPrincipal: outstandingPrincipal,
+		Payments:             payments,
+	}
+}
+
+func (a *Account) getLoanPaymentTime(startTime time.Time, period int) time.Time {
+	switch a.LoanPaymentFrequency {
+	case ACCOUNT_LOAN_PAYMENT_FREQUENCY_WEEKLY:
+		return startTime.AddDate(0, 0, 7*period)
+	case ACCOUNT_LOAN_PAYMENT_FREQUENCY_BIWEEKLY:
+		return startTime.AddDate(0, 0, 14*period)
+	case ACCOUNT_LOAN_PAYMENT_FREQUENCY_QUARTERLY:
+		return utils.GetDateOfMonthWithoutOverflow(startTime.Year(), startTime.Month()+time.Month(3*period), startTime.Day(), startTime.Location())
+	case ACCOUNT_LOAN_PAYMENT_FREQUENCY_YEARLY:
+		return utils.GetDateOfMonthWithoutOverflow(startTime.Year()+period, startTime.Month(), startTime.Day(), startTime.Location())
+	default:
+		return utils.GetDateOfMonthWithoutOverflow(startTime.Year(), startTime.Month()+time.Month(period), startTime.Day(), startTime.Location())
+	}
+}
+
+// ToAccountCreditCardStatementResponse returns a view-object according to credit card statement and credit limit
+func (s *AccountCreditCardStatement) ToAccountCreditCardStatementResponse(creditLimit int64) *AccountCreditCardStatementResponse {
+	statementBalance := int64(0)
+
+	if s.ClosingBalance < 0 {
+		statementBalance = -s.ClosingBalance
+	}
+
+	unpaidAmount := statementBalance - s.PaidAmount
+
+	if unpaidAmount < 0 {
+		unpaidAmount = 0
+	}
+
+	minimumPayment := statementBalance * CreditCardMinimumPaymentPercent / 100
+
+	if minimumPayment < CreditCardMinimumPaymentAmount {
+		minimumPayment = CreditCardMinimumPaymentAmount
+	}
+
+	if minimumPayment > statementBalance {
+		minimumPayment = statementBalance
+	}
+
+	remainingCredit := int64(0)
+
+	if creditLimit > 0 {
+		remainingCredit = creditLimit + s.ClosingBalance
+	}
+
+	return &AccountCreditCardStatementResponse{
+		StartTime:        s.StartTime,
+		EndTime:          s.EndTime,
+		PaymentDueTime:   s.PaymentDueTime,
+		StatementBalance: statementBalance,
+		MinimumPayment:   minimumPayment,
+		PaidAmount:       s.PaidAmount,
+		UnpaidAmount:     unpaidAmount,
+		RemainingCredit:  remainingCredit,
 	}
 }
 
@@ -0,0 +1,83 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccountToAccountLoanScheduleResponse(t *testing.T) {
+	account := &Account{
+		Balance:                -100000,
+		LoanPrincipal:          100000,
+		LoanAnnualInterestRate: 120000, // 12%
+		LoanTermCount:          3,
+		LoanStartUnixTime:      1706659200, // 2024-01-31 00:00:00 UTC
+		LoanPaymentFrequency:   ACCOUNT_LOAN_PAYMENT_FREQUENCY_MONTHLY,
+	}
+
+	testCases := []struct {
+		paymentTime        int64
+		payment            int64
+		principal          int64
+		interest           int64
+		remainingPrincipal int64
+	}{
+		{1709164800, 34002, 33002, 1000, 66998}, // 2024-02-29 00:00:00 UTC
+		{1711843200, 34002, 33332, 670, 33666},  // 2024-03-31 00:00:00 UTC
+		{1714435200, 34003, 33666, 337, 0},      // 2024-04-30 00:00:00 UTC, the last period pays off the rounding difference
+	}
+
+	actualValue := account.ToAccountLoanScheduleResponse(time.UTC)
+	assert.Equal(t, len(testCases), len(actualValue.Payments))
+
+	for i, testCase := range testCases {
+		assert.Equal(t, i+1, actualValue.Payments[i].Period)
+		assert.Equal(t, testCase.paymentTime, actualValue.Payments[i].PaymentTime)
+		assert.Equal(t, testCase.payment, actualValue.Payments[i].Payment)
+		assert.Equal(t, testCase.principal, actualValue.Payments[i].Principal)
+		assert.Equal(t, testCase.interest, actualValue.Payments[i].Interest)
+		assert.Equal(t, testCase.remainingPrincipal, actualValue.Payments[i].RemainingPrincipal)
+	}
+
+	assert.Equal(t, int64(102007), actualValue.TotalPayment)
+	assert.Equal(t, int64(2007), actualValue.TotalInterest)
+	assert.Equal(t, int64(100000), actualValue.OutstandingPrincipal)
+}
+
+func TestAccountToAccountLoanScheduleResponse_ZeroInterestRate(t *testing.T) {
+	testCases := []struct {
+		principal         int64
+		termCount         int
+		expectedPayments  []int64
+		expectedRemaining int64
+	}{
+		{100000, 3, []int64{33334, 33334, 33332}, 0},
+		{10, 4, []int64{3, 3, 3, 1}, 0},
+		{9, 3, []int64{3, 3, 3}, 0},
+	}
+
+	for _, testCase := range testCases {
+		account := &Account{
+			LoanPrincipal:        testCase.principal,
+			LoanTermCount:        testCase.termCount,
+			LoanStartUnixTime:    1704067200, // 2024-01-01 00:00:00 UTC
+			LoanPaymentFrequency: ACCOUNT_LOAN_PAYMENT_FREQUENCY_MONTHLY,
+		}
+
+		actualValue := account.ToAccountLoanScheduleResponse(time.UTC)
+		assert.Equal(t, len(testCase.expectedPayments), len(actualValue.Payments))
+
+		for i := 0; i < len(testCase.expectedPayments); i++ {
+			assert.Equal(t, testCase.expectedPayments[i], actualValue.Payments[i].Payment)
+			assert.Equal(t, testCase.expectedPayments[i], actualValue.Payments[i].Principal)
+			assert.Equal(t, int64(0), actualValue.Payments[i].Interest)
+		}
+
+		assert.Equal(t, testCase.expectedRemaining, actualValue.Payments[len(actualValue.Payments)-1].RemainingPrincipal)
+		assert.Equal(t, testCase.principal, actualValue.TotalPayment)
+		assert.Equal(t, int64(0), actualValue.TotalInterest)
+		assert.Equal(t, int64(0), actualValue.OutstandingPrincipal)
+	}
+}
@@ -0,0 +1,117 @@
+package models
+
+// MaxBudgetRolloverMonthCount represents the maximum count of previous months whose unspent or overspent amounts can be rolled into current month
+const MaxBudgetRolloverMonthCount = 36
+
+// Budget represents the planned expense amount of a transaction category in a month stored in database
+type Budget struct {
+	BudgetId        int64 `xorm:"PK"`
+	Uid             int64 `xorm:"INDEX(IDX_budget_uid_deleted_month) NOT NULL"`
+	Deleted         bool  `xorm:"INDEX(IDX_budget_uid_deleted_month) NOT NULL"`
+	Month           int   `xorm:"INDEX(IDX_budget_uid_deleted_month) NOT NULL"`
+	CategoryId      int64 `xorm:"NOT NULL"`
+	Amount          int64 `xorm:"NOT NULL"`
+	Rollover        bool  `xorm:"NOT NULL"`
+	CreatedUnixTime int64
+	UpdatedUnixTime int64
+	DeletedUnixTime int64
+}
+
+// BudgetListRequest represents all parameters of budget listing request
+type BudgetListRequest struct {
+	Year  int `form:"year" binding:"required,min=1970,max=9999"`
+	Month int `form:"month" binding:"required,min=1,max=12"`
+}
+
+// BudgetCreateRequest represents all parameters of budget creation request
+type BudgetCreateRequest struct {
+	CategoryId int64 `json:"categoryId,string" binding:"required,min=1"`
+	Year       int   `json:"year" binding:"required,min=1970,max=9999"`
+	Month      int   `json:"month" binding:"required,min=1,max=12"`
+	Amount     int64 `json:"amount" binding:"min=0,max=99999999999"`
+	Rollover   bool  `json:"rollover"`
+}
+
+// BudgetModifyRequest represents all parameters of budget modification request
+type BudgetModifyRequest struct {
+	Id       int64 `json:"id,string" binding:"required,min=1"`
+	Amount   int64 `json:"amount" binding:"min=0,max=99999999999"`
+	Rollover bool  `json:"rollover"`
+}
+
+// BudgetDeleteRequest represents all parameters of budget deleting request
+type BudgetDeleteRequest struct {
+	Id int64 `json:"id,string" binding:"required,min=1"`
+}
+
+// BudgetStatusRequest represents all parameters of budget status request
+type BudgetStatusRequest struct {
+	Year  int `form:"year" binding:"required,min=1970,max=9999"`
+	Month int `form:"month" binding:"required,min=1,max=12"`
+}
+
+// BudgetInfoResponse represents a view-object of budget
+type BudgetInfoResponse struct {
+	Id         int64 `json:"id,string"`
+	CategoryId int64 `json:"categoryId,string"`
+	Year       int   `json:"year"`
+	Month      int   `json:"month"`
+	Amount     int64 `json:"amount"`
+	Rollover   bool  `json:"rollover"`
+}
+
+// BudgetStatusItemResponse represents a view-object of the planned, spent and remaining amounts of budget
+type BudgetStatusItemResponse struct {
+	Id             int64 `json:"id,string"`
+	CategoryId     int64 `json:"categoryId,string"`
+	Planned        int64 `json:"planned"`
+	RolloverAmount int64 `json:"rolloverAmount"`
+	Spent          int64 `json:"spent"`
+	Remaining      int64 `json:"remaining"`
+}
+
+// BudgetStatusResponse represents a view-object of the status of all budgets in a month
+type BudgetStatusResponse struct {
+	Year     int                         `json:"year"`
+	Month    int                         `json:"month"`
+	Currency string                      `json:"currency"`
+	Items    []*BudgetStatusItemResponse `json:"items"`
+}
+
+// GetBudgetMonth returns the month value stored in database (in YYYYMM format) according to year and month
+func GetBudgetMonth(year int, month int) int {
+	return year*100 + month
+}
+
+// GetPreviousBudgetMonth returns the previous month value (in YYYYMM format) of specified month value
+func GetPreviousBudgetMonth(budgetMonth int) int {
+	if budgetMonth%100 == 1 {
+		return (budgetMonth/100-1)*100 + 12
+	}
+
+	return budgetMonth - 1
+}
+
+// ToBudgetInfoResponse returns a view-object according to database model
+func (b *Budget) ToBudgetInfoResponse() *BudgetInfoResponse {
+	return &BudgetInfoResponse{
+		Id:         b.BudgetId,
+		CategoryId: b.CategoryId,
+		Year:       b.Month / 100,
+		Month:      b.Month % 100,
+		Amount:     b.Amount,
+		Rollover:   b.Rollover,
+	}
+}
+
+// ToBudgetStatusItemResponse returns a view-object according to database model, amount rolled from previous months and spent amount
+func (b *Budget) ToBudgetStatusItemResponse(rolloverAmount int64, spent int64) *BudgetStatusItemResponse {
+	return &BudgetStatusItemResponse{
+		Id:             b.BudgetId,
+		CategoryId:     b.CategoryId,
+		Planned:        b.Amount,
+		RolloverAmount: rolloverAmount,
+		Spent:          spent,
+		Remaining:      b.Amount + rolloverAmount - spent,
+	}
+}
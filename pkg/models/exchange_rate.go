@@ -1,6 +1,12 @@
 package models
 
-import "strings"
+import (
+	"math"
+	"strings"
+
+	"github.com/mayswind/ezbookkeeping/pkg/errs"
+	"github.com/mayswind/ezbookkeeping/pkg/utils"
+)
 
 // LatestExchangeRateResponse returns a view-object which contains latest exchange rate
 type LatestExchangeRateResponse struct {
@@ -11,6 +17,45 @@ type LatestExchangeRateResponse struct {
 	ExchangeRates LatestExchangeRateSlice `json:"exchangeRates"`
 }
 
+// ExchangeAmount returns the amount converted from the source currency to the target currency according to the latest exchange rates
+func (r *LatestExchangeRateResponse) ExchangeAmount(amount int64, fromCurrency string, toCurrency string) (int64, error) {
+	if fromCurrency == toCurrency {
+		return amount, nil
+	}
+
+	fromRate, err := r.getExchangeRate(fromCurrency)
+
+	if err != nil {
+		return 0, err
+	}
+
+	toRate, err := r.getExchangeRate(toCurrency)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return int64(math.Round(float64(amount) / fromRate * toRate)), nil
+}
+
+func (r *LatestExchangeRateResponse) getExchangeRate(currency string) (float64, error) {
+	for i := 0; i < len(r.ExchangeRates); i++ {
+		if r.ExchangeRates[i].Currency != currency {
+			continue
+		}
+
+		rate, err := utils.StringToFloat64(r.ExchangeRates[i].Rate)
+
+		if err != nil || rate <= 0 {
+			return 0, errs.ErrAccountCurrencyExchangeRateNotFound
+		}
+
+		return rate, nil
+	}
+
+	return 0, errs.ErrAccountCurrencyExchangeRateNotFound
+}
+
 // LatestExchangeRate represents a data pair of currency and exchange rate
 type LatestExchangeRate struct {
 	Currency string `json:"currency"`
@@ -0,0 +1,318 @@
+package models
+
+import (
+	"math/big"
+
+	"github.com/mayswind/ezbookkeeping/pkg/utils"
+)
+
+// InvestmentQuantityPrecision represents the count of quantity units in one share of investment security
+const InvestmentQuantityPrecision = 10000
+
+// InvestmentTransactionType represents investment transaction type
+type InvestmentTransactionType byte
+
+// Investment transaction types
+const (
+	INVESTMENT_TRANSACTION_TYPE_BUY      InvestmentTransactionType = 1
+	INVESTMENT_TRANSACTION_TYPE_SELL     InvestmentTransactionType = 2
+	INVESTMENT_TRANSACTION_TYPE_DIVIDEND InvestmentTransactionType = 3
+)
+
+// InvestmentSecurity represents investment security (stock, fund, bond, etc.) data stored in database
+type InvestmentSecurity struct {
+	SecurityId      int64  `xorm:"PK"`
+	Uid             int64  `xorm:"INDEX(IDX_security_uid_deleted_symbol) NOT NULL"`
+	Deleted         bool   `xorm:"INDEX(IDX_security_uid_deleted_symbol) NOT NULL"`
+	Symbol          string `xorm:"INDEX(IDX_security_uid_deleted_symbol) VARCHAR(32) NOT NULL"`
+	Name            string `xorm:"VARCHAR(64) NOT NULL"`
+	Currency        string `xorm:"VARCHAR(3) NOT NULL"`
+	CreatedUnixTime int64
+	UpdatedUnixTime int64
+	DeletedUnixTime int64
+}
+
+// InvestmentSecurityPrice represents the price of investment security at specified time stored in database
+type InvestmentSecurityPrice struct {
+	PriceId         int64 `xorm:"PK"`
+	Uid             int64 `xorm:"INDEX(IDX_security_price_uid_deleted_security_id_price_time) NOT NULL"`
+	Deleted         bool  `xorm:"INDEX(IDX_security_price_uid_deleted_security_id_price_time) NOT NULL"`
+	SecurityId      int64 `xorm:"INDEX(IDX_security_price_uid_deleted_security_id_price_time) NOT NULL"`
+	PriceUnixTime   int64 `xorm:"INDEX(IDX_security_price_uid_deleted_security_id_price_time) NOT NULL"`
+	Price           int64 `xorm:"NOT NULL"`
+	CreatedUnixTime int64
+	UpdatedUnixTime int64
+	DeletedUnixTime int64
+}
+
+// InvestmentTransaction represents buying, selling or dividend of investment security in investment account stored in database
+type InvestmentTransaction struct {
+	TransactionId     int64                     `xorm:"PK"`
+	Uid               int64                     `xorm:"INDEX(IDX_investment_transaction_uid_deleted_account_id_trade_time) NOT NULL"`
+	Deleted           bool                      `xorm:"INDEX(IDX_investment_transaction_uid_deleted_account_id_trade_time) NOT NULL"`
+	AccountId         int64                     `xorm:"INDEX(IDX_investment_transaction_uid_deleted_account_id_trade_time) NOT NULL"`
+	TradeUnixTime     int64                     `xorm:"INDEX(IDX_investment_transaction_uid_deleted_account_id_trade_time) NOT NULL"`
+	SecurityId        int64                     `xorm:"NOT NULL"`
+	Type              InvestmentTransactionType `xorm:"NOT NULL"`
+	Quantity          int64                     `xorm:"NOT NULL"`
+	Amount            int64                     `xorm:"NOT NULL"`
+	CashTransactionId int64                     `xorm:"NOT NULL DEFAULT 0"`
+	Comment           string                    `xorm:"VARCHAR(255) NOT NULL"`
+	CreatedUnixTime   int64
+	UpdatedUnixTime   int64
+	DeletedUnixTime   int64
+}
+
+// InvestmentHolding represents the holding quantity, cost basis and realized gain of an investment security
+type InvestmentHolding struct {
+	SecurityId     int64
+	Quantity       int64
+	CostBasis      int64
+	RealizedGain   int64
+	DividendIncome int64
+}
+
+// InvestmentSecurityGetRequest represents all parameters of investment security getting request
+type InvestmentSecurityGetRequest struct {
+	Id int64 `form:"id,string" binding:"required,min=1"`
+}
+
+// InvestmentSecurityCreateRequest represents all parameters of investment security creation request
+type InvestmentSecurityCreateRequest struct {
+	Symbol   string `json:"symbol" binding:"required,notBlank,max=32"`
+	Name     string `json:"name" binding:"max=64"`
+	Currency string `json:"currency" binding:"required,len=3,validCurrency"`
+}
+
+// InvestmentSecurityModifyRequest represents all parameters of investment security modification request
+type InvestmentSecurityModifyRequest struct {
+	Id       int64  `json:"id,string" binding:"required,min=1"`
+	Symbol   string `json:"symbol" binding:"required,notBlank,max=32"`
+	Name     string `json:"name" binding:"max=64"`
+	Currency string `json:"currency" binding:"required,len=3,validCurrency"`
+}
+
+// InvestmentSecurityDeleteRequest represents all parameters of investment security deleting request
+type InvestmentSecurityDeleteRequest struct {
+	Id int64 `json:"id,string" binding:"required,min=1"`
+}
+
+// InvestmentSecurityPriceListRequest represents all parameters of investment security price listing request
+type InvestmentSecurityPriceListRequest struct {
+	SecurityId int64 `form:"security_id,string" binding:"required,min=1"`
+	Count      int   `form:"count" binding:"required,min=1,max=1000"`
+}
+
+// InvestmentSecurityPriceCreateRequest represents all parameters of investment security price creation request
+type InvestmentSecurityPriceCreateRequest struct {
+	SecurityId int64 `json:"securityId,string" binding:"required,min=1"`
+	Time       int64 `json:"time" binding:"required,min=1"`
+	Price      int64 `json:"price" binding:"min=0,max=99999999999"`
+}
+
+// InvestmentSecurityPriceDeleteRequest represents all parameters of investment security price deleting request
+type InvestmentSecurityPriceDeleteRequest struct {
+	Id int64 `json:"id,string" binding:"required,min=1"`
+}
+
+// InvestmentTransactionListRequest represents all parameters of investment transaction listing request
+type InvestmentTransactionListRequest struct {
+	AccountId  int64 `form:"account_id,string" binding:"required,min=1"`
+	SecurityId int64 `form:"security_id,string" binding:"min=0"`
+}
+
+// InvestmentTransactionCreateRequest represents all parameters of investment transaction creation request
+type InvestmentTransactionCreateRequest struct {
+	AccountId  int64                     `json:"accountId,string" binding:"required,min=1"`
+	SecurityId int64                     `json:"securityId,string" binding:"required,min=1"`
+	CategoryId int64                     `json:"categoryId,string" binding:"required,min=1"`
+	Type       InvestmentTransactionType `json:"type" binding:"required,min=1,max=3"`
+	Time       int64                     `json:"time" binding:"required,min=1"`
+	UtcOffset  int16                     `json:"utcOffset" binding:"min=-720,max=840"`
+	Quantity   int64                     `json:"quantity" binding:"min=0,max=99999999999"`
+	Amount     int64                     `json:"amount" binding:"min=0,max=99999999999"`
+	Comment    string                    `json:"comment" binding:"max=255"`
+}
+
+// InvestmentTransactionModifyRequest represents all parameters of investment transaction modification request
+type InvestmentTransactionModifyRequest struct {
+	Id         int64                     `json:"id,string" binding:"required,min=1"`
+	CategoryId int64                     `json:"categoryId,string" binding:"required,min=1"`
+	Type       InvestmentTransactionType `json:"type" binding:"required,min=1,max=3"`
+	Time       int64                     `json:"time" binding:"required,min=1"`
+	UtcOffset  int16                     `json:"utcOffset" binding:"min=-720,max=840"`
+	Quantity   int64                     `json:"quantity" binding:"min=0,max=99999999999"`
+	Amount     int64                     `json:"amount" binding:"min=0,max=99999999999"`
+	Comment    string                    `json:"comment" binding:"max=255"`
+}
+
+// InvestmentTransactionDeleteRequest represents all parameters of investment transaction deleting request
+type InvestmentTransactionDeleteRequest struct {
+	Id int64 `json:"id,string" binding:"required,min=1"`
+}
+
+// InvestmentHoldingListRequest represents all parameters of investment holding listing request
+type InvestmentHoldingListRequest struct {
+	AccountId int64 `form:"account_id,string" binding:"min=0"`
+}
+
+// InvestmentSecurityInfoResponse represents a view-object of investment security
+type InvestmentSecurityInfoResponse struct {
+	Id       int64  `json:"id,string"`
+	Symbol   string `json:"symbol"`
+	Name     string `json:"name"`
+	Currency string `json:"currency"`
+}
+
+// InvestmentSecurityPriceInfoResponse represents a view-object of investment security price
+type InvestmentSecurityPriceInfoResponse struct {
+	Id         int64 `json:"id,string"`
+	SecurityId int64 `json:"securityId,string"`
+	Time       int64 `json:"time"`
+	Price      int64 `json:"price"`
+}
+
+// InvestmentSecurityPriceImportResponse represents the result of investment security price importing
+type InvestmentSecurityPriceImportResponse struct {
+	ImportedCount int `json:"importedCount"`
+}
+
+// InvestmentTransactionInfoResponse represents a view-object of investment transaction
+type InvestmentTransactionInfoResponse struct {
+	Id                int64                     `json:"id,string"`
+	AccountId         int64                     `json:"accountId,string"`
+	SecurityId        int64                     `json:"securityId,string"`
+	Type              InvestmentTransactionType `json:"type"`
+	Time              int64                     `json:"time"`
+	Quantity          int64                     `json:"quantity"`
+	Amount            int64                     `json:"amount"`
+	CashTransactionId int64                     `json:"cashTransactionId,string,omitempty"`
+	Comment           string                    `json:"comment"`
+}
+
+// InvestmentAccountHoldingsResponse represents a view-object of all holdings in investment account
+type InvestmentAccountHoldingsResponse struct {
+	AccountId      int64                        `json:"accountId,string"`
+	Currency       string                       `json:"currency"`
+	MarketValue    int64                        `json:"marketValue"`
+	CostBasis      int64                        `json:"costBasis"`
+	UnrealizedGain int64                        `json:"unrealizedGain"`
+	RealizedGain   int64                        `json:"realizedGain"`
+	DividendIncome int64                        `json:"dividendIncome"`
+	Holdings       []*InvestmentHoldingResponse `json:"holdings"`
+}
+
+// InvestmentHoldingResponse represents a view-object of investment security holding
+type InvestmentHoldingResponse struct {
+	SecurityId     int64  `json:"securityId,string"`
+	Symbol         string `json:"symbol"`
+	Name           string `json:"name"`
+	Quantity       int64  `json:"quantity"`
+	CostBasis      int64  `json:"costBasis"`
+	Price          int64  `json:"price"`
+	PriceTime      int64  `json:"priceTime"`
+	MarketValue    int64  `json:"marketValue"`
+	UnrealizedGain int64  `json:"unrealizedGain"`
+	RealizedGain   int64  `json:"realizedGain"`
+	DividendIncome int64  `json:"dividendIncome"`
+}
+
+// ToInvestmentSecurityInfoResponse returns a view-object according to database model
+func (s *InvestmentSecurity) ToInvestmentSecurityInfoResponse() *InvestmentSecurityInfoResponse {
+	return &InvestmentSecurityInfoResponse{
+		Id:       s.SecurityId,
+		Symbol:   s.Symbol,
+		Name:     s.Name,
+		Currency: s.Currency,
+	}
+}
+
+// ToInvestmentSecurityPriceInfoResponse returns a view-object according to database model
+func (p *InvestmentSecurityPrice) ToInvestmentSecurityPriceInfoResponse() *InvestmentSecurityPriceInfoResponse {
+	return &InvestmentSecurityPriceInfoResponse{
+		Id:         p.PriceId,
+		SecurityId: p.SecurityId,
+		Time:       p.PriceUnixTime,
+		Price:      p.Price,
+	}
+}
+
+// ToInvestmentTransactionInfoResponse returns a view-object according to database model
+func (t *InvestmentTransaction) ToInvestmentTransactionInfoResponse() *InvestmentTransactionInfoResponse {
+	return &InvestmentTransactionInfoResponse{
+		Id:                t.TransactionId,
+		AccountId:         t.AccountId,
+		SecurityId:        t.SecurityId,
+		Type:              t.Type,
+		Time:              t.TradeUnixTime,
+		Quantity:          t.Quantity,
+		Amount:            t.Amount,
+		CashTransactionId: t.CashTransactionId,
+		Comment:           t.Comment,
+	}
+}
+
+// ToCashTransaction returns the transaction of the cash leg in investment account, buying is an expense, and selling or dividend is an income
+func (t *InvestmentTransaction) ToCashTransaction(categoryId int64, utcOffset int16) *Transaction {
+	transactionType := TRANSACTION_DB_TYPE_INCOME
+
+	if t.Type == INVESTMENT_TRANSACTION_TYPE_BUY {
+		transactionType = TRANSACTION_DB_TYPE_EXPENSE
+	}
+
+	return &Transaction{
+		TransactionId:     t.CashTransactionId,
+		Uid:               t.Uid,
+		Type:              transactionType,
+		CategoryId:        categoryId,
+		TransactionTime:   utils.GetMinTransactionTimeFromUnixTime(t.TradeUnixTime),
+		TimezoneUtcOffset: utcOffset,
+		AccountId:         t.AccountId,
+		Amount:            t.Amount,
+		Comment:           t.Comment,
+	}
+}
+
+// ToInvestmentHoldingResponse returns a view-object according to holding, security and the latest price, the market value equals to cost basis if there is no price
+func (h *InvestmentHolding) ToInvestmentHoldingResponse(security *InvestmentSecurity, price *InvestmentSecurityPrice) *InvestmentHoldingResponse {
+	holdingResp := &InvestmentHoldingResponse{
+		SecurityId:     h.SecurityId,
+		Quantity:       h.Quantity,
+		CostBasis:      h.CostBasis,
+		MarketValue:    h.CostBasis,
+		RealizedGain:   h.RealizedGain,
+		DividendIncome: h.DividendIncome,
+	}
+
+	if security != nil {
+		holdingResp.Symbol = security.Symbol
+		holdingResp.Name = security.Name
+	}
+
+	if price != nil {
+		holdingResp.Price = price.Price
+		holdingResp.PriceTime = price.PriceUnixTime
+		holdingResp.MarketValue = new(big.Int).Div(new(big.Int).Mul(big.NewInt(h.Quantity), big.NewInt(price.Price)), big.NewInt(InvestmentQuantityPrecision)).Int64()
+		holdingResp.UnrealizedGain = holdingResp.MarketValue - h.CostBasis
+	}
+
+	return holdingResp
+}
+
+// InvestmentSecurityInfoResponseSlice represents the slice data structure of InvestmentSecurityInfoResponse
+type InvestmentSecurityInfoResponseSlice []*InvestmentSecurityInfoResponse
+
+// Len returns the count of items
+func (s InvestmentSecurityInfoResponseSlice) Len() int {
+	return len(s)
+}
+
+// Swap swaps two items
+func (s InvestmentSecurityInfoResponseSlice) Swap(i, j int) {
+	s[i], s[j] = s[j], s[i]
+}
+
+// Less reports whether the first item is less than the second one
+func (s InvestmentSecurityInfoResponseSlice) Less(i, j int) bool {
+	return s[i].Symbol < s[j].Symbol
+}
@@ -0,0 +1,124 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/mayswind/ezbookkeeping/pkg/utils"
+)
+
+// SavingsGoal represents savings goal data stored in database
+type SavingsGoal struct {
+	GoalId          int64  `xorm:"PK"`
+	Uid             int64  `xorm:"INDEX(IDX_savings_goal_uid_deleted_target_time) NOT NULL"`
+	Deleted         bool   `xorm:"INDEX(IDX_savings_goal_uid_deleted_target_time) NOT NULL"`
+	Name            string `xorm:"VARCHAR(64) NOT NULL"`
+	Currency        string `xorm:"VARCHAR(3) NOT NULL"`
+	TargetAmount    int64  `xorm:"NOT NULL"`
+	TargetUnixTime  int64  `xorm:"INDEX(IDX_savings_goal_uid_deleted_target_time) NOT NULL"`
+	AccountIds      string `xorm:"VARCHAR(1000) NOT NULL"`
+	Comment         string `xorm:"VARCHAR(255) NOT NULL"`
+	CreatedUnixTime int64
+	UpdatedUnixTime int64
+	DeletedUnixTime int64
+}
+
+// SavingsGoalGetRequest represents all parameters of savings goal getting request
+type SavingsGoalGetRequest struct {
+	Id int64 `form:"id,string" binding:"required,min=1"`
+}
+
+// SavingsGoalCreateRequest represents all parameters of savings goal creation request
+type SavingsGoalCreateRequest struct {
+	Name         string   `json:"name" binding:"required,notBlank,max=64"`
+	Currency     string   `json:"currency" binding:"required,len=3,validCurrency"`
+	TargetAmount int64    `json:"targetAmount" binding:"min=1,max=99999999999"`
+	TargetTime   int64    `json:"targetTime" binding:"required,min=1"`
+	AccountIds   []string `json:"accountIds" binding:"required,min=1,max=50"`
+	Comment      string   `json:"comment" binding:"max=255"`
+}
+
+// SavingsGoalModifyRequest represents all parameters of savings goal modification request
+type SavingsGoalModifyRequest struct {
+	Id           int64    `json:"id,string" binding:"required,min=1"`
+	Name         string   `json:"name" binding:"required,notBlank,max=64"`
+	Currency     string   `json:"currency" binding:"required,len=3,validCurrency"`
+	TargetAmount int64    `json:"targetAmount" binding:"min=1,max=99999999999"`
+	TargetTime   int64    `json:"targetTime" binding:"required,min=1"`
+	AccountIds   []string `json:"accountIds" binding:"required,min=1,max=50"`
+	Comment      string   `json:"comment" binding:"max=255"`
+}
+
+// SavingsGoalDeleteRequest represents all parameters of savings goal deleting request
+type SavingsGoalDeleteRequest struct {
+	Id int64 `json:"id,string" binding:"required,min=1"`
+}
+
+// SavingsGoalInfoResponse represents a view-object of savings goal and its progress
+type SavingsGoalInfoResponse struct {
+	Id                  int64    `json:"id,string"`
+	Name                string   `json:"name"`
+	Currency            string   `json:"currency"`
+	TargetAmount        int64    `json:"targetAmount"`
+	TargetTime          int64    `json:"targetTime"`
+	AccountIds          []string `json:"accountIds"`
+	Comment             string   `json:"comment"`
+	CurrentAmount       int64    `json:"currentAmount"`
+	RemainingAmount     int64    `json:"remainingAmount"`
+	RemainingMonthCount int      `json:"remainingMonthCount"`
+	MonthlyContribution int64    `json:"monthlyContribution"`
+}
+
+// GetAccountIdStrings returns the linked account ids of savings goal
+func (g *SavingsGoal) GetAccountIdStrings() []string {
+	if g.AccountIds == "" {
+		return []string{}
+	}
+
+	return strings.Split(g.AccountIds, ",")
+}
+
+// GetAccountIds returns the linked account ids of savings goal
+func (g *SavingsGoal) GetAccountIds() ([]int64, error) {
+	if g.AccountIds == "" {
+		return []int64{}, nil
+	}
+
+	return utils.StringArrayToInt64Array(strings.Split(g.AccountIds, ","))
+}
+
+// ToSavingsGoalInfoResponse returns a view-object according to database model, the current amount of linked accounts and current time in user timezone
+func (g *SavingsGoal) ToSavingsGoalInfoResponse(currentAmount int64, now time.Time) *SavingsGoalInfoResponse {
+	remainingAmount := g.TargetAmount - currentAmount
+
+	if remainingAmount < 0 {
+		remainingAmount = 0
+	}
+
+	targetTime := time.Unix(g.TargetUnixTime, 0).In(now.Location())
+	remainingMonthCount := (targetTime.Year()-now.Year())*12 + int(targetTime.Month()) - int(now.Month())
+
+	if remainingMonthCount < 0 {
+		remainingMonthCount = 0
+	}
+
+	monthlyContribution := remainingAmount
+
+	if remainingMonthCount > 0 {
+		monthlyContribution = (remainingAmount + int64(remainingMonthCount) - 1) / int64(remainingMonthCount)
+	}
+
+	return &SavingsGoalInfoResponse{
+		Id:                  g.GoalId,
+		Name:                g.Name,
+		Currency:            g.Currency,
+		TargetAmount:        g.TargetAmount,
+		TargetTime:          g.TargetUnixTime,
+		AccountIds:          g.GetAccountIdStrings(),
+		Comment:             g.Comment,
+		CurrentAmount:       currentAmount,
+		RemainingAmount:     remainingAmount,
+		RemainingMonthCount: remainingMonthCount,
+		MonthlyContribution: monthlyContribution,
+	}
+}
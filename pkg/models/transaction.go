@@ -32,22 +32,61 @@ const (
 	TRANSACTION_DB_TYPE_TRANSFER_IN    TransactionDbType = 5
 )
 
+// TransactionReconcileState represents the reconciliation state of transaction
+type TransactionReconcileState byte
+
+// Transaction reconcile states
+const (
+	TRANSACTION_RECONCILE_STATE_UNCLEARED  TransactionReconcileState = 0
+	TRANSACTION_RECONCILE_STATE_CLEARED    TransactionReconcileState = 1
+	TRANSACTION_RECONCILE_STATE_RECONCILED TransactionReconcileState = 2
+)
+
+// String returns a textual representation of the transaction reconcile state
+func (s TransactionReconcileState) String() string {
+	switch s {
+	case TRANSACTION_RECONCILE_STATE_UNCLEARED:
+		return "Uncleared"
+	case TRANSACTION_RECONCILE_STATE_CLEARED:
+		return "Cleared"
+	case TRANSACTION_RECONCILE_STATE_RECONCILED:
+		return "Reconciled"
+	default:
+		return "Unknown"
+	}
+}
+
+// TransactionTagFilterType represents how transaction tag filter matches tags of transaction
+type TransactionTagFilterType byte
+
+// Transaction tag filter types
+const (
+	TRANSACTION_TAG_FILTER_HAS_ANY TransactionTagFilterType = 0
+	TRANSACTION_TAG_FILTER_HAS_ALL TransactionTagFilterType = 1
+)
+
 // Transaction represents transaction data stored in database
 type Transaction struct {
-	TransactionId        int64             `xorm:"PK"`
-	Uid                  int64             `xorm:"UNIQUE(UQE_transaction_uid_time) INDEX(IDX_transaction_uid_deleted_time) INDEX(IDX_transaction_uid_deleted_type_time) INDEX(IDX_transaction_uid_deleted_category_id_time) INDEX(IDX_transaction_uid_deleted_account_id_time) NOT NULL"`
-	Deleted              bool              `xorm:"INDEX(IDX_transaction_uid_deleted_time) INDEX(IDX_transaction_uid_deleted_type_time) INDEX(IDX_transaction_uid_deleted_category_id_time) INDEX(IDX_transaction_uid_deleted_account_id_time) NOT NULL"`
-	Type                 TransactionDbType `xorm:"INDEX(IDX_transaction_uid_deleted_type_time) NOT NULL"`
-	CategoryId           int64             `xorm:"INDEX(IDX_transaction_uid_deleted_category_id_time) NOT NULL"`
-	AccountId            int64             `xorm:"INDEX(IDX_transaction_uid_deleted_account_id_time) NOT NULL"`
-	TransactionTime      int64             `xorm:"UNIQUE(UQE_transaction_uid_time) INDEX(IDX_transaction_uid_deleted_time) INDEX(IDX_transaction_uid_deleted_type_time) INDEX(IDX_transaction_uid_deleted_category_id_time) INDEX(IDX_transaction_uid_deleted_account_id_time) NOT NULL"`
-	TimezoneUtcOffset    int16             `xorm:"NOT NULL"`
-	Amount               int64             `xorm:"NOT NULL"`
-	RelatedId            int64             `xorm:"NOT NULL"`
-	RelatedAccountId     int64             `xorm:"NOT NULL"`
-	RelatedAccountAmount int64             `xorm:"NOT NULL"`
-	HideAmount           bool              `xorm:"NOT NULL"`
-	Comment              string            `xorm:"VARCHAR(255) NOT NULL"`
+	TransactionId        int64                     `xorm:"PK"`
+	Uid                  int64                     `xorm:"UNIQUE(UQE_transaction_uid_time) INDEX(IDX_transaction_uid_deleted_time) INDEX(IDX_transaction_uid_deleted_type_time) INDEX(IDX_transaction_uid_deleted_category_id_time) INDEX(IDX_transaction_uid_deleted_account_id_time) INDEX(IDX_transaction_uid_deleted_payee_id_time) NOT NULL"`
+	Deleted              bool                      `xorm:"INDEX(IDX_transaction_uid_deleted_time) INDEX(IDX_transaction_uid_deleted_type_time) INDEX(IDX_transaction_uid_deleted_category_id_time) INDEX(IDX_transaction_uid_deleted_account_id_time) INDEX(IDX_transaction_uid_deleted_payee_id_time) NOT NULL"`
+	Type                 TransactionDbType         `xorm:"INDEX(IDX_transaction_uid_deleted_type_time) NOT NULL"`
+	CategoryId           int64                     `xorm:"INDEX(IDX_transaction_uid_deleted_category_id_time) NOT NULL"`
+	AccountId            int64                     `xorm:"INDEX(IDX_transaction_uid_deleted_account_id_time) NOT NULL"`
+	PayeeId              int64                     `xorm:"INDEX(IDX_transaction_uid_deleted_payee_id_time) NOT NULL DEFAULT 0"`
+	TransactionTime      int64                     `xorm:"UNIQUE(UQE_transaction_uid_time) INDEX(IDX_transaction_uid_deleted_time) INDEX(IDX_transaction_uid_deleted_type_time) INDEX(IDX_transaction_uid_deleted_category_id_time) INDEX(IDX_transaction_uid_deleted_account_id_time) INDEX(IDX_transaction_uid_deleted_payee_id_time) NOT NULL"`
+	TimezoneUtcOffset    int16                     `xorm:"NOT NULL"`
+	Amount               int64                     `xorm:"NOT NULL"`
+	RelatedId            int64                     `xorm:"NOT NULL"`
+	RelatedAccountId     int64                     `xorm:"NOT NULL"`
+	RelatedAccountAmount int64                     `xorm:"NOT NULL"`
+	OriginalCurrency     string                    `xorm:"VARCHAR(3) NOT NULL DEFAULT ''"`
+	OriginalAmount       int64                     `xorm:"NOT NULL DEFAULT 0"`
+	ExchangeRate         string                    `xorm:"VARCHAR(32) NOT NULL DEFAULT ''"`
+	HideAmount           bool                      `xorm:"NOT NULL"`
+	Comment              string                    `xorm:"VARCHAR(255) NOT NULL"`
+	ReconcileState       TransactionReconcileState `xorm:"TINYINT NOT NULL DEFAULT 0"`
+	Pending              bool                      `xorm:"INDEX(IDX_transaction_deleted_pending) NOT NULL DEFAULT false"`
 	CreatedUnixTime      int64
 	UpdatedUnixTime      int64
 	DeletedUnixTime      int64
@@ -55,77 +94,147 @@ type Transaction struct {
 
 // TransactionCreateRequest represents all parameters of transaction creation request
 type TransactionCreateRequest struct {
-	Type                 TransactionType `json:"type" binding:"required"`
-	CategoryId           int64           `json:"categoryId,string"`
-	Time                 int64           `json:"time" binding:"required,min=1"`
-	UtcOffset            int16           `json:"utcOffset" binding:"min=-720,max=840"`
-	SourceAccountId      int64           `json:"sourceAccountId,string" binding:"required,min=1"`
-	DestinationAccountId int64           `json:"destinationAccountId,string" binding:"min=0"`
-	SourceAmount         int64           `json:"sourceAmount" binding:"min=-99999999999,max=99999999999"`
-	DestinationAmount    int64           `json:"destinationAmount" binding:"min=-99999999999,max=99999999999"`
-	HideAmount           bool            `json:"hideAmount"`
-	TagIds               []string        `json:"tagIds"`
-	Comment              string          `json:"comment" binding:"max=255"`
+	Type                 TransactionType            `json:"type" binding:"required"`
+	CategoryId           int64                      `json:"categoryId,string"`
+	Time                 int64                      `json:"time" binding:"required,min=1"`
+	UtcOffset            int16                      `json:"utcOffset" binding:"min=-720,max=840"`
+	SourceAccountId      int64                      `json:"sourceAccountId,string" binding:"required,min=1"`
+	DestinationAccountId int64                      `json:"destinationAccountId,string" binding:"min=0"`
+	PayeeId              int64                      `json:"payeeId,string" binding:"min=0"`
+	SourceAmount         int64                      `json:"sourceAmount" binding:"min=-99999999999,max=99999999999"`
+	DestinationAmount    int64                      `json:"destinationAmount" binding:"min=-99999999999,max=99999999999"`
+	OriginalCurrency     string                     `json:"originalCurrency" binding:"omitempty,len=3,validCurrency"`
+	OriginalAmount       int64                      `json:"originalAmount" binding:"min=-99999999999,max=99999999999"`
+	ExchangeRate         string                     `json:"exchangeRate" binding:"max=32"`
+	HideAmount           bool                       `json:"hideAmount"`
+	TagIds               []string                   `json:"tagIds"`
+	Comment              string                     `json:"comment" binding:"max=255"`
+	Splits               []*TransactionSplitRequest `json:"splits" binding:"omitempty,max=100,dive"`
 }
 
 // TransactionModifyRequest represents all parameters of transaction modification request
 type TransactionModifyRequest struct {
-	Id                   int64    `json:"id,string" binding:"required,min=1"`
-	CategoryId           int64    `json:"categoryId,string"`
-	Time                 int64    `json:"time" binding:"required,min=1"`
-	UtcOffset            int16    `json:"utcOffset" binding:"min=-720,max=840"`
-	SourceAccountId      int64    `json:"sourceAccountId,string" binding:"required,min=1"`
-	DestinationAccountId int64    `json:"destinationAccountId,string" binding:"min=0"`
-	SourceAmount         int64    `json:"sourceAmount" binding:"min=-99999999999,max=99999999999"`
-	DestinationAmount    int64    `json:"destinationAmount" binding:"min=-99999999999,max=99999999999"`
-	HideAmount           bool     `json:"hideAmount"`
-	TagIds               []string `json:"tagIds"`
-	Comment              string   `json:"comment" binding:"max=255"`
+	Id                   int64                      `json:"id,string" binding:"required,min=1"`
+	CategoryId           int64                      `json:"categoryId,string"`
+	Time                 int64                      `json:"time" binding:"required,min=1"`
+	UtcOffset            int16                      `json:"utcOffset" binding:"min=-720,max=840"`
+	SourceAccountId      int64                      `json:"sourceAccountId,string" binding:"required,min=1"`
+	DestinationAccountId int64                      `json:"destinationAccountId,string" binding:"min=0"`
+	PayeeId              int64                      `json:"payeeId,string" binding:"min=0"`
+	SourceAmount         int64                      `json:"sourceAmount" binding:"min=-99999999999,max=99999999999"`
+	DestinationAmount    int64                      `json:"destinationAmount" binding:"min=-99999999999,max=99999999999"`
+	OriginalCurrency     string                     `json:"originalCurrency" binding:"omitempty,len=3,validCurrency"`
+	OriginalAmount       int64                      `json:"originalAmount" binding:"min=-99999999999,max=99999999999"`
+	ExchangeRate         string                     `json:"exchangeRate" binding:"max=32"`
+	HideAmount           bool                       `json:"hideAmount"`
+	TagIds               []string                   `json:"tagIds"`
+	Comment              string                     `json:"comment" binding:"max=255"`
+	Splits               []*TransactionSplitRequest `json:"splits" binding:"omitempty,max=100,dive"`
+	ClearSplits          bool                       `json:"clearSplits"`
+	Force                bool                       `json:"force"`
 }
 
 // TransactionCountRequest represents transaction count request
 type TransactionCountRequest struct {
-	Type       TransactionDbType `form:"type" binding:"min=0,max=4"`
-	CategoryId int64             `form:"category_id" binding:"min=0"`
-	AccountId  int64             `form:"account_id" binding:"min=0"`
-	Keyword    string            `form:"keyword"`
-	MaxTime    int64             `form:"max_time" binding:"min=0"`
-	MinTime    int64             `form:"min_time" binding:"min=0"`
+	Type          TransactionDbType        `form:"type" binding:"min=0,max=4"`
+	CategoryId    int64                    `form:"category_id" binding:"min=0"`
+	CategoryIds   string                   `form:"category_ids"`
+	AccountId     int64                    `form:"account_id" binding:"min=0"`
+	AccountIds    string                   `form:"account_ids"`
+	PayeeIds      string                   `form:"payee_ids"`
+	TagIds        string                   `form:"tag_ids"`
+	TagFilterType TransactionTagFilterType `form:"tag_filter_type" binding:"min=0,max=1"`
+	Keyword       string                   `form:"keyword"`
+	MaxTime       int64                    `form:"max_time" binding:"min=0"`
+	MinTime       int64                    `form:"min_time" binding:"min=0"`
 }
 
 // TransactionListByMaxTimeRequest represents all parameters of transaction listing by max time request
 type TransactionListByMaxTimeRequest struct {
-	Type         TransactionDbType `form:"type" binding:"min=0,max=4"`
-	CategoryId   int64             `form:"category_id" binding:"min=0"`
-	AccountId    int64             `form:"account_id" binding:"min=0"`
-	Keyword      string            `form:"keyword"`
-	MaxTime      int64             `form:"max_time" binding:"min=0"`
-	MinTime      int64             `form:"min_time" binding:"min=0"`
-	Count        int               `form:"count" binding:"required,min=1,max=50"`
-	TrimAccount  bool              `form:"trim_account"`
-	TrimCategory bool              `form:"trim_category"`
-	TrimTag      bool              `form:"trim_tag"`
+	Type          TransactionDbType        `form:"type" binding:"min=0,max=4"`
+	CategoryId    int64                    `form:"category_id" binding:"min=0"`
+	CategoryIds   string                   `form:"category_ids"`
+	AccountId     int64                    `form:"account_id" binding:"min=0"`
+	AccountIds    string                   `form:"account_ids"`
+	PayeeIds      string                   `form:"payee_ids"`
+	TagIds        string                   `form:"tag_ids"`
+	TagFilterType TransactionTagFilterType `form:"tag_filter_type" binding:"min=0,max=1"`
+	Keyword       string                   `form:"keyword"`
+	MaxTime       int64                    `form:"max_time" binding:"min=0"`
+	MinTime       int64                    `form:"min_time" binding:"min=0"`
+	Count         int                      `form:"count" binding:"required,min=1,max=50"`
+	TrimAccount   bool                     `form:"trim_account"`
+	TrimCategory  bool                     `form:"trim_category"`
+	TrimTag       bool                     `form:"trim_tag"`
+}
+
+// TransactionSearchRequest represents all parameters of transaction searching request
+type TransactionSearchRequest struct {
+	Query         string                   `form:"query" binding:"max=1000"`
+	Type          TransactionDbType        `form:"type" binding:"min=0,max=4"`
+	CategoryIds   string                   `form:"category_ids"`
+	AccountIds    string                   `form:"account_ids"`
+	PayeeIds      string                   `form:"payee_ids"`
+	TagIds        string                   `form:"tag_ids"`
+	TagFilterType TransactionTagFilterType `form:"tag_filter_type" binding:"min=0,max=1"`
+	MinAmount     *int64                   `form:"min_amount"`
+	MaxAmount     *int64                   `form:"max_amount"`
+	Keyword       string                   `form:"keyword"`
+	MaxTime       int64                    `form:"max_time" binding:"min=0"`
+	MinTime       int64                    `form:"min_time" binding:"min=0"`
+	Count         int                      `form:"count" binding:"required,min=1,max=50"`
+	TrimAccount   bool                     `form:"trim_account"`
+	TrimCategory  bool                     `form:"trim_category"`
+	TrimTag       bool                     `form:"trim_tag"`
+}
+
+// TransactionSearchFilter represents all conditions of transaction searching
+type TransactionSearchFilter struct {
+	MaxTransactionTime int64
+	MinTransactionTime int64
+	Type               TransactionDbType
+	CategoryIds        []int64
+	ExcludeCategoryIds []int64
+	AccountIds         []int64
+	ExcludeAccountIds  []int64
+	PayeeIds           []int64
+	ExcludePayeeIds    []int64
+	TagIds             []int64
+	TagFilterType      TransactionTagFilterType
+	ExcludeTagIds      []int64
+	MinAmount          *int64
+	MaxAmount          *int64
+	Keywords           []string
+	ExcludeKeywords    []string
 }
 
 // TransactionListInMonthByPageRequest represents all parameters of transaction listing by month request
 type TransactionListInMonthByPageRequest struct {
-	Year         int               `form:"year" binding:"required,min=1"`
-	Month        int               `form:"month" binding:"required,min=1"`
-	Type         TransactionDbType `form:"type" binding:"min=0,max=4"`
-	CategoryId   int64             `form:"category_id" binding:"min=0"`
-	AccountId    int64             `form:"account_id" binding:"min=0"`
-	Keyword      string            `form:"keyword"`
-	Page         int               `form:"page" binding:"required,min=1"`
-	Count        int               `form:"count" binding:"required,min=1,max=50"`
-	TrimAccount  bool              `form:"trim_account"`
-	TrimCategory bool              `form:"trim_category"`
-	TrimTag      bool              `form:"trim_tag"`
+	Year          int                      `form:"year" binding:"required,min=1"`
+	Month         int                      `form:"month" binding:"required,min=1"`
+	Type          TransactionDbType        `form:"type" binding:"min=0,max=4"`
+	CategoryId    int64                    `form:"category_id" binding:"min=0"`
+	CategoryIds   string                   `form:"category_ids"`
+	AccountId     int64                    `form:"account_id" binding:"min=0"`
+	AccountIds    string                   `form:"account_ids"`
+	PayeeIds      string                   `form:"payee_ids"`
+	TagIds        string                   `form:"tag_ids"`
+	TagFilterType TransactionTagFilterType `form:"tag_filter_type" binding:"min=0,max=1"`
+	Keyword       string                   `form:"keyword"`
+	Page          int                      `form:"page" binding:"required,min=1"`
+	Count         int                      `form:"count" binding:"required,min=1,max=50"`
+	TrimAccount   bool                     `form:"trim_account"`
+	TrimCategory  bool                     `form:"trim_category"`
+	TrimTag       bool                     `form:"trim_tag"`
 }
 
 // TransactionStatisticRequest represents all parameters of transaction statistic request
 type TransactionStatisticRequest struct {
-	StartTime int64 `form:"start_time" binding:"min=0"`
-	EndTime   int64 `form:"end_time" binding:"min=0"`
+	StartTime    int64  `form:"start_time" binding:"min=0"`
+	EndTime      int64  `form:"end_time" binding:"min=0"`
+	PayeeIds     string `form:"payee_ids"`
+	GroupByPayee bool   `form:"group_by_payee"`
+	GroupByTag   bool   `form:"group_by_tag"`
 }
 
 // TransactionAmountsRequest represents all parameters of transaction amounts request
@@ -156,6 +265,58 @@ type TransactionGetRequest struct {
 
 // TransactionDeleteRequest represents all parameters of transaction deleting request
 type TransactionDeleteRequest struct {
+	Id    int64 `json:"id,string" binding:"required,min=1"`
+	Force bool  `json:"force"`
+}
+
+// TransactionReconcileStateModifyRequest represents all parameters of transaction reconcile state modification request
+type TransactionReconcileStateModifyRequest struct {
+	Id    int64                     `json:"id,string" binding:"required,min=1"`
+	State TransactionReconcileState `json:"state" binding:"min=0,max=1"`
+	Force bool                      `json:"force"`
+}
+
+// TransactionBatchCreateRequest represents all parameters of transaction batch creation request
+type TransactionBatchCreateRequest struct {
+	Transactions []*TransactionCreateRequest `json:"transactions" binding:"required,min=1,max=1000,dive"`
+}
+
+// TransactionBatchModifyRequest represents all parameters of transaction batch modification request
+type TransactionBatchModifyRequest struct {
+	Transactions []*TransactionModifyRequest `json:"transactions" binding:"required,min=1,max=1000,dive"`
+}
+
+// TransactionBatchDeleteRequest represents all parameters of transaction batch deleting request
+type TransactionBatchDeleteRequest struct {
+	Transactions []*TransactionDeleteRequest `json:"transactions" binding:"required,min=1,max=1000,dive"`
+}
+
+// TransactionReassignCategoryRequest represents all parameters of reassigning category of filtered transactions request
+type TransactionReassignCategoryRequest struct {
+	CategoryIds   string                   `json:"categoryIds"`
+	AccountIds    string                   `json:"accountIds"`
+	PayeeIds      string                   `json:"payeeIds"`
+	TagIds        string                   `json:"tagIds"`
+	TagFilterType TransactionTagFilterType `json:"tagFilterType" binding:"min=0,max=1"`
+	Keyword       string                   `json:"keyword"`
+	StartTime     int64                    `json:"startTime" binding:"min=0"`
+	EndTime       int64                    `json:"endTime" binding:"min=0"`
+	ToCategoryId  int64                    `json:"toCategoryId,string" binding:"required,min=1"`
+}
+
+// TransactionTrashListRequest represents all parameters of deleted transaction listing request
+type TransactionTrashListRequest struct {
+	Page  int `form:"page" binding:"required,min=1"`
+	Count int `form:"count" binding:"required,min=1,max=50"`
+}
+
+// TransactionRestoreRequest represents all parameters of deleted transaction restoring request
+type TransactionRestoreRequest struct {
+	Id int64 `json:"id,string" binding:"required,min=1"`
+}
+
+// TransactionPurgeRequest represents all parameters of deleted transaction purging request
+type TransactionPurgeRequest struct {
 	Id int64 `json:"id,string" binding:"required,min=1"`
 }
 
@@ -169,6 +330,83 @@ type TransactionAccountAmount struct {
 	TotalExpenseAmount int64
 }
 
+// TransactionBatchItem represents a transaction and its related data to be saved in batch
+type TransactionBatchItem struct {
+	Transaction  *Transaction
+	AddTagIds    []int64
+	RemoveTagIds []int64
+	Splits       []*TransactionSplit
+}
+
+// TransactionBatchResponse represents the result of transaction batch operation
+type TransactionBatchResponse struct {
+	Committed bool                          `json:"committed"`
+	Items     []*TransactionBatchResultItem `json:"items"`
+}
+
+// TransactionBatchResultItem represents the result of a single item in transaction batch operation
+type TransactionBatchResultItem struct {
+	Index        int    `json:"index"`
+	Success      bool   `json:"success"`
+	Id           int64  `json:"id,string,omitempty"`
+	ErrorCode    int    `json:"errorCode,omitempty"`
+	ErrorMessage string `json:"errorMessage,omitempty"`
+}
+
+// NewTransactionBatchResponse returns a new transaction batch response with the given item count
+func NewTransactionBatchResponse(count int) *TransactionBatchResponse {
+	items := make([]*TransactionBatchResultItem, count)
+
+	for i := 0; i < count; i++ {
+		items[i] = &TransactionBatchResultItem{
+			Index: i,
+		}
+	}
+
+	return &TransactionBatchResponse{
+		Items: items,
+	}
+}
+
+// SetItemSuccess marks the specified item as succeeded
+func (r *TransactionBatchResponse) SetItemSuccess(index int, id int64) {
+	item := r.Items[index]
+	item.Success = true
+	item.Id = id
+	item.ErrorCode = 0
+	item.ErrorMessage = ""
+}
+
+// SetItemError marks the specified item as failed
+func (r *TransactionBatchResponse) SetItemError(index int, err *errs.Error) {
+	item := r.Items[index]
+	item.Success = false
+	item.Id = 0
+	item.ErrorCode = err.Code()
+	item.ErrorMessage = err.Message
+}
+
+// ClearSuccessItems resets all succeeded items, it should be called when the batch is not committed
+func (r *TransactionBatchResponse) ClearSuccessItems() {
+	for i := 0; i < len(r.Items); i++ {
+		if r.Items[i].Success {
+			r.Items[i].Success = false
+			r.Items[i].Id = 0
+		}
+	}
+}
+
+// HasError returns whether any item of the batch is failed
+func (r *TransactionBatchResponse) HasError() bool {
+	for i := 0; i < len(r.Items); i++ {
+		if r.Items[i].ErrorCode != 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
 // TransactionInfoResponse represents a view-object of transaction
 type TransactionInfoResponse struct {
 	Id                   int64                            `json:"id,string"`
@@ -182,13 +420,27 @@ type TransactionInfoResponse struct {
 	SourceAccount        *AccountInfoResponse             `json:"sourceAccount,omitempty"`
 	DestinationAccountId int64                            `json:"destinationAccountId,string,omitempty"`
 	DestinationAccount   *AccountInfoResponse             `json:"destinationAccount,omitempty"`
+	PayeeId              int64                            `json:"payeeId,string,omitempty"`
 	SourceAmount         int64                            `json:"sourceAmount"`
 	DestinationAmount    int64                            `json:"destinationAmount,omitempty"`
+	OriginalCurrency     string                           `json:"originalCurrency,omitempty"`
+	OriginalAmount       int64                            `json:"originalAmount,omitempty"`
+	ExchangeRate         string                           `json:"exchangeRate,omitempty"`
 	HideAmount           bool                             `json:"hideAmount"`
 	TagIds               []string                         `json:"tagIds"`
 	Tags                 []*TransactionTagInfoResponse    `json:"tags,omitempty"`
 	Comment              string                           `json:"comment"`
+	Splits               []*TransactionSplitInfoResponse  `json:"splits,omitempty"`
+	ReconcileState       TransactionReconcileState        `json:"reconcileState"`
+	Pending              bool                             `json:"pending"`
 	Editable             bool                             `json:"editable"`
+	DeletedTime          int64                            `json:"deletedTime,omitempty"`
+}
+
+// TransactionReassignCategoryResponse represents the result of reassigning category of filtered transactions
+type TransactionReassignCategoryResponse struct {
+	UpdatedCount int `json:"updatedCount"`
+	SkippedCount int `json:"skippedCount"`
 }
 
 // TransactionCountResponse represents transaction count response
@@ -208,6 +460,15 @@ type TransactionInfoPageWrapperResponse2 struct {
 	TotalCount int64                        `json:"totalCount"`
 }
 
+// TransactionTagTotalAmount represents total amount of transactions grouped by tag, category, account and payee, the tag id of transactions without any tag is zero
+type TransactionTagTotalAmount struct {
+	TagId      int64
+	CategoryId int64
+	AccountId  int64
+	PayeeId    int64
+	Amount     int64
+}
+
 // TransactionStatisticResponse represents an item of transaction amounts
 type TransactionStatisticResponse struct {
 	StartTime int64                               `json:"startTime"`
@@ -219,6 +480,8 @@ type TransactionStatisticResponse struct {
 type TransactionStatisticResponseItem struct {
 	CategoryId  int64 `json:"categoryId,string"`
 	AccountId   int64 `json:"accountId,string"`
+	PayeeId     int64 `json:"payeeId,string,omitempty"`
+	TagId       int64 `json:"tagId,string,omitempty"`
 	TotalAmount int64 `json:"amount"`
 }
 
@@ -253,6 +516,10 @@ func (t *Transaction) IsEditable(currentUser *User, utcOffset int16, account *Ac
 		return false
 	}
 
+	if t.ReconcileState == TRANSACTION_RECONCILE_STATE_RECONCILED {
+		return false
+	}
+
 	if t.Type == TRANSACTION_DB_TYPE_TRANSFER_OUT {
 		if relatedAccount == nil || relatedAccount.Hidden {
 			return false
@@ -262,6 +529,15 @@ func (t *Transaction) IsEditable(currentUser *User, utcOffset int16, account *Ac
 	return true
 }
 
+// IsEditableInBulk returns whether this transaction can be changed by bulk operations, the transfer in transaction is changed together with its transfer out transaction
+func (t *Transaction) IsEditableInBulk(currentUser *User, accountMap map[int64]*Account) bool {
+	if t.Type == TRANSACTION_DB_TYPE_TRANSFER_IN {
+		return false
+	}
+
+	return t.IsEditable(currentUser, t.TimezoneUtcOffset, accountMap[t.AccountId], accountMap[t.RelatedAccountId])
+}
+
 // ToTransactionInfoResponse returns a view-object according to database model
 func (t *Transaction) ToTransactionInfoResponse(tagIds []int64, editable bool) *TransactionInfoResponse {
 	var transactionType TransactionType
@@ -306,11 +582,17 @@ func (t *Transaction) ToTransactionInfoResponse(tagIds []int64, editable bool) *
 		UtcOffset:            t.TimezoneUtcOffset,
 		SourceAccountId:      sourceAccountId,
 		DestinationAccountId: destinationAccountId,
+		PayeeId:              t.PayeeId,
 		SourceAmount:         sourceAmount,
 		DestinationAmount:    destinationAmount,
+		OriginalCurrency:     t.OriginalCurrency,
+		OriginalAmount:       t.OriginalAmount,
+		ExchangeRate:         t.ExchangeRate,
 		HideAmount:           t.HideAmount,
 		TagIds:               utils.Int64ArrayToStringArray(tagIds),
 		Comment:              t.Comment,
+		ReconcileState:       t.ReconcileState,
+		Pending:              t.Pending,
 		Editable:             editable,
 	}
 }
@@ -0,0 +1,69 @@
+package models
+
+import "fmt"
+
+// TransactionAttachment represents transaction attachment metadata stored in database
+type TransactionAttachment struct {
+	AttachmentId    int64  `xorm:"PK"`
+	Uid             int64  `xorm:"INDEX(IDX_transaction_attachment_uid_deleted_transaction_id) NOT NULL"`
+	Deleted         bool   `xorm:"INDEX(IDX_transaction_attachment_uid_deleted_transaction_id) NOT NULL"`
+	TransactionId   int64  `xorm:"INDEX(IDX_transaction_attachment_uid_deleted_transaction_id) NOT NULL"`
+	FileName        string `xorm:"VARCHAR(255) NOT NULL"`
+	ContentType     string `xorm:"VARCHAR(64) NOT NULL"`
+	Size            int64  `xorm:"NOT NULL"`
+	CreatedUnixTime int64
+	UpdatedUnixTime int64
+	DeletedUnixTime int64
+}
+
+// TransactionAttachmentListRequest represents all parameters of transaction attachment listing request
+type TransactionAttachmentListRequest struct {
+	TransactionId int64 `form:"transaction_id" binding:"required,min=1"`
+}
+
+// TransactionAttachmentUploadRequest represents all parameters of transaction attachment uploading request
+type TransactionAttachmentUploadRequest struct {
+	TransactionId int64 `form:"transaction_id" binding:"required,min=1"`
+}
+
+// TransactionAttachmentGetRequest represents all parameters of transaction attachment getting request
+type TransactionAttachmentGetRequest struct {
+	Id int64 `form:"id,string" binding:"required,min=1"`
+}
+
+// TransactionAttachmentDeleteRequest represents all parameters of transaction attachment deleting request
+type TransactionAttachmentDeleteRequest struct {
+	Id int64 `json:"id,string" binding:"required,min=1"`
+}
+
+// TransactionAttachmentInfoResponse represents a view-object of transaction attachment
+type TransactionAttachmentInfoResponse struct {
+	Id            int64  `json:"id,string"`
+	TransactionId int64  `json:"transactionId,string"`
+	FileName      string `json:"fileName"`
+	ContentType   string `json:"contentType"`
+	Size          int64  `json:"size"`
+	CreatedTime   int64  `json:"createdTime"`
+}
+
+// GetStoragePath returns the path of attachment file in object storage
+func (a *TransactionAttachment) GetStoragePath() string {
+	return fmt.Sprintf("%s/%d", GetTransactionAttachmentStoragePathPrefix(a.Uid), a.AttachmentId)
+}
+
+// ToTransactionAttachmentInfoResponse returns a view-object according to database model
+func (a *TransactionAttachment) ToTransactionAttachmentInfoResponse() *TransactionAttachmentInfoResponse {
+	return &TransactionAttachmentInfoResponse{
+		Id:            a.AttachmentId,
+		TransactionId: a.TransactionId,
+		FileName:      a.FileName,
+		ContentType:   a.ContentType,
+		Size:          a.Size,
+		CreatedTime:   a.CreatedUnixTime,
+	}
+}
+
+// GetTransactionAttachmentStoragePathPrefix returns the path prefix of all attachment files of given user in object storage
+func GetTransactionAttachmentStoragePathPrefix(uid int64) string {
+	return fmt.Sprintf("transaction_attachments/%d", uid)
+}
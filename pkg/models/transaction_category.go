@@ -94,6 +94,12 @@ type TransactionCategoryNewDisplayOrderRequest struct {
 	DisplayOrder int   `json:"displayOrder"`
 }
 
+// TransactionCategoryMergeRequest represents all parameters of transaction category merging request
+type TransactionCategoryMergeRequest struct {
+	FromIds []string `json:"fromIds" binding:"required,min=1,max=100"`
+	ToId    int64    `json:"toId,string" binding:"required,min=1"`
+}
+
 // TransactionCategoryDeleteRequest represents all parameters of transaction category deleting request
 type TransactionCategoryDeleteRequest struct {
 	Id int64 `json:"id,string" binding:"required,min=1"`
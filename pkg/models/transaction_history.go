@@ -0,0 +1,151 @@
+package models
+
+import (
+	"encoding/json"
+
+	"github.com/mayswind/ezbookkeeping/pkg/core"
+	"github.com/mayswind/ezbookkeeping/pkg/utils"
+)
+
+// TransactionHistoryOperation represents the operation type of transaction history
+type TransactionHistoryOperation byte
+
+// Transaction history operations
+const (
+	TRANSACTION_HISTORY_OPERATION_CREATE     TransactionHistoryOperation = 1
+	TRANSACTION_HISTORY_OPERATION_MODIFY     TransactionHistoryOperation = 2
+	TRANSACTION_HISTORY_OPERATION_DELETE     TransactionHistoryOperation = 3
+	TRANSACTION_HISTORY_OPERATION_DELETE_ALL TransactionHistoryOperation = 4
+	TRANSACTION_HISTORY_OPERATION_RESTORE    TransactionHistoryOperation = 5
+	TRANSACTION_HISTORY_OPERATION_PURGE      TransactionHistoryOperation = 6
+)
+
+// String returns a textual representation of the transaction history operation
+func (o TransactionHistoryOperation) String() string {
+	switch o {
+	case TRANSACTION_HISTORY_OPERATION_CREATE:
+		return "Create"
+	case TRANSACTION_HISTORY_OPERATION_MODIFY:
+		return "Modify"
+	case TRANSACTION_HISTORY_OPERATION_DELETE:
+		return "Delete"
+	case TRANSACTION_HISTORY_OPERATION_DELETE_ALL:
+		return "Delete All"
+	case TRANSACTION_HISTORY_OPERATION_RESTORE:
+		return "Restore"
+	case TRANSACTION_HISTORY_OPERATION_PURGE:
+		return "Purge"
+	default:
+		return "Unknown"
+	}
+}
+
+// TransactionHistory represents an immutable change record of transaction stored in database
+type TransactionHistory struct {
+	HistoryId       int64                       `xorm:"PK"`
+	Uid             int64                       `xorm:"INDEX(IDX_transaction_history_uid_transaction_id) INDEX(IDX_transaction_history_uid_created_unix_time) NOT NULL"`
+	TransactionId   int64                       `xorm:"INDEX(IDX_transaction_history_uid_transaction_id) NOT NULL"`
+	Operation       TransactionHistoryOperation `xorm:"TINYINT NOT NULL"`
+	UserTokenId     int64                       `xorm:"NOT NULL"`
+	RequestId       string                      `xorm:"VARCHAR(64) NOT NULL"`
+	BeforeData      string                      `xorm:"TEXT"`
+	AfterData       string                      `xorm:"TEXT"`
+	CreatedUnixTime int64                       `xorm:"INDEX(IDX_transaction_history_uid_created_unix_time)"`
+}
+
+// TransactionHistorySnapshot represents the field values of transaction at a moment
+type TransactionHistorySnapshot struct {
+	Type                  TransactionDbType               `json:"type"`
+	CategoryId            int64                           `json:"categoryId,string"`
+	AccountId             int64                           `json:"accountId,string"`
+	RelatedAccountId      int64                           `json:"relatedAccountId,string"`
+	PayeeId               int64                           `json:"payeeId,string,omitempty"`
+	TransactionTime       int64                           `json:"transactionTime"`
+	TimezoneUtcOffset     int16                           `json:"utcOffset"`
+	Amount                int64                           `json:"amount"`
+	RelatedAccountAmount  int64                           `json:"relatedAccountAmount"`
+	OriginalCurrency      string                          `json:"originalCurrency,omitempty"`
+	OriginalAmount        int64                           `json:"originalAmount,omitempty"`
+	ExchangeRate          string                          `json:"exchangeRate,omitempty"`
+	HideAmount            bool                            `json:"hideAmount"`
+	Comment               string                          `json:"comment"`
+	TagIds                []string                        `json:"tagIds"`
+	Splits                []*TransactionSplitInfoResponse `json:"splits,omitempty"`
+	ReconcileState        TransactionReconcileState       `json:"reconcileState"`
+	RelatedReconcileState TransactionReconcileState       `json:"relatedReconcileState,omitempty"`
+	Pending               bool                            `json:"pending"`
+}
+
+// TransactionOperator represents who performs an operation on transactions
+type TransactionOperator struct {
+	UserTokenId int64
+	RequestId   string
+}
+
+// TransactionHistoryListRequest represents all parameters of transaction history listing request
+type TransactionHistoryListRequest struct {
+	Id int64 `form:"id,string" binding:"required,min=1"`
+}
+
+// TransactionHistoryInfoResponse represents a view-object of transaction history
+type TransactionHistoryInfoResponse struct {
+	Id            int64                       `json:"id,string"`
+	TransactionId int64                       `json:"transactionId,string"`
+	Operation     TransactionHistoryOperation `json:"operation"`
+	UserTokenId   int64                       `json:"userTokenId,string"`
+	RequestId     string                      `json:"requestId"`
+	Before        *TransactionHistorySnapshot `json:"before,omitempty"`
+	After         *TransactionHistorySnapshot `json:"after,omitempty"`
+	Time          int64                       `json:"time"`
+}
+
+// NewTransactionOperator returns the transaction operator of current request context
+func NewTransactionOperator(c *core.Context) *TransactionOperator {
+	operator := &TransactionOperator{
+		RequestId: c.GetRequestId(),
+	}
+
+	if claims := c.GetTokenClaims(); claims != nil {
+		operator.UserTokenId, _ = utils.StringToInt64(claims.UserTokenId)
+	}
+
+	return operator
+}
+
+// ToTransactionHistoryInfoResponse returns a view-object according to database model
+func (h *TransactionHistory) ToTransactionHistoryInfoResponse() *TransactionHistoryInfoResponse {
+	return &TransactionHistoryInfoResponse{
+		Id:            h.HistoryId,
+		TransactionId: h.TransactionId,
+		Operation:     h.Operation,
+		UserTokenId:   h.UserTokenId,
+		RequestId:     h.RequestId,
+		Before:        h.GetBeforeSnapshot(),
+		After:         h.GetAfterSnapshot(),
+		Time:          h.CreatedUnixTime,
+	}
+}
+
+// GetBeforeSnapshot returns the field values of transaction before the operation
+func (h *TransactionHistory) GetBeforeSnapshot() *TransactionHistorySnapshot {
+	return parseTransactionHistorySnapshot(h.BeforeData)
+}
+
+// GetAfterSnapshot returns the field values of transaction after the operation
+func (h *TransactionHistory) GetAfterSnapshot() *TransactionHistorySnapshot {
+	return parseTransactionHistorySnapshot(h.AfterData)
+}
+
+func parseTransactionHistorySnapshot(data string) *TransactionHistorySnapshot {
+	if data == "" {
+		return nil
+	}
+
+	snapshot := &TransactionHistorySnapshot{}
+
+	if err := json.Unmarshal([]byte(data), snapshot); err != nil {
+		return nil
+	}
+
+	return snapshot
+}
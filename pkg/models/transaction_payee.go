@@ -0,0 +1,104 @@
+package models
+
+// TransactionPayee represents transaction payee (merchant or counterparty) data stored in database
+type TransactionPayee struct {
+	PayeeId         int64  `xorm:"PK"`
+	Uid             int64  `xorm:"INDEX(IDX_payee_uid_deleted_name) NOT NULL"`
+	Deleted         bool   `xorm:"INDEX(IDX_payee_uid_deleted_name) NOT NULL"`
+	Name            string `xorm:"INDEX(IDX_payee_uid_deleted_name) VARCHAR(64) NOT NULL"`
+	Comment         string `xorm:"VARCHAR(255) NOT NULL"`
+	Hidden          bool   `xorm:"NOT NULL"`
+	CreatedUnixTime int64
+	UpdatedUnixTime int64
+	DeletedUnixTime int64
+}
+
+// TransactionPayeeGetRequest represents all parameters of transaction payee getting request
+type TransactionPayeeGetRequest struct {
+	Id int64 `form:"id,string" binding:"required,min=1"`
+}
+
+// TransactionPayeeAutocompleteRequest represents all parameters of transaction payee autocomplete request
+type TransactionPayeeAutocompleteRequest struct {
+	Prefix string `form:"prefix" binding:"required,notBlank,max=64"`
+	Count  int    `form:"count" binding:"required,min=1,max=50"`
+}
+
+// TransactionPayeeSuggestionRequest represents all parameters of transaction payee suggestion request
+type TransactionPayeeSuggestionRequest struct {
+	Id int64 `form:"id,string" binding:"required,min=1"`
+}
+
+// TransactionPayeeCreateRequest represents all parameters of transaction payee creation request
+type TransactionPayeeCreateRequest struct {
+	Name    string `json:"name" binding:"required,notBlank,max=64"`
+	Comment string `json:"comment" binding:"max=255"`
+}
+
+// TransactionPayeeModifyRequest represents all parameters of transaction payee modification request
+type TransactionPayeeModifyRequest struct {
+	Id      int64  `json:"id,string" binding:"required,min=1"`
+	Name    string `json:"name" binding:"required,notBlank,max=64"`
+	Comment string `json:"comment" binding:"max=255"`
+}
+
+// TransactionPayeeHideRequest represents all parameters of transaction payee hiding request
+type TransactionPayeeHideRequest struct {
+	Id     int64 `json:"id,string" binding:"required,min=1"`
+	Hidden bool  `json:"hidden"`
+}
+
+// TransactionPayeeMergeRequest represents all parameters of transaction payee merging request
+type TransactionPayeeMergeRequest struct {
+	FromIds []string `json:"fromIds" binding:"required,min=1,max=100"`
+	ToId    int64    `json:"toId,string" binding:"required,min=1"`
+}
+
+// TransactionPayeeDeleteRequest represents all parameters of transaction payee deleting request
+type TransactionPayeeDeleteRequest struct {
+	Id int64 `json:"id,string" binding:"required,min=1"`
+}
+
+// TransactionPayeeInfoResponse represents a view-object of transaction payee
+type TransactionPayeeInfoResponse struct {
+	Id      int64  `json:"id,string"`
+	Name    string `json:"name"`
+	Comment string `json:"comment"`
+	Hidden  bool   `json:"hidden"`
+}
+
+// TransactionPayeeSuggestionResponse represents the category and account which were used in the latest transaction of the payee
+type TransactionPayeeSuggestionResponse struct {
+	PayeeId    int64           `json:"payeeId,string"`
+	Type       TransactionType `json:"type,omitempty"`
+	CategoryId int64           `json:"categoryId,string,omitempty"`
+	AccountId  int64           `json:"accountId,string,omitempty"`
+}
+
+// ToTransactionPayeeInfoResponse returns a view-object according to database model
+func (p *TransactionPayee) ToTransactionPayeeInfoResponse() *TransactionPayeeInfoResponse {
+	return &TransactionPayeeInfoResponse{
+		Id:      p.PayeeId,
+		Name:    p.Name,
+		Comment: p.Comment,
+		Hidden:  p.Hidden,
+	}
+}
+
+// TransactionPayeeInfoResponseSlice represents the slice data structure of TransactionPayeeInfoResponse
+type TransactionPayeeInfoResponseSlice []*TransactionPayeeInfoResponse
+
+// Len returns the count of items
+func (s TransactionPayeeInfoResponseSlice) Len() int {
+	return len(s)
+}
+
+// Swap swaps two items
+func (s TransactionPayeeInfoResponseSlice) Swap(i, j int) {
+	s[i], s[j] = s[j], s[i]
+}
+
+// Less reports whether the first item is less than the second one
+func (s TransactionPayeeInfoResponseSlice) Less(i, j int) bool {
+	return s[i].Name < s[j].Name
+}
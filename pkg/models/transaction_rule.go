@@ -0,0 +1,181 @@
+package models
+
+import (
+	"strings"
+)
+
+// TransactionRule represents transaction rule data stored in database
+type TransactionRule struct {
+	RuleId             int64             `xorm:"PK"`
+	Uid                int64             `xorm:"INDEX(IDX_transaction_rule_uid_deleted_priority) NOT NULL"`
+	Deleted            bool              `xorm:"INDEX(IDX_transaction_rule_uid_deleted_priority) NOT NULL"`
+	Priority           int               `xorm:"INDEX(IDX_transaction_rule_uid_deleted_priority) NOT NULL"`
+	Name               string            `xorm:"VARCHAR(32) NOT NULL"`
+	Disabled           bool              `xorm:"NOT NULL"`
+	ConditionType      TransactionDbType `xorm:"NOT NULL DEFAULT 0"`
+	ConditionComment   string            `xorm:"VARCHAR(255) NOT NULL DEFAULT ''"`
+	ConditionPayeeId   int64             `xorm:"NOT NULL DEFAULT 0"`
+	ConditionAccountId int64             `xorm:"NOT NULL DEFAULT 0"`
+	ConditionMinAmount *int64            `xorm:"NULL"`
+	ConditionMaxAmount *int64            `xorm:"NULL"`
+	ActionCategoryId   int64             `xorm:"NOT NULL DEFAULT 0"`
+	ActionTagIds       string            `xorm:"VARCHAR(1000) NOT NULL DEFAULT ''"`
+	ActionHideAmount   bool              `xorm:"NOT NULL DEFAULT false"`
+	CreatedUnixTime    int64
+	UpdatedUnixTime    int64
+	DeletedUnixTime    int64
+}
+
+// TransactionRuleGetRequest represents all parameters of transaction rule getting request
+type TransactionRuleGetRequest struct {
+	Id int64 `form:"id,string" binding:"required,min=1"`
+}
+
+// TransactionRuleCreateRequest represents all parameters of transaction rule creation request
+type TransactionRuleCreateRequest struct {
+	Name               string          `json:"name" binding:"required,notBlank,max=32"`
+	Priority           int             `json:"priority" binding:"min=0"`
+	Disabled           bool            `json:"disabled"`
+	ConditionType      TransactionType `json:"conditionType" binding:"min=0,max=4"`
+	ConditionComment   string          `json:"conditionComment" binding:"max=255"`
+	ConditionPayeeId   int64           `json:"conditionPayeeId,string" binding:"min=0"`
+	ConditionAccountId int64           `json:"conditionAccountId,string" binding:"min=0"`
+	ConditionMinAmount *int64          `json:"conditionMinAmount" binding:"omitempty,min=-99999999999,max=99999999999"`
+	ConditionMaxAmount *int64          `json:"conditionMaxAmount" binding:"omitempty,min=-99999999999,max=99999999999"`
+	ActionCategoryId   int64           `json:"actionCategoryId,string" binding:"min=0"`
+	ActionTagIds       []string        `json:"actionTagIds"`
+	ActionHideAmount   bool            `json:"actionHideAmount"`
+}
+
+// TransactionRuleModifyRequest represents all parameters of transaction rule modification request
+type TransactionRuleModifyRequest struct {
+	Id                 int64           `json:"id,string" binding:"required,min=1"`
+	Name               string          `json:"name" binding:"required,notBlank,max=32"`
+	Priority           int             `json:"priority" binding:"min=0"`
+	Disabled           bool            `json:"disabled"`
+	ConditionType      TransactionType `json:"conditionType" binding:"min=0,max=4"`
+	ConditionComment   string          `json:"conditionComment" binding:"max=255"`
+	ConditionPayeeId   int64           `json:"conditionPayeeId,string" binding:"min=0"`
+	ConditionAccountId int64           `json:"conditionAccountId,string" binding:"min=0"`
+	ConditionMinAmount *int64          `json:"conditionMinAmount" binding:"omitempty,min=-99999999999,max=99999999999"`
+	ConditionMaxAmount *int64          `json:"conditionMaxAmount" binding:"omitempty,min=-99999999999,max=99999999999"`
+	ActionCategoryId   int64           `json:"actionCategoryId,string" binding:"min=0"`
+	ActionTagIds       []string        `json:"actionTagIds"`
+	ActionHideAmount   bool            `json:"actionHideAmount"`
+}
+
+// TransactionRuleDeleteRequest represents all parameters of transaction rule deleting request
+type TransactionRuleDeleteRequest struct {
+	Id int64 `json:"id,string" binding:"required,min=1"`
+}
+
+// TransactionRuleApplyRequest represents all parameters of applying transaction rules to existed transactions request
+type TransactionRuleApplyRequest struct {
+	RuleIds   []string `json:"ruleIds"`
+	StartTime int64    `json:"startTime" binding:"min=0"`
+	EndTime   int64    `json:"endTime" binding:"min=0"`
+	DryRun    bool     `json:"dryRun"`
+}
+
+// TransactionRuleInfoResponse represents a view-object of transaction rule
+type TransactionRuleInfoResponse struct {
+	Id                 int64           `json:"id,string"`
+	Name               string          `json:"name"`
+	Priority           int             `json:"priority"`
+	Disabled           bool            `json:"disabled"`
+	ConditionType      TransactionType `json:"conditionType,omitempty"`
+	ConditionComment   string          `json:"conditionComment,omitempty"`
+	ConditionPayeeId   int64           `json:"conditionPayeeId,string,omitempty"`
+	ConditionAccountId int64           `json:"conditionAccountId,string,omitempty"`
+	ConditionMinAmount *int64          `json:"conditionMinAmount,omitempty"`
+	ConditionMaxAmount *int64          `json:"conditionMaxAmount,omitempty"`
+	ActionCategoryId   int64           `json:"actionCategoryId,string,omitempty"`
+	ActionTagIds       []string        `json:"actionTagIds"`
+	ActionHideAmount   bool            `json:"actionHideAmount"`
+}
+
+// TransactionRuleApplyResponse represents the result of applying transaction rules to existed transactions
+type TransactionRuleApplyResponse struct {
+	DryRun bool                                `json:"dryRun"`
+	Items  []*TransactionRuleApplyResponseItem `json:"items"`
+}
+
+// TransactionRuleApplyResponseItem represents the changes of a transaction made by transaction rules
+type TransactionRuleApplyResponseItem struct {
+	TransactionId  int64    `json:"transactionId,string"`
+	MatchedRuleIds []string `json:"matchedRuleIds"`
+	CategoryId     int64    `json:"categoryId,string,omitempty"`
+	AddTagIds      []string `json:"addTagIds,omitempty"`
+	HideAmount     bool     `json:"hideAmount,omitempty"`
+}
+
+// GetConditionTransactionType returns the transaction type of the type condition of this transaction rule
+func (r *TransactionRule) GetConditionTransactionType() TransactionType {
+	if r.ConditionType == TRANSACTION_DB_TYPE_MODIFY_BALANCE {
+		return TRANSACTION_TYPE_MODIFY_BALANCE
+	} else if r.ConditionType == TRANSACTION_DB_TYPE_EXPENSE {
+		return TRANSACTION_TYPE_EXPENSE
+	} else if r.ConditionType == TRANSACTION_DB_TYPE_INCOME {
+		return TRANSACTION_TYPE_INCOME
+	} else if r.ConditionType == TRANSACTION_DB_TYPE_TRANSFER_OUT {
+		return TRANSACTION_TYPE_TRANSFER
+	}
+
+	return 0
+}
+
+// GetActionTagIdStrings returns the tag ids which would be added by this transaction rule in string format
+func (r *TransactionRule) GetActionTagIdStrings() []string {
+	if r.ActionTagIds == "" {
+		return make([]string, 0)
+	}
+
+	return strings.Split(r.ActionTagIds, ",")
+}
+
+// HasCondition returns whether this transaction rule has any condition
+func (r *TransactionRule) HasCondition() bool {
+	return r.ConditionType != 0 || r.ConditionComment != "" || r.ConditionPayeeId != 0 || r.ConditionAccountId != 0 || r.ConditionMinAmount != nil || r.ConditionMaxAmount != nil
+}
+
+// HasAction returns whether this transaction rule has any action
+func (r *TransactionRule) HasAction() bool {
+	return r.ActionCategoryId != 0 || r.ActionTagIds != "" || r.ActionHideAmount
+}
+
+// ToTransactionRuleInfoResponse returns a view-object according to database model
+func (r *TransactionRule) ToTransactionRuleInfoResponse() *TransactionRuleInfoResponse {
+	return &TransactionRuleInfoResponse{
+		Id:                 r.RuleId,
+		Name:               r.Name,
+		Priority:           r.Priority,
+		Disabled:           r.Disabled,
+		ConditionType:      r.GetConditionTransactionType(),
+		ConditionComment:   r.ConditionComment,
+		ConditionPayeeId:   r.ConditionPayeeId,
+		ConditionAccountId: r.ConditionAccountId,
+		ConditionMinAmount: r.ConditionMinAmount,
+		ConditionMaxAmount: r.ConditionMaxAmount,
+		ActionCategoryId:   r.ActionCategoryId,
+		ActionTagIds:       r.GetActionTagIdStrings(),
+		ActionHideAmount:   r.ActionHideAmount,
+	}
+}
+
+// TransactionRuleInfoResponseSlice represents the slice data structure of TransactionRuleInfoResponse
+type TransactionRuleInfoResponseSlice []*TransactionRuleInfoResponse
+
+// Len returns the count of items
+func (s TransactionRuleInfoResponseSlice) Len() int {
+	return len(s)
+}
+
+// Swap swaps two items
+func (s TransactionRuleInfoResponseSlice) Swap(i, j int) {
+	s[i], s[j] = s[j], s[i]
+}
+
+// Less reports whether the first item is less than the second one
+func (s TransactionRuleInfoResponseSlice) Less(i, j int) bool {
+	return s[i].Priority < s[j].Priority
+}
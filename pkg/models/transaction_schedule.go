@@ -0,0 +1,257 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/mayswind/ezbookkeeping/pkg/utils"
+)
+
+// TransactionScheduleFrequency represents the frequency of transaction schedule
+type TransactionScheduleFrequency byte
+
+// Transaction schedule frequencies
+const (
+	TRANSACTION_SCHEDULE_FREQUENCY_DAILY   TransactionScheduleFrequency = 1
+	TRANSACTION_SCHEDULE_FREQUENCY_WEEKLY  TransactionScheduleFrequency = 2
+	TRANSACTION_SCHEDULE_FREQUENCY_MONTHLY TransactionScheduleFrequency = 3
+	TRANSACTION_SCHEDULE_FREQUENCY_YEARLY  TransactionScheduleFrequency = 4
+)
+
+// TransactionSchedule represents transaction schedule data stored in database
+type TransactionSchedule struct {
+	ScheduleId           int64                        `xorm:"PK"`
+	Uid                  int64                        `xorm:"INDEX(IDX_transaction_schedule_uid_deleted) NOT NULL"`
+	Deleted              bool                         `xorm:"INDEX(IDX_transaction_schedule_uid_deleted) INDEX(IDX_transaction_schedule_deleted_next_unix_time) NOT NULL"`
+	Type                 TransactionDbType            `xorm:"NOT NULL"`
+	CategoryId           int64                        `xorm:"NOT NULL"`
+	AccountId            int64                        `xorm:"NOT NULL"`
+	RelatedAccountId     int64                        `xorm:"NOT NULL"`
+	Amount               int64                        `xorm:"NOT NULL"`
+	RelatedAccountAmount int64                        `xorm:"NOT NULL"`
+	HideAmount           bool                         `xorm:"NOT NULL"`
+	TagIds               string                       `xorm:"VARCHAR(1000) NOT NULL"`
+	Comment              string                       `xorm:"VARCHAR(255) NOT NULL"`
+	TimezoneUtcOffset    int16                        `xorm:"NOT NULL"`
+	Frequency            TransactionScheduleFrequency `xorm:"TINYINT NOT NULL"`
+	FrequencyInterval    int                          `xorm:"NOT NULL"`
+	StartUnixTime        int64                        `xorm:"NOT NULL"`
+	EndUnixTime          int64                        `xorm:"NOT NULL"`
+	NextUnixTime         int64                        `xorm:"INDEX(IDX_transaction_schedule_deleted_next_unix_time) NOT NULL"`
+	ExecutedCount        int                          `xorm:"NOT NULL"`
+	CreatedUnixTime      int64
+	UpdatedUnixTime      int64
+	DeletedUnixTime      int64
+}
+
+// TransactionScheduleGetRequest represents all parameters of transaction schedule getting request
+type TransactionScheduleGetRequest struct {
+	Id int64 `form:"id,string" binding:"required,min=1"`
+}
+
+// TransactionScheduleCreateRequest represents all parameters of transaction schedule creation request
+type TransactionScheduleCreateRequest struct {
+	Type                 TransactionType              `json:"type" binding:"required"`
+	CategoryId           int64                        `json:"categoryId,string"`
+	UtcOffset            int16                        `json:"utcOffset" binding:"min=-720,max=840"`
+	SourceAccountId      int64                        `json:"sourceAccountId,string" binding:"required,min=1"`
+	DestinationAccountId int64                        `json:"destinationAccountId,string" binding:"min=0"`
+	SourceAmount         int64                        `json:"sourceAmount" binding:"min=-99999999999,max=99999999999"`
+	DestinationAmount    int64                        `json:"destinationAmount" binding:"min=-99999999999,max=99999999999"`
+	HideAmount           bool                         `json:"hideAmount"`
+	TagIds               []string                     `json:"tagIds"`
+	Comment              string                       `json:"comment" binding:"max=255"`
+	Frequency            TransactionScheduleFrequency `json:"frequency" binding:"required"`
+	Interval             int                          `json:"interval" binding:"required,min=1,max=1000"`
+	StartTime            int64                        `json:"startTime" binding:"required,min=1"`
+	EndTime              int64                        `json:"endTime" binding:"min=0"`
+}
+
+// TransactionScheduleModifyRequest represents all parameters of transaction schedule modification request
+type TransactionScheduleModifyRequest struct {
+	Id                   int64                        `json:"id,string" binding:"required,min=1"`
+	CategoryId           int64                        `json:"categoryId,string"`
+	UtcOffset            int16                        `json:"utcOffset" binding:"min=-720,max=840"`
+	SourceAccountId      int64                        `json:"sourceAccountId,string" binding:"required,min=1"`
+	DestinationAccountId int64                        `json:"destinationAccountId,string" binding:"min=0"`
+	SourceAmount         int64                        `json:"sourceAmount" binding:"min=-99999999999,max=99999999999"`
+	DestinationAmount    int64                        `json:"destinationAmount" binding:"min=-99999999999,max=99999999999"`
+	HideAmount           bool                         `json:"hideAmount"`
+	TagIds               []string                     `json:"tagIds"`
+	Comment              string                       `json:"comment" binding:"max=255"`
+	Frequency            TransactionScheduleFrequency `json:"frequency" binding:"required"`
+	Interval             int                          `json:"interval" binding:"required,min=1,max=1000"`
+	StartTime            int64                        `json:"startTime" binding:"required,min=1"`
+	EndTime              int64                        `json:"endTime" binding:"min=0"`
+}
+
+// TransactionScheduleDeleteRequest represents all parameters of transaction schedule deleting request
+type TransactionScheduleDeleteRequest struct {
+	Id int64 `json:"id,string" binding:"required,min=1"`
+}
+
+// TransactionScheduleInfoResponse represents a view-object of transaction schedule
+type TransactionScheduleInfoResponse struct {
+	Id                   int64                        `json:"id,string"`
+	Type                 TransactionType              `json:"type"`
+	CategoryId           int64                        `json:"categoryId,string"`
+	UtcOffset            int16                        `json:"utcOffset"`
+	SourceAccountId      int64                        `json:"sourceAccountId,string"`
+	DestinationAccountId int64                        `json:"destinationAccountId,string,omitempty"`
+	SourceAmount         int64                        `json:"sourceAmount"`
+	DestinationAmount    int64                        `json:"destinationAmount,omitempty"`
+	HideAmount           bool                         `json:"hideAmount"`
+	TagIds               []string                     `json:"tagIds"`
+	Comment              string                       `json:"comment"`
+	Frequency            TransactionScheduleFrequency `json:"frequency"`
+	Interval             int                          `json:"interval"`
+	StartTime            int64                        `json:"startTime"`
+	EndTime              int64                        `json:"endTime,omitempty"`
+	NextTime             int64                        `json:"nextTime,omitempty"`
+	ExecutedCount        int                          `json:"executedCount"`
+}
+
+// IsFrequencyValid returns whether the frequency of this transaction schedule is valid
+func (s *TransactionSchedule) IsFrequencyValid() bool {
+	return s.Frequency >= TRANSACTION_SCHEDULE_FREQUENCY_DAILY && s.Frequency <= TRANSACTION_SCHEDULE_FREQUENCY_YEARLY && s.FrequencyInterval > 0
+}
+
+// GetOccurrenceUnixTime returns the unix time of the specified occurrence (starts from 0) of this transaction schedule
+func (s *TransactionSchedule) GetOccurrenceUnixTime(index int) int64 {
+	timezone := time.FixedZone("Timezone", int(s.TimezoneUtcOffset)*60)
+	startTime := time.Unix(s.StartUnixTime, 0).In(timezone)
+	periods := index * s.FrequencyInterval
+
+	if s.Frequency == TRANSACTION_SCHEDULE_FREQUENCY_DAILY {
+		return startTime.AddDate(0, 0, periods).Unix()
+	} else if s.Frequency == TRANSACTION_SCHEDULE_FREQUENCY_WEEKLY {
+		return startTime.AddDate(0, 0, 7*periods).Unix()
+	} else if s.Frequency == TRANSACTION_SCHEDULE_FREQUENCY_MONTHLY {
+		return utils.AddMonthsWithoutOverflow(startTime, periods).Unix()
+	} else if s.Frequency == TRANSACTION_SCHEDULE_FREQUENCY_YEARLY {
+		return utils.AddMonthsWithoutOverflow(startTime, 12*periods).Unix()
+	}
+
+	return 0
+}
+
+// GetOccurrenceCountUntilUnixTime returns the count of occurrences of this transaction schedule which are not later than the specified unix time
+func (s *TransactionSchedule) GetOccurrenceCountUntilUnixTime(unixTime int64) int {
+	if !s.IsFrequencyValid() {
+		return 0
+	}
+
+	count := 0
+
+	for s.GetOccurrenceUnixTime(count) <= unixTime {
+		count++
+	}
+
+	return count
+}
+
+// GetNextUnixTime returns the unix time of the next occurrence which has not been executed, or 0 if the transaction schedule has been finished
+func (s *TransactionSchedule) GetNextUnixTime() int64 {
+	nextUnixTime := s.GetOccurrenceUnixTime(s.ExecutedCount)
+
+	if s.EndUnixTime > 0 && nextUnixTime > s.EndUnixTime {
+		return 0
+	}
+
+	return nextUnixTime
+}
+
+// GetTagIds returns the tag ids of this transaction schedule
+func (s *TransactionSchedule) GetTagIds() ([]int64, error) {
+	if s.TagIds == "" {
+		return []int64{}, nil
+	}
+
+	return utils.StringArrayToInt64Array(strings.Split(s.TagIds, ","))
+}
+
+// ToTransaction returns a new transaction model of the specified unix time according to this transaction schedule
+func (s *TransactionSchedule) ToTransaction(unixTime int64) *Transaction {
+	return &Transaction{
+		Uid:                  s.Uid,
+		Type:                 s.Type,
+		CategoryId:           s.CategoryId,
+		TransactionTime:      utils.GetMinTransactionTimeFromUnixTime(unixTime),
+		TimezoneUtcOffset:    s.TimezoneUtcOffset,
+		AccountId:            s.AccountId,
+		Amount:               s.Amount,
+		RelatedAccountId:     s.RelatedAccountId,
+		RelatedAccountAmount: s.RelatedAccountAmount,
+		HideAmount:           s.HideAmount,
+		Comment:              s.Comment,
+	}
+}
+
+// ToTransactionScheduleInfoResponse returns a view-object according to database model
+func (s *TransactionSchedule) ToTransactionScheduleInfoResponse() *TransactionScheduleInfoResponse {
+	var transactionType TransactionType
+
+	if s.Type == TRANSACTION_DB_TYPE_EXPENSE {
+		transactionType = TRANSACTION_TYPE_EXPENSE
+	} else if s.Type == TRANSACTION_DB_TYPE_INCOME {
+		transactionType = TRANSACTION_TYPE_INCOME
+	} else if s.Type == TRANSACTION_DB_TYPE_TRANSFER_OUT {
+		transactionType = TRANSACTION_TYPE_TRANSFER
+	} else {
+		return nil
+	}
+
+	tagIds := make([]string, 0)
+
+	if s.TagIds != "" {
+		tagIds = strings.Split(s.TagIds, ",")
+	}
+
+	return &TransactionScheduleInfoResponse{
+		Id:                   s.ScheduleId,
+		Type:                 transactionType,
+		CategoryId:           s.CategoryId,
+		UtcOffset:            s.TimezoneUtcOffset,
+		SourceAccountId:      s.AccountId,
+		DestinationAccountId: s.RelatedAccountId,
+		SourceAmount:         s.Amount,
+		DestinationAmount:    s.RelatedAccountAmount,
+		HideAmount:           s.HideAmount,
+		TagIds:               tagIds,
+		Comment:              s.Comment,
+		Frequency:            s.Frequency,
+		Interval:             s.FrequencyInterval,
+		StartTime:            s.StartUnixTime,
+		EndTime:              s.EndUnixTime,
+		NextTime:             s.NextUnixTime,
+		ExecutedCount:        s.ExecutedCount,
+	}
+}
+
+// TransactionScheduleInfoResponseSlice represents the slice data structure of TransactionScheduleInfoResponse
+type TransactionScheduleInfoResponseSlice []*TransactionScheduleInfoResponse
+
+// Len returns the count of items
+func (s TransactionScheduleInfoResponseSlice) Len() int {
+	return len(s)
+}
+
+// Swap swaps two items
+func (s TransactionScheduleInfoResponseSlice) Swap(i, j int) {
+	s[i], s[j] = s[j], s[i]
+}
+
+// Less reports whether the first item is less than the second one
+func (s TransactionScheduleInfoResponseSlice) Less(i, j int) bool {
+	if s[i].NextTime != s[j].NextTime {
+		if s[i].NextTime == 0 {
+			return false
+		} else if s[j].NextTime == 0 {
+			return true
+		}
+
+		return s[i].NextTime < s[j].NextTime
+	}
+
+	return s[i].StartTime < s[j].StartTime
+}
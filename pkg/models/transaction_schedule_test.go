@@ -0,0 +1,126 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransactionScheduleGetOccurrenceUnixTime(t *testing.T) {
+	testCases := []struct {
+		frequency TransactionScheduleFrequency
+		interval  int
+		index     int
+		expected  int64
+	}{
+		{TRANSACTION_SCHEDULE_FREQUENCY_DAILY, 1, 0, 1704110400},   // 2024-01-01 12:00:00 UTC
+		{TRANSACTION_SCHEDULE_FREQUENCY_DAILY, 3, 2, 1704628800},   // 2024-01-07 12:00:00 UTC
+		{TRANSACTION_SCHEDULE_FREQUENCY_WEEKLY, 2, 1, 1705320000},  // 2024-01-15 12:00:00 UTC
+		{TRANSACTION_SCHEDULE_FREQUENCY_MONTHLY, 1, 1, 1706788800}, // 2024-02-01 12:00:00 UTC
+		{TRANSACTION_SCHEDULE_FREQUENCY_MONTHLY, 6, 2, 1735732800}, // 2025-01-01 12:00:00 UTC
+		{TRANSACTION_SCHEDULE_FREQUENCY_YEARLY, 1, 1, 1735732800},  // 2025-01-01 12:00:00 UTC
+	}
+
+	for _, testCase := range testCases {
+		schedule := &TransactionSchedule{
+			Frequency:         testCase.frequency,
+			FrequencyInterval: testCase.interval,
+			StartUnixTime:     1704110400, // 2024-01-01 12:00:00 UTC
+		}
+
+		actualValue := schedule.GetOccurrenceUnixTime(testCase.index)
+		assert.Equal(t, testCase.expected, actualValue)
+	}
+}
+
+func TestTransactionScheduleGetOccurrenceUnixTime_MonthEndWithoutOverflow(t *testing.T) {
+	schedule := &TransactionSchedule{
+		TimezoneUtcOffset: 480,
+		Frequency:         TRANSACTION_SCHEDULE_FREQUENCY_MONTHLY,
+		FrequencyInterval: 1,
+		StartUnixTime:     1706673600, // 2024-01-31 12:00:00 UTC+8
+	}
+
+	expectedValue := int64(1709179200) // 2024-02-29 12:00:00 UTC+8
+	actualValue := schedule.GetOccurrenceUnixTime(1)
+	assert.Equal(t, expectedValue, actualValue)
+
+	expectedValue = int64(1711857600) // 2024-03-31 12:00:00 UTC+8
+	actualValue = schedule.GetOccurrenceUnixTime(2)
+	assert.Equal(t, expectedValue, actualValue)
+}
+
+func TestTransactionScheduleGetNextUnixTime(t *testing.T) {
+	testCases := []struct {
+		executedCount int
+		endUnixTime   int64
+		expected      int64
+	}{
+		{0, 0, 1704110400},          // 2024-01-01 12:00:00 UTC
+		{2, 0, 1706529600},          // 2024-01-29 12:00:00 UTC
+		{2, 1706529600, 1706529600}, // the end time is inclusive
+		{3, 1706529600, 0},          // finished
+	}
+
+	for _, testCase := range testCases {
+		schedule := &TransactionSchedule{
+			Frequency:         TRANSACTION_SCHEDULE_FREQUENCY_WEEKLY,
+			FrequencyInterval: 2,
+			StartUnixTime:     1704110400, // 2024-01-01 12:00:00 UTC
+			EndUnixTime:       testCase.endUnixTime,
+			ExecutedCount:     testCase.executedCount,
+		}
+
+		actualValue := schedule.GetNextUnixTime()
+		assert.Equal(t, testCase.expected, actualValue)
+	}
+}
+
+func TestTransactionScheduleGetOccurrenceCountUntilUnixTime(t *testing.T) {
+	schedule := &TransactionSchedule{
+		Frequency:         TRANSACTION_SCHEDULE_FREQUENCY_DAILY,
+		FrequencyInterval: 1,
+		StartUnixTime:     1704110400, // 2024-01-01 12:00:00 UTC
+	}
+
+	testCases := []struct {
+		unixTime int64
+		expected int
+	}{
+		{1704110399, 0}, // before the first occurrence
+		{1704110400, 1}, // exactly the first occurrence
+		{1704283200, 3}, // 2024-01-03 12:00:00 UTC
+		{1704369599, 3}, // 2024-01-04 11:59:59 UTC
+	}
+
+	for _, testCase := range testCases {
+		actualValue := schedule.GetOccurrenceCountUntilUnixTime(testCase.unixTime)
+		assert.Equal(t, testCase.expected, actualValue)
+	}
+
+	schedule.FrequencyInterval = 0
+	assert.Equal(t, 0, schedule.GetOccurrenceCountUntilUnixTime(1704283200))
+}
+
+func TestTransactionScheduleGetOccurrenceCountUntilUnixTime_RecurrenceChanged(t *testing.T) {
+	oldSchedule := &TransactionSchedule{
+		Frequency:         TRANSACTION_SCHEDULE_FREQUENCY_WEEKLY,
+		FrequencyInterval: 1,
+		StartUnixTime:     1704110400, // 2024-01-01 12:00:00 UTC
+		ExecutedCount:     3,
+	}
+
+	newSchedule := &TransactionSchedule{
+		Frequency:         TRANSACTION_SCHEDULE_FREQUENCY_DAILY,
+		FrequencyInterval: 4,
+		StartUnixTime:     1704110400, // 2024-01-01 12:00:00 UTC
+	}
+
+	lastExecutedUnixTime := oldSchedule.GetOccurrenceUnixTime(oldSchedule.ExecutedCount - 1) // 2024-01-15 12:00:00 UTC
+	newSchedule.ExecutedCount = newSchedule.GetOccurrenceCountUntilUnixTime(lastExecutedUnixTime)
+	assert.Equal(t, 4, newSchedule.ExecutedCount)
+
+	expectedValue := int64(1705492800) // 2024-01-17 12:00:00 UTC
+	actualValue := newSchedule.GetNextUnixTime()
+	assert.Equal(t, expectedValue, actualValue)
+}
@@ -0,0 +1,58 @@
+package models
+
+// TransactionSplit represents a category line of split transaction stored in database
+type TransactionSplit struct {
+	SplitId         int64  `xorm:"PK"`
+	Uid             int64  `xorm:"INDEX(IDX_transaction_split_uid_deleted_transaction_id) INDEX(IDX_transaction_split_uid_deleted_category_id) INDEX(IDX_transaction_split_uid_deleted_transaction_time) NOT NULL"`
+	Deleted         bool   `xorm:"INDEX(IDX_transaction_split_uid_deleted_transaction_id) INDEX(IDX_transaction_split_uid_deleted_category_id) INDEX(IDX_transaction_split_uid_deleted_transaction_time) NOT NULL"`
+	TransactionId   int64  `xorm:"INDEX(IDX_transaction_split_uid_deleted_transaction_id) NOT NULL"`
+	TransactionTime int64  `xorm:"INDEX(IDX_transaction_split_uid_deleted_transaction_time) NOT NULL"`
+	CategoryId      int64  `xorm:"INDEX(IDX_transaction_split_uid_deleted_category_id) NOT NULL"`
+	Amount          int64  `xorm:"NOT NULL"`
+	Comment         string `xorm:"VARCHAR(255) NOT NULL"`
+	DisplayOrder    int    `xorm:"NOT NULL"`
+	CreatedUnixTime int64
+	UpdatedUnixTime int64
+	DeletedUnixTime int64
+}
+
+// TransactionSplitRequest represents all parameters of a split line in transaction creation or modification request
+type TransactionSplitRequest struct {
+	CategoryId int64  `json:"categoryId,string" binding:"required,min=1"`
+	Amount     int64  `json:"amount" binding:"min=-99999999999,max=99999999999"`
+	Comment    string `json:"comment" binding:"max=255"`
+}
+
+// TransactionSplitInfoResponse represents a view-object of transaction split line
+type TransactionSplitInfoResponse struct {
+	CategoryId int64  `json:"categoryId,string"`
+	Amount     int64  `json:"amount"`
+	Comment    string `json:"comment"`
+}
+
+// ToTransactionSplitInfoResponse returns a view-object according to database model
+func (s *TransactionSplit) ToTransactionSplitInfoResponse() *TransactionSplitInfoResponse {
+	return &TransactionSplitInfoResponse{
+		CategoryId: s.CategoryId,
+		Amount:     s.Amount,
+		Comment:    s.Comment,
+	}
+}
+
+// TransactionSplitSlice represents the slice data structure of TransactionSplit
+type TransactionSplitSlice []*TransactionSplit
+
+// Len returns the count of items
+func (s TransactionSplitSlice) Len() int {
+	return len(s)
+}
+
+// Swap swaps two items
+func (s TransactionSplitSlice) Swap(i, j int) {
+	s[i], s[j] = s[j], s[i]
+}
+
+// Less reports whether the first item is less than the second one
+func (s TransactionSplitSlice) Less(i, j int) bool {
+	return s[i].DisplayOrder < s[j].DisplayOrder
+}
@@ -46,6 +46,12 @@ type TransactionTagNewDisplayOrderRequest struct {
 	DisplayOrder int   `json:"displayOrder"`
 }
 
+// TransactionTagMergeRequest represents all parameters of transaction tag merging request
+type TransactionTagMergeRequest struct {
+	FromIds []string `json:"fromIds" binding:"required,min=1,max=100"`
+	ToId    int64    `json:"toId,string" binding:"required,min=1"`
+}
+
 // TransactionTagDeleteRequest represents all parameters of transaction tag deleting request
 type TransactionTagDeleteRequest struct {
 	Id int64 `json:"id,string" binding:"required,min=1"`
@@ -0,0 +1,161 @@
+package models
+
+import (
+	"strings"
+)
+
+// TransactionTemplate represents transaction template data stored in database
+type TransactionTemplate struct {
+	TemplateId           int64             `xorm:"PK"`
+	Uid                  int64             `xorm:"INDEX(IDX_transaction_template_uid_deleted_name) NOT NULL"`
+	Deleted              bool              `xorm:"INDEX(IDX_transaction_template_uid_deleted_name) NOT NULL"`
+	Name                 string            `xorm:"INDEX(IDX_transaction_template_uid_deleted_name) VARCHAR(32) NOT NULL"`
+	Type                 TransactionDbType `xorm:"NOT NULL"`
+	CategoryId           int64             `xorm:"NOT NULL"`
+	AccountId            int64             `xorm:"NOT NULL"`
+	RelatedAccountId     int64             `xorm:"NOT NULL"`
+	Amount               int64             `xorm:"NOT NULL"`
+	RelatedAccountAmount int64             `xorm:"NOT NULL"`
+	HideAmount           bool              `xorm:"NOT NULL"`
+	TagIds               string            `xorm:"VARCHAR(1000) NOT NULL"`
+	Comment              string            `xorm:"VARCHAR(255) NOT NULL"`
+	CreatedUnixTime      int64
+	UpdatedUnixTime      int64
+	DeletedUnixTime      int64
+}
+
+// TransactionTemplateGetRequest represents all parameters of transaction template getting request
+type TransactionTemplateGetRequest struct {
+	Id int64 `form:"id,string" binding:"required,min=1"`
+}
+
+// TransactionTemplateCreateRequest represents all parameters of transaction template creation request
+type TransactionTemplateCreateRequest struct {
+	Name                 string          `json:"name" binding:"required,notBlank,max=32"`
+	Type                 TransactionType `json:"type" binding:"required"`
+	CategoryId           int64           `json:"categoryId,string"`
+	SourceAccountId      int64           `json:"sourceAccountId,string" binding:"required,min=1"`
+	DestinationAccountId int64           `json:"destinationAccountId,string" binding:"min=0"`
+	SourceAmount         int64           `json:"sourceAmount" binding:"min=-99999999999,max=99999999999"`
+	DestinationAmount    int64           `json:"destinationAmount" binding:"min=-99999999999,max=99999999999"`
+	HideAmount           bool            `json:"hideAmount"`
+	TagIds               []string        `json:"tagIds"`
+	Comment              string          `json:"comment" binding:"max=255"`
+}
+
+// TransactionTemplateModifyRequest represents all parameters of transaction template modification request
+type TransactionTemplateModifyRequest struct {
+	Id                   int64    `json:"id,string" binding:"required,min=1"`
+	Name                 string   `json:"name" binding:"required,notBlank,max=32"`
+	CategoryId           int64    `json:"categoryId,string"`
+	SourceAccountId      int64    `json:"sourceAccountId,string" binding:"required,min=1"`
+	DestinationAccountId int64    `json:"destinationAccountId,string" binding:"min=0"`
+	SourceAmount         int64    `json:"sourceAmount" binding:"min=-99999999999,max=99999999999"`
+	DestinationAmount    int64    `json:"destinationAmount" binding:"min=-99999999999,max=99999999999"`
+	HideAmount           bool     `json:"hideAmount"`
+	TagIds               []string `json:"tagIds"`
+	Comment              string   `json:"comment" binding:"max=255"`
+}
+
+// TransactionTemplateApplyRequest represents all parameters of creating transaction by transaction template request
+type TransactionTemplateApplyRequest struct {
+	Id        int64 `json:"id,string" binding:"required,min=1"`
+	Time      int64 `json:"time" binding:"min=0"`
+	UtcOffset int16 `json:"utcOffset" binding:"min=-720,max=840"`
+}
+
+// TransactionTemplateDeleteRequest represents all parameters of transaction template deleting request
+type TransactionTemplateDeleteRequest struct {
+	Id int64 `json:"id,string" binding:"required,min=1"`
+}
+
+// TransactionTemplateInfoResponse represents a view-object of transaction template
+type TransactionTemplateInfoResponse struct {
+	Id                   int64           `json:"id,string"`
+	Name                 string          `json:"name"`
+	Type                 TransactionType `json:"type"`
+	CategoryId           int64           `json:"categoryId,string"`
+	SourceAccountId      int64           `json:"sourceAccountId,string"`
+	DestinationAccountId int64           `json:"destinationAccountId,string,omitempty"`
+	SourceAmount         int64           `json:"sourceAmount"`
+	DestinationAmount    int64           `json:"destinationAmount,omitempty"`
+	HideAmount           bool            `json:"hideAmount"`
+	TagIds               []string        `json:"tagIds"`
+	Comment              string          `json:"comment"`
+}
+
+// GetTransactionType returns the transaction type of this transaction template
+func (t *TransactionTemplate) GetTransactionType() TransactionType {
+	if t.Type == TRANSACTION_DB_TYPE_MODIFY_BALANCE {
+		return TRANSACTION_TYPE_MODIFY_BALANCE
+	} else if t.Type == TRANSACTION_DB_TYPE_EXPENSE {
+		return TRANSACTION_TYPE_EXPENSE
+	} else if t.Type == TRANSACTION_DB_TYPE_INCOME {
+		return TRANSACTION_TYPE_INCOME
+	} else if t.Type == TRANSACTION_DB_TYPE_TRANSFER_OUT {
+		return TRANSACTION_TYPE_TRANSFER
+	}
+
+	return 0
+}
+
+// GetTagIdStrings returns the tag ids of this transaction template in string format
+func (t *TransactionTemplate) GetTagIdStrings() []string {
+	if t.TagIds == "" {
+		return make([]string, 0)
+	}
+
+	return strings.Split(t.TagIds, ",")
+}
+
+// ToTransactionCreateRequest returns a transaction creation request of the specified unix time according to this transaction template
+func (t *TransactionTemplate) ToTransactionCreateRequest(unixTime int64, utcOffset int16) *TransactionCreateRequest {
+	return &TransactionCreateRequest{
+		Type:                 t.GetTransactionType(),
+		CategoryId:           t.CategoryId,
+		Time:                 unixTime,
+		UtcOffset:            utcOffset,
+		SourceAccountId:      t.AccountId,
+		DestinationAccountId: t.RelatedAccountId,
+		SourceAmount:         t.Amount,
+		DestinationAmount:    t.RelatedAccountAmount,
+		HideAmount:           t.HideAmount,
+		TagIds:               t.GetTagIdStrings(),
+		Comment:              t.Comment,
+	}
+}
+
+// ToTransactionTemplateInfoResponse returns a view-object according to database model
+func (t *TransactionTemplate) ToTransactionTemplateInfoResponse() *TransactionTemplateInfoResponse {
+	return &TransactionTemplateInfoResponse{
+		Id:                   t.TemplateId,
+		Name:                 t.Name,
+		Type:                 t.GetTransactionType(),
+		CategoryId:           t.CategoryId,
+		SourceAccountId:      t.AccountId,
+		DestinationAccountId: t.RelatedAccountId,
+		SourceAmount:         t.Amount,
+		DestinationAmount:    t.RelatedAccountAmount,
+		HideAmount:           t.HideAmount,
+		TagIds:               t.GetTagIdStrings(),
+		Comment:              t.Comment,
+	}
+}
+
+// TransactionTemplateInfoResponseSlice represents the slice data structure of TransactionTemplateInfoResponse
+type TransactionTemplateInfoResponseSlice []*TransactionTemplateInfoResponse
+
+// Len returns the count of items
+func (s TransactionTemplateInfoResponseSlice) Len() int {
+	return len(s)
+}
+
+// Swap swaps two items
+func (s TransactionTemplateInfoResponseSlice) Swap(i, j int) {
+	s[i], s[j] = s[j], s[i]
+}
+
+// Less reports whether the first item is less than the second one
+func (s TransactionTemplateInfoResponseSlice) Less(i, j int) bool {
+	return s[i].Name < s[j].Name
+}
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransactionIsEditableInBulk(t *testing.T) {
+	user := &User{
+		TransactionEditScope: TRANSACTION_EDIT_SCOPE_ALL,
+	}
+
+	accountMap := map[int64]*Account{
+		1: {AccountId: 1},
+		2: {AccountId: 2},
+		3: {AccountId: 3, Hidden: true},
+	}
+
+	testCases := []struct {
+		name        string
+		transaction *Transaction
+		expected    bool
+	}{
+		{"expense", &Transaction{Type: TRANSACTION_DB_TYPE_EXPENSE, AccountId: 1}, true},
+		{"transfer out", &Transaction{Type: TRANSACTION_DB_TYPE_TRANSFER_OUT, AccountId: 1, RelatedAccountId: 2}, true},
+		{"transfer in", &Transaction{Type: TRANSACTION_DB_TYPE_TRANSFER_IN, AccountId: 2, RelatedAccountId: 1}, false},
+		{"reconciled", &Transaction{Type: TRANSACTION_DB_TYPE_EXPENSE, AccountId: 1, ReconcileState: TRANSACTION_RECONCILE_STATE_RECONCILED}, false},
+		{"hidden account", &Transaction{Type: TRANSACTION_DB_TYPE_EXPENSE, AccountId: 3}, false},
+		{"hidden destination account", &Transaction{Type: TRANSACTION_DB_TYPE_TRANSFER_OUT, AccountId: 1, RelatedAccountId: 3}, false},
+		{"missing account", &Transaction{Type: TRANSACTION_DB_TYPE_EXPENSE, AccountId: 4}, false},
+	}
+
+	for _, testCase := range testCases {
+		actualValue := testCase.transaction.IsEditableInBulk(user, accountMap)
+		assert.Equal(t, testCase.expected, actualValue, testCase.name)
+	}
+
+	user.TransactionEditScope = TRANSACTION_EDIT_SCOPE_NONE
+	assert.Equal(t, false, (&Transaction{Type: TRANSACTION_DB_TYPE_EXPENSE, AccountId: 1}).IsEditableInBulk(user, accountMap))
+}
@@ -0,0 +1,12 @@
+package securityprices
+
+import (
+	"github.com/mayswind/ezbookkeeping/pkg/core"
+	"github.com/mayswind/ezbookkeeping/pkg/models"
+)
+
+// SecurityPricesDataSource defines the structure of investment security prices data source
+type SecurityPricesDataSource interface {
+	// GetLatestPrices returns the latest prices of the specified securities, the returned prices only need to fill security id, price time and price
+	GetLatestPrices(c *core.Context, securities []*models.InvestmentSecurity) ([]*models.InvestmentSecurityPrice, error)
+}
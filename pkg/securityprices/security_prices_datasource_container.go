@@ -0,0 +1,31 @@
+package securityprices
+
+import (
+	"github.com/mayswind/ezbookkeeping/pkg/core"
+	"github.com/mayswind/ezbookkeeping/pkg/errs"
+	"github.com/mayswind/ezbookkeeping/pkg/models"
+)
+
+// SecurityPricesDataSourceContainer contains the current investment security prices data source
+type SecurityPricesDataSourceContainer struct {
+	Current SecurityPricesDataSource
+}
+
+// Initialize a investment security prices data source container singleton instance
+var (
+	Container = &SecurityPricesDataSourceContainer{}
+)
+
+// SetDataSource sets the current investment security prices data source, prices can only be imported manually if no data source is set
+func (s *SecurityPricesDataSourceContainer) SetDataSource(dataSource SecurityPricesDataSource) {
+	s.Current = dataSource
+}
+
+// GetLatestPrices returns the latest prices of the specified securities from the current data source
+func (s *SecurityPricesDataSourceContainer) GetLatestPrices(c *core.Context, securities []*models.InvestmentSecurity) ([]*models.InvestmentSecurityPrice, error) {
+	if s.Current == nil {
+		return nil, errs.ErrInvestmentSecurityPriceDataSourceNotSet
+	}
+
+	return s.Current.GetLatestPrices(c, securities)
+}
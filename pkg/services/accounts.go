@@ -58,6 +58,28 @@ func (s *AccountService) GetAccountAndSubAccountsByAccountId(uid int64, accountI
 	return accounts, err
 }
 
+// GetAccountByAccountId returns account model according to account id
+func (s *AccountService) GetAccountByAccountId(uid int64, accountId int64) (*models.Account, error) {
+	if uid <= 0 {
+		return nil, errs.ErrUserIdInvalid
+	}
+
+	if accountId <= 0 {
+		return nil, errs.ErrAccountIdInvalid
+	}
+
+	account := &models.Account{}
+	has, err := s.UserDataDB(uid).ID(accountId).Where("uid=? AND deleted=?", uid, false).Get(account)
+
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, errs.ErrAccountNotFound
+	}
+
+	return account, nil
+}
+
 // GetAccountsByAccountIds returns account models according to account ids
 func (s *AccountService) GetAccountsByAccountIds(uid int64, accountIds []int64) (map[int64]*models.Account, error) {
 	if uid <= 0 {
@@ -167,6 +189,7 @@ func (s *AccountService) CreateAccounts(mainAccount *models.Account, childrenAcc
 				Amount:               allAccounts[i].Balance,
 				RelatedAccountId:     allAccounts[i].AccountId,
 				RelatedAccountAmount: allAccounts[i].Balance,
+				ReconcileState:       models.TRANSACTION_RECONCILE_STATE_CLEARED,
 				CreatedUnixTime:      now,
 				UpdatedUnixTime:      now,
 			}
@@ -214,7 +237,7 @@ func (s *AccountService) ModifyAccounts(uid int64, accounts []*models.Account) e
 	return s.UserDataDB(uid).DoTransaction(func(sess *xorm.Session) error {
 		for i := 0; i < len(accounts); i++ {
 			account := accounts[i]
-			updatedRows, err := sess.ID(account.AccountId).Cols("name", "category", "icon", "color", "comment", "hidden", "updated_unix_time").Where("uid=? AND deleted=?", uid, false).Update(account)
+			updatedRows, err := sess.ID(account.AccountId).Cols("name", "category", "icon", "color", "comment", "hidden", "statement_day", "payment_due_day", "credit_limit", "updated_unix_time").Where("uid=? AND deleted=?", uid, false).Update(account)
 
 			if err != nil {
 				return err
@@ -253,6 +276,27 @@ func (s *AccountService) HideAccount(uid int64, ids []int64, hidden bool) error
 	})
 }
 
+// ModifyAccountLoanSettings updates the loan terms of given account
+func (s *AccountService) ModifyAccountLoanSettings(uid int64, account *models.Account) error {
+	if uid <= 0 {
+		return errs.ErrUserIdInvalid
+	}
+
+	account.UpdatedUnixTime = time.Now().Unix()
+
+	return s.UserDataDB(uid).DoTransaction(func(sess *xorm.Session) error {
+		updatedRows, err := sess.ID(account.AccountId).Cols("loan_principal", "loan_annual_interest_rate", "loan_term_count", "loan_start_unix_time", "loan_payment_frequency", "updated_unix_time").Where("uid=? AND deleted=?", uid, false).Update(account)
+
+		if err != nil {
+			return err
+		} else if updatedRows < 1 {
+			return errs.ErrAccountNotFound
+		}
+
+		return nil
+	})
+}
+
 // ModifyAccountDisplayOrders updates display order of given accounts
 func (s *AccountService) ModifyAccountDisplayOrders(uid int64, accounts []*models.Account) error {
 	if uid <= 0 {
@@ -332,6 +376,14 @@ func (s *AccountService) DeleteAccount(uid int64, accountId int64) error {
 			}
 		}
 
+		exists, err := sess.Cols("uid", "deleted", "account_id").Where("uid=? AND deleted=?", uid, false).In("account_id", accountAndSubAccountIds).Limit(1).Exist(&models.InvestmentTransaction{})
+
+		if err != nil {
+			return err
+		} else if exists {
+			return errs.ErrAccountInUseCannotBeDeleted
+		}
+
 		deletedRows, err := sess.Cols("balance", "deleted", "deleted_unix_time").Where("uid=? AND deleted=?", uid, false).In("account_id", accountAndSubAccountIds).Update(updateModel)
 
 		if err != nil {
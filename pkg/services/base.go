@@ -3,6 +3,7 @@ package services
 import (
 	"github.com/mayswind/ezbookkeeping/pkg/datastore"
 	"github.com/mayswind/ezbookkeeping/pkg/settings"
+	"github.com/mayswind/ezbookkeeping/pkg/storage"
 	"github.com/mayswind/ezbookkeeping/pkg/uuid"
 )
 
@@ -45,3 +46,13 @@ type ServiceUsingUuid struct {
 func (s *ServiceUsingUuid) GenerateUuid(uuidType uuid.UuidType) int64 {
 	return s.container.GenerateUuid(uuidType)
 }
+
+// ServiceUsingStorage represents a service that need to use object storage
+type ServiceUsingStorage struct {
+	container *storage.ObjectStorageContainer
+}
+
+// ObjectStorage returns the current object storage
+func (s *ServiceUsingStorage) ObjectStorage() *storage.ObjectStorageContainer {
+	return s.container
+}
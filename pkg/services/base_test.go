@@ -0,0 +1,108 @@
+package services
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mayswind/ezbookkeeping/pkg/datastore"
+	"github.com/mayswind/ezbookkeeping/pkg/models"
+	"github.com/mayswind/ezbookkeeping/pkg/settings"
+	"github.com/mayswind/ezbookkeeping/pkg/uuid"
+)
+
+const testUid int64 = 1
+
+// initializeTestDataStore initializes a new sqlite database in a temporary directory for the service singletons
+func initializeTestDataStore(t *testing.T) {
+	// Some queries are executed outside the database transaction (e.g. full-text index checking), so more than one connection is required
+	config := &settings.Config{
+		DatabaseConfig: &settings.DatabaseConfig{
+			DatabaseType:      settings.Sqlite3DbType,
+			DatabasePath:      filepath.Join(t.TempDir(), "ezbookkeeping.db"),
+			MaxIdleConnection: 2,
+			MaxOpenConnection: 2,
+		},
+		UuidGeneratorType:             settings.InternalUuidGeneratorType,
+		TransactionTrashRetentionDays: 30,
+	}
+
+	settings.SetCurrentConfig(config)
+
+	err := uuid.InitializeUuidGenerator(config)
+	assert.Nil(t, err)
+
+	err = datastore.InitializeDataStore(config)
+	assert.Nil(t, err)
+
+	t.Cleanup(func() {
+		datastore.Container.UserDataStore.Choose(0).Close()
+	})
+
+	err = datastore.Container.UserDataStore.SyncStructs(new(models.Account), new(models.Transaction), new(models.TransactionCategory),
+		new(models.TransactionTag), new(models.TransactionTagIndex), new(models.TransactionPayee), new(models.TransactionRule),
+		new(models.TransactionSplit), new(models.TransactionSchedule), new(models.TransactionTemplate), new(models.TransactionAttachment),
+		new(models.TransactionHistory), new(models.InvestmentSecurity), new(models.InvestmentSecurityPrice), new(models.InvestmentTransaction),
+		new(models.Budget), new(models.SavingsGoal))
+	assert.Nil(t, err)
+}
+
+func createTestAccount(t *testing.T, name string, balance int64) *models.Account {
+	account := &models.Account{
+		AccountId:       uuid.Container.GenerateUuid(uuid.UUID_TYPE_ACCOUNT),
+		Uid:             testUid,
+		Category:        models.ACCOUNT_CATEGORY_CASH,
+		Type:            models.ACCOUNT_TYPE_SINGLE_ACCOUNT,
+		ParentAccountId: models.LevelOneAccountParentId,
+		Name:            name,
+		Currency:        "USD",
+		Balance:         balance,
+		CreatedUnixTime: time.Now().Unix(),
+	}
+
+	_, err := datastore.Container.UserDataStore.Choose(testUid).Insert(account)
+	assert.Nil(t, err)
+
+	return account
+}
+
+func createTestCategory(t *testing.T, categoryType models.TransactionCategoryType, name string) *models.TransactionCategory {
+	category := &models.TransactionCategory{
+		CategoryId:       uuid.Container.GenerateUuid(uuid.UUID_TYPE_CATEGORY),
+		Uid:              testUid,
+		Type:             categoryType,
+		ParentCategoryId: uuid.Container.GenerateUuid(uuid.UUID_TYPE_CATEGORY),
+		Name:             name,
+		CreatedUnixTime:  time.Now().Unix(),
+	}
+
+	_, err := datastore.Container.UserDataStore.Choose(testUid).Insert(category)
+	assert.Nil(t, err)
+
+	return category
+}
+
+func createTestTag(t *testing.T, name string) *models.TransactionTag {
+	tag := &models.TransactionTag{
+		TagId:           uuid.Container.GenerateUuid(uuid.UUID_TYPE_TAG),
+		Uid:             testUid,
+		Name:            name,
+		CreatedUnixTime: time.Now().Unix(),
+	}
+
+	_, err := datastore.Container.UserDataStore.Choose(testUid).Insert(tag)
+	assert.Nil(t, err)
+
+	return tag
+}
+
+func getTestAccountBalance(t *testing.T, accountId int64) int64 {
+	account := &models.Account{}
+	has, err := datastore.Container.UserDataStore.Choose(testUid).ID(accountId).Get(account)
+	assert.Nil(t, err)
+	assert.True(t, has)
+
+	return account.Balance
+}
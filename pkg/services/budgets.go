@@ -0,0 +1,267 @@
+package services
+
+import (
+	"time"
+
+	"xorm.io/xorm"
+
+	"github.com/mayswind/ezbookkeeping/pkg/datastore"
+	"github.com/mayswind/ezbookkeeping/pkg/errs"
+	"github.com/mayswind/ezbookkeeping/pkg/models"
+	"github.com/mayswind/ezbookkeeping/pkg/uuid"
+)
+
+// BudgetService represents budget service
+type BudgetService struct {
+	ServiceUsingDB
+	ServiceUsingUuid
+}
+
+// Initialize a budget service singleton instance
+var (
+	Budgets = &BudgetService{
+		ServiceUsingDB: ServiceUsingDB{
+			container: datastore.Container,
+		},
+		ServiceUsingUuid: ServiceUsingUuid{
+			container: uuid.Container,
+		},
+	}
+)
+
+// GetBudgetsByMonth returns all budget models of user in specified month (in YYYYMM format)
+func (s *BudgetService) GetBudgetsByMonth(uid int64, month int) ([]*models.Budget, error) {
+	if uid <= 0 {
+		return nil, errs.ErrUserIdInvalid
+	}
+
+	var budgets []*models.Budget
+	err := s.UserDataDB(uid).Where("uid=? AND deleted=? AND month=?", uid, false, month).OrderBy("category_id asc").Find(&budgets)
+
+	return budgets, err
+}
+
+// GetBudgetsByCategoryIdsAndMonthRange returns all budget models of specified categories between the minimum month and the maximum month (both inclusive)
+func (s *BudgetService) GetBudgetsByCategoryIdsAndMonthRange(uid int64, categoryIds []int64, minMonth int, maxMonth int) ([]*models.Budget, error) {
+	if uid <= 0 {
+		return nil, errs.ErrUserIdInvalid
+	}
+
+	var budgets []*models.Budget
+
+	if len(categoryIds) < 1 {
+		return budgets, nil
+	}
+
+	err := s.UserDataDB(uid).Where("uid=? AND deleted=? AND month>=? AND month<=?", uid, false, minMonth, maxMonth).In("category_id", categoryIds).Find(&budgets)
+
+	return budgets, err
+}
+
+// GetRolloverBudgets returns the consecutive previous budgets of every specified budget which roll their remaining amounts into the month of that budget, ordered from the latest month to the earliest month
+func (s *BudgetService) GetRolloverBudgets(uid int64, budgets []*models.Budget) ([][]*models.Budget, error) {
+	if uid <= 0 {
+		return nil, errs.ErrUserIdInvalid
+	}
+
+	rolloverBudgets := make([][]*models.Budget, len(budgets))
+
+	if len(budgets) < 1 {
+		return rolloverBudgets, nil
+	}
+
+	categoryIds := make([]int64, len(budgets))
+	minMonth := budgets[0].Month
+	maxMonth := budgets[0].Month
+
+	for i := 0; i < len(budgets); i++ {
+		categoryIds[i] = budgets[i].CategoryId
+
+		if budgets[i].Month < minMonth {
+			minMonth = budgets[i].Month
+		}
+
+		if budgets[i].Month > maxMonth {
+			maxMonth = budgets[i].Month
+		}
+	}
+
+	for i := 0; i < models.MaxBudgetRolloverMonthCount; i++ {
+		minMonth = models.GetPreviousBudgetMonth(minMonth)
+	}
+
+	previousBudgets, err := s.GetBudgetsByCategoryIdsAndMonthRange(uid, categoryIds, minMonth, models.GetPreviousBudgetMonth(maxMonth))
+
+	if err != nil {
+		return nil, err
+	}
+
+	previousBudgetMap := make(map[int64]map[int]*models.Budget, len(budgets))
+
+	for i := 0; i < len(previousBudgets); i++ {
+		budget := previousBudgets[i]
+
+		if _, exists := previousBudgetMap[budget.CategoryId]; !exists {
+			previousBudgetMap[budget.CategoryId] = make(map[int]*models.Budget)
+		}
+
+		previousBudgetMap[budget.CategoryId][budget.Month] = budget
+	}
+
+	for i := 0; i < len(budgets); i++ {
+		previousMonth := models.GetPreviousBudgetMonth(budgets[i].Month)
+
+		for len(rolloverBudgets[i]) < models.MaxBudgetRolloverMonthCount {
+			previousBudget, exists := previousBudgetMap[budgets[i].CategoryId][previousMonth]
+
+			if !exists || !previousBudget.Rollover {
+				break
+			}
+
+			rolloverBudgets[i] = append(rolloverBudgets[i], previousBudget)
+			previousMonth = models.GetPreviousBudgetMonth(previousMonth)
+		}
+	}
+
+	return rolloverBudgets, nil
+}
+
+// GetRolloverAmount returns the amount rolled into the next month of the latest budget by the consecutive previous budgets (ordered from the latest month to the earliest month) and the spent amounts of every category in every month
+func (s *BudgetService) GetRolloverAmount(rolloverBudgets []*models.Budget, spentAmounts map[int]map[int64]int64) int64 {
+	rolloverAmount := int64(0)
+
+	// Roll the remaining amounts of previous budgets from the earliest month
+	for i := len(rolloverBudgets) - 1; i >= 0; i-- {
+		budget := rolloverBudgets[i]
+		rolloverAmount = budget.Amount + rolloverAmount - spentAmounts[budget.Month][budget.CategoryId]
+	}
+
+	return rolloverAmount
+}
+
+// GetBudgetByBudgetId returns a budget model according to budget id
+func (s *BudgetService) GetBudgetByBudgetId(uid int64, budgetId int64) (*models.Budget, error) {
+	if uid <= 0 {
+		return nil, errs.ErrUserIdInvalid
+	}
+
+	if budgetId <= 0 {
+		return nil, errs.ErrBudgetIdInvalid
+	}
+
+	budget := &models.Budget{}
+	has, err := s.UserDataDB(uid).ID(budgetId).Where("uid=? AND deleted=?", uid, false).Get(budget)
+
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, errs.ErrBudgetNotFound
+	}
+
+	return budget, nil
+}
+
+// CreateBudget saves a new budget model to database
+func (s *BudgetService) CreateBudget(budget *models.Budget) error {
+	if budget.Uid <= 0 {
+		return errs.ErrUserIdInvalid
+	}
+
+	budget.BudgetId = s.GenerateUuid(uuid.UUID_TYPE_BUDGET)
+
+	budget.Deleted = false
+	budget.CreatedUnixTime = time.Now().Unix()
+	budget.UpdatedUnixTime = time.Now().Unix()
+
+	return s.UserDataDB(budget.Uid).DoTransaction(func(sess *xorm.Session) error {
+		category := &models.TransactionCategory{}
+		has, err := sess.ID(budget.CategoryId).Where("uid=? AND deleted=?", budget.Uid, false).Get(category)
+
+		if err != nil {
+			return err
+		} else if !has {
+			return errs.ErrTransactionCategoryNotFound
+		}
+
+		if category.Type != models.CATEGORY_TYPE_EXPENSE {
+			return errs.ErrBudgetCategoryTypeInvalid
+		}
+
+		exists, err := sess.Cols("uid", "deleted", "month", "category_id").Where("uid=? AND deleted=? AND month=? AND category_id=?", budget.Uid, false, budget.Month, budget.CategoryId).Exist(&models.Budget{})
+
+		if err != nil {
+			return err
+		} else if exists {
+			return errs.ErrBudgetAlreadyExists
+		}
+
+		_, err = sess.Insert(budget)
+		return err
+	})
+}
+
+// ModifyBudget saves an existed budget model to database
+func (s *BudgetService) ModifyBudget(budget *models.Budget) error {
+	if budget.Uid <= 0 {
+		return errs.ErrUserIdInvalid
+	}
+
+	budget.UpdatedUnixTime = time.Now().Unix()
+
+	return s.UserDataDB(budget.Uid).DoTransaction(func(sess *xorm.Session) error {
+		updatedRows, err := sess.ID(budget.BudgetId).Cols("amount", "rollover", "updated_unix_time").Where("uid=? AND deleted=?", budget.Uid, false).Update(budget)
+
+		if err != nil {
+			return err
+		} else if updatedRows < 1 {
+			return errs.ErrBudgetNotFound
+		}
+
+		return err
+	})
+}
+
+// DeleteBudget deletes an existed budget from database
+func (s *BudgetService) DeleteBudget(uid int64, budgetId int64) error {
+	if uid <= 0 {
+		return errs.ErrUserIdInvalid
+	}
+
+	now := time.Now().Unix()
+
+	updateModel := &models.Budget{
+		Deleted:         true,
+		DeletedUnixTime: now,
+	}
+
+	return s.UserDataDB(uid).DoTransaction(func(sess *xorm.Session) error {
+		deletedRows, err := sess.ID(budgetId).Cols("deleted", "deleted_unix_time").Where("uid=? AND deleted=?", uid, false).Update(updateModel)
+
+		if err != nil {
+			return err
+		} else if deletedRows < 1 {
+			return errs.ErrBudgetNotFound
+		}
+
+		return err
+	})
+}
+
+// DeleteAllBudgets deletes all existed budgets from database
+func (s *BudgetService) DeleteAllBudgets(uid int64) error {
+	if uid <= 0 {
+		return errs.ErrUserIdInvalid
+	}
+
+	now := time.Now().Unix()
+
+	updateModel := &models.Budget{
+		Deleted:         true,
+		DeletedUnixTime: now,
+	}
+
+	return s.UserDataDB(uid).DoTransaction(func(sess *xorm.Session) error {
+		_, err := sess.Cols("deleted", "deleted_unix_time").Where("uid=? AND deleted=?", uid, false).Update(updateModel)
+		return err
+	})
+}
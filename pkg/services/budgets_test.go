@@ -0,0 +1,77 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mayswind/ezbookkeeping/pkg/models"
+)
+
+func TestBudgetServiceGetRolloverBudgets(t *testing.T) {
+	initializeTestDataStore(t)
+
+	food := createTestCategory(t, models.CATEGORY_TYPE_EXPENSE, "food")
+	grocery := createTestCategory(t, models.CATEGORY_TYPE_EXPENSE, "grocery")
+
+	testBudgets := []*models.Budget{
+		{CategoryId: food.CategoryId, Month: 202311, Amount: 1000, Rollover: true},
+		{CategoryId: food.CategoryId, Month: 202312, Amount: 1000, Rollover: false},
+		{CategoryId: food.CategoryId, Month: 202401, Amount: 1000, Rollover: true},
+		{CategoryId: food.CategoryId, Month: 202402, Amount: 1000, Rollover: true},
+		{CategoryId: food.CategoryId, Month: 202403, Amount: 1000, Rollover: true},
+		{CategoryId: grocery.CategoryId, Month: 202401, Amount: 500, Rollover: true},
+		{CategoryId: grocery.CategoryId, Month: 202403, Amount: 500, Rollover: true},
+	}
+
+	for i := 0; i < len(testBudgets); i++ {
+		testBudgets[i].Uid = testUid
+		err := Budgets.CreateBudget(testBudgets[i])
+		assert.Nil(t, err)
+	}
+
+	budgets, err := Budgets.GetBudgetsByMonth(testUid, 202403)
+	assert.Nil(t, err)
+
+	rolloverBudgets, err := Budgets.GetRolloverBudgets(testUid, budgets)
+	assert.Nil(t, err)
+	assert.Equal(t, len(budgets), len(rolloverBudgets))
+
+	for i := 0; i < len(budgets); i++ {
+		if budgets[i].CategoryId == food.CategoryId {
+			// The budgets before the month which does not roll over are not included
+			assert.Equal(t, 2, len(rolloverBudgets[i]))
+			assert.Equal(t, 202402, rolloverBudgets[i][0].Month)
+			assert.Equal(t, 202401, rolloverBudgets[i][1].Month)
+		} else {
+			// The budgets before the month which has no budget are not included
+			assert.Equal(t, 0, len(rolloverBudgets[i]))
+		}
+	}
+}
+
+func TestBudgetServiceGetRolloverAmount(t *testing.T) {
+	rolloverBudgets := []*models.Budget{
+		{CategoryId: 1, Month: 202402, Amount: 1000, Rollover: true},
+		{CategoryId: 1, Month: 202401, Amount: 1000, Rollover: true},
+	}
+
+	testCases := []struct {
+		name          string
+		spentAmounts  map[int]map[int64]int64
+		expectedValue int64
+	}{
+		{"nothing spent", map[int]map[int64]int64{}, 2000},
+		{"underspent", map[int]map[int64]int64{202401: {1: 600}, 202402: {1: 700}}, 700},
+		{"overspent", map[int]map[int64]int64{202401: {1: 1200}, 202402: {1: 500}}, 300},
+		{"overspent in total", map[int]map[int64]int64{202401: {1: 1500}, 202402: {1: 1500}}, -1000},
+		{"spent in other category", map[int]map[int64]int64{202401: {2: 1500}}, 2000},
+	}
+
+	for _, testCase := range testCases {
+		actualValue := Budgets.GetRolloverAmount(rolloverBudgets, testCase.spentAmounts)
+		assert.Equal(t, testCase.expectedValue, actualValue, testCase.name)
+	}
+
+	assert.Equal(t, int64(0), Budgets.GetRolloverAmount(nil, map[int]map[int64]int64{}))
+}
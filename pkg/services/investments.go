@@ -0,0 +1,782 @@
+package services
+
+import (
+	"math/big"
+	"sort"
+	"time"
+
+	"xorm.io/xorm"
+
+	"github.com/mayswind/ezbookkeeping/pkg/datastore"
+	"github.com/mayswind/ezbookkeeping/pkg/errs"
+	"github.com/mayswind/ezbookkeeping/pkg/models"
+	"github.com/mayswind/ezbookkeeping/pkg/uuid"
+)
+
+// InvestmentService represents investment security, price and investment transaction service
+type InvestmentService struct {
+	ServiceUsingDB
+	ServiceUsingUuid
+}
+
+// Initialize an investment service singleton instance
+var (
+	Investments = &InvestmentService{
+		ServiceUsingDB: ServiceUsingDB{
+			container: datastore.Container,
+		},
+		ServiceUsingUuid: ServiceUsingUuid{
+			container: uuid.Container,
+		},
+	}
+)
+
+// GetAllSecuritiesByUid returns all investment security models of user
+func (s *InvestmentService) GetAllSecuritiesByUid(uid int64) ([]*models.InvestmentSecurity, error) {
+	if uid <= 0 {
+		return nil, errs.ErrUserIdInvalid
+	}
+
+	var securities []*models.InvestmentSecurity
+	err := s.UserDataDB(uid).Where("uid=? AND deleted=?", uid, false).Find(&securities)
+
+	return securities, err
+}
+
+// GetSecurityBySecurityId returns an investment security model according to security id
+func (s *InvestmentService) GetSecurityBySecurityId(uid int64, securityId int64) (*models.InvestmentSecurity, error) {
+	if uid <= 0 {
+		return nil, errs.ErrUserIdInvalid
+	}
+
+	if securityId <= 0 {
+		return nil, errs.ErrInvestmentSecurityIdInvalid
+	}
+
+	security := &models.InvestmentSecurity{}
+	has, err := s.UserDataDB(uid).ID(securityId).Where("uid=? AND deleted=?", uid, false).Get(security)
+
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, errs.ErrInvestmentSecurityNotFound
+	}
+
+	return security, nil
+}
+
+// GetSecurityMapByList returns an investment security map by a list
+func (s *InvestmentService) GetSecurityMapByList(securities []*models.InvestmentSecurity) map[int64]*models.InvestmentSecurity {
+	securityMap := make(map[int64]*models.InvestmentSecurity)
+
+	for i := 0; i < len(securities); i++ {
+		security := securities[i]
+		securityMap[security.SecurityId] = security
+	}
+
+	return securityMap
+}
+
+// CreateSecurity saves a new investment security model to database
+func (s *InvestmentService) CreateSecurity(security *models.InvestmentSecurity) error {
+	if security.Uid <= 0 {
+		return errs.ErrUserIdInvalid
+	}
+
+	exists, err := s.ExistsSecuritySymbol(security.Uid, security.Symbol)
+
+	if err != nil {
+		return err
+	} else if exists {
+		return errs.ErrInvestmentSecuritySymbolAlreadyExists
+	}
+
+	security.SecurityId = s.GenerateUuid(uuid.UUID_TYPE_INVESTMENT)
+
+	security.Deleted = false
+	security.CreatedUnixTime = time.Now().Unix()
+	security.UpdatedUnixTime = time.Now().Unix()
+
+	return s.UserDataDB(security.Uid).DoTransaction(func(sess *xorm.Session) error {
+		_, err := sess.Insert(security)
+		return err
+	})
+}
+
+// ModifySecurity saves an existed investment security model to database, the currency can only be modified when the security is not in use
+func (s *InvestmentService) ModifySecurity(security *models.InvestmentSecurity, symbolChanged bool, currencyChanged bool) error {
+	if security.Uid <= 0 {
+		return errs.ErrUserIdInvalid
+	}
+
+	if symbolChanged {
+		exists, err := s.ExistsSecuritySymbol(security.Uid, security.Symbol)
+
+		if err != nil {
+			return err
+		} else if exists {
+			return errs.ErrInvestmentSecuritySymbolAlreadyExists
+		}
+	}
+
+	security.UpdatedUnixTime = time.Now().Unix()
+
+	return s.UserDataDB(security.Uid).DoTransaction(func(sess *xorm.Session) error {
+		if currencyChanged {
+			exists, err := sess.Cols("uid", "deleted", "security_id").Where("uid=? AND deleted=? AND security_id=?", security.Uid, false, security.SecurityId).Limit(1).Exist(&models.InvestmentTransaction{})
+
+			if err != nil {
+				return err
+			} else if exists {
+				return errs.ErrInvestmentSecurityCurrencyCannotBeModified
+			}
+		}
+
+		updatedRows, err := sess.ID(security.SecurityId).Cols("symbol", "name", "currency", "updated_unix_time").Where("uid=? AND deleted=?", security.Uid, false).Update(security)
+
+		if err != nil {
+			return err
+		} else if updatedRows < 1 {
+			return errs.ErrInvestmentSecurityNotFound
+		}
+
+		return err
+	})
+}
+
+// DeleteSecurity deletes an existed investment security and all its prices from database
+func (s *InvestmentService) DeleteSecurity(uid int64, securityId int64) error {
+	if uid <= 0 {
+		return errs.ErrUserIdInvalid
+	}
+
+	now := time.Now().Unix()
+
+	updateModel := &models.InvestmentSecurity{
+		Deleted:         true,
+		DeletedUnixTime: now,
+	}
+
+	priceUpdateModel := &models.InvestmentSecurityPrice{
+		Deleted:         true,
+		DeletedUnixTime: now,
+	}
+
+	return s.UserDataDB(uid).DoTransaction(func(sess *xorm.Session) error {
+		exists, err := sess.Cols("uid", "deleted", "security_id").Where("uid=? AND deleted=? AND security_id=?", uid, false, securityId).Limit(1).Exist(&models.InvestmentTransaction{})
+
+		if err != nil {
+			return err
+		} else if exists {
+			return errs.ErrInvestmentSecurityInUseCannotBeDeleted
+		}
+
+		deletedRows, err := sess.ID(securityId).Cols("deleted", "deleted_unix_time").Where("uid=? AND deleted=?", uid, false).Update(updateModel)
+
+		if err != nil {
+			return err
+		} else if deletedRows < 1 {
+			return errs.ErrInvestmentSecurityNotFound
+		}
+
+		_, err = sess.Cols("deleted", "deleted_unix_time").Where("uid=? AND deleted=? AND security_id=?", uid, false, securityId).Update(priceUpdateModel)
+
+		return err
+	})
+}
+
+// ExistsSecuritySymbol returns whether the given investment security symbol exists
+func (s *InvestmentService) ExistsSecuritySymbol(uid int64, symbol string) (bool, error) {
+	return s.UserDataDB(uid).Cols("symbol").Where("uid=? AND deleted=? AND symbol=?", uid, false, symbol).Exist(&models.InvestmentSecurity{})
+}
+
+// GetPricesBySecurityId returns the latest investment security price models of specified security
+func (s *InvestmentService) GetPricesBySecurityId(uid int64, securityId int64, count int) ([]*models.InvestmentSecurityPrice, error) {
+	if uid <= 0 {
+		return nil, errs.ErrUserIdInvalid
+	}
+
+	if securityId <= 0 {
+		return nil, errs.ErrInvestmentSecurityIdInvalid
+	}
+
+	var prices []*models.InvestmentSecurityPrice
+	err := s.UserDataDB(uid).Where("uid=? AND deleted=? AND security_id=?", uid, false, securityId).OrderBy("price_unix_time desc").Limit(count, 0).Find(&prices)
+
+	return prices, err
+}
+
+// GetLatestPricesBySecurityIds returns a map of security id to the latest investment security price model
+func (s *InvestmentService) GetLatestPricesBySecurityIds(uid int64, securityIds []int64) (map[int64]*models.InvestmentSecurityPrice, error) {
+	if uid <= 0 {
+		return nil, errs.ErrUserIdInvalid
+	}
+
+	priceMap := make(map[int64]*models.InvestmentSecurityPrice, len(securityIds))
+
+	for i := 0; i < len(securityIds); i++ {
+		price := &models.InvestmentSecurityPrice{}
+		has, err := s.UserDataDB(uid).Where("uid=? AND deleted=? AND security_id=?", uid, false, securityIds[i]).OrderBy("price_unix_time desc").Limit(1).Get(price)
+
+		if err != nil {
+			return nil, err
+		} else if has {
+			priceMap[securityIds[i]] = price
+		}
+	}
+
+	return priceMap, nil
+}
+
+// SavePrices saves the given investment security price models to database, the existed price of the same security at the same time would be replaced
+func (s *InvestmentService) SavePrices(uid int64, prices []*models.InvestmentSecurityPrice) error {
+	if uid <= 0 {
+		return errs.ErrUserIdInvalid
+	}
+
+	now := time.Now().Unix()
+	securityIds := make([]int64, 0, len(prices))
+	securityIdExists := make(map[int64]bool)
+
+	for i := 0; i < len(prices); i++ {
+		price := prices[i]
+
+		if !securityIdExists[price.SecurityId] {
+			securityIds = append(securityIds, price.SecurityId)
+			securityIdExists[price.SecurityId] = true
+		}
+
+		price.PriceId = s.GenerateUuid(uuid.UUID_TYPE_INVESTMENT)
+		price.Uid = uid
+		price.Deleted = false
+		price.CreatedUnixTime = now
+		price.UpdatedUnixTime = now
+	}
+
+	updateModel := &models.InvestmentSecurityPrice{
+		Deleted:         true,
+		DeletedUnixTime: now,
+	}
+
+	return s.UserDataDB(uid).DoTransaction(func(sess *xorm.Session) error {
+		securityCount, err := sess.Where("uid=? AND deleted=?", uid, false).In("security_id", securityIds).Count(&models.InvestmentSecurity{})
+
+		if err != nil {
+			return err
+		} else if securityCount != int64(len(securityIds)) {
+			return errs.ErrInvestmentSecurityNotFound
+		}
+
+		for i := 0; i < len(prices); i++ {
+			price := prices[i]
+			_, err = sess.Cols("deleted", "deleted_unix_time").Where("uid=? AND deleted=? AND security_id=? AND price_unix_time=?", uid, false, price.SecurityId, price.PriceUnixTime).Update(updateModel)
+
+			if err != nil {
+				return err
+			}
+
+			_, err = sess.Insert(price)
+
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// DeletePrice deletes an existed investment security price from database
+func (s *InvestmentService) DeletePrice(uid int64, priceId int64) error {
+	if uid <= 0 {
+		return errs.ErrUserIdInvalid
+	}
+
+	if priceId <= 0 {
+		return errs.ErrInvestmentSecurityPriceIdInvalid
+	}
+
+	updateModel := &models.InvestmentSecurityPrice{
+		Deleted:         true,
+		DeletedUnixTime: time.Now().Unix(),
+	}
+
+	return s.UserDataDB(uid).DoTransaction(func(sess *xorm.Session) error {
+		deletedRows, err := sess.ID(priceId).Cols("deleted", "deleted_unix_time").Where("uid=? AND deleted=?", uid, false).Update(updateModel)
+
+		if err != nil {
+			return err
+		} else if deletedRows < 1 {
+			return errs.ErrInvestmentSecurityPriceNotFound
+		}
+
+		return nil
+	})
+}
+
+// GetTransactionsByAccountId returns the investment transaction models of specified account (and specified security if security id is set), the latest transaction comes first
+func (s *InvestmentService) GetTransactionsByAccountId(uid int64, accountId int64, securityId int64) ([]*models.InvestmentTransaction, error) {
+	if uid <= 0 {
+		return nil, errs.ErrUserIdInvalid
+	}
+
+	if accountId <= 0 {
+		return nil, errs.ErrAccountIdInvalid
+	}
+
+	condition := "uid=? AND deleted=? AND account_id=?"
+	conditionParams := []interface{}{uid, false, accountId}
+
+	if securityId > 0 {
+		condition = condition + " AND security_id=?"
+		conditionParams = append(conditionParams, securityId)
+	}
+
+	var transactions []*models.InvestmentTransaction
+	err := s.UserDataDB(uid).Where(condition, conditionParams...).OrderBy("trade_unix_time desc, created_unix_time desc").Find(&transactions)
+
+	return transactions, err
+}
+
+// GetTransactionByTransactionId returns an investment transaction model according to transaction id
+func (s *InvestmentService) GetTransactionByTransactionId(uid int64, transactionId int64) (*models.InvestmentTransaction, error) {
+	if uid <= 0 {
+		return nil, errs.ErrUserIdInvalid
+	}
+
+	if transactionId <= 0 {
+		return nil, errs.ErrInvestmentTransactionIdInvalid
+	}
+
+	transaction := &models.InvestmentTransaction{}
+	has, err := s.UserDataDB(uid).ID(transactionId).Where("uid=? AND deleted=?", uid, false).Get(transaction)
+
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, errs.ErrInvestmentTransactionNotFound
+	}
+
+	return transaction, nil
+}
+
+// CreateTransaction saves a new investment transaction model and the transaction of its cash leg in investment account to database
+func (s *InvestmentService) CreateTransaction(transaction *models.InvestmentTransaction, categoryId int64, utcOffset int16, operator *models.TransactionOperator) error {
+	if transaction.Uid <= 0 {
+		return errs.ErrUserIdInvalid
+	}
+
+	err := s.validateTransactionQuantity(transaction)
+
+	if err != nil {
+		return err
+	}
+
+	transaction.TransactionId = s.GenerateUuid(uuid.UUID_TYPE_INVESTMENT)
+
+	transaction.Deleted = false
+	transaction.CreatedUnixTime = time.Now().Unix()
+	transaction.UpdatedUnixTime = time.Now().Unix()
+
+	return s.UserDataDB(transaction.Uid).DoTransaction(func(sess *xorm.Session) error {
+		err := s.checkAccountAndSecurity(sess, transaction.Uid, transaction.AccountId, transaction.SecurityId)
+
+		if err != nil {
+			return err
+		}
+
+		err = s.checkHoldingsAfterChange(sess, transaction.Uid, transaction.AccountId, transaction.SecurityId, transaction, 0)
+
+		if err != nil {
+			return err
+		}
+
+		// Record the cash leg so that the balance of investment account and the income statistics include it
+		cashTransaction := transaction.ToCashTransaction(categoryId, utcOffset)
+		balanceChanges := make(accountBalanceChanges)
+		err = Transactions.createTransaction(sess, cashTransaction, nil, nil, operator, balanceChanges)
+
+		if err != nil {
+			return err
+		}
+
+		err = Transactions.updateAccountBalances(sess, transaction.Uid, balanceChanges)
+
+		if err != nil {
+			return err
+		}
+
+		transaction.CashTransactionId = cashTransaction.TransactionId
+
+		_, err = sess.Insert(transaction)
+		return err
+	})
+}
+
+// ModifyTransaction saves an existed investment transaction model and the transaction of its cash leg to database
+func (s *InvestmentService) ModifyTransaction(transaction *models.InvestmentTransaction, categoryId int64, utcOffset int16, operator *models.TransactionOperator) error {
+	if transaction.Uid <= 0 {
+		return errs.ErrUserIdInvalid
+	}
+
+	err := s.validateTransactionQuantity(transaction)
+
+	if err != nil {
+		return err
+	}
+
+	transaction.UpdatedUnixTime = time.Now().Unix()
+
+	return s.UserDataDB(transaction.Uid).DoTransaction(func(sess *xorm.Session) error {
+		oldTransaction := &models.InvestmentTransaction{}
+		has, err := sess.ID(transaction.TransactionId).Where("uid=? AND deleted=?", transaction.Uid, false).Get(oldTransaction)
+
+		if err != nil {
+			return err
+		} else if !has {
+			return errs.ErrInvestmentTransactionNotFound
+		}
+
+		err = s.checkHoldingsAfterChange(sess, transaction.Uid, transaction.AccountId, transaction.SecurityId, transaction, transaction.TransactionId)
+
+		if err != nil {
+			return err
+		}
+
+		// Modify the cash leg, or replace it if its type is changed (e.g. from buying to selling)
+		transaction.CashTransactionId = oldTransaction.CashTransactionId
+		cashTransaction := transaction.ToCashTransaction(categoryId, utcOffset)
+		oldCashTransaction, err := s.getCashTransaction(sess, oldTransaction)
+
+		if err != nil {
+			return err
+		}
+
+		balanceChanges := make(accountBalanceChanges)
+
+		if oldCashTransaction != nil && oldCashTransaction.Type == cashTransaction.Type {
+			err = Transactions.modifyTransaction(sess, cashTransaction, nil, nil, nil, operator, balanceChanges)
+		} else {
+			if oldCashTransaction != nil {
+				err = Transactions.deleteTransaction(sess, transaction.Uid, oldCashTransaction.TransactionId, operator, balanceChanges)
+
+				if err != nil {
+					return err
+				}
+			}
+
+			// The deleted cash leg still takes its transaction time, so the new one must use another time
+			cashTransaction.TransactionTime, err = Transactions.getNextAvailableTransactionTime(sess, transaction.Uid, cashTransaction.TransactionTime)
+
+			if err != nil {
+				return err
+			}
+
+			err = Transactions.createTransaction(sess, cashTransaction, nil, nil, operator, balanceChanges)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		err = Transactions.updateAccountBalances(sess, transaction.Uid, balanceChanges)
+
+		if err != nil {
+			return err
+		}
+
+		transaction.CashTransactionId = cashTransaction.TransactionId
+
+		updatedRows, err := sess.ID(transaction.TransactionId).Cols("type", "trade_unix_time", "quantity", "amount", "cash_transaction_id", "comment", "updated_unix_time").Where("uid=? AND deleted=?", transaction.Uid, false).Update(transaction)
+
+		if err != nil {
+			return err
+		} else if updatedRows < 1 {
+			return errs.ErrInvestmentTransactionNotFound
+		}
+
+		return err
+	})
+}
+
+// DeleteTransaction deletes an existed investment transaction and the transaction of its cash leg from database
+func (s *InvestmentService) DeleteTransaction(uid int64, transactionId int64, operator *models.TransactionOperator) error {
+	if uid <= 0 {
+		return errs.ErrUserIdInvalid
+	}
+
+	updateModel := &models.InvestmentTransaction{
+		Deleted:         true,
+		DeletedUnixTime: time.Now().Unix(),
+	}
+
+	return s.UserDataDB(uid).DoTransaction(func(sess *xorm.Session) error {
+		transaction := &models.InvestmentTransaction{}
+		has, err := sess.ID(transactionId).Where("uid=? AND deleted=?", uid, false).Get(transaction)
+
+		if err != nil {
+			return err
+		} else if !has {
+			return errs.ErrInvestmentTransactionNotFound
+		}
+
+		err = s.checkHoldingsAfterChange(sess, uid, transaction.AccountId, transaction.SecurityId, nil, transactionId)
+
+		if err != nil {
+			return err
+		}
+
+		cashTransaction, err := s.getCashTransaction(sess, transaction)
+
+		if err != nil {
+			return err
+		}
+
+		if cashTransaction != nil {
+			balanceChanges := make(accountBalanceChanges)
+			err = Transactions.deleteTransaction(sess, uid, cashTransaction.TransactionId, operator, balanceChanges)
+
+			if err != nil {
+				return err
+			}
+
+			err = Transactions.updateAccountBalances(sess, uid, balanceChanges)
+
+			if err != nil {
+				return err
+			}
+		}
+
+		deletedRows, err := sess.ID(transactionId).Cols("deleted", "deleted_unix_time").Where("uid=? AND deleted=?", uid, false).Update(updateModel)
+
+		if err != nil {
+			return err
+		} else if deletedRows < 1 {
+			return errs.ErrInvestmentTransactionNotFound
+		}
+
+		return nil
+	})
+}
+
+// GetAccountsHoldings returns a map of account id to the holdings of all securities which have been traded in that account
+func (s *InvestmentService) GetAccountsHoldings(uid int64, accountIds []int64) (map[int64][]*models.InvestmentHolding, error) {
+	if uid <= 0 {
+		return nil, errs.ErrUserIdInvalid
+	}
+
+	accountsHoldings := make(map[int64][]*models.InvestmentHolding, len(accountIds))
+
+	if len(accountIds) < 1 {
+		return accountsHoldings, nil
+	}
+
+	var transactions []*models.InvestmentTransaction
+	err := s.UserDataDB(uid).Where("uid=? AND deleted=?", uid, false).In("account_id", accountIds).OrderBy("trade_unix_time asc, created_unix_time asc").Find(&transactions)
+
+	if err != nil {
+		return nil, err
+	}
+
+	accountsTransactions := make(map[int64][]*models.InvestmentTransaction)
+
+	for i := 0; i < len(transactions); i++ {
+		transaction := transactions[i]
+		accountsTransactions[transaction.AccountId] = append(accountsTransactions[transaction.AccountId], transaction)
+	}
+
+	for accountId, accountTransactions := range accountsTransactions {
+		holdings, err := s.calculateHoldings(accountTransactions)
+
+		if err != nil {
+			return nil, err
+		}
+
+		accountsHoldings[accountId] = holdings
+	}
+
+	return accountsHoldings, nil
+}
+
+// DeleteAllInvestmentData deletes all existed investment securities, prices and investment transactions from database
+func (s *InvestmentService) DeleteAllInvestmentData(uid int64) error {
+	if uid <= 0 {
+		return errs.ErrUserIdInvalid
+	}
+
+	now := time.Now().Unix()
+
+	return s.UserDataDB(uid).DoTransaction(func(sess *xorm.Session) error {
+		_, err := sess.Cols("deleted", "deleted_unix_time").Where("uid=? AND deleted=?", uid, false).Update(&models.InvestmentTransaction{
+			Deleted:         true,
+			DeletedUnixTime: now,
+		})
+
+		if err != nil {
+			return err
+		}
+
+		_, err = sess.Cols("deleted", "deleted_unix_time").Where("uid=? AND deleted=?", uid, false).Update(&models.InvestmentSecurityPrice{
+			Deleted:         true,
+			DeletedUnixTime: now,
+		})
+
+		if err != nil {
+			return err
+		}
+
+		_, err = sess.Cols("deleted", "deleted_unix_time").Where("uid=? AND deleted=?", uid, false).Update(&models.InvestmentSecurity{
+			Deleted:         true,
+			DeletedUnixTime: now,
+		})
+
+		return err
+	})
+}
+
+func (s *InvestmentService) validateTransactionQuantity(transaction *models.InvestmentTransaction) error {
+	if transaction.Type == models.INVESTMENT_TRANSACTION_TYPE_BUY || transaction.Type == models.INVESTMENT_TRANSACTION_TYPE_SELL {
+		if transaction.Quantity <= 0 {
+			return errs.ErrInvestmentTransactionQuantityInvalid
+		}
+	} else if transaction.Type == models.INVESTMENT_TRANSACTION_TYPE_DIVIDEND {
+		if transaction.Quantity != 0 {
+			return errs.ErrInvestmentTransactionQuantityInvalid
+		}
+	} else {
+		return errs.ErrInvestmentTransactionTypeInvalid
+	}
+
+	return nil
+}
+
+// getCashTransaction returns the transaction of the cash leg of investment transaction, or returns nil if it does not exist or has been deleted
+func (s *InvestmentService) getCashTransaction(sess *xorm.Session, transaction *models.InvestmentTransaction) (*models.Transaction, error) {
+	if transaction.CashTransactionId <= 0 {
+		return nil, nil
+	}
+
+	cashTransaction := &models.Transaction{}
+	has, err := sess.ID(transaction.CashTransactionId).Where("uid=? AND deleted=?", transaction.Uid, false).Get(cashTransaction)
+
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, nil
+	}
+
+	return cashTransaction, nil
+}
+
+func (s *InvestmentService) checkAccountAndSecurity(sess *xorm.Session, uid int64, accountId int64, securityId int64) error {
+	account := &models.Account{}
+	has, err := sess.ID(accountId).Where("uid=? AND deleted=?", uid, false).Get(account)
+
+	if err != nil {
+		return err
+	} else if !has {
+		return errs.ErrAccountNotFound
+	}
+
+	if account.Category != models.ACCOUNT_CATEGORY_INVESTMENT || account.Type != models.ACCOUNT_TYPE_SINGLE_ACCOUNT {
+		return errs.ErrInvestmentAccountInvalid
+	}
+
+	security := &models.InvestmentSecurity{}
+	has, err = sess.ID(securityId).Where("uid=? AND deleted=?", uid, false).Get(security)
+
+	if err != nil {
+		return err
+	} else if !has {
+		return errs.ErrInvestmentSecurityNotFound
+	}
+
+	if security.Currency != account.Currency {
+		return errs.ErrInvestmentSecurityCurrencyNotEqualsToAccount
+	}
+
+	return nil
+}
+
+// checkHoldingsAfterChange returns error if the holding quantity of the security would be negative at any time after the transaction is replaced (or removed if new transaction is nil)
+func (s *InvestmentService) checkHoldingsAfterChange(sess *xorm.Session, uid int64, accountId int64, securityId int64, newTransaction *models.InvestmentTransaction, oldTransactionId int64) error {
+	var transactions []*models.InvestmentTransaction
+	err := sess.Where("uid=? AND deleted=? AND account_id=? AND security_id=?", uid, false, accountId, securityId).Find(&transactions)
+
+	if err != nil {
+		return err
+	}
+
+	changedTransactions := make([]*models.InvestmentTransaction, 0, len(transactions)+1)
+
+	for i := 0; i < len(transactions); i++ {
+		if transactions[i].TransactionId == oldTransactionId {
+			if newTransaction != nil {
+				newTransaction.CreatedUnixTime = transactions[i].CreatedUnixTime
+			}
+
+			continue
+		}
+
+		changedTransactions = append(changedTransactions, transactions[i])
+	}
+
+	if newTransaction != nil {
+		changedTransactions = append(changedTransactions, newTransaction)
+	}
+
+	sort.SliceStable(changedTransactions, func(i, j int) bool {
+		if changedTransactions[i].TradeUnixTime != changedTransactions[j].TradeUnixTime {
+			return changedTransactions[i].TradeUnixTime < changedTransactions[j].TradeUnixTime
+		}
+
+		return changedTransactions[i].CreatedUnixTime < changedTransactions[j].CreatedUnixTime
+	})
+
+	_, err = s.calculateHoldings(changedTransactions)
+
+	return err
+}
+
+// calculateHoldings returns the holdings of every security using average cost method, the transactions must be in ascending order of trade time
+func (s *InvestmentService) calculateHoldings(transactions []*models.InvestmentTransaction) ([]*models.InvestmentHolding, error) {
+	holdings := make([]*models.InvestmentHolding, 0)
+	holdingMap := make(map[int64]*models.InvestmentHolding)
+
+	for i := 0; i < len(transactions); i++ {
+		transaction := transactions[i]
+		holding, exists := holdingMap[transaction.SecurityId]
+
+		if !exists {
+			holding = &models.InvestmentHolding{
+				SecurityId: transaction.SecurityId,
+			}
+			holdingMap[transaction.SecurityId] = holding
+			holdings = append(holdings, holding)
+		}
+
+		if transaction.Type == models.INVESTMENT_TRANSACTION_TYPE_BUY {
+			holding.Quantity += transaction.Quantity
+			holding.CostBasis += transaction.Amount
+		} else if transaction.Type == models.INVESTMENT_TRANSACTION_TYPE_SELL {
+			if transaction.Quantity > holding.Quantity {
+				return nil, errs.ErrInvestmentSellQuantityExceedsHolding
+			}
+
+			soldCostBasis := holding.CostBasis
+
+			// The product of cost basis and quantity may overflow int64, so calculate it in big integer
+			if transaction.Quantity < holding.Quantity {
+				soldCostBasis = new(big.Int).Div(new(big.Int).Mul(big.NewInt(holding.CostBasis), big.NewInt(transaction.Quantity)), big.NewInt(holding.Quantity)).Int64()
+			}
+
+			holding.Quantity -= transaction.Quantity
+			holding.CostBasis -= soldCostBasis
+			holding.RealizedGain += transaction.Amount - soldCostBasis
+		} else if transaction.Type == models.INVESTMENT_TRANSACTION_TYPE_DIVIDEND {
+			holding.DividendIncome += transaction.Amount
+		}
+	}
+
+	return holdings, nil
+}
@@ -0,0 +1,124 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mayswind/ezbookkeeping/pkg/errs"
+	"github.com/mayswind/ezbookkeeping/pkg/models"
+)
+
+func TestInvestmentServiceCalculateHoldings(t *testing.T) {
+	testCases := []struct {
+		name                 string
+		transactions         []*models.InvestmentTransaction
+		expectedQuantity     int64
+		expectedCostBasis    int64
+		expectedRealizedGain int64
+		expectedDividend     int64
+	}{
+		{
+			name: "partial sell",
+			transactions: []*models.InvestmentTransaction{
+				{SecurityId: 1, Type: models.INVESTMENT_TRANSACTION_TYPE_BUY, Quantity: 100000, Amount: 10000},
+				{SecurityId: 1, Type: models.INVESTMENT_TRANSACTION_TYPE_BUY, Quantity: 200000, Amount: 26000},
+				{SecurityId: 1, Type: models.INVESTMENT_TRANSACTION_TYPE_SELL, Quantity: 100000, Amount: 15000},
+			},
+			expectedQuantity:     200000,
+			expectedCostBasis:    24000,
+			expectedRealizedGain: 3000,
+		},
+		{
+			name: "partial sell with rounding",
+			transactions: []*models.InvestmentTransaction{
+				{SecurityId: 1, Type: models.INVESTMENT_TRANSACTION_TYPE_BUY, Quantity: 30000, Amount: 1000},
+				{SecurityId: 1, Type: models.INVESTMENT_TRANSACTION_TYPE_SELL, Quantity: 10000, Amount: 500},
+			},
+			expectedQuantity:     20000,
+			expectedCostBasis:    667,
+			expectedRealizedGain: 167,
+		},
+		{
+			name: "full sell",
+			transactions: []*models.InvestmentTransaction{
+				{SecurityId: 1, Type: models.INVESTMENT_TRANSACTION_TYPE_BUY, Quantity: 30000, Amount: 1000},
+				{SecurityId: 1, Type: models.INVESTMENT_TRANSACTION_TYPE_SELL, Quantity: 10000, Amount: 500},
+				{SecurityId: 1, Type: models.INVESTMENT_TRANSACTION_TYPE_SELL, Quantity: 20000, Amount: 600},
+			},
+			expectedQuantity:     0,
+			expectedCostBasis:    0,
+			expectedRealizedGain: 100,
+		},
+		{
+			name: "dividend",
+			transactions: []*models.InvestmentTransaction{
+				{SecurityId: 1, Type: models.INVESTMENT_TRANSACTION_TYPE_BUY, Quantity: 10000, Amount: 1000},
+				{SecurityId: 1, Type: models.INVESTMENT_TRANSACTION_TYPE_DIVIDEND, Amount: 30},
+				{SecurityId: 1, Type: models.INVESTMENT_TRANSACTION_TYPE_DIVIDEND, Amount: 20},
+			},
+			expectedQuantity:  10000,
+			expectedCostBasis: 1000,
+			expectedDividend:  50,
+		},
+		{
+			name: "large cost basis and quantity",
+			transactions: []*models.InvestmentTransaction{
+				{SecurityId: 1, Type: models.INVESTMENT_TRANSACTION_TYPE_BUY, Quantity: 99999999999, Amount: 99999999999},
+				{SecurityId: 1, Type: models.INVESTMENT_TRANSACTION_TYPE_SELL, Quantity: 33333333333, Amount: 33333333333},
+			},
+			expectedQuantity:     66666666666,
+			expectedCostBasis:    66666666666,
+			expectedRealizedGain: 0,
+		},
+	}
+
+	for _, testCase := range testCases {
+		holdings, err := Investments.calculateHoldings(testCase.transactions)
+		assert.Nil(t, err, testCase.name)
+		assert.Equal(t, 1, len(holdings), testCase.name)
+		assert.Equal(t, testCase.expectedQuantity, holdings[0].Quantity, testCase.name)
+		assert.Equal(t, testCase.expectedCostBasis, holdings[0].CostBasis, testCase.name)
+		assert.Equal(t, testCase.expectedRealizedGain, holdings[0].RealizedGain, testCase.name)
+		assert.Equal(t, testCase.expectedDividend, holdings[0].DividendIncome, testCase.name)
+	}
+}
+
+func TestInvestmentServiceCalculateHoldings_MultipleSecurities(t *testing.T) {
+	transactions := []*models.InvestmentTransaction{
+		{SecurityId: 2, Type: models.INVESTMENT_TRANSACTION_TYPE_BUY, Quantity: 10000, Amount: 500},
+		{SecurityId: 1, Type: models.INVESTMENT_TRANSACTION_TYPE_BUY, Quantity: 20000, Amount: 800},
+		{SecurityId: 2, Type: models.INVESTMENT_TRANSACTION_TYPE_SELL, Quantity: 10000, Amount: 700},
+	}
+
+	holdings, err := Investments.calculateHoldings(transactions)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(holdings))
+
+	assert.Equal(t, int64(2), holdings[0].SecurityId)
+	assert.Equal(t, int64(0), holdings[0].Quantity)
+	assert.Equal(t, int64(200), holdings[0].RealizedGain)
+
+	assert.Equal(t, int64(1), holdings[1].SecurityId)
+	assert.Equal(t, int64(20000), holdings[1].Quantity)
+	assert.Equal(t, int64(800), holdings[1].CostBasis)
+}
+
+func TestInvestmentServiceCalculateHoldings_Oversell(t *testing.T) {
+	transactions := []*models.InvestmentTransaction{
+		{SecurityId: 1, Type: models.INVESTMENT_TRANSACTION_TYPE_BUY, Quantity: 10000, Amount: 1000},
+		{SecurityId: 1, Type: models.INVESTMENT_TRANSACTION_TYPE_SELL, Quantity: 10001, Amount: 1200},
+	}
+
+	holdings, err := Investments.calculateHoldings(transactions)
+	assert.Nil(t, holdings)
+	assert.Equal(t, errs.ErrInvestmentSellQuantityExceedsHolding, err)
+
+	transactions = []*models.InvestmentTransaction{
+		{SecurityId: 1, Type: models.INVESTMENT_TRANSACTION_TYPE_SELL, Quantity: 1, Amount: 1},
+	}
+
+	holdings, err = Investments.calculateHoldings(transactions)
+	assert.Nil(t, holdings)
+	assert.Equal(t, errs.ErrInvestmentSellQuantityExceedsHolding, err)
+}
@@ -0,0 +1,191 @@
+package services
+
+import (
+	"strings"
+	"time"
+
+	"xorm.io/xorm"
+
+	"github.com/mayswind/ezbookkeeping/pkg/datastore"
+	"github.com/mayswind/ezbookkeeping/pkg/errs"
+	"github.com/mayswind/ezbookkeeping/pkg/models"
+	"github.com/mayswind/ezbookkeeping/pkg/utils"
+	"github.com/mayswind/ezbookkeeping/pkg/uuid"
+)
+
+// SavingsGoalService represents savings goal service
+type SavingsGoalService struct {
+	ServiceUsingDB
+	ServiceUsingUuid
+}
+
+// Initialize a savings goal service singleton instance
+var (
+	SavingsGoals = &SavingsGoalService{
+		ServiceUsingDB: ServiceUsingDB{
+			container: datastore.Container,
+		},
+		ServiceUsingUuid: ServiceUsingUuid{
+			container: uuid.Container,
+		},
+	}
+)
+
+// GetAllGoalsByUid returns all savings goal models of user
+func (s *SavingsGoalService) GetAllGoalsByUid(uid int64) ([]*models.SavingsGoal, error) {
+	if uid <= 0 {
+		return nil, errs.ErrUserIdInvalid
+	}
+
+	var goals []*models.SavingsGoal
+	err := s.UserDataDB(uid).Where("uid=? AND deleted=?", uid, false).OrderBy("target_unix_time asc").Find(&goals)
+
+	return goals, err
+}
+
+// GetGoalByGoalId returns a savings goal model according to savings goal id
+func (s *SavingsGoalService) GetGoalByGoalId(uid int64, goalId int64) (*models.SavingsGoal, error) {
+	if uid <= 0 {
+		return nil, errs.ErrUserIdInvalid
+	}
+
+	if goalId <= 0 {
+		return nil, errs.ErrSavingsGoalIdInvalid
+	}
+
+	goal := &models.SavingsGoal{}
+	has, err := s.UserDataDB(uid).ID(goalId).Where("uid=? AND deleted=?", uid, false).Get(goal)
+
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, errs.ErrSavingsGoalNotFound
+	}
+
+	return goal, nil
+}
+
+// CreateGoal saves a new savings goal model linked to the given accounts to database
+func (s *SavingsGoalService) CreateGoal(goal *models.SavingsGoal, accountIds []int64) error {
+	if goal.Uid <= 0 {
+		return errs.ErrUserIdInvalid
+	}
+
+	accountIds = utils.ToUniqueInt64Slice(accountIds)
+
+	goal.GoalId = s.GenerateUuid(uuid.UUID_TYPE_BUDGET)
+	goal.AccountIds = strings.Join(utils.Int64ArrayToStringArray(accountIds), ",")
+
+	goal.Deleted = false
+	goal.CreatedUnixTime = time.Now().Unix()
+	goal.UpdatedUnixTime = time.Now().Unix()
+
+	return s.UserDataDB(goal.Uid).DoTransaction(func(sess *xorm.Session) error {
+		err := s.isAccountsValid(sess, goal.Uid, accountIds)
+
+		if err != nil {
+			return err
+		}
+
+		_, err = sess.Insert(goal)
+		return err
+	})
+}
+
+// ModifyGoal saves an existed savings goal model linked to the given accounts to database
+func (s *SavingsGoalService) ModifyGoal(goal *models.SavingsGoal, accountIds []int64) error {
+	if goal.Uid <= 0 {
+		return errs.ErrUserIdInvalid
+	}
+
+	accountIds = utils.ToUniqueInt64Slice(accountIds)
+
+	goal.AccountIds = strings.Join(utils.Int64ArrayToStringArray(accountIds), ",")
+	goal.UpdatedUnixTime = time.Now().Unix()
+
+	return s.UserDataDB(goal.Uid).DoTransaction(func(sess *xorm.Session) error {
+		err := s.isAccountsValid(sess, goal.Uid, accountIds)
+
+		if err != nil {
+			return err
+		}
+
+		updatedRows, err := sess.ID(goal.GoalId).Cols("name", "currency", "target_amount", "target_unix_time", "account_ids", "comment", "updated_unix_time").Where("uid=? AND deleted=?", goal.Uid, false).Update(goal)
+
+		if err != nil {
+			return err
+		} else if updatedRows < 1 {
+			return errs.ErrSavingsGoalNotFound
+		}
+
+		return err
+	})
+}
+
+// DeleteGoal deletes an existed savings goal from database
+func (s *SavingsGoalService) DeleteGoal(uid int64, goalId int64) error {
+	if uid <= 0 {
+		return errs.ErrUserIdInvalid
+	}
+
+	now := time.Now().Unix()
+
+	updateModel := &models.SavingsGoal{
+		Deleted:         true,
+		DeletedUnixTime: now,
+	}
+
+	return s.UserDataDB(uid).DoTransaction(func(sess *xorm.Session) error {
+		deletedRows, err := sess.ID(goalId).Cols("deleted", "deleted_unix_time").Where("uid=? AND deleted=?", uid, false).Update(updateModel)
+
+		if err != nil {
+			return err
+		} else if deletedRows < 1 {
+			return errs.ErrSavingsGoalNotFound
+		}
+
+		return err
+	})
+}
+
+// DeleteAllGoals deletes all existed savings goals from database
+func (s *SavingsGoalService) DeleteAllGoals(uid int64) error {
+	if uid <= 0 {
+		return errs.ErrUserIdInvalid
+	}
+
+	now := time.Now().Unix()
+
+	updateModel := &models.SavingsGoal{
+		Deleted:         true,
+		DeletedUnixTime: now,
+	}
+
+	return s.UserDataDB(uid).DoTransaction(func(sess *xorm.Session) error {
+		_, err := sess.Cols("deleted", "deleted_unix_time").Where("uid=? AND deleted=?", uid, false).Update(updateModel)
+		return err
+	})
+}
+
+func (s *SavingsGoalService) isAccountsValid(sess *xorm.Session, uid int64, accountIds []int64) error {
+	if len(accountIds) < 1 {
+		return errs.ErrSavingsGoalAccountIdInvalid
+	}
+
+	var accounts []*models.Account
+	err := sess.Where("uid=? AND deleted=?", uid, false).In("account_id", accountIds).Find(&accounts)
+
+	if err != nil {
+		return err
+	} else if len(accounts) < len(accountIds) {
+		return errs.ErrAccountNotFound
+	}
+
+	for i := 0; i < len(accounts); i++ {
+		if accounts[i].Type != models.ACCOUNT_TYPE_SINGLE_ACCOUNT || !accounts[i].IsAsset() {
+			return errs.ErrSavingsGoalAccountInvalid
+		}
+	}
+
+	return nil
+}
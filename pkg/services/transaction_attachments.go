@@ -0,0 +1,196 @@
+package services
+
+import (
+	"io"
+	"time"
+
+	"xorm.io/xorm"
+
+	"github.com/mayswind/ezbookkeeping/pkg/datastore"
+	"github.com/mayswind/ezbookkeeping/pkg/errs"
+	"github.com/mayswind/ezbookkeeping/pkg/log"
+	"github.com/mayswind/ezbookkeeping/pkg/models"
+	"github.com/mayswind/ezbookkeeping/pkg/storage"
+	"github.com/mayswind/ezbookkeeping/pkg/uuid"
+)
+
+// TransactionAttachmentService represents transaction attachment service
+type TransactionAttachmentService struct {
+	ServiceUsingDB
+	ServiceUsingUuid
+	ServiceUsingStorage
+}
+
+// Initialize a transaction attachment service singleton instance
+var (
+	TransactionAttachments = &TransactionAttachmentService{
+		ServiceUsingDB: ServiceUsingDB{
+			container: datastore.Container,
+		},
+		ServiceUsingUuid: ServiceUsingUuid{
+			container: uuid.Container,
+		},
+		ServiceUsingStorage: ServiceUsingStorage{
+			container: storage.Container,
+		},
+	}
+)
+
+// GetAllAttachmentsByTransactionId returns all transaction attachment models of given transaction
+func (s *TransactionAttachmentService) GetAllAttachmentsByTransactionId(uid int64, transactionId int64) ([]*models.TransactionAttachment, error) {
+	if uid <= 0 {
+		return nil, errs.ErrUserIdInvalid
+	}
+
+	if transactionId <= 0 {
+		return nil, errs.ErrTransactionIdInvalid
+	}
+
+	var attachments []*models.TransactionAttachment
+	err := s.UserDataDB(uid).Where("uid=? AND deleted=? AND transaction_id=?", uid, false, transactionId).OrderBy("created_unix_time asc, attachment_id asc").Find(&attachments)
+
+	return attachments, err
+}
+
+// GetAttachmentByAttachmentId returns a transaction attachment model according to transaction attachment id
+func (s *TransactionAttachmentService) GetAttachmentByAttachmentId(uid int64, attachmentId int64) (*models.TransactionAttachment, error) {
+	if uid <= 0 {
+		return nil, errs.ErrUserIdInvalid
+	}
+
+	if attachmentId <= 0 {
+		return nil, errs.ErrTransactionAttachmentIdInvalid
+	}
+
+	attachment := &models.TransactionAttachment{}
+	has, err := s.UserDataDB(uid).ID(attachmentId).Where("uid=? AND deleted=?", uid, false).Get(attachment)
+
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, errs.ErrTransactionAttachmentNotFound
+	}
+
+	return attachment, nil
+}
+
+// GetAttachmentContent returns the file content of given transaction attachment
+func (s *TransactionAttachmentService) GetAttachmentContent(attachment *models.TransactionAttachment) ([]byte, error) {
+	reader, err := s.ObjectStorage().Read(attachment.GetStoragePath())
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer reader.Close()
+
+	return io.ReadAll(reader)
+}
+
+// CreateAttachment saves the file content to object storage and saves a new transaction attachment model to database
+func (s *TransactionAttachmentService) CreateAttachment(attachment *models.TransactionAttachment, reader io.Reader) error {
+	if attachment.Uid <= 0 {
+		return errs.ErrUserIdInvalid
+	}
+
+	exists, err := s.UserDataDB(attachment.Uid).Cols("transaction_id").Where("uid=? AND deleted=? AND transaction_id=?", attachment.Uid, false, attachment.TransactionId).Exist(&models.Transaction{})
+
+	if err != nil {
+		return err
+	} else if !exists {
+		return errs.ErrTransactionNotFound
+	}
+
+	attachment.AttachmentId = s.GenerateUuid(uuid.UUID_TYPE_ATTACHMENT)
+
+	attachment.Deleted = false
+	attachment.CreatedUnixTime = time.Now().Unix()
+	attachment.UpdatedUnixTime = time.Now().Unix()
+
+	err = s.ObjectStorage().Save(attachment.GetStoragePath(), reader)
+
+	if err != nil {
+		return err
+	}
+
+	err = s.UserDataDB(attachment.Uid).DoTransaction(func(sess *xorm.Session) error {
+		_, err := sess.Insert(attachment)
+		return err
+	})
+
+	if err != nil {
+		if deleteErr := s.ObjectStorage().Delete(attachment.GetStoragePath()); deleteErr != nil {
+			log.Warnf("[transaction_attachments.CreateAttachment] failed to delete orphan attachment file \"%s\", because %s", attachment.GetStoragePath(), deleteErr.Error())
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// DeleteAttachment deletes an existed transaction attachment from database and removes its file from object storage
+func (s *TransactionAttachmentService) DeleteAttachment(uid int64, attachmentId int64) error {
+	if uid <= 0 {
+		return errs.ErrUserIdInvalid
+	}
+
+	now := time.Now().Unix()
+
+	updateModel := &models.TransactionAttachment{
+		Deleted:         true,
+		DeletedUnixTime: now,
+	}
+
+	err := s.UserDataDB(uid).DoTransaction(func(sess *xorm.Session) error {
+		deletedRows, err := sess.ID(attachmentId).Cols("deleted", "deleted_unix_time").Where("uid=? AND deleted=?", uid, false).Update(updateModel)
+
+		if err != nil {
+			return err
+		} else if deletedRows < 1 {
+			return errs.ErrTransactionAttachmentNotFound
+		}
+
+		return err
+	})
+
+	if err != nil {
+		return err
+	}
+
+	attachment := &models.TransactionAttachment{
+		Uid:          uid,
+		AttachmentId: attachmentId,
+	}
+
+	if err = s.ObjectStorage().Delete(attachment.GetStoragePath()); err != nil {
+		log.Warnf("[transaction_attachments.DeleteAttachment] failed to delete attachment file \"%s\", because %s", attachment.GetStoragePath(), err.Error())
+	}
+
+	return nil
+}
+
+// DeleteAllAttachments deletes all existed transaction attachments of user from database and removes their files from object storage
+func (s *TransactionAttachmentService) DeleteAllAttachments(uid int64) error {
+	if uid <= 0 {
+		return errs.ErrUserIdInvalid
+	}
+
+	now := time.Now().Unix()
+
+	updateModel := &models.TransactionAttachment{
+		Deleted:         true,
+		DeletedUnixTime: now,
+	}
+
+	err := s.UserDataDB(uid).DoTransaction(func(sess *xorm.Session) error {
+		_, err := sess.Cols("deleted", "deleted_unix_time").Where("uid=? AND deleted=?", uid, false).Update(updateModel)
+		return err
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return s.ObjectStorage().DeleteAll(models.GetTransactionAttachmentStoragePathPrefix(uid))
+}
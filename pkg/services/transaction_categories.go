@@ -8,6 +8,7 @@ import (
 	"github.com/mayswind/ezbookkeeping/pkg/datastore"
 	"github.com/mayswind/ezbookkeeping/pkg/errs"
 	"github.com/mayswind/ezbookkeeping/pkg/models"
+	"github.com/mayswind/ezbookkeeping/pkg/utils"
 	"github.com/mayswind/ezbookkeeping/pkg/uuid"
 )
 
@@ -325,6 +326,14 @@ func (s *TransactionCategoryService) DeleteCategory(uid int64, categoryId int64)
 			return errs.ErrTransactionCategoryInUseCannotBeDeleted
 		}
 
+		exists, err = sess.Cols("uid", "deleted", "category_id").Where("uid=? AND deleted=?", uid, false).In("category_id", categoryAndSubCategoryIds).Limit(1).Exist(&models.TransactionSplit{})
+
+		if err != nil {
+			return err
+		} else if exists {
+			return errs.ErrTransactionCategoryInUseCannotBeDeleted
+		}
+
 		deletedRows, err := sess.Cols("deleted", "deleted_unix_time").Where("uid=? AND deleted=?", uid, false).In("category_id", categoryAndSubCategoryIds).Update(updateModel)
 
 		if err != nil {
@@ -333,6 +342,198 @@ func (s *TransactionCategoryService) DeleteCategory(uid int64, categoryId int64)
 			return errs.ErrTransactionCategoryNotFound
 		}
 
+		budgetUpdateModel := &models.Budget{
+			Deleted:         true,
+			DeletedUnixTime: now,
+		}
+
+		_, err = sess.Cols("deleted", "deleted_unix_time").Where("uid=? AND deleted=?", uid, false).In("category_id", categoryAndSubCategoryIds).Update(budgetUpdateModel)
+
+		return err
+	})
+}
+
+// MergeCategories moves all transactions of the source categories and their sub categories to the target category, and then deletes the source categories
+func (s *TransactionCategoryService) MergeCategories(uid int64, fromCategoryIds []int64, toCategoryId int64, operator *models.TransactionOperator) error {
+	if uid <= 0 {
+		return errs.ErrUserIdInvalid
+	}
+
+	fromCategoryIds = utils.ToUniqueInt64Slice(fromCategoryIds)
+
+	for i := 0; i < len(fromCategoryIds); i++ {
+		if fromCategoryIds[i] == toCategoryId {
+			return errs.ErrCannotMergeTransactionCategoryIntoItself
+		}
+	}
+
+	now := time.Now().Unix()
+
+	return s.UserDataDB(uid).DoTransaction(func(sess *xorm.Session) error {
+		toCategory := &models.TransactionCategory{}
+		has, err := sess.ID(toCategoryId).Where("uid=? AND deleted=?", uid, false).Get(toCategory)
+
+		if err != nil {
+			return err
+		} else if !has {
+			return errs.ErrTransactionCategoryNotFound
+		}
+
+		if toCategory.ParentCategoryId == models.LevelOneTransactionParentId {
+			return errs.ErrCannotUsePrimaryCategoryForTransaction
+		}
+
+		var fromCategories []*models.TransactionCategory
+		err = sess.Where("uid=? AND deleted=?", uid, false).In("category_id", fromCategoryIds).Find(&fromCategories)
+
+		if err != nil {
+			return err
+		} else if len(fromCategories) != len(fromCategoryIds) {
+			return errs.ErrTransactionCategoryNotFound
+		}
+
+		for i := 0; i < len(fromCategories); i++ {
+			if fromCategories[i].Type != toCategory.Type {
+				return errs.ErrCannotMergeDifferentTypeCategories
+			}
+
+			if fromCategories[i].CategoryId == toCategory.ParentCategoryId {
+				return errs.ErrCannotMergeTransactionCategoryIntoItself
+			}
+		}
+
+		var subCategories []*models.TransactionCategory
+		err = sess.Where("uid=? AND deleted=?", uid, false).In("parent_category_id", fromCategoryIds).Find(&subCategories)
+
+		if err != nil {
+			return err
+		}
+
+		allFromCategoryIds := make([]int64, 0, len(fromCategories)+len(subCategories))
+
+		for i := 0; i < len(fromCategories); i++ {
+			allFromCategoryIds = append(allFromCategoryIds, fromCategories[i].CategoryId)
+		}
+
+		for i := 0; i < len(subCategories); i++ {
+			allFromCategoryIds = append(allFromCategoryIds, subCategories[i].CategoryId)
+		}
+
+		allFromCategoryIds = utils.ToUniqueInt64Slice(allFromCategoryIds)
+
+		// Deleted transactions are also moved, so that they can still be restored after source categories are deleted
+		var movedTransactions []*models.Transaction
+		err = sess.Cols("transaction_id").Where("uid=? AND type<>?", uid, models.TRANSACTION_DB_TYPE_TRANSFER_IN).In("category_id", allFromCategoryIds).Find(&movedTransactions)
+
+		if err != nil {
+			return err
+		}
+
+		var movedSplits []*models.TransactionSplit
+		err = sess.Cols("transaction_id").Where("uid=?", uid).In("category_id", allFromCategoryIds).Find(&movedSplits)
+
+		if err != nil {
+			return err
+		}
+
+		movedTransactionIds := make([]int64, 0, len(movedTransactions)+len(movedSplits))
+
+		for i := 0; i < len(movedTransactions); i++ {
+			movedTransactionIds = append(movedTransactionIds, movedTransactions[i].TransactionId)
+		}
+
+		for i := 0; i < len(movedSplits); i++ {
+			movedTransactionIds = append(movedTransactionIds, movedSplits[i].TransactionId)
+		}
+
+		movedTransactionIds = utils.ToUniqueInt64Slice(movedTransactionIds)
+		beforeSnapshots, err := Transactions.getTransactionHistorySnapshots(sess, uid, movedTransactionIds)
+
+		if err != nil {
+			return err
+		}
+
+		transactionUpdateModel := &models.Transaction{
+			CategoryId:      toCategoryId,
+			UpdatedUnixTime: now,
+		}
+
+		_, err = sess.Cols("category_id", "updated_unix_time").Where("uid=?", uid).In("category_id", allFromCategoryIds).Update(transactionUpdateModel)
+
+		if err != nil {
+			return err
+		}
+
+		splitUpdateModel := &models.TransactionSplit{
+			CategoryId:      toCategoryId,
+			UpdatedUnixTime: now,
+		}
+
+		_, err = sess.Cols("category_id", "updated_unix_time").Where("uid=?", uid).In("category_id", allFromCategoryIds).Update(splitUpdateModel)
+
+		if err != nil {
+			return err
+		}
+
+		err = Transactions.insertModifiedTransactionHistories(sess, uid, movedTransactionIds, operator, beforeSnapshots)
+
+		if err != nil {
+			return err
+		}
+
+		templateUpdateModel := &models.TransactionTemplate{
+			CategoryId:      toCategoryId,
+			UpdatedUnixTime: now,
+		}
+
+		_, err = sess.Cols("category_id", "updated_unix_time").Where("uid=? AND deleted=?", uid, false).In("category_id", allFromCategoryIds).Update(templateUpdateModel)
+
+		if err != nil {
+			return err
+		}
+
+		scheduleUpdateModel := &models.TransactionSchedule{
+			CategoryId:      toCategoryId,
+			UpdatedUnixTime: now,
+		}
+
+		_, err = sess.Cols("category_id", "updated_unix_time").Where("uid=? AND deleted=?", uid, false).In("category_id", allFromCategoryIds).Update(scheduleUpdateModel)
+
+		if err != nil {
+			return err
+		}
+
+		ruleUpdateModel := &models.TransactionRule{
+			ActionCategoryId: toCategoryId,
+			UpdatedUnixTime:  now,
+		}
+
+		_, err = sess.Cols("action_category_id", "updated_unix_time").Where("uid=? AND deleted=?", uid, false).In("action_category_id", allFromCategoryIds).Update(ruleUpdateModel)
+
+		if err != nil {
+			return err
+		}
+
+		categoryUpdateModel := &models.TransactionCategory{
+			Deleted:         true,
+			DeletedUnixTime: now,
+		}
+
+		deletedRows, err := sess.Cols("deleted", "deleted_unix_time").Where("uid=? AND deleted=?", uid, false).In("category_id", allFromCategoryIds).Update(categoryUpdateModel)
+
+		if err != nil {
+			return err
+		} else if deletedRows < int64(len(allFromCategoryIds)) {
+			return errs.ErrTransactionCategoryNotFound
+		}
+
+		budgetUpdateModel := &models.Budget{
+			Deleted:         true,
+			DeletedUnixTime: now,
+		}
+
+		_, err = sess.Cols("deleted", "deleted_unix_time").Where("uid=? AND deleted=?", uid, false).In("category_id", allFromCategoryIds).Update(budgetUpdateModel)
+
 		return err
 	})
 }
@@ -0,0 +1,49 @@
+package services
+
+import (
+	"github.com/mayswind/ezbookkeeping/pkg/datastore"
+	"github.com/mayswind/ezbookkeeping/pkg/errs"
+	"github.com/mayswind/ezbookkeeping/pkg/models"
+)
+
+// TransactionHistoryService represents transaction history service
+type TransactionHistoryService struct {
+	ServiceUsingDB
+}
+
+// Initialize a transaction history service singleton instance
+var (
+	TransactionHistories = &TransactionHistoryService{
+		ServiceUsingDB: ServiceUsingDB{
+			container: datastore.Container,
+		},
+	}
+)
+
+// GetAllHistoriesByUid returns all transaction history models of user
+func (s *TransactionHistoryService) GetAllHistoriesByUid(uid int64) ([]*models.TransactionHistory, error) {
+	if uid <= 0 {
+		return nil, errs.ErrUserIdInvalid
+	}
+
+	var histories []*models.TransactionHistory
+	err := s.UserDataDB(uid).Where("uid=?", uid).OrderBy("created_unix_time asc, history_id asc").Find(&histories)
+
+	return histories, err
+}
+
+// GetAllHistoriesByTransactionId returns all history models of given transaction
+func (s *TransactionHistoryService) GetAllHistoriesByTransactionId(uid int64, transactionId int64) ([]*models.TransactionHistory, error) {
+	if uid <= 0 {
+		return nil, errs.ErrUserIdInvalid
+	}
+
+	if transactionId <= 0 {
+		return nil, errs.ErrTransactionIdInvalid
+	}
+
+	var histories []*models.TransactionHistory
+	err := s.UserDataDB(uid).Where("uid=? AND transaction_id=?", uid, transactionId).OrderBy("created_unix_time asc, history_id asc").Find(&histories)
+
+	return histories, err
+}
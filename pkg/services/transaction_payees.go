@@ -0,0 +1,344 @@
+package services
+
+import (
+	"time"
+
+	"xorm.io/xorm"
+
+	"github.com/mayswind/ezbookkeeping/pkg/datastore"
+	"github.com/mayswind/ezbookkeeping/pkg/errs"
+	"github.com/mayswind/ezbookkeeping/pkg/models"
+	"github.com/mayswind/ezbookkeeping/pkg/utils"
+	"github.com/mayswind/ezbookkeeping/pkg/uuid"
+)
+
+// TransactionPayeeService represents transaction payee service
+type TransactionPayeeService struct {
+	ServiceUsingDB
+	ServiceUsingUuid
+}
+
+// Initialize a transaction payee service singleton instance
+var (
+	TransactionPayees = &TransactionPayeeService{
+		ServiceUsingDB: ServiceUsingDB{
+			container: datastore.Container,
+		},
+		ServiceUsingUuid: ServiceUsingUuid{
+			container: uuid.Container,
+		},
+	}
+)
+
+// GetAllPayeesByUid returns all transaction payee models of user
+func (s *TransactionPayeeService) GetAllPayeesByUid(uid int64) ([]*models.TransactionPayee, error) {
+	if uid <= 0 {
+		return nil, errs.ErrUserIdInvalid
+	}
+
+	var payees []*models.TransactionPayee
+	err := s.UserDataDB(uid).Where("uid=? AND deleted=?", uid, false).Find(&payees)
+
+	return payees, err
+}
+
+// GetPayeeByPayeeId returns a transaction payee model according to transaction payee id
+func (s *TransactionPayeeService) GetPayeeByPayeeId(uid int64, payeeId int64) (*models.TransactionPayee, error) {
+	if uid <= 0 {
+		return nil, errs.ErrUserIdInvalid
+	}
+
+	if payeeId <= 0 {
+		return nil, errs.ErrTransactionPayeeIdInvalid
+	}
+
+	payee := &models.TransactionPayee{}
+	has, err := s.UserDataDB(uid).ID(payeeId).Where("uid=? AND deleted=?", uid, false).Get(payee)
+
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, errs.ErrTransactionPayeeNotFound
+	}
+
+	return payee, nil
+}
+
+// GetPayeesByNamePrefix returns visible transaction payee models whose name starts with the given prefix
+func (s *TransactionPayeeService) GetPayeesByNamePrefix(uid int64, prefix string, count int) ([]*models.TransactionPayee, error) {
+	if uid <= 0 {
+		return nil, errs.ErrUserIdInvalid
+	}
+
+	var payees []*models.TransactionPayee
+	err := s.UserDataDB(uid).Where("uid=? AND deleted=? AND hidden=? AND name LIKE ?", uid, false, false, prefix+"%").OrderBy("name asc").Limit(count, 0).Find(&payees)
+
+	return payees, err
+}
+
+// GetLastUsedTransactionOfPayee returns the latest income or expense transaction of the given payee, or nil if the payee has never been used
+func (s *TransactionPayeeService) GetLastUsedTransactionOfPayee(uid int64, payeeId int64) (*models.Transaction, error) {
+	if uid <= 0 {
+		return nil, errs.ErrUserIdInvalid
+	}
+
+	if payeeId <= 0 {
+		return nil, errs.ErrTransactionPayeeIdInvalid
+	}
+
+	transaction := &models.Transaction{}
+	has, err := s.UserDataDB(uid).Cols("transaction_id", "type", "category_id", "account_id").Where("uid=? AND deleted=? AND payee_id=? AND (type=? OR type=?)", uid, false, payeeId, models.TRANSACTION_DB_TYPE_INCOME, models.TRANSACTION_DB_TYPE_EXPENSE).OrderBy("transaction_time desc").Limit(1).Get(transaction)
+
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, nil
+	}
+
+	return transaction, nil
+}
+
+// CreatePayee saves a new transaction payee model to database
+func (s *TransactionPayeeService) CreatePayee(payee *models.TransactionPayee) error {
+	if payee.Uid <= 0 {
+		return errs.ErrUserIdInvalid
+	}
+
+	exists, err := s.ExistsPayeeName(payee.Uid, payee.Name)
+
+	if err != nil {
+		return err
+	} else if exists {
+		return errs.ErrTransactionPayeeNameAlreadyExists
+	}
+
+	payee.PayeeId = s.GenerateUuid(uuid.UUID_TYPE_PAYEE)
+
+	payee.Deleted = false
+	payee.CreatedUnixTime = time.Now().Unix()
+	payee.UpdatedUnixTime = time.Now().Unix()
+
+	return s.UserDataDB(payee.Uid).DoTransaction(func(sess *xorm.Session) error {
+		_, err := sess.Insert(payee)
+		return err
+	})
+}
+
+// ModifyPayee saves an existed transaction payee model to database
+func (s *TransactionPayeeService) ModifyPayee(payee *models.TransactionPayee, nameChanged bool) error {
+	if payee.Uid <= 0 {
+		return errs.ErrUserIdInvalid
+	}
+
+	if nameChanged {
+		exists, err := s.ExistsPayeeName(payee.Uid, payee.Name)
+
+		if err != nil {
+			return err
+		} else if exists {
+			return errs.ErrTransactionPayeeNameAlreadyExists
+		}
+	}
+
+	payee.UpdatedUnixTime = time.Now().Unix()
+
+	return s.UserDataDB(payee.Uid).DoTransaction(func(sess *xorm.Session) error {
+		updatedRows, err := sess.ID(payee.PayeeId).Cols("name", "comment", "updated_unix_time").Where("uid=? AND deleted=?", payee.Uid, false).Update(payee)
+
+		if err != nil {
+			return err
+		} else if updatedRows < 1 {
+			return errs.ErrTransactionPayeeNotFound
+		}
+
+		return err
+	})
+}
+
+// HidePayee updates hidden field of given transaction payees
+func (s *TransactionPayeeService) HidePayee(uid int64, ids []int64, hidden bool) error {
+	if uid <= 0 {
+		return errs.ErrUserIdInvalid
+	}
+
+	now := time.Now().Unix()
+
+	updateModel := &models.TransactionPayee{
+		Hidden:          hidden,
+		UpdatedUnixTime: now,
+	}
+
+	return s.UserDataDB(uid).DoTransaction(func(sess *xorm.Session) error {
+		updatedRows, err := sess.Cols("hidden", "updated_unix_time").Where("uid=? AND deleted=?", uid, false).In("payee_id", ids).Update(updateModel)
+
+		if err != nil {
+			return err
+		} else if updatedRows < 1 {
+			return errs.ErrTransactionPayeeNotFound
+		}
+
+		return err
+	})
+}
+
+// MergePayees moves all transactions of the source payees to the target payee, and then deletes the source payees
+func (s *TransactionPayeeService) MergePayees(uid int64, fromPayeeIds []int64, toPayeeId int64, operator *models.TransactionOperator) error {
+	if uid <= 0 {
+		return errs.ErrUserIdInvalid
+	}
+
+	fromPayeeIds = utils.ToUniqueInt64Slice(fromPayeeIds)
+
+	for i := 0; i < len(fromPayeeIds); i++ {
+		if fromPayeeIds[i] == toPayeeId {
+			return errs.ErrCannotMergeTransactionPayeeIntoItself
+		}
+	}
+
+	now := time.Now().Unix()
+
+	return s.UserDataDB(uid).DoTransaction(func(sess *xorm.Session) error {
+		exists, err := sess.Cols("uid", "deleted", "payee_id").Where("uid=? AND deleted=? AND payee_id=?", uid, false, toPayeeId).Exist(&models.TransactionPayee{})
+
+		if err != nil {
+			return err
+		} else if !exists {
+			return errs.ErrTransactionPayeeNotFound
+		}
+
+		fromPayeeCount, err := sess.Where("uid=? AND deleted=?", uid, false).In("payee_id", fromPayeeIds).Count(&models.TransactionPayee{})
+
+		if err != nil {
+			return err
+		} else if fromPayeeCount != int64(len(fromPayeeIds)) {
+			return errs.ErrTransactionPayeeNotFound
+		}
+
+		// Deleted transactions are also moved, so that they can still be restored after source payees are deleted
+		var movedTransactions []*models.Transaction
+		err = sess.Cols("transaction_id").Where("uid=? AND type<>?", uid, models.TRANSACTION_DB_TYPE_TRANSFER_IN).In("payee_id", fromPayeeIds).Find(&movedTransactions)
+
+		if err != nil {
+			return err
+		}
+
+		movedTransactionIds := make([]int64, len(movedTransactions))
+
+		for i := 0; i < len(movedTransactions); i++ {
+			movedTransactionIds[i] = movedTransactions[i].TransactionId
+		}
+
+		beforeSnapshots, err := Transactions.getTransactionHistorySnapshots(sess, uid, movedTransactionIds)
+
+		if err != nil {
+			return err
+		}
+
+		transactionUpdateModel := &models.Transaction{
+			PayeeId:         toPayeeId,
+			UpdatedUnixTime: now,
+		}
+
+		_, err = sess.Cols("payee_id", "updated_unix_time").Where("uid=?", uid).In("payee_id", fromPayeeIds).Update(transactionUpdateModel)
+
+		if err != nil {
+			return err
+		}
+
+		err = Transactions.insertModifiedTransactionHistories(sess, uid, movedTransactionIds, operator, beforeSnapshots)
+
+		if err != nil {
+			return err
+		}
+
+		payeeUpdateModel := &models.TransactionPayee{
+			Deleted:         true,
+			DeletedUnixTime: now,
+		}
+
+		deletedRows, err := sess.Cols("deleted", "deleted_unix_time").Where("uid=? AND deleted=?", uid, false).In("payee_id", fromPayeeIds).Update(payeeUpdateModel)
+
+		if err != nil {
+			return err
+		} else if deletedRows < int64(len(fromPayeeIds)) {
+			return errs.ErrTransactionPayeeNotFound
+		}
+
+		return nil
+	})
+}
+
+// DeletePayee deletes an existed transaction payee from database
+func (s *TransactionPayeeService) DeletePayee(uid int64, payeeId int64) error {
+	if uid <= 0 {
+		return errs.ErrUserIdInvalid
+	}
+
+	now := time.Now().Unix()
+
+	updateModel := &models.TransactionPayee{
+		Deleted:         true,
+		DeletedUnixTime: now,
+	}
+
+	return s.UserDataDB(uid).DoTransaction(func(sess *xorm.Session) error {
+		exists, err := sess.Cols("uid", "deleted", "payee_id").Where("uid=? AND deleted=? AND payee_id=?", uid, false, payeeId).Limit(1).Exist(&models.Transaction{})
+
+		if err != nil {
+			return err
+		} else if exists {
+			return errs.ErrTransactionPayeeInUseCannotBeDeleted
+		}
+
+		deletedRows, err := sess.ID(payeeId).Cols("deleted", "deleted_unix_time").Where("uid=? AND deleted=?", uid, false).Update(updateModel)
+
+		if err != nil {
+			return err
+		} else if deletedRows < 1 {
+			return errs.ErrTransactionPayeeNotFound
+		}
+
+		return err
+	})
+}
+
+// DeleteAllPayees deletes all existed transaction payees from database
+func (s *TransactionPayeeService) DeleteAllPayees(uid int64) error {
+	if uid <= 0 {
+		return errs.ErrUserIdInvalid
+	}
+
+	now := time.Now().Unix()
+
+	updateModel := &models.TransactionPayee{
+		Deleted:         true,
+		DeletedUnixTime: now,
+	}
+
+	return s.UserDataDB(uid).DoTransaction(func(sess *xorm.Session) error {
+		exists, err := sess.Cols("uid", "deleted", "payee_id").Where("uid=? AND deleted=? AND payee_id<>?", uid, false, 0).Limit(1).Exist(&models.Transaction{})
+
+		if err != nil {
+			return err
+		} else if exists {
+			return errs.ErrTransactionPayeeInUseCannotBeDeleted
+		}
+
+		_, err = sess.Cols("deleted", "deleted_unix_time").Where("uid=? AND deleted=?", uid, false).Update(updateModel)
+
+		if err != nil {
+			return err
+		}
+
+		return nil
+	})
+}
+
+// ExistsPayeeName returns whether the given payee name exists
+func (s *TransactionPayeeService) ExistsPayeeName(uid int64, name string) (bool, error) {
+	if name == "" {
+		return false, errs.ErrTransactionPayeeNameIsEmpty
+	}
+
+	return s.UserDataDB(uid).Cols("name").Where("uid=? AND deleted=? AND name=?", uid, false, name).Exist(&models.TransactionPayee{})
+}
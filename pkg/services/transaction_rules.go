@@ -0,0 +1,331 @@
+package services
+
+import (
+	"strings"
+	"time"
+
+	"xorm.io/xorm"
+
+	"github.com/mayswind/ezbookkeeping/pkg/datastore"
+	"github.com/mayswind/ezbookkeeping/pkg/errs"
+	"github.com/mayswind/ezbookkeeping/pkg/models"
+	"github.com/mayswind/ezbookkeeping/pkg/utils"
+	"github.com/mayswind/ezbookkeeping/pkg/uuid"
+)
+
+// TransactionRuleService represents transaction rule service
+type TransactionRuleService struct {
+	ServiceUsingDB
+	ServiceUsingUuid
+}
+
+// Initialize a transaction rule service singleton instance
+var (
+	TransactionRules = &TransactionRuleService{
+		ServiceUsingDB: ServiceUsingDB{
+			container: datastore.Container,
+		},
+		ServiceUsingUuid: ServiceUsingUuid{
+			container: uuid.Container,
+		},
+	}
+)
+
+// GetAllRulesByUid returns all transaction rule models of user
+func (s *TransactionRuleService) GetAllRulesByUid(uid int64) ([]*models.TransactionRule, error) {
+	if uid <= 0 {
+		return nil, errs.ErrUserIdInvalid
+	}
+
+	var rules []*models.TransactionRule
+	err := s.UserDataDB(uid).Where("uid=? AND deleted=?", uid, false).OrderBy("priority asc, created_unix_time asc").Find(&rules)
+
+	return rules, err
+}
+
+// GetAllEnabledRulesByUid returns all enabled transaction rule models of user in priority order
+func (s *TransactionRuleService) GetAllEnabledRulesByUid(uid int64) ([]*models.TransactionRule, error) {
+	if uid <= 0 {
+		return nil, errs.ErrUserIdInvalid
+	}
+
+	var rules []*models.TransactionRule
+	err := s.UserDataDB(uid).Where("uid=? AND deleted=? AND disabled=?", uid, false, false).OrderBy("priority asc, created_unix_time asc").Find(&rules)
+
+	return rules, err
+}
+
+// GetRuleByRuleId returns a transaction rule model according to transaction rule id
+func (s *TransactionRuleService) GetRuleByRuleId(uid int64, ruleId int64) (*models.TransactionRule, error) {
+	if uid <= 0 {
+		return nil, errs.ErrUserIdInvalid
+	}
+
+	if ruleId <= 0 {
+		return nil, errs.ErrTransactionRuleIdInvalid
+	}
+
+	rule := &models.TransactionRule{}
+	has, err := s.UserDataDB(uid).ID(ruleId).Where("uid=? AND deleted=?", uid, false).Get(rule)
+
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, errs.ErrTransactionRuleNotFound
+	}
+
+	return rule, nil
+}
+
+// GetRulesByRuleIds returns transaction rule models according to transaction rule ids in priority order
+func (s *TransactionRuleService) GetRulesByRuleIds(uid int64, ruleIds []int64) ([]*models.TransactionRule, error) {
+	if uid <= 0 {
+		return nil, errs.ErrUserIdInvalid
+	}
+
+	if ruleIds == nil {
+		return nil, errs.ErrTransactionRuleIdInvalid
+	}
+
+	var rules []*models.TransactionRule
+	err := s.UserDataDB(uid).Where("uid=? AND deleted=?", uid, false).In("rule_id", ruleIds).OrderBy("priority asc, created_unix_time asc").Find(&rules)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rules) < len(utils.ToUniqueInt64Slice(ruleIds)) {
+		return nil, errs.ErrTransactionRuleNotFound
+	}
+
+	return rules, nil
+}
+
+// CreateRule saves a new transaction rule model to database
+func (s *TransactionRuleService) CreateRule(rule *models.TransactionRule, tagIds []int64) error {
+	if rule.Uid <= 0 {
+		return errs.ErrUserIdInvalid
+	}
+
+	tagIds = utils.ToUniqueInt64Slice(tagIds)
+
+	rule.RuleId = s.GenerateUuid(uuid.UUID_TYPE_RULE)
+	rule.ActionTagIds = strings.Join(utils.Int64ArrayToStringArray(tagIds), ",")
+
+	rule.Deleted = false
+	rule.CreatedUnixTime = time.Now().Unix()
+	rule.UpdatedUnixTime = time.Now().Unix()
+
+	return s.UserDataDB(rule.Uid).DoTransaction(func(sess *xorm.Session) error {
+		err := s.isRuleValid(sess, rule, tagIds)
+
+		if err != nil {
+			return err
+		}
+
+		_, err = sess.Insert(rule)
+		return err
+	})
+}
+
+// ModifyRule saves an existed transaction rule model to database
+func (s *TransactionRuleService) ModifyRule(rule *models.TransactionRule, tagIds []int64) error {
+	if rule.Uid <= 0 {
+		return errs.ErrUserIdInvalid
+	}
+
+	tagIds = utils.ToUniqueInt64Slice(tagIds)
+
+	rule.ActionTagIds = strings.Join(utils.Int64ArrayToStringArray(tagIds), ",")
+	rule.UpdatedUnixTime = time.Now().Unix()
+
+	return s.UserDataDB(rule.Uid).DoTransaction(func(sess *xorm.Session) error {
+		err := s.isRuleValid(sess, rule, tagIds)
+
+		if err != nil {
+			return err
+		}
+
+		updatedRows, err := sess.ID(rule.RuleId).Cols("name", "priority", "disabled", "condition_type", "condition_comment", "condition_payee_id", "condition_account_id", "condition_min_amount", "condition_max_amount", "action_category_id", "action_tag_ids", "action_hide_amount", "updated_unix_time").Where("uid=? AND deleted=?", rule.Uid, false).Update(rule)
+
+		if err != nil {
+			return err
+		} else if updatedRows < 1 {
+			return errs.ErrTransactionRuleNotFound
+		}
+
+		return err
+	})
+}
+
+// DeleteRule deletes an existed transaction rule from database
+func (s *TransactionRuleService) DeleteRule(uid int64, ruleId int64) error {
+	if uid <= 0 {
+		return errs.ErrUserIdInvalid
+	}
+
+	now := time.Now().Unix()
+
+	updateModel := &models.TransactionRule{
+		Deleted:         true,
+		DeletedUnixTime: now,
+	}
+
+	return s.UserDataDB(uid).DoTransaction(func(sess *xorm.Session) error {
+		deletedRows, err := sess.ID(ruleId).Cols("deleted", "deleted_unix_time").Where("uid=? AND deleted=?", uid, false).Update(updateModel)
+
+		if err != nil {
+			return err
+		} else if deletedRows < 1 {
+			return errs.ErrTransactionRuleNotFound
+		}
+
+		return err
+	})
+}
+
+// DeleteAllRules deletes all existed transaction rules from database
+func (s *TransactionRuleService) DeleteAllRules(uid int64) error {
+	if uid <= 0 {
+		return errs.ErrUserIdInvalid
+	}
+
+	now := time.Now().Unix()
+
+	updateModel := &models.TransactionRule{
+		Deleted:         true,
+		DeletedUnixTime: now,
+	}
+
+	return s.UserDataDB(uid).DoTransaction(func(sess *xorm.Session) error {
+		_, err := sess.Cols("deleted", "deleted_unix_time").Where("uid=? AND deleted=?", uid, false).Update(updateModel)
+		return err
+	})
+}
+
+// ApplyRulesToTransaction applies the given rules to the transaction in order and returns the tag ids with tags added by rules and the ids of matched rules.
+// Only the first matched rule which sets category can change the category, and the category of split transaction would never be changed.
+func (s *TransactionRuleService) ApplyRulesToTransaction(rules []*models.TransactionRule, transaction *models.Transaction, tagIds []int64, hasSplits bool) ([]int64, []int64) {
+	var matchedRuleIds []int64
+	newTagIds := make([]int64, 0, len(tagIds))
+	newTagIds = append(newTagIds, tagIds...)
+	categorySet := false
+
+	for i := 0; i < len(rules); i++ {
+		rule := rules[i]
+
+		if !s.isRuleMatched(rule, transaction) {
+			continue
+		}
+
+		matchedRuleIds = append(matchedRuleIds, rule.RuleId)
+
+		if rule.ActionCategoryId > 0 && !categorySet && !hasSplits {
+			transaction.CategoryId = rule.ActionCategoryId
+			categorySet = true
+		}
+
+		if rule.ActionTagIds != "" {
+			ruleTagIds, err := utils.StringArrayToInt64Array(rule.GetActionTagIdStrings())
+
+			if err == nil {
+				newTagIds = utils.ToUniqueInt64Slice(append(newTagIds, ruleTagIds...))
+			}
+		}
+
+		if rule.ActionHideAmount {
+			transaction.HideAmount = true
+		}
+	}
+
+	return newTagIds, matchedRuleIds
+}
+
+func (s *TransactionRuleService) isRuleMatched(rule *models.TransactionRule, transaction *models.Transaction) bool {
+	if rule.ConditionType != 0 && rule.ConditionType != transaction.Type {
+		return false
+	}
+
+	if rule.ConditionComment != "" && !strings.Contains(strings.ToLower(transaction.Comment), strings.ToLower(rule.ConditionComment)) {
+		return false
+	}
+
+	if rule.ConditionPayeeId != 0 && rule.ConditionPayeeId != transaction.PayeeId {
+		return false
+	}
+
+	if rule.ConditionAccountId != 0 && rule.ConditionAccountId != transaction.AccountId {
+		return false
+	}
+
+	if rule.ConditionMinAmount != nil && transaction.Amount < *rule.ConditionMinAmount {
+		return false
+	}
+
+	if rule.ConditionMaxAmount != nil && transaction.Amount > *rule.ConditionMaxAmount {
+		return false
+	}
+
+	return true
+}
+
+func (s *TransactionRuleService) isRuleValid(sess *xorm.Session, rule *models.TransactionRule, tagIds []int64) error {
+	if !rule.HasCondition() {
+		return errs.ErrTransactionRuleNoCondition
+	}
+
+	if !rule.HasAction() {
+		return errs.ErrTransactionRuleNoAction
+	}
+
+	if rule.ConditionMinAmount != nil && rule.ConditionMaxAmount != nil && *rule.ConditionMinAmount > *rule.ConditionMaxAmount {
+		return errs.ErrTransactionRuleAmountRangeInvalid
+	}
+
+	if rule.ConditionPayeeId > 0 {
+		exists, err := sess.Cols("uid", "deleted", "payee_id").Where("uid=? AND deleted=? AND payee_id=?", rule.Uid, false, rule.ConditionPayeeId).Exist(&models.TransactionPayee{})
+
+		if err != nil {
+			return err
+		} else if !exists {
+			return errs.ErrTransactionPayeeNotFound
+		}
+	}
+
+	if rule.ConditionAccountId > 0 {
+		exists, err := sess.Cols("uid", "deleted", "account_id").Where("uid=? AND deleted=? AND account_id=?", rule.Uid, false, rule.ConditionAccountId).Exist(&models.Account{})
+
+		if err != nil {
+			return err
+		} else if !exists {
+			return errs.ErrAccountNotFound
+		}
+	}
+
+	if rule.ActionCategoryId > 0 {
+		if rule.ConditionType != models.TRANSACTION_DB_TYPE_INCOME && rule.ConditionType != models.TRANSACTION_DB_TYPE_EXPENSE && rule.ConditionType != models.TRANSACTION_DB_TYPE_TRANSFER_OUT {
+			return errs.ErrTransactionRuleCategoryActionRequiresType
+		}
+
+		err := Transactions.isCategoryValid(sess, &models.Transaction{
+			Uid:        rule.Uid,
+			Type:       rule.ConditionType,
+			CategoryId: rule.ActionCategoryId,
+		})
+
+		if err != nil {
+			return err
+		}
+	}
+
+	transactionTagIndexs := make([]*models.TransactionTagIndex, len(tagIds))
+
+	for i := 0; i < len(tagIds); i++ {
+		transactionTagIndexs[i] = &models.TransactionTagIndex{
+			Uid:   rule.Uid,
+			TagId: tagIds[i],
+		}
+	}
+
+	return Transactions.isTagsValid(sess, &models.Transaction{Uid: rule.Uid}, transactionTagIndexs, tagIds)
+}
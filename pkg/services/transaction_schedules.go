@@ -0,0 +1,273 @@
+package services
+
+import (
+	"strings"
+	"time"
+
+	"xorm.io/xorm"
+
+	"github.com/mayswind/ezbookkeeping/pkg/datastore"
+	"github.com/mayswind/ezbookkeeping/pkg/errs"
+	"github.com/mayswind/ezbookkeeping/pkg/models"
+	"github.com/mayswind/ezbookkeeping/pkg/utils"
+	"github.com/mayswind/ezbookkeeping/pkg/uuid"
+)
+
+// TransactionScheduleService represents transaction schedule service
+type TransactionScheduleService struct {
+	ServiceUsingDB
+	ServiceUsingUuid
+}
+
+// Initialize a transaction schedule service singleton instance
+var (
+	TransactionSchedules = &TransactionScheduleService{
+		ServiceUsingDB: ServiceUsingDB{
+			container: datastore.Container,
+		},
+		ServiceUsingUuid: ServiceUsingUuid{
+			container: uuid.Container,
+		},
+	}
+)
+
+// GetAllSchedulesByUid returns all transaction schedule models of user
+func (s *TransactionScheduleService) GetAllSchedulesByUid(uid int64) ([]*models.TransactionSchedule, error) {
+	if uid <= 0 {
+		return nil, errs.ErrUserIdInvalid
+	}
+
+	var schedules []*models.TransactionSchedule
+	err := s.UserDataDB(uid).Where("uid=? AND deleted=?", uid, false).Find(&schedules)
+
+	return schedules, err
+}
+
+// GetScheduleByScheduleId returns a transaction schedule model according to transaction schedule id
+func (s *TransactionScheduleService) GetScheduleByScheduleId(uid int64, scheduleId int64) (*models.TransactionSchedule, error) {
+	if uid <= 0 {
+		return nil, errs.ErrUserIdInvalid
+	}
+
+	if scheduleId <= 0 {
+		return nil, errs.ErrTransactionScheduleIdInvalid
+	}
+
+	schedule := &models.TransactionSchedule{}
+	has, err := s.UserDataDB(uid).ID(scheduleId).Where("uid=? AND deleted=?", uid, false).Get(schedule)
+
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, errs.ErrTransactionScheduleNotFound
+	}
+
+	return schedule, nil
+}
+
+// GetAllDueSchedules returns transaction schedule models of all users whose next occurrence is not later than given unix time
+func (s *TransactionScheduleService) GetAllDueSchedules(maxUnixTime int64, count int) ([]*models.TransactionSchedule, error) {
+	if count < 1 {
+		return nil, errs.ErrPageCountInvalid
+	}
+
+	var schedules []*models.TransactionSchedule
+	err := s.UserDataDB(0).Where("deleted=? AND next_unix_time>? AND next_unix_time<=?", false, 0, maxUnixTime).Limit(count, 0).OrderBy("next_unix_time asc").Find(&schedules)
+
+	return schedules, err
+}
+
+// CreateSchedule saves a new transaction schedule model to database
+func (s *TransactionScheduleService) CreateSchedule(schedule *models.TransactionSchedule, tagIds []int64) error {
+	if schedule.Uid <= 0 {
+		return errs.ErrUserIdInvalid
+	}
+
+	if !schedule.IsFrequencyValid() {
+		return errs.ErrTransactionScheduleFrequencyInvalid
+	}
+
+	if schedule.EndUnixTime > 0 && schedule.EndUnixTime < schedule.StartUnixTime {
+		return errs.ErrTransactionScheduleEndTimeInvalid
+	}
+
+	tagIds = utils.ToUniqueInt64Slice(tagIds)
+
+	schedule.ScheduleId = s.GenerateUuid(uuid.UUID_TYPE_SCHEDULE)
+	schedule.TagIds = strings.Join(utils.Int64ArrayToStringArray(tagIds), ",")
+	schedule.ExecutedCount = 0
+	schedule.NextUnixTime = schedule.GetNextUnixTime()
+
+	schedule.Deleted = false
+	schedule.CreatedUnixTime = time.Now().Unix()
+	schedule.UpdatedUnixTime = time.Now().Unix()
+
+	return s.UserDataDB(schedule.Uid).DoTransaction(func(sess *xorm.Session) error {
+		err := s.isScheduleValid(sess, schedule, tagIds)
+
+		if err != nil {
+			return err
+		}
+
+		_, err = sess.Insert(schedule)
+		return err
+	})
+}
+
+// ModifySchedule saves an existed transaction schedule model to database, the old transaction schedule model is used to make sure no occurrence has been processed after it was read
+func (s *TransactionScheduleService) ModifySchedule(schedule *models.TransactionSchedule, oldSchedule *models.TransactionSchedule, tagIds []int64) error {
+	if schedule.Uid <= 0 {
+		return errs.ErrUserIdInvalid
+	}
+
+	if !schedule.IsFrequencyValid() {
+		return errs.ErrTransactionScheduleFrequencyInvalid
+	}
+
+	if schedule.EndUnixTime > 0 && schedule.EndUnixTime < schedule.StartUnixTime {
+		return errs.ErrTransactionScheduleEndTimeInvalid
+	}
+
+	tagIds = utils.ToUniqueInt64Slice(tagIds)
+
+	schedule.TagIds = strings.Join(utils.Int64ArrayToStringArray(tagIds), ",")
+	schedule.NextUnixTime = schedule.GetNextUnixTime()
+	schedule.UpdatedUnixTime = time.Now().Unix()
+
+	return s.UserDataDB(schedule.Uid).DoTransaction(func(sess *xorm.Session) error {
+		err := s.isScheduleValid(sess, schedule, tagIds)
+
+		if err != nil {
+			return err
+		}
+
+		updatedRows, err := sess.ID(schedule.ScheduleId).Cols("category_id", "account_id", "related_account_id", "amount", "related_account_amount", "hide_amount", "tag_ids", "comment", "timezone_utc_offset", "frequency", "frequency_interval", "start_unix_time", "end_unix_time", "next_unix_time", "executed_count", "updated_unix_time").Where("uid=? AND deleted=? AND next_unix_time=? AND executed_count=?", schedule.Uid, false, oldSchedule.NextUnixTime, oldSchedule.ExecutedCount).Update(schedule)
+
+		if err != nil {
+			return err
+		} else if updatedRows < 1 {
+			exists, err := sess.ID(schedule.ScheduleId).Where("uid=? AND deleted=?", schedule.Uid, false).Exist(&models.TransactionSchedule{})
+
+			if err != nil {
+				return err
+			} else if !exists {
+				return errs.ErrTransactionScheduleNotFound
+			}
+
+			return errs.ErrTransactionScheduleHasBeenProcessed
+		}
+
+		return err
+	})
+}
+
+// DeleteSchedule deletes an existed transaction schedule from database
+func (s *TransactionScheduleService) DeleteSchedule(uid int64, scheduleId int64) error {
+	if uid <= 0 {
+		return errs.ErrUserIdInvalid
+	}
+
+	now := time.Now().Unix()
+
+	updateModel := &models.TransactionSchedule{
+		Deleted:         true,
+		DeletedUnixTime: now,
+	}
+
+	return s.UserDataDB(uid).DoTransaction(func(sess *xorm.Session) error {
+		deletedRows, err := sess.ID(scheduleId).Cols("deleted", "deleted_unix_time").Where("uid=? AND deleted=?", uid, false).Update(updateModel)
+
+		if err != nil {
+			return err
+		} else if deletedRows < 1 {
+			return errs.ErrTransactionScheduleNotFound
+		}
+
+		return err
+	})
+}
+
+// ExecuteSchedule creates the transaction of the next occurrence of transaction schedule, and moves the transaction schedule to the following occurrence
+func (s *TransactionScheduleService) ExecuteSchedule(schedule *models.TransactionSchedule) (*models.Transaction, error) {
+	if schedule.Uid <= 0 {
+		return nil, errs.ErrUserIdInvalid
+	}
+
+	if schedule.NextUnixTime <= 0 {
+		return nil, errs.ErrTransactionScheduleHasBeenProcessed
+	}
+
+	tagIds, err := schedule.GetTagIds()
+
+	if err != nil {
+		return nil, errs.ErrTransactionTagIdInvalid
+	}
+
+	transaction := schedule.ToTransaction(schedule.NextUnixTime)
+
+	err = s.UserDataDB(schedule.Uid).DoTransaction(func(sess *xorm.Session) error {
+		err := s.moveToNextOccurrence(sess, schedule)
+
+		if err != nil {
+			return err
+		}
+
+		balanceChanges := make(accountBalanceChanges)
+		err = Transactions.createTransaction(sess, transaction, tagIds, nil, nil, balanceChanges)
+
+		if err != nil {
+			return err
+		}
+
+		return Transactions.updateAccountBalances(sess, transaction.Uid, balanceChanges)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return transaction, nil
+}
+
+// SkipSchedule moves the transaction schedule to the following occurrence without creating transaction
+func (s *TransactionScheduleService) SkipSchedule(schedule *models.TransactionSchedule) error {
+	if schedule.Uid <= 0 {
+		return errs.ErrUserIdInvalid
+	}
+
+	if schedule.NextUnixTime <= 0 {
+		return errs.ErrTransactionScheduleHasBeenProcessed
+	}
+
+	return s.UserDataDB(schedule.Uid).DoTransaction(func(sess *xorm.Session) error {
+		return s.moveToNextOccurrence(sess, schedule)
+	})
+}
+
+func (s *TransactionScheduleService) moveToNextOccurrence(sess *xorm.Session, schedule *models.TransactionSchedule) error {
+	nextSchedule := *schedule
+	nextSchedule.ExecutedCount = schedule.ExecutedCount + 1
+	nextSchedule.NextUnixTime = nextSchedule.GetNextUnixTime()
+	nextSchedule.UpdatedUnixTime = time.Now().Unix()
+
+	// Only the executor which still sees the current occurrence can move the schedule, so each occurrence is processed only once
+	updatedRows, err := sess.ID(schedule.ScheduleId).Cols("next_unix_time", "executed_count", "updated_unix_time").Where("uid=? AND deleted=? AND next_unix_time=? AND executed_count=?", schedule.Uid, false, schedule.NextUnixTime, schedule.ExecutedCount).Update(&nextSchedule)
+
+	if err != nil {
+		return err
+	} else if updatedRows < 1 {
+		return errs.ErrTransactionScheduleHasBeenProcessed
+	}
+
+	return nil
+}
+
+func (s *TransactionScheduleService) isScheduleValid(sess *xorm.Session, schedule *models.TransactionSchedule, tagIds []int64) error {
+	if schedule.Type == models.TRANSACTION_DB_TYPE_MODIFY_BALANCE {
+		return errs.ErrBalanceModificationTransactionCannotBeScheduled
+	}
+
+	transaction := schedule.ToTransaction(schedule.StartUnixTime)
+
+	return Transactions.isNewTransactionValid(sess, transaction, tagIds)
+}
@@ -0,0 +1,66 @@
+package services
+
+import (
+	"sort"
+
+	"github.com/mayswind/ezbookkeeping/pkg/datastore"
+	"github.com/mayswind/ezbookkeeping/pkg/errs"
+	"github.com/mayswind/ezbookkeeping/pkg/models"
+)
+
+// TransactionSplitService represents transaction split service
+type TransactionSplitService struct {
+	ServiceUsingDB
+}
+
+// Initialize a transaction split service singleton instance
+var (
+	TransactionSplits = &TransactionSplitService{
+		ServiceUsingDB: ServiceUsingDB{
+			container: datastore.Container,
+		},
+	}
+)
+
+// GetAllSplitsOfAllTransactions returns all transaction splits
+func (s *TransactionSplitService) GetAllSplitsOfAllTransactions(uid int64) (map[int64][]*models.TransactionSplit, error) {
+	if uid <= 0 {
+		return nil, errs.ErrUserIdInvalid
+	}
+
+	var splits []*models.TransactionSplit
+	err := s.UserDataDB(uid).Where("uid=? AND deleted=?", uid, false).Find(&splits)
+
+	allTransactionSplits := s.getGroupedTransactionSplits(splits)
+
+	return allTransactionSplits, err
+}
+
+// GetAllSplitsOfTransactions returns transaction splits for given transactions
+func (s *TransactionSplitService) GetAllSplitsOfTransactions(uid int64, transactionIds []int64) (map[int64][]*models.TransactionSplit, error) {
+	if uid <= 0 {
+		return nil, errs.ErrUserIdInvalid
+	}
+
+	var splits []*models.TransactionSplit
+	err := s.UserDataDB(uid).Where("uid=? AND deleted=?", uid, false).In("transaction_id", transactionIds).Find(&splits)
+
+	allTransactionSplits := s.getGroupedTransactionSplits(splits)
+
+	return allTransactionSplits, err
+}
+
+func (s *TransactionSplitService) getGroupedTransactionSplits(splits []*models.TransactionSplit) map[int64][]*models.TransactionSplit {
+	allTransactionSplits := make(map[int64][]*models.TransactionSplit)
+
+	for i := 0; i < len(splits); i++ {
+		split := splits[i]
+		allTransactionSplits[split.TransactionId] = append(allTransactionSplits[split.TransactionId], split)
+	}
+
+	for _, transactionSplits := range allTransactionSplits {
+		sort.Sort(models.TransactionSplitSlice(transactionSplits))
+	}
+
+	return allTransactionSplits
+}
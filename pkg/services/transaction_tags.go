@@ -1,6 +1,8 @@
 package services
 
 import (
+	"fmt"
+	"strings"
 	"time"
 
 	"xorm.io/xorm"
@@ -8,6 +10,7 @@ import (
 	"github.com/mayswind/ezbookkeeping/pkg/datastore"
 	"github.com/mayswind/ezbookkeeping/pkg/errs"
 	"github.com/mayswind/ezbookkeeping/pkg/models"
+	"github.com/mayswind/ezbookkeeping/pkg/utils"
 	"github.com/mayswind/ezbookkeeping/pkg/uuid"
 )
 
@@ -239,6 +242,196 @@ func (s *TransactionTagService) ModifyTagDisplayOrders(uid int64, tags []*models
 	})
 }
 
+// MergeTags rewrites all transaction tag indexes of the source tags to the target tag, and then deletes the source tags
+func (s *TransactionTagService) MergeTags(uid int64, fromTagIds []int64, toTagId int64) error {
+	if uid <= 0 {
+		return errs.ErrUserIdInvalid
+	}
+
+	fromTagIds = utils.ToUniqueInt64Slice(fromTagIds)
+	fromTagIdsMap := make(map[int64]bool, len(fromTagIds))
+
+	for i := 0; i < len(fromTagIds); i++ {
+		if fromTagIds[i] == toTagId {
+			return errs.ErrCannotMergeTransactionTagIntoItself
+		}
+
+		fromTagIdsMap[fromTagIds[i]] = true
+	}
+
+	now := time.Now().Unix()
+
+	return s.UserDataDB(uid).DoTransaction(func(sess *xorm.Session) error {
+		exists, err := sess.Cols("uid", "deleted", "tag_id").Where("uid=? AND deleted=? AND tag_id=?", uid, false, toTagId).Exist(&models.TransactionTag{})
+
+		if err != nil {
+			return err
+		} else if !exists {
+			return errs.ErrTransactionTagNotFound
+		}
+
+		fromTagCount, err := sess.Where("uid=? AND deleted=?", uid, false).In("tag_id", fromTagIds).Count(&models.TransactionTag{})
+
+		if err != nil {
+			return err
+		} else if fromTagCount != int64(len(fromTagIds)) {
+			return errs.ErrTransactionTagNotFound
+		}
+
+		// Deleted tag indexes are also rewritten, so that they can still be restored with deleted transactions
+		allTagIds := make([]int64, 0, len(fromTagIds)+1)
+		allTagIds = append(allTagIds, fromTagIds...)
+		allTagIds = append(allTagIds, toTagId)
+
+		var tagIndexes []*models.TransactionTagIndex
+		err = sess.Where("uid=?", uid).In("tag_id", allTagIds).OrderBy("tag_index_id asc").Find(&tagIndexes)
+
+		if err != nil {
+			return err
+		}
+
+		taggedTransactions := make(map[string]bool, len(tagIndexes))
+
+		for i := 0; i < len(tagIndexes); i++ {
+			if tagIndexes[i].TagId == toTagId {
+				taggedTransactions[s.getTagIndexMergeKey(tagIndexes[i])] = true
+			}
+		}
+
+		rewrittenTagIndexIds := make([]int64, 0, len(tagIndexes))
+		duplicatedTagIndexIds := make([]int64, 0, len(tagIndexes))
+
+		for i := 0; i < len(tagIndexes); i++ {
+			tagIndex := tagIndexes[i]
+
+			if tagIndex.TagId == toTagId {
+				continue
+			}
+
+			key := s.getTagIndexMergeKey(tagIndex)
+
+			if taggedTransactions[key] {
+				duplicatedTagIndexIds = append(duplicatedTagIndexIds, tagIndex.TagIndexId)
+			} else {
+				rewrittenTagIndexIds = append(rewrittenTagIndexIds, tagIndex.TagIndexId)
+				taggedTransactions[key] = true
+			}
+		}
+
+		if len(rewrittenTagIndexIds) > 0 {
+			tagIndexUpdateModel := &models.TransactionTagIndex{
+				TagId:           toTagId,
+				UpdatedUnixTime: now,
+			}
+
+			_, err = sess.Cols("tag_id", "updated_unix_time").Where("uid=?", uid).In("tag_index_id", rewrittenTagIndexIds).Update(tagIndexUpdateModel)
+
+			if err != nil {
+				return err
+			}
+		}
+
+		// The transaction already has the target tag, so the duplicated tag indexes would never be used again and remove them directly
+		if len(duplicatedTagIndexIds) > 0 {
+			_, err = sess.Where("uid=?", uid).In("tag_index_id", duplicatedTagIndexIds).Delete(&models.TransactionTagIndex{})
+
+			if err != nil {
+				return err
+			}
+		}
+
+		var templates []*models.TransactionTemplate
+		err = sess.Where("uid=? AND deleted=? AND tag_ids<>?", uid, false, "").Find(&templates)
+
+		if err != nil {
+			return err
+		}
+
+		for i := 0; i < len(templates); i++ {
+			template := templates[i]
+			newTagIds, changed := s.getMergedTagIdsString(template.TagIds, fromTagIdsMap, toTagId)
+
+			if !changed {
+				continue
+			}
+
+			template.TagIds = newTagIds
+			template.UpdatedUnixTime = now
+
+			_, err = sess.ID(template.TemplateId).Cols("tag_ids", "updated_unix_time").Where("uid=? AND deleted=?", uid, false).Update(template)
+
+			if err != nil {
+				return err
+			}
+		}
+
+		var schedules []*models.TransactionSchedule
+		err = sess.Where("uid=? AND deleted=? AND tag_ids<>?", uid, false, "").Find(&schedules)
+
+		if err != nil {
+			return err
+		}
+
+		for i := 0; i < len(schedules); i++ {
+			schedule := schedules[i]
+			newTagIds, changed := s.getMergedTagIdsString(schedule.TagIds, fromTagIdsMap, toTagId)
+
+			if !changed {
+				continue
+			}
+
+			schedule.TagIds = newTagIds
+			schedule.UpdatedUnixTime = now
+
+			_, err = sess.ID(schedule.ScheduleId).Cols("tag_ids", "updated_unix_time").Where("uid=? AND deleted=?", uid, false).Update(schedule)
+
+			if err != nil {
+				return err
+			}
+		}
+
+		var rules []*models.TransactionRule
+		err = sess.Where("uid=? AND deleted=? AND action_tag_ids<>?", uid, false, "").Find(&rules)
+
+		if err != nil {
+			return err
+		}
+
+		for i := 0; i < len(rules); i++ {
+			rule := rules[i]
+			newTagIds, changed := s.getMergedTagIdsString(rule.ActionTagIds, fromTagIdsMap, toTagId)
+
+			if !changed {
+				continue
+			}
+
+			rule.ActionTagIds = newTagIds
+			rule.UpdatedUnixTime = now
+
+			_, err = sess.ID(rule.RuleId).Cols("action_tag_ids", "updated_unix_time").Where("uid=? AND deleted=?", uid, false).Update(rule)
+
+			if err != nil {
+				return err
+			}
+		}
+
+		tagUpdateModel := &models.TransactionTag{
+			Deleted:         true,
+			DeletedUnixTime: now,
+		}
+
+		deletedRows, err := sess.Cols("deleted", "deleted_unix_time").Where("uid=? AND deleted=?", uid, false).In("tag_id", fromTagIds).Update(tagUpdateModel)
+
+		if err != nil {
+			return err
+		} else if deletedRows < int64(len(fromTagIds)) {
+			return errs.ErrTransactionTagNotFound
+		}
+
+		return nil
+	})
+}
+
 // DeleteTag deletes an existed transaction tag from database
 func (s *TransactionTagService) DeleteTag(uid int64, tagId int64) error {
 	if uid <= 0 {
@@ -342,3 +535,34 @@ func (s *TransactionTagService) getGroupedTransactionTagIds(tagIndexs []*models.
 	}
 	return allTransactionTagIds
 }
+
+func (s *TransactionTagService) getTagIndexMergeKey(tagIndex *models.TransactionTagIndex) string {
+	return fmt.Sprintf("%d_%t_%d", tagIndex.TransactionId, tagIndex.Deleted, tagIndex.DeletedUnixTime)
+}
+
+func (s *TransactionTagService) getMergedTagIdsString(tagIds string, fromTagIds map[int64]bool, toTagId int64) (string, bool) {
+	tagIdStrings := strings.Split(tagIds, ",")
+	newTagIds := make([]int64, 0, len(tagIdStrings))
+	changed := false
+
+	for i := 0; i < len(tagIdStrings); i++ {
+		tagId, err := utils.StringToInt64(tagIdStrings[i])
+
+		if err != nil {
+			return tagIds, false
+		}
+
+		if fromTagIds[tagId] {
+			tagId = toTagId
+			changed = true
+		}
+
+		newTagIds = append(newTagIds, tagId)
+	}
+
+	if !changed {
+		return tagIds, false
+	}
+
+	return strings.Join(utils.Int64ArrayToStringArray(utils.ToUniqueInt64Slice(newTagIds)), ","), true
+}
@@ -0,0 +1,71 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mayswind/ezbookkeeping/pkg/datastore"
+	"github.com/mayswind/ezbookkeeping/pkg/errs"
+	"github.com/mayswind/ezbookkeeping/pkg/models"
+	"github.com/mayswind/ezbookkeeping/pkg/utils"
+)
+
+func TestTransactionTagServiceMergeTags(t *testing.T) {
+	initializeTestDataStore(t)
+
+	account := createTestAccount(t, "cash", 0)
+	food := createTestCategory(t, models.CATEGORY_TYPE_EXPENSE, "food")
+	fromTag1 := createTestTag(t, "tag1")
+	fromTag2 := createTestTag(t, "tag2")
+	toTag := createTestTag(t, "tag3")
+	unixTime := time.Now().Unix() - 3600
+
+	testCases := []struct {
+		name   string
+		tagIds []int64
+	}{
+		{"source and target tags", []int64{fromTag1.TagId, toTag.TagId}},
+		{"all source tags", []int64{fromTag1.TagId, fromTag2.TagId}},
+		{"one source tag", []int64{fromTag2.TagId}},
+		{"target tag", []int64{toTag.TagId}},
+	}
+
+	transactionIds := make([]int64, len(testCases))
+
+	for i, testCase := range testCases {
+		transaction := &models.Transaction{
+			Uid:             testUid,
+			Type:            models.TRANSACTION_DB_TYPE_EXPENSE,
+			CategoryId:      food.CategoryId,
+			TransactionTime: utils.GetMinTransactionTimeFromUnixTime(unixTime + int64(i)),
+			AccountId:       account.AccountId,
+			Amount:          100,
+		}
+
+		err := Transactions.CreateTransaction(transaction, testCase.tagIds, nil, nil)
+		assert.Nil(t, err, testCase.name)
+		transactionIds[i] = transaction.TransactionId
+	}
+
+	err := TransactionTags.MergeTags(testUid, []int64{fromTag1.TagId, toTag.TagId}, toTag.TagId)
+	assert.Equal(t, errs.ErrCannotMergeTransactionTagIntoItself, err)
+
+	err = TransactionTags.MergeTags(testUid, []int64{fromTag1.TagId, fromTag2.TagId}, toTag.TagId)
+	assert.Nil(t, err)
+
+	// Every transaction has only one index of the target tag after merging
+	for i, testCase := range testCases {
+		var tagIndexes []*models.TransactionTagIndex
+		err = datastore.Container.UserDataStore.Choose(testUid).Where("uid=? AND transaction_id=?", testUid, transactionIds[i]).Find(&tagIndexes)
+		assert.Nil(t, err, testCase.name)
+		assert.Equal(t, 1, len(tagIndexes), testCase.name)
+		assert.Equal(t, toTag.TagId, tagIndexes[0].TagId, testCase.name)
+	}
+
+	tags, err := TransactionTags.GetAllTagsByUid(testUid)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(tags))
+	assert.Equal(t, toTag.TagId, tags[0].TagId)
+}
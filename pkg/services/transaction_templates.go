@@ -0,0 +1,207 @@
+package services
+
+import (
+	"strings"
+	"time"
+
+	"xorm.io/xorm"
+
+	"github.com/mayswind/ezbookkeeping/pkg/datastore"
+	"github.com/mayswind/ezbookkeeping/pkg/errs"
+	"github.com/mayswind/ezbookkeeping/pkg/models"
+	"github.com/mayswind/ezbookkeeping/pkg/utils"
+	"github.com/mayswind/ezbookkeeping/pkg/uuid"
+)
+
+// TransactionTemplateService represents transaction template service
+type TransactionTemplateService struct {
+	ServiceUsingDB
+	ServiceUsingUuid
+}
+
+// Initialize a transaction template service singleton instance
+var (
+	TransactionTemplates = &TransactionTemplateService{
+		ServiceUsingDB: ServiceUsingDB{
+			container: datastore.Container,
+		},
+		ServiceUsingUuid: ServiceUsingUuid{
+			container: uuid.Container,
+		},
+	}
+)
+
+// GetAllTemplatesByUid returns all transaction template models of user
+func (s *TransactionTemplateService) GetAllTemplatesByUid(uid int64) ([]*models.TransactionTemplate, error) {
+	if uid <= 0 {
+		return nil, errs.ErrUserIdInvalid
+	}
+
+	var templates []*models.TransactionTemplate
+	err := s.UserDataDB(uid).Where("uid=? AND deleted=?", uid, false).Find(&templates)
+
+	return templates, err
+}
+
+// GetTemplateByTemplateId returns a transaction template model according to transaction template id
+func (s *TransactionTemplateService) GetTemplateByTemplateId(uid int64, templateId int64) (*models.TransactionTemplate, error) {
+	if uid <= 0 {
+		return nil, errs.ErrUserIdInvalid
+	}
+
+	if templateId <= 0 {
+		return nil, errs.ErrTransactionTemplateIdInvalid
+	}
+
+	template := &models.TransactionTemplate{}
+	has, err := s.UserDataDB(uid).ID(templateId).Where("uid=? AND deleted=?", uid, false).Get(template)
+
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, errs.ErrTransactionTemplateNotFound
+	}
+
+	return template, nil
+}
+
+// CreateTemplate saves a new transaction template model to database
+func (s *TransactionTemplateService) CreateTemplate(template *models.TransactionTemplate, tagIds []int64) error {
+	if template.Uid <= 0 {
+		return errs.ErrUserIdInvalid
+	}
+
+	exists, err := s.ExistsTemplateName(template.Uid, template.Name, 0)
+
+	if err != nil {
+		return err
+	} else if exists {
+		return errs.ErrTransactionTemplateNameAlreadyExists
+	}
+
+	tagIds = utils.ToUniqueInt64Slice(tagIds)
+
+	template.TemplateId = s.GenerateUuid(uuid.UUID_TYPE_TEMPLATE)
+	template.TagIds = strings.Join(utils.Int64ArrayToStringArray(tagIds), ",")
+
+	template.Deleted = false
+	template.CreatedUnixTime = time.Now().Unix()
+	template.UpdatedUnixTime = time.Now().Unix()
+
+	return s.UserDataDB(template.Uid).DoTransaction(func(sess *xorm.Session) error {
+		err := s.isTemplateValid(sess, template, tagIds)
+
+		if err != nil {
+			return err
+		}
+
+		_, err = sess.Insert(template)
+		return err
+	})
+}
+
+// ModifyTemplate saves an existed transaction template model to database
+func (s *TransactionTemplateService) ModifyTemplate(template *models.TransactionTemplate, tagIds []int64) error {
+	if template.Uid <= 0 {
+		return errs.ErrUserIdInvalid
+	}
+
+	exists, err := s.ExistsTemplateName(template.Uid, template.Name, template.TemplateId)
+
+	if err != nil {
+		return err
+	} else if exists {
+		return errs.ErrTransactionTemplateNameAlreadyExists
+	}
+
+	tagIds = utils.ToUniqueInt64Slice(tagIds)
+
+	template.TagIds = strings.Join(utils.Int64ArrayToStringArray(tagIds), ",")
+	template.UpdatedUnixTime = time.Now().Unix()
+
+	return s.UserDataDB(template.Uid).DoTransaction(func(sess *xorm.Session) error {
+		err := s.isTemplateValid(sess, template, tagIds)
+
+		if err != nil {
+			return err
+		}
+
+		updatedRows, err := sess.ID(template.TemplateId).Cols("name", "category_id", "account_id", "related_account_id", "amount", "related_account_amount", "hide_amount", "tag_ids", "comment", "updated_unix_time").Where("uid=? AND deleted=?", template.Uid, false).Update(template)
+
+		if err != nil {
+			return err
+		} else if updatedRows < 1 {
+			return errs.ErrTransactionTemplateNotFound
+		}
+
+		return err
+	})
+}
+
+// DeleteTemplate deletes an existed transaction template from database
+func (s *TransactionTemplateService) DeleteTemplate(uid int64, templateId int64) error {
+	if uid <= 0 {
+		return errs.ErrUserIdInvalid
+	}
+
+	now := time.Now().Unix()
+
+	updateModel := &models.TransactionTemplate{
+		Deleted:         true,
+		DeletedUnixTime: now,
+	}
+
+	return s.UserDataDB(uid).DoTransaction(func(sess *xorm.Session) error {
+		deletedRows, err := sess.ID(templateId).Cols("deleted", "deleted_unix_time").Where("uid=? AND deleted=?", uid, false).Update(updateModel)
+
+		if err != nil {
+			return err
+		} else if deletedRows < 1 {
+			return errs.ErrTransactionTemplateNotFound
+		}
+
+		return err
+	})
+}
+
+// DeleteAllTemplates deletes all existed transaction templates from database
+func (s *TransactionTemplateService) DeleteAllTemplates(uid int64) error {
+	if uid <= 0 {
+		return errs.ErrUserIdInvalid
+	}
+
+	now := time.Now().Unix()
+
+	updateModel := &models.TransactionTemplate{
+		Deleted:         true,
+		DeletedUnixTime: now,
+	}
+
+	return s.UserDataDB(uid).DoTransaction(func(sess *xorm.Session) error {
+		_, err := sess.Cols("deleted", "deleted_unix_time").Where("uid=? AND deleted=?", uid, false).Update(updateModel)
+		return err
+	})
+}
+
+// ExistsTemplateName returns whether the given template name exists, the template with the excluded id would not be checked
+func (s *TransactionTemplateService) ExistsTemplateName(uid int64, name string, excludeTemplateId int64) (bool, error) {
+	if name == "" {
+		return false, errs.ErrTransactionTemplateNameIsEmpty
+	}
+
+	return s.UserDataDB(uid).Cols("name").Where("uid=? AND deleted=? AND name=? AND template_id<>?", uid, false, name, excludeTemplateId).Exist(&models.TransactionTemplate{})
+}
+
+func (s *TransactionTemplateService) isTemplateValid(sess *xorm.Session, template *models.TransactionTemplate, tagIds []int64) error {
+	transaction := &models.Transaction{
+		Uid:                  template.Uid,
+		Type:                 template.Type,
+		CategoryId:           template.CategoryId,
+		AccountId:            template.AccountId,
+		Amount:               template.Amount,
+		RelatedAccountId:     template.RelatedAccountId,
+		RelatedAccountAmount: template.RelatedAccountAmount,
+	}
+
+	return Transactions.isNewTransactionValid(sess, transaction, tagIds)
+}
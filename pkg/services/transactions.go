@@ -1,7 +1,9 @@
 package services
 
 import (
+	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
@@ -9,7 +11,10 @@ import (
 
 	"github.com/mayswind/ezbookkeeping/pkg/datastore"
 	"github.com/mayswind/ezbookkeeping/pkg/errs"
+	"github.com/mayswind/ezbookkeeping/pkg/log"
 	"github.com/mayswind/ezbookkeeping/pkg/models"
+	"github.com/mayswind/ezbookkeeping/pkg/settings"
+	"github.com/mayswind/ezbookkeeping/pkg/storage"
 	"github.com/mayswind/ezbookkeeping/pkg/utils"
 	"github.com/mayswind/ezbookkeeping/pkg/uuid"
 )
@@ -17,7 +22,9 @@ import (
 // TransactionService represents transaction service
 type TransactionService struct {
 	ServiceUsingDB
+	ServiceUsingConfig
 	ServiceUsingUuid
+	ServiceUsingStorage
 }
 
 // Initialize a transaction service singleton instance
@@ -26,12 +33,40 @@ var (
 		ServiceUsingDB: ServiceUsingDB{
 			container: datastore.Container,
 		},
+		ServiceUsingConfig: ServiceUsingConfig{
+			container: settings.Container,
+		},
 		ServiceUsingUuid: ServiceUsingUuid{
 			container: uuid.Container,
 		},
+		ServiceUsingStorage: ServiceUsingStorage{
+			container: storage.Container,
+		},
 	}
 )
 
+// TransactionCommentFullTextIndex represents the full-text index definition of transaction comment
+var TransactionCommentFullTextIndex = &datastore.FullTextIndexDefinition{
+	IndexName:  "IDX_transaction_comment_fulltext",
+	TableName:  "transaction",
+	IdColumn:   "transaction_id",
+	UidColumn:  "uid",
+	TextColumn: "comment",
+}
+
+// accountBalanceChanges represents the balance changes of accounts which would be updated together
+type accountBalanceChanges map[int64]int64
+
+// transactionTagGrouping represents how transactions are filtered and grouped by tags in statistics
+type transactionTagGrouping byte
+
+// Transaction tag groupings
+const (
+	transactionTagGroupingNone     transactionTagGrouping = 0
+	transactionTagGroupingUntagged transactionTagGrouping = 1
+	transactionTagGroupingTagged   transactionTagGrouping = 2
+)
+
 // GetAllTransactions returns all transactions
 func (s *TransactionService) GetAllTransactions(uid int64, pageCount int, noDuplicated bool) ([]*models.Transaction, error) {
 	maxTransactionTime := utils.GetMaxTransactionTimeFromUnixTime(time.Now().Unix())
@@ -59,11 +94,11 @@ func (s *TransactionService) GetAllTransactions(uid int64, pageCount int, noDupl
 
 // GetAllTransactionsByMaxTime returns all transactions before given time
 func (s *TransactionService) GetAllTransactionsByMaxTime(uid int64, maxTransactionTime int64, count int, noDuplicated bool) ([]*models.Transaction, error) {
-	return s.GetTransactionsByMaxTime(uid, maxTransactionTime, 0, 0, nil, 0, "", count, noDuplicated)
+	return s.GetTransactionsByMaxTime(uid, maxTransactionTime, 0, 0, nil, nil, nil, nil, models.TRANSACTION_TAG_FILTER_HAS_ANY, "", count, noDuplicated)
 }
 
 // GetTransactionsByMaxTime returns transactions before given time
-func (s *TransactionService) GetTransactionsByMaxTime(uid int64, maxTransactionTime int64, minTransactionTime int64, transactionType models.TransactionDbType, categoryIds []int64, accountId int64, keyword string, count int, noDuplicated bool) ([]*models.Transaction, error) {
+func (s *TransactionService) GetTransactionsByMaxTime(uid int64, maxTransactionTime int64, minTransactionTime int64, transactionType models.TransactionDbType, categoryIds []int64, accountIds []int64, payeeIds []int64, tagIds []int64, tagFilterType models.TransactionTagFilterType, keyword string, count int, noDuplicated bool) ([]*models.Transaction, error) {
 	if uid <= 0 {
 		return nil, errs.ErrUserIdInvalid
 	}
@@ -75,14 +110,14 @@ func (s *TransactionService) GetTransactionsByMaxTime(uid int64, maxTransactionT
 	var transactions []*models.Transaction
 	var err error
 
-	condition, conditionParams := s.getTransactionQueryCondition(uid, maxTransactionTime, minTransactionTime, transactionType, categoryIds, accountId, keyword, noDuplicated)
+	condition, conditionParams := s.getTransactionQueryCondition(uid, maxTransactionTime, minTransactionTime, transactionType, categoryIds, accountIds, payeeIds, tagIds, tagFilterType, keyword, noDuplicated)
 	err = s.UserDataDB(uid).Where(condition, conditionParams...).Limit(count, 0).OrderBy("transaction_time desc").Find(&transactions)
 
 	return transactions, err
 }
 
 // GetTransactionsInMonthByPage returns transactions in given year and month
-func (s *TransactionService) GetTransactionsInMonthByPage(uid int64, year int, month int, transactionType models.TransactionDbType, categoryIds []int64, accountId int64, keyword string, page int, count int, utcOffset int16) ([]*models.Transaction, error) {
+func (s *TransactionService) GetTransactionsInMonthByPage(uid int64, year int, month int, transactionType models.TransactionDbType, categoryIds []int64, accountIds []int64, payeeIds []int64, tagIds []int64, tagFilterType models.TransactionTagFilterType, keyword string, page int, count int, utcOffset int16) ([]*models.Transaction, error) {
 	if uid <= 0 {
 		return nil, errs.ErrUserIdInvalid
 	}
@@ -108,12 +143,30 @@ func (s *TransactionService) GetTransactionsInMonthByPage(uid int64, year int, m
 
 	var transactions []*models.Transaction
 
-	condition, conditionParams := s.getTransactionQueryCondition(uid, maxTransactionTime, minTransactionTime, transactionType, categoryIds, accountId, keyword, true)
+	condition, conditionParams := s.getTransactionQueryCondition(uid, maxTransactionTime, minTransactionTime, transactionType, categoryIds, accountIds, payeeIds, tagIds, tagFilterType, keyword, true)
 	err = s.UserDataDB(uid).Where(condition, conditionParams...).Limit(count, count*(page-1)).OrderBy("transaction_time desc").Find(&transactions)
 
 	return transactions, err
 }
 
+// SearchTransactions returns transactions matched given search filter before max transaction time of the filter
+func (s *TransactionService) SearchTransactions(uid int64, filter *models.TransactionSearchFilter, count int) ([]*models.Transaction, error) {
+	if uid <= 0 {
+		return nil, errs.ErrUserIdInvalid
+	}
+
+	if count < 1 {
+		return nil, errs.ErrPageCountInvalid
+	}
+
+	var transactions []*models.Transaction
+
+	condition, conditionParams := s.getTransactionSearchCondition(uid, filter)
+	err := s.UserDataDB(uid).Where(condition, conditionParams...).Limit(count, 0).OrderBy("transaction_time desc").Find(&transactions)
+
+	return transactions, err
+}
+
 // GetTransactionByTransactionId returns a transaction model according to transaction id
 func (s *TransactionService) GetTransactionByTransactionId(uid int64, transactionId int64) (*models.Transaction, error) {
 	if uid <= 0 {
@@ -138,11 +191,11 @@ func (s *TransactionService) GetTransactionByTransactionId(uid int64, transactio
 
 // GetAllTransactionCount returns total count of transactions
 func (s *TransactionService) GetAllTransactionCount(uid int64) (int64, error) {
-	return s.GetTransactionCount(uid, 0, 0, 0, nil, 0, "")
+	return s.GetTransactionCount(uid, 0, 0, 0, nil, nil, nil, nil, models.TRANSACTION_TAG_FILTER_HAS_ANY, "")
 }
 
 // GetMonthTransactionCount returns total count of transactions in given year and month
-func (s *TransactionService) GetMonthTransactionCount(uid int64, year int, month int, transactionType models.TransactionDbType, categoryIds []int64, accountId int64, keyword string, utcOffset int16) (int64, error) {
+func (s *TransactionService) GetMonthTransactionCount(uid int64, year int, month int, transactionType models.TransactionDbType, categoryIds []int64, accountIds []int64, payeeIds []int64, tagIds []int64, tagFilterType models.TransactionTagFilterType, keyword string, utcOffset int16) (int64, error) {
 	if uid <= 0 {
 		return 0, errs.ErrUserIdInvalid
 	}
@@ -158,604 +211,595 @@ func (s *TransactionService) GetMonthTransactionCount(uid int64, year int, month
 	minTransactionTime := utils.GetMinTransactionTimeFromUnixTime(startTime.Unix())
 	maxTransactionTime := utils.GetMinTransactionTimeFromUnixTime(endTime.Unix()) - 1
 
-	return s.GetTransactionCount(uid, maxTransactionTime, minTransactionTime, transactionType, categoryIds, accountId, keyword)
+	return s.GetTransactionCount(uid, maxTransactionTime, minTransactionTime, transactionType, categoryIds, accountIds, payeeIds, tagIds, tagFilterType, keyword)
 }
 
 // GetTransactionCount returns count of transactions
-func (s *TransactionService) GetTransactionCount(uid int64, maxTransactionTime int64, minTransactionTime int64, transactionType models.TransactionDbType, categoryIds []int64, accountId int64, keyword string) (int64, error) {
+func (s *TransactionService) GetTransactionCount(uid int64, maxTransactionTime int64, minTransactionTime int64, transactionType models.TransactionDbType, categoryIds []int64, accountIds []int64, payeeIds []int64, tagIds []int64, tagFilterType models.TransactionTagFilterType, keyword string) (int64, error) {
 	if uid <= 0 {
 		return 0, errs.ErrUserIdInvalid
 	}
 
-	condition, conditionParams := s.getTransactionQueryCondition(uid, maxTransactionTime, minTransactionTime, transactionType, categoryIds, accountId, keyword, true)
+	condition, conditionParams := s.getTransactionQueryCondition(uid, maxTransactionTime, minTransactionTime, transactionType, categoryIds, accountIds, payeeIds, tagIds, tagFilterType, keyword, true)
 	return s.UserDataDB(uid).Where(condition, conditionParams...).Count(&models.Transaction{})
 }
 
 // CreateTransaction saves a new transaction to database
-func (s *TransactionService) CreateTransaction(transaction *models.Transaction, tagIds []int64) error {
+func (s *TransactionService) CreateTransaction(transaction *models.Transaction, tagIds []int64, splits []*models.TransactionSplit, operator *models.TransactionOperator) error {
 	if transaction.Uid <= 0 {
 		return errs.ErrUserIdInvalid
 	}
 
-	// Check whether account id is valid
-	err := s.isAccountIdValid(transaction)
-
-	if err != nil {
-		return err
-	}
-
-	now := time.Now().Unix()
-
-	transaction.TransactionId = s.GenerateUuid(uuid.UUID_TYPE_TRANSACTION)
-	transaction.TransactionTime = utils.GetMinTransactionTimeFromUnixTime(utils.GetUnixTimeFromTransactionTime(transaction.TransactionTime))
+	return s.UserDataDB(transaction.Uid).DoTransaction(func(sess *xorm.Session) error {
+		balanceChanges := make(accountBalanceChanges)
+		err := s.createTransaction(sess, transaction, tagIds, splits, operator, balanceChanges)
 
-	transaction.CreatedUnixTime = now
-	transaction.UpdatedUnixTime = now
+		if err != nil {
+			return err
+		}
 
-	tagIds = utils.ToUniqueInt64Slice(tagIds)
-	transactionTagIndexs := make([]*models.TransactionTagIndex, len(tagIds))
+		return s.updateAccountBalances(sess, transaction.Uid, balanceChanges)
+	})
+}
 
-	for i := 0; i < len(tagIds); i++ {
-		transactionTagIndexs[i] = &models.TransactionTagIndex{
-			TagIndexId:      s.GenerateUuid(uuid.UUID_TYPE_TAG_INDEX),
-			Uid:             transaction.Uid,
-			Deleted:         false,
-			TagId:           tagIds[i],
-			TransactionId:   transaction.TransactionId,
-			CreatedUnixTime: now,
-			UpdatedUnixTime: now,
-		}
+// ModifyTransaction saves an existed transaction to database, the existed splits of transaction would be replaced by the given splits
+func (s *TransactionService) ModifyTransaction(transaction *models.Transaction, addTagIds []int64, removeTagIds []int64, splits []*models.TransactionSplit, operator *models.TransactionOperator) error {
+	if transaction.Uid <= 0 {
+		return errs.ErrUserIdInvalid
 	}
 
 	return s.UserDataDB(transaction.Uid).DoTransaction(func(sess *xorm.Session) error {
-		// Get and verify source and destination account
-		sourceAccount, destinationAccount, err := s.getAccountModels(sess, transaction)
+		balanceChanges := make(accountBalanceChanges)
+		err := s.modifyTransaction(sess, transaction, addTagIds, removeTagIds, splits, operator, balanceChanges)
 
 		if err != nil {
 			return err
 		}
 
-		if sourceAccount.Hidden || (destinationAccount != nil && destinationAccount.Hidden) {
-			return errs.ErrCannotAddTransactionToHiddenAccount
-		}
+		return s.updateAccountBalances(sess, transaction.Uid, balanceChanges)
+	})
+}
 
-		if (transaction.Type == models.TRANSACTION_DB_TYPE_TRANSFER_OUT || transaction.Type == models.TRANSACTION_DB_TYPE_TRANSFER_IN) &&
-			sourceAccount.Currency == destinationAccount.Currency && transaction.Amount != transaction.RelatedAccountAmount {
-			return errs.ErrTransactionSourceAndDestinationAmountNotEqual
-		}
+// DeleteTransaction deletes an existed transaction from database
+func (s *TransactionService) DeleteTransaction(uid int64, transactionId int64, operator *models.TransactionOperator) error {
+	if uid <= 0 {
+		return errs.ErrUserIdInvalid
+	}
 
-		// Get and verify category
-		err = s.isCategoryValid(sess, transaction)
+	return s.UserDataDB(uid).DoTransaction(func(sess *xorm.Session) error {
+		balanceChanges := make(accountBalanceChanges)
+		err := s.deleteTransaction(sess, uid, transactionId, operator, balanceChanges)
 
 		if err != nil {
 			return err
 		}
 
-		// Get and verify tags
-		err = s.isTagsValid(sess, transaction, transactionTagIndexs, tagIds)
+		return s.updateAccountBalances(sess, uid, balanceChanges)
+	})
+}
 
-		if err != nil {
-			return err
-		}
+// BatchCreateTransactions saves new transactions to database in one database transaction, returns the index of the failed item if any item cannot be saved
+func (s *TransactionService) BatchCreateTransactions(uid int64, items []*models.TransactionBatchItem, operator *models.TransactionOperator) (int, error) {
+	if uid <= 0 {
+		return -1, errs.ErrUserIdInvalid
+	}
 
-		// Verify balance modification transaction and calculate real amount
-		if transaction.Type == models.TRANSACTION_DB_TYPE_MODIFY_BALANCE {
-			otherTransactionExists, err := sess.Cols("uid", "deleted", "account_id").Where("uid=? AND deleted=? AND account_id=?", transaction.Uid, false, sourceAccount.AccountId).Limit(1).Exist(&models.Transaction{})
+	failedIndex := -1
 
-			if err != nil {
-				return err
-			} else if otherTransactionExists {
-				return errs.ErrBalanceModificationTransactionCannotAddWhenNotEmpty
+	err := s.UserDataDB(uid).DoTransaction(func(sess *xorm.Session) error {
+		for i := 0; i < len(items); i++ {
+			if items[i].Transaction.Uid != uid {
+				failedIndex = i
+				return errs.ErrUserIdInvalid
 			}
-
-			transaction.RelatedAccountId = transaction.AccountId
-			transaction.RelatedAccountAmount = transaction.Amount - sourceAccount.Balance
 		}
 
-		// Insert transaction row
-		var relatedTransaction *models.Transaction
+		index, err := s.prepareBatchTransactionTimes(sess, uid, items)
 
-		if transaction.Type == models.TRANSACTION_DB_TYPE_TRANSFER_OUT || transaction.Type == models.TRANSACTION_DB_TYPE_TRANSFER_IN {
-			relatedTransaction = s.GetRelatedTransferTransaction(transaction, s.GenerateUuid(uuid.UUID_TYPE_TRANSACTION))
-			transaction.RelatedId = relatedTransaction.TransactionId
+		if err != nil {
+			failedIndex = index
+			return err
 		}
 
-		createdRows, err := sess.Insert(transaction)
-
-		if err != nil || createdRows < 1 { // maybe another transaction has same time
-			sameSecondLatestTransaction := &models.Transaction{}
-			minTransactionTime := utils.GetMinTransactionTimeFromUnixTime(utils.GetUnixTimeFromTransactionTime(transaction.TransactionTime))
-			maxTransactionTime := utils.GetMaxTransactionTimeFromUnixTime(utils.GetUnixTimeFromTransactionTime(transaction.TransactionTime))
+		balanceChanges := make(accountBalanceChanges)
 
-			has, err := sess.Where("uid=? AND deleted=? AND transaction_time>=? AND transaction_time<=?", transaction.Uid, false, minTransactionTime, maxTransactionTime).OrderBy("transaction_time desc").Limit(1).Get(sameSecondLatestTransaction)
-
-			if err != nil {
-				return err
-			} else if !has {
-				return errs.ErrDatabaseOperationFailed
-			} else if sameSecondLatestTransaction.TransactionTime == maxTransactionTime-1 {
-				return errs.ErrTooMuchTransactionInOneSecond
-			}
+		for i := 0; i < len(items); i++ {
+			item := items[i]
 
-			transaction.TransactionTime = sameSecondLatestTransaction.TransactionTime + 1
-			createdRows, err := sess.Insert(transaction)
+			err := s.createTransaction(sess, item.Transaction, item.AddTagIds, item.Splits, operator, balanceChanges)
 
 			if err != nil {
+				failedIndex = i
 				return err
-			} else if createdRows < 1 {
-				return errs.ErrDatabaseOperationFailed
 			}
 		}
 
-		if relatedTransaction != nil {
-			relatedTransaction.TransactionTime = transaction.TransactionTime + 1
+		return s.updateAccountBalances(sess, uid, balanceChanges)
+	})
 
-			if utils.GetUnixTimeFromTransactionTime(transaction.TransactionTime) != utils.GetUnixTimeFromTransactionTime(relatedTransaction.TransactionTime) {
-				return errs.ErrTooMuchTransactionInOneSecond
-			}
+	if err == nil {
+		failedIndex = -1
+	}
+
+	return failedIndex, err
+}
 
-			createdRows, err := sess.Insert(relatedTransaction)
+// BatchModifyTransactions saves existed transactions to database in one database transaction, returns the index of the failed item if any item cannot be saved
+func (s *TransactionService) BatchModifyTransactions(uid int64, items []*models.TransactionBatchItem, operator *models.TransactionOperator) (int, error) {
+	if uid <= 0 {
+		return -1, errs.ErrUserIdInvalid
+	}
 
-			if err != nil {
-				return err
-			} else if createdRows < 1 {
-				return errs.ErrDatabaseOperationFailed
-			}
-		}
+	failedIndex := -1
 
-		err = nil
+	err := s.UserDataDB(uid).DoTransaction(func(sess *xorm.Session) error {
+		balanceChanges := make(accountBalanceChanges)
 
-		// Insert transaction tag index
-		if len(transactionTagIndexs) > 0 {
-			for i := 0; i < len(transactionTagIndexs); i++ {
-				transactionTagIndex := transactionTagIndexs[i]
-				_, err := sess.Insert(transactionTagIndex)
+		for i := 0; i < len(items); i++ {
+			item := items[i]
 
-				if err != nil {
-					return err
-				}
+			if item.Transaction.Uid != uid {
+				failedIndex = i
+				return errs.ErrUserIdInvalid
 			}
-		}
 
-		// Update account table
-		if transaction.Type == models.TRANSACTION_DB_TYPE_MODIFY_BALANCE {
-			sourceAccount.UpdatedUnixTime = time.Now().Unix()
-			updatedRows, err := sess.ID(sourceAccount.AccountId).SetExpr("balance", fmt.Sprintf("balance+(%d)", transaction.RelatedAccountAmount)).Cols("updated_unix_time").Where("uid=? AND deleted=?", sourceAccount.Uid, false).Update(sourceAccount)
+			err := s.modifyTransaction(sess, item.Transaction, item.AddTagIds, item.RemoveTagIds, item.Splits, operator, balanceChanges)
 
 			if err != nil {
+				failedIndex = i
 				return err
-			} else if updatedRows < 1 {
-				return errs.ErrDatabaseOperationFailed
 			}
-		} else if transaction.Type == models.TRANSACTION_DB_TYPE_INCOME {
-			sourceAccount.UpdatedUnixTime = time.Now().Unix()
-			updatedRows, err := sess.ID(sourceAccount.AccountId).SetExpr("balance", fmt.Sprintf("balance+(%d)", transaction.Amount)).Cols("updated_unix_time").Where("uid=? AND deleted=?", sourceAccount.Uid, false).Update(sourceAccount)
+		}
 
-			if err != nil {
-				return err
-			} else if updatedRows < 1 {
-				return errs.ErrDatabaseOperationFailed
-			}
-		} else if transaction.Type == models.TRANSACTION_DB_TYPE_EXPENSE {
-			sourceAccount.UpdatedUnixTime = time.Now().Unix()
-			updatedRows, err := sess.ID(sourceAccount.AccountId).SetExpr("balance", fmt.Sprintf("balance-(%d)", transaction.Amount)).Cols("updated_unix_time").Where("uid=? AND deleted=?", sourceAccount.Uid, false).Update(sourceAccount)
+		return s.updateAccountBalances(sess, uid, balanceChanges)
+	})
 
-			if err != nil {
-				return err
-			} else if updatedRows < 1 {
-				return errs.ErrDatabaseOperationFailed
-			}
-		} else if transaction.Type == models.TRANSACTION_DB_TYPE_TRANSFER_OUT {
-			sourceAccount.UpdatedUnixTime = time.Now().Unix()
-			updatedSourceRows, err := sess.ID(sourceAccount.AccountId).SetExpr("balance", fmt.Sprintf("balance-(%d)", transaction.Amount)).Cols("updated_unix_time").Where("uid=? AND deleted=?", sourceAccount.Uid, false).Update(sourceAccount)
+	if err == nil {
+		failedIndex = -1
+	}
 
-			if err != nil {
-				return err
-			} else if updatedSourceRows < 1 {
-				return errs.ErrDatabaseOperationFailed
-			}
+	return failedIndex, err
+}
+
+// BatchDeleteTransactions deletes existed transactions from database in one database transaction, returns the index of the failed item if any item cannot be deleted
+func (s *TransactionService) BatchDeleteTransactions(uid int64, transactionIds []int64, operator *models.TransactionOperator) (int, error) {
+	if uid <= 0 {
+		return -1, errs.ErrUserIdInvalid
+	}
+
+	failedIndex := -1
+
+	err := s.UserDataDB(uid).DoTransaction(func(sess *xorm.Session) error {
+		balanceChanges := make(accountBalanceChanges)
 
-			destinationAccount.UpdatedUnixTime = time.Now().Unix()
-			updatedDestinationRows, err := sess.ID(destinationAccount.AccountId).SetExpr("balance", fmt.Sprintf("balance+(%d)", transaction.RelatedAccountAmount)).Cols("updated_unix_time").Where("uid=? AND deleted=?", destinationAccount.Uid, false).Update(destinationAccount)
+		for i := 0; i < len(transactionIds); i++ {
+			err := s.deleteTransaction(sess, uid, transactionIds[i], operator, balanceChanges)
 
 			if err != nil {
+				failedIndex = i
 				return err
-			} else if updatedDestinationRows < 1 {
-				return errs.ErrDatabaseOperationFailed
 			}
-		} else if transaction.Type == models.TRANSACTION_DB_TYPE_TRANSFER_IN {
-			return errs.ErrTransactionTypeInvalid
 		}
 
-		return err
+		return s.updateAccountBalances(sess, uid, balanceChanges)
 	})
+
+	if err == nil {
+		failedIndex = -1
+	}
+
+	return failedIndex, err
 }
 
-// ModifyTransaction saves an existed transaction to database
-func (s *TransactionService) ModifyTransaction(transaction *models.Transaction, addTagIds []int64, removeTagIds []int64) error {
-	if transaction.Uid <= 0 {
+// DeleteAllTransactions deletes all existed transactions from database
+func (s *TransactionService) DeleteAllTransactions(uid int64, operator *models.TransactionOperator) error {
+	if uid <= 0 {
 		return errs.ErrUserIdInvalid
 	}
 
-	updateCols := make([]string, 0, 16)
-
 	now := time.Now().Unix()
 
-	transaction.TransactionTime = utils.GetMinTransactionTimeFromUnixTime(utils.GetUnixTimeFromTransactionTime(transaction.TransactionTime))
-	transaction.UpdatedUnixTime = now
-	updateCols = append(updateCols, "updated_unix_time")
+	updateModel := &models.Transaction{
+		Deleted:         true,
+		DeletedUnixTime: now,
+	}
 
-	addTagIds = utils.ToUniqueInt64Slice(addTagIds)
-	removeTagIds = utils.ToUniqueInt64Slice(removeTagIds)
+	tagIndexUpdateModel := &models.TransactionTagIndex{
+		Deleted:         true,
+		DeletedUnixTime: now,
+	}
 
-	transactionTagIndexs := make([]*models.TransactionTagIndex, len(addTagIds))
+	splitUpdateModel := &models.TransactionSplit{
+		Deleted:         true,
+		DeletedUnixTime: now,
+	}
 
-	for i := 0; i < len(addTagIds); i++ {
-		transactionTagIndexs[i] = &models.TransactionTagIndex{
-			TagIndexId:      s.GenerateUuid(uuid.UUID_TYPE_TAG_INDEX),
-			Uid:             transaction.Uid,
-			Deleted:         false,
-			TagId:           addTagIds[i],
-			TransactionId:   transaction.TransactionId,
-			CreatedUnixTime: now,
-			UpdatedUnixTime: now,
-		}
+	accountUpdateModel := &models.Account{
+		Balance:         0,
+		Deleted:         true,
+		DeletedUnixTime: now,
 	}
 
-	err := s.UserDataDB(transaction.Uid).DoTransaction(func(sess *xorm.Session) error {
-		// Get and verify current transaction
-		oldTransaction := &models.Transaction{}
-		has, err := sess.ID(transaction.TransactionId).Where("uid=? AND deleted=?", transaction.Uid, false).Get(oldTransaction)
+	scheduleUpdateModel := &models.TransactionSchedule{
+		Deleted:         true,
+		DeletedUnixTime: now,
+	}
+
+	attachmentUpdateModel := &models.TransactionAttachment{
+		Deleted:         true,
+		DeletedUnixTime: now,
+	}
+
+	err := s.UserDataDB(uid).DoTransaction(func(sess *xorm.Session) error {
+		// Update all transaction to deleted
+		_, err := sess.Cols("deleted", "deleted_unix_time").Where("uid=? AND deleted=?", uid, false).Update(updateModel)
 
 		if err != nil {
 			return err
-		} else if !has {
-			return errs.ErrTransactionNotFound
 		}
 
-		transaction.Type = oldTransaction.Type
+		// Update all transaction tag index to deleted
+		_, err = sess.Cols("deleted", "deleted_unix_time").Where("uid=? AND deleted=?", uid, false).Update(tagIndexUpdateModel)
 
-		if transaction.Type == models.TRANSACTION_DB_TYPE_TRANSFER_OUT {
-			transaction.RelatedId = oldTransaction.RelatedId
+		if err != nil {
+			return err
 		}
 
-		// Check whether account id is valid
-		err = s.isAccountIdValid(transaction)
+		// Update all transaction splits to deleted
+		_, err = sess.Cols("deleted", "deleted_unix_time").Where("uid=? AND deleted=?", uid, false).Update(splitUpdateModel)
 
 		if err != nil {
 			return err
 		}
 
-		// Get and verify source and destination account (if necessary)
-		sourceAccount, destinationAccount, err := s.getAccountModels(sess, transaction)
+		// Update all account table to deleted
+		_, err = sess.Cols("balance", "deleted", "deleted_unix_time").Where("uid=? AND deleted=?", uid, false).Update(accountUpdateModel)
 
 		if err != nil {
 			return err
 		}
 
-		if sourceAccount.Hidden || (destinationAccount != nil && destinationAccount.Hidden) {
-			return errs.ErrCannotModifyTransactionInHiddenAccount
-		}
+		// Update all transaction schedules to deleted
+		_, err = sess.Cols("deleted", "deleted_unix_time").Where("uid=? AND deleted=?", uid, false).Update(scheduleUpdateModel)
 
-		if (transaction.Type == models.TRANSACTION_DB_TYPE_TRANSFER_OUT || transaction.Type == models.TRANSACTION_DB_TYPE_TRANSFER_IN) &&
-			sourceAccount.Currency == destinationAccount.Currency && transaction.Amount != transaction.RelatedAccountAmount {
-			return errs.ErrTransactionSourceAndDestinationAmountNotEqual
+		if err != nil {
+			return err
 		}
 
-		oldSourceAccount, oldDestinationAccount, err := s.getOldAccountModels(sess, transaction, oldTransaction, sourceAccount, destinationAccount)
+		// Update all transaction attachments to deleted
+		_, err = sess.Cols("deleted", "deleted_unix_time").Where("uid=? AND deleted=?", uid, false).Update(attachmentUpdateModel)
 
 		if err != nil {
 			return err
 		}
 
-		if oldSourceAccount.Hidden || (oldDestinationAccount != nil && oldDestinationAccount.Hidden) {
-			return errs.ErrCannotAddTransactionToHiddenAccount
-		}
-
-		// Append modified columns and verify
-		if transaction.CategoryId != oldTransaction.CategoryId {
-			// Get and verify category
-			err = s.isCategoryValid(sess, transaction)
+		// Delete all transaction comment full-text index
+		if fullTextIndex := s.UserDataDB(uid).GetFullTextIndex(TransactionCommentFullTextIndex); fullTextIndex != nil {
+			err = fullTextIndex.DeleteAllDocuments(sess, uid)
 
 			if err != nil {
 				return err
 			}
-
-			updateCols = append(updateCols, "category_id")
 		}
 
-		if utils.GetUnixTimeFromTransactionTime(transaction.TransactionTime) != utils.GetUnixTimeFromTransactionTime(oldTransaction.TransactionTime) {
-			sameSecondLatestTransaction := &models.Transaction{}
-			minTransactionTime := utils.GetMinTransactionTimeFromUnixTime(utils.GetUnixTimeFromTransactionTime(transaction.TransactionTime))
-			maxTransactionTime := utils.GetMaxTransactionTimeFromUnixTime(utils.GetUnixTimeFromTransactionTime(transaction.TransactionTime))
+		// Insert transaction history
+		return s.insertTransactionHistory(sess, uid, 0, models.TRANSACTION_HISTORY_OPERATION_DELETE_ALL, operator, nil, nil)
+	})
 
-			has, err = sess.Where("uid=? AND deleted=? AND transaction_time>=? AND transaction_time<=?", transaction.Uid, false, minTransactionTime, maxTransactionTime).OrderBy("transaction_time desc").Limit(1).Get(sameSecondLatestTransaction)
+	if err != nil {
+		return err
+	}
 
-			if err != nil {
-				return err
-			}
+	storagePathPrefix := models.GetTransactionAttachmentStoragePathPrefix(uid)
 
-			if has && sameSecondLatestTransaction.TransactionTime < maxTransactionTime-1 {
-				transaction.TransactionTime = sameSecondLatestTransaction.TransactionTime + 1
-			} else if has && sameSecondLatestTransaction.TransactionTime == maxTransactionTime-1 {
-				return errs.ErrTooMuchTransactionInOneSecond
-			}
+	if err := s.ObjectStorage().DeleteAll(storagePathPrefix); err != nil {
+		log.Warnf("[transactions.DeleteAllTransactions] failed to delete attachment files in \"%s\", because %s", storagePathPrefix, err.Error())
+	}
 
-			updateCols = append(updateCols, "transaction_time")
-		}
+	return nil
+}
 
-		if transaction.TimezoneUtcOffset != oldTransaction.TimezoneUtcOffset {
-			updateCols = append(updateCols, "timezone_utc_offset")
-		}
+// GetDeletedTransactionsByPage returns deleted transactions in trash of user by page
+func (s *TransactionService) GetDeletedTransactionsByPage(uid int64, page int, count int) ([]*models.Transaction, error) {
+	if uid <= 0 {
+		return nil, errs.ErrUserIdInvalid
+	}
 
-		if transaction.AccountId != oldTransaction.AccountId {
-			updateCols = append(updateCols, "account_id")
-		}
+	if page < 0 {
+		return nil, errs.ErrPageIndexInvalid
+	} else if page == 0 {
+		page = 1
+	}
 
-		if transaction.Amount != oldTransaction.Amount {
-			if oldTransaction.Type == models.TRANSACTION_DB_TYPE_MODIFY_BALANCE {
-				originalBalance := sourceAccount.Balance - oldTransaction.RelatedAccountAmount
-				transaction.RelatedAccountAmount = transaction.Amount - originalBalance
-				updateCols = append(updateCols, "related_account_amount")
-			}
+	if count < 1 {
+		return nil, errs.ErrPageCountInvalid
+	}
 
-			updateCols = append(updateCols, "amount")
-		}
+	var transactions []*models.Transaction
+	err := s.UserDataDB(uid).Where("uid=? AND deleted=? AND type<>? AND deleted_unix_time>=?", uid, true, models.TRANSACTION_DB_TYPE_TRANSFER_IN, s.getMinDeletedUnixTimeInTrash()).Limit(count, count*(page-1)).OrderBy("deleted_unix_time desc, transaction_id desc").Find(&transactions)
 
-		if transaction.Type == models.TRANSACTION_DB_TYPE_TRANSFER_OUT || transaction.Type == models.TRANSACTION_DB_TYPE_TRANSFER_IN {
-			if transaction.RelatedAccountId != oldTransaction.RelatedAccountId {
-				updateCols = append(updateCols, "related_account_id")
-			}
+	return transactions, err
+}
 
-			if transaction.RelatedAccountAmount != oldTransaction.RelatedAccountAmount {
-				updateCols = append(updateCols, "related_account_amount")
-			}
-		}
+// GetDeletedTransactionCount returns total count of deleted transactions in trash of user
+func (s *TransactionService) GetDeletedTransactionCount(uid int64) (int64, error) {
+	if uid <= 0 {
+		return 0, errs.ErrUserIdInvalid
+	}
 
-		if transaction.HideAmount != oldTransaction.HideAmount {
-			updateCols = append(updateCols, "hide_amount")
-		}
+	return s.UserDataDB(uid).Where("uid=? AND deleted=? AND type<>? AND deleted_unix_time>=?", uid, true, models.TRANSACTION_DB_TYPE_TRANSFER_IN, s.getMinDeletedUnixTimeInTrash()).Count(&models.Transaction{})
+}
 
-		if transaction.Comment != oldTransaction.Comment {
-			updateCols = append(updateCols, "comment")
-		}
+// GetDeletedTransactionByTransactionId returns a deleted transaction model in trash according to transaction id
+func (s *TransactionService) GetDeletedTransactionByTransactionId(uid int64, transactionId int64) (*models.Transaction, error) {
+	if uid <= 0 {
+		return nil, errs.ErrUserIdInvalid
+	}
 
-		// Get and verify tags
-		err = s.isTagsValid(sess, transaction, transactionTagIndexs, addTagIds)
+	if transactionId <= 0 {
+		return nil, errs.ErrTransactionIdInvalid
+	}
 
-		if err != nil {
-			return err
-		}
+	transaction := &models.Transaction{}
+	has, err := s.UserDataDB(uid).ID(transactionId).Where("uid=? AND deleted=? AND type<>? AND deleted_unix_time>=?", uid, true, models.TRANSACTION_DB_TYPE_TRANSFER_IN, s.getMinDeletedUnixTimeInTrash()).Get(transaction)
 
-		// Update transaction row
-		updatedRows, err := sess.ID(transaction.TransactionId).Cols(updateCols...).Where("uid=? AND deleted=?", transaction.Uid, false).Update(transaction)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, errs.ErrTransactionNotFound
+	}
 
-		if err != nil {
-			return err
-		} else if updatedRows < 1 {
-			return errs.ErrTransactionNotFound
-		}
+	return transaction, nil
+}
 
-		if transaction.Type == models.TRANSACTION_DB_TYPE_TRANSFER_OUT || transaction.Type == models.TRANSACTION_DB_TYPE_TRANSFER_IN {
-			relatedTransaction := s.GetRelatedTransferTransaction(transaction, transaction.RelatedId)
+// GetAllExpiredDeletedTransactions returns deleted transactions of all users which have been kept in trash longer than the retention period
+func (s *TransactionService) GetAllExpiredDeletedTransactions(count int) ([]*models.Transaction, error) {
+	if count < 1 {
+		return nil, errs.ErrPageCountInvalid
+	}
 
-			if utils.GetUnixTimeFromTransactionTime(transaction.TransactionTime) != utils.GetUnixTimeFromTransactionTime(relatedTransaction.TransactionTime) {
-				return errs.ErrTooMuchTransactionInOneSecond
-			}
+	if s.CurrentConfig().TransactionTrashRetentionDays <= 0 {
+		return nil, nil
+	}
+
+	var transactions []*models.Transaction
+	err := s.UserDataDB(0).Where("deleted=? AND type<>? AND deleted_unix_time<?", true, models.TRANSACTION_DB_TYPE_TRANSFER_IN, s.getMinDeletedUnixTimeInTrash()).Limit(count, 0).OrderBy("deleted_unix_time asc").Find(&transactions)
+
+	return transactions, err
+}
+
+// RestoreTransaction restores a deleted transaction from trash and re-applies its balance effect to accounts
+func (s *TransactionService) RestoreTransaction(uid int64, transactionId int64, operator *models.TransactionOperator) error {
+	if uid <= 0 {
+		return errs.ErrUserIdInvalid
+	}
+
+	now := time.Now().Unix()
+
+	updateModel := &models.Transaction{
+		Deleted:         false,
+		DeletedUnixTime: 0,
+		UpdatedUnixTime: now,
+	}
+
+	tagIndexUpdateModel := &models.TransactionTagIndex{
+		Deleted:         false,
+		DeletedUnixTime: 0,
+		UpdatedUnixTime: now,
+	}
+
+	splitUpdateModel := &models.TransactionSplit{
+		Deleted:         false,
+		DeletedUnixTime: 0,
+		UpdatedUnixTime: now,
+	}
+
+	attachmentUpdateModel := &models.TransactionAttachment{
+		Deleted:         false,
+		DeletedUnixTime: 0,
+		UpdatedUnixTime: now,
+	}
+
+	return s.UserDataDB(uid).DoTransaction(func(sess *xorm.Session) error {
+		// Get and verify deleted transaction
+		transaction := &models.Transaction{}
+		has, err := sess.ID(transactionId).Where("uid=? AND deleted=? AND deleted_unix_time>=?", uid, true, s.getMinDeletedUnixTimeInTrash()).Get(transaction)
+
+		if err != nil {
+			return err
+		} else if !has {
+			return errs.ErrTransactionNotFound
+		}
+
+		if transaction.Type == models.TRANSACTION_DB_TYPE_TRANSFER_IN {
+			return errs.ErrTransactionTypeInvalid
+		}
+
+		// Transfers between two accounts which have been merged become transfers to the same account, so they cannot be restored
+		if transaction.Type == models.TRANSACTION_DB_TYPE_TRANSFER_OUT && transaction.AccountId == transaction.RelatedAccountId {
+			return errs.ErrTransactionSourceAndDestinationIdCannotBeEqual
+		}
+
+		// Get and verify source and destination account
+		sourceAccount, destinationAccount, err := s.getAccountModels(sess, transaction)
+
+		if err != nil {
+			return err
+		}
+
+		if sourceAccount.Hidden || (destinationAccount != nil && destinationAccount.Hidden) {
+			return errs.ErrCannotRestoreTransactionInHiddenAccount
+		}
+
+		// Get and verify category
+		err = s.isCategoryValid(sess, transaction)
+
+		if err != nil {
+			return err
+		}
+
+		// Get and verify payee
+		err = s.isPayeeValid(sess, transaction)
+
+		if err != nil {
+			return err
+		}
+
+		// Get and verify splits
+		var splits []*models.TransactionSplit
+		err = sess.Where("uid=? AND deleted=? AND transaction_id=? AND deleted_unix_time=?", uid, true, transaction.TransactionId, transaction.DeletedUnixTime).Find(&splits)
+
+		if err != nil {
+			return err
+		}
 
-			relatedUpdateCols := s.getRelatedUpdateColumns(updateCols)
-			updatedRows, err := sess.ID(relatedTransaction.TransactionId).Cols(relatedUpdateCols...).Where("uid=? AND deleted=?", relatedTransaction.Uid, false).Update(relatedTransaction)
+		if len(splits) > 0 {
+			err = s.isSplitsValid(sess, transaction, splits)
 
 			if err != nil {
 				return err
-			} else if updatedRows < 1 {
-				return errs.ErrDatabaseOperationFailed
 			}
 		}
 
-		// Update transaction tag index
-		if len(removeTagIds) > 0 {
-			tagIndexUpdateModel := &models.TransactionTagIndex{
-				Deleted:         true,
-				DeletedUnixTime: now,
-			}
-
-			deletedRows, err := sess.Cols("deleted", "deleted_unix_time").Where("uid=? AND deleted=? AND transaction_id=?", transaction.Uid, false, transaction.TransactionId).In("tag_id", removeTagIds).Update(tagIndexUpdateModel)
+		// Verify balance modification transaction
+		if transaction.Type == models.TRANSACTION_DB_TYPE_MODIFY_BALANCE {
+			otherTransactionExists, err := sess.Cols("uid", "deleted", "account_id").Where("uid=? AND deleted=? AND account_id=?", uid, false, sourceAccount.AccountId).Limit(1).Exist(&models.Transaction{})
 
 			if err != nil {
 				return err
-			} else if deletedRows < 1 {
-				return errs.ErrTransactionTagNotFound
+			} else if otherTransactionExists {
+				return errs.ErrBalanceModificationTransactionCannotAddWhenNotEmpty
 			}
 		}
 
-		if len(transactionTagIndexs) > 0 {
-			for i := 0; i < len(transactionTagIndexs); i++ {
-				transactionTagIndex := transactionTagIndexs[i]
-				_, err := sess.Insert(transactionTagIndex)
+		// Update transaction row to not deleted
+		restoredRows, err := sess.ID(transaction.TransactionId).Cols("deleted", "deleted_unix_time", "updated_unix_time").Where("uid=? AND deleted=?", uid, true).Update(updateModel)
 
-				if err != nil {
-					return err
-				}
-			}
+		if err != nil {
+			return err
+		} else if restoredRows < 1 {
+			return errs.ErrTransactionNotFound
 		}
 
-		// Update account table
-		if oldTransaction.Type == models.TRANSACTION_DB_TYPE_MODIFY_BALANCE {
-			if transaction.AccountId != oldTransaction.AccountId {
-				return errs.ErrBalanceModificationTransactionCannotChangeAccountId
-			}
+		attachmentTransactionIds := []int64{transaction.TransactionId}
 
-			if transaction.RelatedAccountAmount != oldTransaction.RelatedAccountAmount {
-				sourceAccount.UpdatedUnixTime = time.Now().Unix()
-				updatedRows, err := sess.ID(sourceAccount.AccountId).SetExpr("balance", fmt.Sprintf("balance-(%d)+(%d)", oldTransaction.RelatedAccountAmount, transaction.RelatedAccountAmount)).Cols("updated_unix_time").Where("uid=? AND deleted=?", sourceAccount.Uid, false).Update(sourceAccount)
+		if transaction.Type == models.TRANSACTION_DB_TYPE_TRANSFER_OUT {
+			restoredRows, err = sess.ID(transaction.RelatedId).Cols("deleted", "deleted_unix_time", "updated_unix_time").Where("uid=? AND deleted=?", uid, true).Update(updateModel)
 
-				if err != nil {
-					return err
-				} else if updatedRows < 1 {
-					return errs.ErrDatabaseOperationFailed
-				}
-			}
-		} else if oldTransaction.Type == models.TRANSACTION_DB_TYPE_INCOME {
-			var oldAccountNewAmount int64 = 0
-			var newAccountNewAmount int64 = 0
-
-			if transaction.AccountId == oldTransaction.AccountId {
-				oldAccountNewAmount = transaction.Amount
-			} else if transaction.AccountId != oldTransaction.AccountId {
-				newAccountNewAmount = transaction.Amount
+			if err != nil {
+				return err
+			} else if restoredRows < 1 {
+				return errs.ErrTransactionNotFound
 			}
 
-			if oldAccountNewAmount != oldTransaction.Amount {
-				oldSourceAccount.UpdatedUnixTime = time.Now().Unix()
-				updatedRows, err := sess.ID(oldSourceAccount.AccountId).SetExpr("balance", fmt.Sprintf("balance-(%d)+(%d)", oldTransaction.Amount, oldAccountNewAmount)).Cols("updated_unix_time").Where("uid=? AND deleted=?", oldSourceAccount.Uid, false).Update(oldSourceAccount)
+			attachmentTransactionIds = append(attachmentTransactionIds, transaction.RelatedId)
+		}
 
-				if err != nil {
-					return err
-				} else if updatedRows < 1 {
-					return errs.ErrDatabaseOperationFailed
-				}
-			}
+		// Update transaction comment full-text index
+		err = s.updateTransactionCommentFullTextIndex(sess, transaction)
 
-			if newAccountNewAmount != 0 {
-				sourceAccount.UpdatedUnixTime = time.Now().Unix()
-				updatedRows, err := sess.ID(sourceAccount.AccountId).SetExpr("balance", fmt.Sprintf("balance+(%d)", newAccountNewAmount)).Cols("updated_unix_time").Where("uid=? AND deleted=?", sourceAccount.Uid, false).Update(sourceAccount)
+		if err != nil {
+			return err
+		}
 
-				if err != nil {
-					return err
-				} else if updatedRows < 1 {
-					return errs.ErrDatabaseOperationFailed
-				}
-			}
-		} else if oldTransaction.Type == models.TRANSACTION_DB_TYPE_EXPENSE {
-			var oldAccountNewAmount int64 = 0
-			var newAccountNewAmount int64 = 0
-
-			if transaction.AccountId == oldTransaction.AccountId {
-				oldAccountNewAmount = transaction.Amount
-			} else if transaction.AccountId != oldTransaction.AccountId {
-				newAccountNewAmount = transaction.Amount
-			}
+		// Update transaction tag index whose tag still exists
+		var tagIndexes []*models.TransactionTagIndex
+		err = sess.Where("uid=? AND deleted=? AND transaction_id=? AND deleted_unix_time=?", uid, true, transaction.TransactionId, transaction.DeletedUnixTime).Find(&tagIndexes)
 
-			if oldAccountNewAmount != oldTransaction.Amount {
-				oldSourceAccount.UpdatedUnixTime = time.Now().Unix()
-				updatedRows, err := sess.ID(oldSourceAccount.AccountId).SetExpr("balance", fmt.Sprintf("balance+(%d)-(%d)", oldTransaction.Amount, oldAccountNewAmount)).Cols("updated_unix_time").Where("uid=? AND deleted=?", oldSourceAccount.Uid, false).Update(oldSourceAccount)
+		if err != nil {
+			return err
+		}
 
-				if err != nil {
-					return err
-				} else if updatedRows < 1 {
-					return errs.ErrDatabaseOperationFailed
-				}
+		if len(tagIndexes) > 0 {
+			tagIds := make([]int64, len(tagIndexes))
+
+			for i := 0; i < len(tagIndexes); i++ {
+				tagIds[i] = tagIndexes[i].TagId
 			}
 
-			if newAccountNewAmount != 0 {
-				sourceAccount.UpdatedUnixTime = time.Now().Unix()
-				updatedRows, err := sess.ID(sourceAccount.AccountId).SetExpr("balance", fmt.Sprintf("balance-(%d)", newAccountNewAmount)).Cols("updated_unix_time").Where("uid=? AND deleted=?", sourceAccount.Uid, false).Update(sourceAccount)
+			var tags []*models.TransactionTag
+			err = sess.Where("uid=? AND deleted=?", uid, false).In("tag_id", utils.ToUniqueInt64Slice(tagIds)).Find(&tags)
 
-				if err != nil {
-					return err
-				} else if updatedRows < 1 {
-					return errs.ErrDatabaseOperationFailed
-				}
+			if err != nil {
+				return err
 			}
-		} else if oldTransaction.Type == models.TRANSACTION_DB_TYPE_TRANSFER_OUT {
-			var oldSourceAccountNewAmount int64 = 0
-			var newSourceAccountNewAmount int64 = 0
-
-			if transaction.AccountId == oldTransaction.AccountId {
-				oldSourceAccountNewAmount = transaction.Amount
-			} else if transaction.AccountId != oldTransaction.AccountId {
-				newSourceAccountNewAmount = transaction.Amount
+
+			existedTagIds := make(map[int64]bool, len(tags))
+
+			for i := 0; i < len(tags); i++ {
+				existedTagIds[tags[i].TagId] = true
 			}
 
-			if oldSourceAccountNewAmount != oldTransaction.Amount {
-				oldSourceAccount.UpdatedUnixTime = time.Now().Unix()
-				updatedRows, err := sess.ID(oldSourceAccount.AccountId).SetExpr("balance", fmt.Sprintf("balance+(%d)-(%d)", oldTransaction.Amount, oldSourceAccountNewAmount)).Cols("updated_unix_time").Where("uid=? AND deleted=?", oldSourceAccount.Uid, false).Update(oldSourceAccount)
+			restoredTagIndexIds := make([]int64, 0, len(tagIndexes))
 
-				if err != nil {
-					return err
-				} else if updatedRows < 1 {
-					return errs.ErrDatabaseOperationFailed
+			for i := 0; i < len(tagIndexes); i++ {
+				if existedTagIds[tagIndexes[i].TagId] {
+					restoredTagIndexIds = append(restoredTagIndexIds, tagIndexes[i].TagIndexId)
 				}
 			}
 
-			if newSourceAccountNewAmount != 0 {
-				sourceAccount.UpdatedUnixTime = time.Now().Unix()
-				updatedRows, err := sess.ID(sourceAccount.AccountId).SetExpr("balance", fmt.Sprintf("balance-(%d)", newSourceAccountNewAmount)).Cols("updated_unix_time").Where("uid=? AND deleted=?", sourceAccount.Uid, false).Update(sourceAccount)
+			if len(restoredTagIndexIds) > 0 {
+				_, err = sess.Cols("deleted", "deleted_unix_time", "updated_unix_time").Where("uid=? AND deleted=?", uid, true).In("tag_index_id", restoredTagIndexIds).Update(tagIndexUpdateModel)
 
 				if err != nil {
 					return err
-				} else if updatedRows < 1 {
-					return errs.ErrDatabaseOperationFailed
 				}
 			}
+		}
 
-			var oldDestinationAccountNewAmount int64 = 0
-			var newDestinationAccountNewAmount int64 = 0
+		// Update transaction splits
+		if len(splits) > 0 {
+			_, err = sess.Cols("deleted", "deleted_unix_time", "updated_unix_time").Where("uid=? AND deleted=? AND transaction_id=? AND deleted_unix_time=?", uid, true, transaction.TransactionId, transaction.DeletedUnixTime).Update(splitUpdateModel)
 
-			if transaction.RelatedAccountId == oldTransaction.RelatedAccountId {
-				oldDestinationAccountNewAmount = transaction.RelatedAccountAmount
-			} else if transaction.RelatedAccountId != oldTransaction.RelatedAccountId {
-				newDestinationAccountNewAmount = transaction.RelatedAccountAmount
+			if err != nil {
+				return err
 			}
+		}
 
-			if oldDestinationAccountNewAmount != oldTransaction.RelatedAccountAmount {
-				oldDestinationAccount.UpdatedUnixTime = time.Now().Unix()
-				updatedRows, err := sess.ID(oldDestinationAccount.AccountId).SetExpr("balance", fmt.Sprintf("balance-(%d)+(%d)", oldTransaction.RelatedAccountAmount, oldDestinationAccountNewAmount)).Cols("updated_unix_time").Where("uid=? AND deleted=?", oldDestinationAccount.Uid, false).Update(oldDestinationAccount)
+		// Update transaction attachments
+		_, err = sess.Cols("deleted", "deleted_unix_time", "updated_unix_time").Where("uid=? AND deleted=? AND deleted_unix_time=?", uid, true, transaction.DeletedUnixTime).In("transaction_id", attachmentTransactionIds).Update(attachmentUpdateModel)
 
-				if err != nil {
-					return err
-				} else if updatedRows < 1 {
-					return errs.ErrDatabaseOperationFailed
-				}
-			}
+		if err != nil {
+			return err
+		}
 
-			if newDestinationAccountNewAmount != 0 {
-				destinationAccount.UpdatedUnixTime = time.Now().Unix()
-				updatedRows, err := sess.ID(destinationAccount.AccountId).SetExpr("balance", fmt.Sprintf("balance+(%d)", newDestinationAccountNewAmount)).Cols("updated_unix_time").Where("uid=? AND deleted=?", destinationAccount.Uid, false).Update(destinationAccount)
+		// Update account table
+		balanceChanges := make(accountBalanceChanges)
+		err = s.addTransactionBalanceChanges(balanceChanges, transaction, false)
 
-				if err != nil {
-					return err
-				} else if updatedRows < 1 {
-					return errs.ErrDatabaseOperationFailed
-				}
-			}
-		} else if oldTransaction.Type == models.TRANSACTION_DB_TYPE_TRANSFER_IN {
-			return errs.ErrTransactionTypeInvalid
+		if err != nil {
+			return err
 		}
 
-		return nil
-	})
+		err = s.updateAccountBalances(sess, uid, balanceChanges)
 
-	if err != nil {
-		return err
-	}
+		if err != nil {
+			return err
+		}
 
-	return nil
+		// Insert transaction history
+		afterSnapshot, err := s.getTransactionHistorySnapshot(sess, uid, transaction.TransactionId)
+
+		if err != nil {
+			return err
+		}
+
+		return s.insertTransactionHistory(sess, uid, transaction.TransactionId, models.TRANSACTION_HISTORY_OPERATION_RESTORE, operator, nil, afterSnapshot)
+	})
 }
 
-// DeleteTransaction deletes an existed transaction from database
-func (s *TransactionService) DeleteTransaction(uid int64, transactionId int64) error {
+// PurgeTransaction removes a deleted transaction and all its related data in trash permanently
+func (s *TransactionService) PurgeTransaction(uid int64, transactionId int64, operator *models.TransactionOperator) error {
 	if uid <= 0 {
 		return errs.ErrUserIdInvalid
 	}
 
-	now := time.Now().Unix()
-
-	updateModel := &models.Transaction{
-		Deleted:         true,
-		DeletedUnixTime: now,
-	}
-
-	tagIndexUpdateModel := &models.TransactionTagIndex{
-		Deleted:         true,
-		DeletedUnixTime: now,
-	}
+	var purgedAttachments []*models.TransactionAttachment
 
-	return s.UserDataDB(uid).DoTransaction(func(sess *xorm.Session) error {
-		// Get and verify current transaction
-		oldTransaction := &models.Transaction{}
-		has, err := sess.ID(transactionId).Where("uid=? AND deleted=?", uid, false).Get(oldTransaction)
+	err := s.UserDataDB(uid).DoTransaction(func(sess *xorm.Session) error {
+		// Get and verify deleted transaction
+		transaction := &models.Transaction{}
+		has, err := sess.ID(transactionId).Where("uid=? AND deleted=?", uid, true).Get(transaction)
 
 		if err != nil {
 			return err
@@ -763,392 +807,2623 @@ func (s *TransactionService) DeleteTransaction(uid int64, transactionId int64) e
 			return errs.ErrTransactionNotFound
 		}
 
-		// Get and verify source and destination account
-		sourceAccount, destinationAccount, err := s.getAccountModels(sess, oldTransaction)
+		if transaction.Type == models.TRANSACTION_DB_TYPE_TRANSFER_IN {
+			return errs.ErrTransactionTypeInvalid
+		}
+
+		beforeSnapshot, err := s.getTransactionHistorySnapshot(sess, uid, transaction.TransactionId)
 
 		if err != nil {
 			return err
 		}
 
-		if sourceAccount.Hidden || (destinationAccount != nil && destinationAccount.Hidden) {
-			return errs.ErrCannotDeleteTransactionInHiddenAccount
+		transactionIds := []int64{transaction.TransactionId}
+
+		if transaction.Type == models.TRANSACTION_DB_TYPE_TRANSFER_OUT {
+			transactionIds = append(transactionIds, transaction.RelatedId)
 		}
 
-		// Update transaction row to deleted
-		deletedRows, err := sess.ID(oldTransaction.TransactionId).Cols("deleted", "deleted_unix_time").Where("uid=? AND deleted=?", uid, false).Update(updateModel)
+		// Delete transaction rows
+		purgedRows, err := sess.Where("uid=? AND deleted=?", uid, true).In("transaction_id", transactionIds).Delete(&models.Transaction{})
 
 		if err != nil {
 			return err
-		} else if deletedRows < 1 {
+		} else if purgedRows < 1 {
 			return errs.ErrTransactionNotFound
 		}
 
-		if oldTransaction.Type == models.TRANSACTION_DB_TYPE_TRANSFER_OUT || oldTransaction.Type == models.TRANSACTION_DB_TYPE_TRANSFER_IN {
-			deletedRows, err = sess.ID(oldTransaction.RelatedId).Cols("deleted", "deleted_unix_time").Where("uid=? AND deleted=?", uid, false).Update(updateModel)
+		// Delete transaction tag index
+		_, err = sess.Where("uid=? AND deleted=? AND transaction_id=?", uid, true, transaction.TransactionId).Delete(&models.TransactionTagIndex{})
 
-			if err != nil {
-				return err
-			} else if deletedRows < 1 {
-				return errs.ErrTransactionNotFound
-			}
+		if err != nil {
+			return err
 		}
 
-		// Update transaction tag index
-		_, err = sess.Cols("deleted", "deleted_unix_time").Where("uid=? AND deleted=? AND transaction_id=?", uid, false, oldTransaction.TransactionId).Update(tagIndexUpdateModel)
+		// Delete transaction comment full-text index
+		err = s.deleteTransactionCommentFullTextIndex(sess, transaction)
 
 		if err != nil {
 			return err
 		}
 
-		// Update account table
-		if oldTransaction.Type == models.TRANSACTION_DB_TYPE_MODIFY_BALANCE {
-			sourceAccount.UpdatedUnixTime = time.Now().Unix()
-			updatedRows, err := sess.ID(sourceAccount.AccountId).SetExpr("balance", fmt.Sprintf("balance-(%d)", oldTransaction.RelatedAccountAmount)).Cols("updated_unix_time").Where("uid=? AND deleted=?", sourceAccount.Uid, false).Update(sourceAccount)
-
-			if err != nil {
-				return err
-			} else if updatedRows < 1 {
-				return errs.ErrDatabaseOperationFailed
-			}
-		} else if oldTransaction.Type == models.TRANSACTION_DB_TYPE_INCOME {
-			sourceAccount.UpdatedUnixTime = time.Now().Unix()
-			updatedRows, err := sess.ID(sourceAccount.AccountId).SetExpr("balance", fmt.Sprintf("balance-(%d)", oldTransaction.Amount)).Cols("updated_unix_time").Where("uid=? AND deleted=?", sourceAccount.Uid, false).Update(sourceAccount)
+		// Delete transaction splits
+		_, err = sess.Where("uid=? AND deleted=? AND transaction_id=?", uid, true, transaction.TransactionId).Delete(&models.TransactionSplit{})
 
-			if err != nil {
-				return err
-			} else if updatedRows < 1 {
-				return errs.ErrDatabaseOperationFailed
-			}
-		} else if oldTransaction.Type == models.TRANSACTION_DB_TYPE_EXPENSE {
-			sourceAccount.UpdatedUnixTime = time.Now().Unix()
-			updatedRows, err := sess.ID(sourceAccount.AccountId).SetExpr("balance", fmt.Sprintf("balance+(%d)", oldTransaction.Amount)).Cols("updated_unix_time").Where("uid=? AND deleted=?", sourceAccount.Uid, false).Update(sourceAccount)
+		if err != nil {
+			return err
+		}
 
-			if err != nil {
-				return err
-			} else if updatedRows < 1 {
-				return errs.ErrDatabaseOperationFailed
-			}
-		} else if oldTransaction.Type == models.TRANSACTION_DB_TYPE_TRANSFER_OUT {
-			sourceAccount.UpdatedUnixTime = time.Now().Unix()
-			updatedSourceRows, err := sess.ID(sourceAccount.AccountId).SetExpr("balance", fmt.Sprintf("balance+(%d)", oldTransaction.Amount)).Cols("updated_unix_time").Where("uid=? AND deleted=?", sourceAccount.Uid, false).Update(sourceAccount)
+		// Delete transaction attachments
+		err = sess.Where("uid=? AND deleted=?", uid, true).In("transaction_id", transactionIds).Find(&purgedAttachments)
 
-			if err != nil {
-				return err
-			} else if updatedSourceRows < 1 {
-				return errs.ErrDatabaseOperationFailed
-			}
+		if err != nil {
+			return err
+		}
 
-			destinationAccount.UpdatedUnixTime = time.Now().Unix()
-			updatedDestinationRows, err := sess.ID(destinationAccount.AccountId).SetExpr("balance", fmt.Sprintf("balance-(%d)", oldTransaction.RelatedAccountAmount)).Cols("updated_unix_time").Where("uid=? AND deleted=?", destinationAccount.Uid, false).Update(destinationAccount)
+		_, err = sess.Where("uid=? AND deleted=?", uid, true).In("transaction_id", transactionIds).Delete(&models.TransactionAttachment{})
 
-			if err != nil {
-				return err
-			} else if updatedDestinationRows < 1 {
-				return errs.ErrDatabaseOperationFailed
-			}
-		} else if oldTransaction.Type == models.TRANSACTION_DB_TYPE_TRANSFER_IN {
-			return errs.ErrTransactionTypeInvalid
+		if err != nil {
+			return err
 		}
 
-		return err
+		// Insert transaction history
+		return s.insertTransactionHistory(sess, uid, transaction.TransactionId, models.TRANSACTION_HISTORY_OPERATION_PURGE, operator, beforeSnapshot, nil)
 	})
-}
 
-// DeleteAllTransactions deletes all existed transactions from database
-func (s *TransactionService) DeleteAllTransactions(uid int64) error {
-	if uid <= 0 {
-		return errs.ErrUserIdInvalid
+	if err != nil {
+		return err
 	}
 
-	now := time.Now().Unix()
+	for i := 0; i < len(purgedAttachments); i++ {
+		storagePath := purgedAttachments[i].GetStoragePath()
 
-	updateModel := &models.Transaction{
-		Deleted:         true,
-		DeletedUnixTime: now,
+		if err := s.ObjectStorage().Delete(storagePath); err != nil {
+			log.Warnf("[transactions.PurgeTransaction] failed to delete attachment file \"%s\", because %s", storagePath, err.Error())
+		}
 	}
 
-	tagIndexUpdateModel := &models.TransactionTagIndex{
-		Deleted:         true,
-		DeletedUnixTime: now,
+	return nil
+}
+
+// ModifyTransactionReconcileState saves the reconcile state of an existed transaction to database
+func (s *TransactionService) ModifyTransactionReconcileState(uid int64, transactionId int64, reconcileState models.TransactionReconcileState, operator *models.TransactionOperator) error {
+	if uid <= 0 {
+		return errs.ErrUserIdInvalid
 	}
 
-	accountUpdateModel := &models.Account{
-		Balance:         0,
-		Deleted:         true,
-		DeletedUnixTime: now,
+	updateModel := &models.Transaction{
+		ReconcileState:  reconcileState,
+		UpdatedUnixTime: time.Now().Unix(),
 	}
 
 	return s.UserDataDB(uid).DoTransaction(func(sess *xorm.Session) error {
-		// Update all transaction to deleted
-		_, err := sess.Cols("deleted", "deleted_unix_time").Where("uid=? AND deleted=?", uid, false).Update(updateModel)
+		transaction := &models.Transaction{}
+		has, err := sess.ID(transactionId).Where("uid=? AND deleted=?", uid, false).Get(transaction)
 
 		if err != nil {
 			return err
+		} else if !has {
+			return errs.ErrTransactionNotFound
 		}
 
-		// Update all transaction tag index to deleted
-		_, err = sess.Cols("deleted", "deleted_unix_time").Where("uid=? AND deleted=?", uid, false).Update(tagIndexUpdateModel)
+		if transaction.ReconcileState == reconcileState {
+			return errs.ErrNothingWillBeUpdated
+		}
+
+		// The reconcile state belongs to the account side of transaction, so only the given row is updated,
+		// and the history of transfer is recorded on the transfer out row
+		historyTransactionId := transaction.TransactionId
+
+		if transaction.Type == models.TRANSACTION_DB_TYPE_TRANSFER_IN {
+			historyTransactionId = transaction.RelatedId
+		}
+
+		beforeSnapshot, err := s.getTransactionHistorySnapshot(sess, uid, historyTransactionId)
 
 		if err != nil {
 			return err
 		}
 
-		// Update all account table to deleted
-		_, err = sess.Cols("balance", "deleted", "deleted_unix_time").Where("uid=? AND deleted=?", uid, false).Update(accountUpdateModel)
+		updatedRows, err := sess.ID(transaction.TransactionId).Cols("reconcile_state", "updated_unix_time").Where("uid=? AND deleted=?", uid, false).Update(updateModel)
+
+		if err != nil {
+			return err
+		} else if updatedRows < 1 {
+			return errs.ErrTransactionNotFound
+		}
+
+		afterSnapshot, err := s.getTransactionHistorySnapshot(sess, uid, historyTransactionId)
 
 		if err != nil {
 			return err
 		}
 
-		return nil
+		return s.insertTransactionHistory(sess, uid, historyTransactionId, models.TRANSACTION_HISTORY_OPERATION_MODIFY, operator, beforeSnapshot, afterSnapshot)
 	})
 }
 
-// GetRelatedTransferTransaction returns the related transaction for transfer transaction
-func (s *TransactionService) GetRelatedTransferTransaction(originalTransaction *models.Transaction, relatedTransactionId int64) *models.Transaction {
-	var relatedType models.TransactionDbType
-	var relatedTransactionTime int64
+// GetAccountReconcileSummary returns the cleared balance and transaction counts of specified account until the max transaction time
+func (s *TransactionService) GetAccountReconcileSummary(uid int64, accountId int64, maxTransactionTime int64) (*models.AccountReconcileSummary, error) {
+	if uid <= 0 {
+		return nil, errs.ErrUserIdInvalid
+	}
+
+	if accountId <= 0 {
+		return nil, errs.ErrAccountIdInvalid
+	}
+
+	var transactions []*models.Transaction
+	err := s.UserDataDB(uid).Where("uid=? AND deleted=? AND account_id=? AND transaction_time<=?", uid, false, accountId, maxTransactionTime).Find(&transactions)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return s.getAccountReconcileSummary(transactions), nil
+}
+
+// GetCreditCardStatements returns the closed statement cycles of specified credit card account, the latest cycle comes first
+func (s *TransactionService) GetCreditCardStatements(uid int64, account *models.Account, count int, utcOffset int16) ([]*models.AccountCreditCardStatement, error) {
+	if uid <= 0 {
+		return nil, errs.ErrUserIdInvalid
+	}
+
+	if account.StatementDay <= 0 {
+		return nil, errs.ErrAccountStatementDayNotSet
+	}
+
+	now := time.Now().Unix()
+	timezone := time.FixedZone("Client Timezone", int(utcOffset)*60)
+	currentTime := time.Unix(now, 0).In(timezone)
+	year := currentTime.Year()
+	month := currentTime.Month()
+
+	if utils.GetDateOfMonthWithoutOverflow(year, month, account.StatementDay, timezone).AddDate(0, 0, 1).Unix() > now {
+		month--
+	}
+
+	var transactions []*models.Transaction
+	err := s.UserDataDB(uid).Where("uid=? AND deleted=? AND account_id=? AND transaction_time<=?", uid, false, account.AccountId, utils.GetMaxTransactionTimeFromUnixTime(now)).Find(&transactions)
+
+	if err != nil {
+		return nil, err
+	}
+
+	statements := make([]*models.AccountCreditCardStatement, count)
+
+	for i := 0; i < count; i++ {
+		closingDate := utils.GetDateOfMonthWithoutOverflow(year, month-time.Month(i), account.StatementDay, timezone)
+		startDate := utils.GetDateOfMonthWithoutOverflow(year, month-time.Month(i+1), account.StatementDay, timezone).AddDate(0, 0, 1)
+		paymentEndDate := utils.GetDateOfMonthWithoutOverflow(year, month-time.Month(i-1), account.StatementDay, timezone)
+
+		statement := &models.AccountCreditCardStatement{
+			StartTime: startDate.Unix(),
+			EndTime:   closingDate.AddDate(0, 0, 1).Unix() - 1,
+		}
+
+		if account.PaymentDueDay > 0 {
+			dueDate := utils.GetDateOfMonthWithoutOverflow(year, month-time.Month(i), account.PaymentDueDay, timezone)
+
+			if !dueDate.After(closingDate) {
+				dueDate = utils.GetDateOfMonthWithoutOverflow(year, month-time.Month(i-1), account.PaymentDueDay, timezone)
+			}
+
+			paymentEndDate = dueDate
+			statement.PaymentDueTime = dueDate.AddDate(0, 0, 1).Unix() - 1
+		}
+
+		maxStatementTransactionTime := utils.GetMaxTransactionTimeFromUnixTime(statement.EndTime)
+		maxPaymentTransactionTime := utils.GetMaxTransactionTimeFromUnixTime(paymentEndDate.AddDate(0, 0, 1).Unix() - 1)
+
+		for j := 0; j < len(transactions); j++ {
+			transaction := transactions[j]
+
+			if transaction.TransactionTime <= maxStatementTransactionTime {
+				statement.ClosingBalance += s.getAccountBalanceChangeOfTransaction(transaction)
+			} else if transaction.TransactionTime <= maxPaymentTransactionTime && transaction.Type == models.TRANSACTION_DB_TYPE_TRANSFER_IN {
+				statement.PaidAmount += transaction.Amount
+			}
+		}
+
+		statements[i] = statement
+	}
+
+	return statements, nil
+}
+
+// ReconcileTransactions marks all cleared transactions of specified account until the max transaction time as reconciled
+func (s *TransactionService) ReconcileTransactions(uid int64, accountId int64, maxTransactionTime int64, endingBalance int64, force bool, operator *models.TransactionOperator) (*models.AccountReconcileSummary, error) {
+	if uid <= 0 {
+		return nil, errs.ErrUserIdInvalid
+	}
+
+	if accountId <= 0 {
+		return nil, errs.ErrAccountIdInvalid
+	}
+
+	var summary *models.AccountReconcileSummary
+
+	updateModel := &models.Transaction{
+		ReconcileState:  models.TRANSACTION_RECONCILE_STATE_RECONCILED,
+		UpdatedUnixTime: time.Now().Unix(),
+	}
+
+	err := s.UserDataDB(uid).DoTransaction(func(sess *xorm.Session) error {
+		// Get and verify account
+		account := &models.Account{}
+		has, err := sess.ID(accountId).Where("uid=? AND deleted=?", uid, false).Get(account)
+
+		if err != nil {
+			return err
+		} else if !has {
+			return errs.ErrAccountNotFound
+		}
+
+		if account.Type == models.ACCOUNT_TYPE_MULTI_SUB_ACCOUNTS {
+			return errs.ErrCannotReconcileParentAccount
+		}
+
+		// Get and verify cleared balance
+		var transactions []*models.Transaction
+		err = sess.Where("uid=? AND deleted=? AND account_id=? AND transaction_time<=?", uid, false, accountId, maxTransactionTime).Find(&transactions)
+
+		if err != nil {
+			return err
+		}
+
+		summary = s.getAccountReconcileSummary(transactions)
+
+		if !force && summary.ClearedBalance != endingBalance {
+			return errs.ErrAccountReconcileBalanceNotMatched
+		}
+
+		// Update all cleared transactions to reconciled
+		for i := 0; i < len(transactions); i++ {
+			transaction := transactions[i]
+
+			if transaction.ReconcileState != models.TRANSACTION_RECONCILE_STATE_CLEARED {
+				continue
+			}
+
+			transactionId := transaction.TransactionId
+
+			if transaction.Type == models.TRANSACTION_DB_TYPE_TRANSFER_IN {
+				transactionId = transaction.RelatedId
+			}
+
+			beforeSnapshot, err := s.getTransactionHistorySnapshot(sess, uid, transactionId)
+
+			if err != nil {
+				return err
+			}
+
+			// Only the row of current account is reconciled, the other side of transfer keeps its own reconcile state
+			_, err = sess.ID(transaction.TransactionId).Cols("reconcile_state", "updated_unix_time").Where("uid=? AND deleted=?", uid, false).Update(updateModel)
+
+			if err != nil {
+				return err
+			}
+
+			afterSnapshot, err := s.getTransactionHistorySnapshot(sess, uid, transactionId)
+
+			if err != nil {
+				return err
+			}
+
+			err = s.insertTransactionHistory(sess, uid, transactionId, models.TRANSACTION_HISTORY_OPERATION_MODIFY, operator, beforeSnapshot, afterSnapshot)
+
+			if err != nil {
+				return err
+			}
+		}
+
+		summary.ReconciledCount += summary.ClearedCount
+		summary.ClearedCount = 0
 
-	if originalTransaction.Type == models.TRANSACTION_DB_TYPE_TRANSFER_OUT {
-		relatedType = models.TRANSACTION_DB_TYPE_TRANSFER_IN
-		relatedTransactionTime = originalTransaction.TransactionTime + 1
-	} else if originalTransaction.Type == models.TRANSACTION_DB_TYPE_TRANSFER_IN {
-		relatedType = models.TRANSACTION_DB_TYPE_TRANSFER_OUT
-		relatedTransactionTime = originalTransaction.TransactionTime - 1
-	} else {
 		return nil
+	})
+
+	if err != nil {
+		return nil, err
 	}
 
-	relatedTransaction := &models.Transaction{
-		TransactionId:        relatedTransactionId,
-		Uid:                  originalTransaction.Uid,
-		Deleted:              originalTransaction.Deleted,
-		Type:                 relatedType,
-		CategoryId:           originalTransaction.CategoryId,
-		TransactionTime:      relatedTransactionTime,
-		TimezoneUtcOffset:    originalTransaction.TimezoneUtcOffset,
-		AccountId:            originalTransaction.RelatedAccountId,
-		Amount:               originalTransaction.RelatedAccountAmount,
-		RelatedId:            originalTransaction.TransactionId,
-		RelatedAccountId:     originalTransaction.AccountId,
-		RelatedAccountAmount: originalTransaction.Amount,
-		Comment:              originalTransaction.Comment,
-		CreatedUnixTime:      originalTransaction.CreatedUnixTime,
-		UpdatedUnixTime:      originalTransaction.UpdatedUnixTime,
-		DeletedUnixTime:      originalTransaction.DeletedUnixTime,
+	return summary, nil
+}
+
+// CloseAccount transfers the remaining balance of specified account into another account and then hides the specified account, returns the transfer transaction if the balance is not zero
+func (s *TransactionService) CloseAccount(uid int64, accountId int64, toAccountId int64, categoryId int64, toAccountAmount int64, utcOffset int16, operator *models.TransactionOperator) (*models.Transaction, error) {
+	if uid <= 0 {
+		return nil, errs.ErrUserIdInvalid
+	}
+
+	if accountId <= 0 {
+		return nil, errs.ErrAccountIdInvalid
+	}
+
+	var transaction *models.Transaction
+
+	err := s.UserDataDB(uid).DoTransaction(func(sess *xorm.Session) error {
+		// Get and verify account
+		account := &models.Account{}
+		has, err := sess.ID(accountId).Where("uid=? AND deleted=?", uid, false).Get(account)
+
+		if err != nil {
+			return err
+		} else if !has {
+			return errs.ErrAccountNotFound
+		}
+
+		if account.Type == models.ACCOUNT_TYPE_MULTI_SUB_ACCOUNTS {
+			return errs.ErrCannotCloseParentAccount
+		}
+
+		// Pending transactions would still be posted into the account after it is closed
+		pendingTransactionExists, err := sess.Cols("uid", "deleted", "account_id", "pending").Where("uid=? AND deleted=? AND account_id=? AND pending=?", uid, false, accountId, true).Limit(1).Exist(&models.Transaction{})
+
+		if err != nil {
+			return err
+		} else if pendingTransactionExists {
+			return errs.ErrCannotCloseAccountWithPendingTransactions
+		}
+
+		now := time.Now().Unix()
+
+		// Transfer remaining balance into another account
+		if account.Balance != 0 {
+			if toAccountId <= 0 {
+				return errs.ErrAccountCloseTransferAccountRequired
+			}
+
+			toAccount := &models.Account{}
+			has, err = sess.ID(toAccountId).Where("uid=? AND deleted=?", uid, false).Get(toAccount)
+
+			if err != nil {
+				return err
+			} else if !has {
+				return errs.ErrDestinationAccountNotFound
+			}
+
+			remainingBalance := account.Balance
+
+			if remainingBalance < 0 {
+				remainingBalance = -remainingBalance
+			}
+
+			if toAccount.Currency == account.Currency {
+				toAccountAmount = remainingBalance
+			} else if toAccountAmount <= 0 {
+				return errs.ErrAccountCloseTransferAmountRequired
+			}
+
+			transaction = &models.Transaction{
+				Uid:               uid,
+				Type:              models.TRANSACTION_DB_TYPE_TRANSFER_OUT,
+				CategoryId:        categoryId,
+				TransactionTime:   utils.GetMinTransactionTimeFromUnixTime(now),
+				TimezoneUtcOffset: utcOffset,
+			}
+
+			if account.Balance > 0 {
+				transaction.AccountId = account.AccountId
+				transaction.Amount = remainingBalance
+				transaction.RelatedAccountId = toAccount.AccountId
+				transaction.RelatedAccountAmount = toAccountAmount
+			} else {
+				transaction.AccountId = toAccount.AccountId
+				transaction.Amount = toAccountAmount
+				transaction.RelatedAccountId = account.AccountId
+				transaction.RelatedAccountAmount = remainingBalance
+			}
+
+			balanceChanges := make(accountBalanceChanges)
+			err = s.createTransaction(sess, transaction, nil, nil, operator, balanceChanges)
+
+			if err != nil {
+				return err
+			}
+
+			err = s.updateAccountBalances(sess, uid, balanceChanges)
+
+			if err != nil {
+				return err
+			}
+		}
+
+		// Hide the closed account
+		updateModel := &models.Account{
+			Hidden:          true,
+			UpdatedUnixTime: now,
+		}
+
+		updatedRows, err := sess.ID(account.AccountId).Cols("hidden", "updated_unix_time").Where("uid=? AND deleted=?", uid, false).Update(updateModel)
+
+		if err != nil {
+			return err
+		} else if updatedRows < 1 {
+			return errs.ErrAccountNotFound
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return transaction, nil
+}
+
+// CreateLoanPayment creates a transfer of principal into the loan account and an expense of interest from the payment account in one database transaction
+func (s *TransactionService) CreateLoanPayment(uid int64, loanAccountId int64, sourceAccountId int64, transferCategoryId int64, interestCategoryId int64, principalAmount int64, interestAmount int64, transactionUnixTime int64, utcOffset int16, comment string, operator *models.TransactionOperator) (principalTransaction *models.Transaction, interestTransaction *models.Transaction, err error) {
+	if uid <= 0 {
+		return nil, nil, errs.ErrUserIdInvalid
+	}
+
+	if loanAccountId <= 0 || sourceAccountId <= 0 {
+		return nil, nil, errs.ErrAccountIdInvalid
+	}
+
+	if principalAmount < 0 || interestAmount < 0 || principalAmount+interestAmount <= 0 {
+		return nil, nil, errs.ErrAccountLoanPaymentAmountInvalid
+	}
+
+	err = s.UserDataDB(uid).DoTransaction(func(sess *xorm.Session) error {
+		// Get and verify loan account and payment account
+		loanAccount := &models.Account{}
+		has, err := sess.ID(loanAccountId).Where("uid=? AND deleted=?", uid, false).Get(loanAccount)
+
+		if err != nil {
+			return err
+		} else if !has {
+			return errs.ErrAccountNotFound
+		}
+
+		if loanAccount.Type != models.ACCOUNT_TYPE_SINGLE_ACCOUNT || loanAccount.Category != models.ACCOUNT_CATEGORY_DEBT || !loanAccount.HasLoanSettings() {
+			return errs.ErrAccountLoanSettingsNotSet
+		}
+
+		sourceAccount := &models.Account{}
+		has, err = sess.ID(sourceAccountId).Where("uid=? AND deleted=?", uid, false).Get(sourceAccount)
+
+		if err != nil {
+			return err
+		} else if !has {
+			return errs.ErrSourceAccountNotFound
+		}
+
+		if sourceAccount.Currency != loanAccount.Currency {
+			return errs.ErrAccountLoanPaymentCurrencyNotEqual
+		}
+
+		balanceChanges := make(accountBalanceChanges)
+
+		// Transfer principal from payment account into loan account
+		if principalAmount > 0 {
+			transactionTime, err := s.getNextAvailableTransactionTime(sess, uid, utils.GetMinTransactionTimeFromUnixTime(transactionUnixTime))
+
+			if err != nil {
+				return err
+			}
+
+			principalTransaction = &models.Transaction{
+				Uid:                  uid,
+				Type:                 models.TRANSACTION_DB_TYPE_TRANSFER_OUT,
+				CategoryId:           transferCategoryId,
+				TransactionTime:      transactionTime,
+				TimezoneUtcOffset:    utcOffset,
+				AccountId:            sourceAccount.AccountId,
+				Amount:               principalAmount,
+				RelatedAccountId:     loanAccount.AccountId,
+				RelatedAccountAmount: principalAmount,
+				Comment:              comment,
+			}
+
+			err = s.createTransaction(sess, principalTransaction, nil, nil, operator, balanceChanges)
+
+			if err != nil {
+				return err
+			}
+		}
+
+		// Record interest as expense of payment account
+		if interestAmount > 0 {
+			// The transfer of principal may have taken the transaction times of this second, so the interest uses the next available one
+			transactionTime, err := s.getNextAvailableTransactionTime(sess, uid, utils.GetMinTransactionTimeFromUnixTime(transactionUnixTime))
+
+			if err != nil {
+				return err
+			}
+
+			interestTransaction = &models.Transaction{
+				Uid:               uid,
+				Type:              models.TRANSACTION_DB_TYPE_EXPENSE,
+				CategoryId:        interestCategoryId,
+				TransactionTime:   transactionTime,
+				TimezoneUtcOffset: utcOffset,
+				AccountId:         sourceAccount.AccountId,
+				Amount:            interestAmount,
+				Comment:           comment,
+			}
+
+			err = s.createTransaction(sess, interestTransaction, nil, nil, operator, balanceChanges)
+
+			if err != nil {
+				return err
+			}
+		}
+
+		return s.updateAccountBalances(sess, uid, balanceChanges)
+	})
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return principalTransaction, interestTransaction, nil
+}
+
+// MergeAccounts moves all transactions of the source account to the target account with the same currency, and then deletes the source account
+func (s *TransactionService) MergeAccounts(uid int64, fromAccountId int64, toAccountId int64, operator *models.TransactionOperator) error {
+	if uid <= 0 {
+		return errs.ErrUserIdInvalid
+	}
+
+	if fromAccountId <= 0 || toAccountId <= 0 {
+		return errs.ErrAccountIdInvalid
+	}
+
+	if fromAccountId == toAccountId {
+		return errs.ErrCannotMergeAccountIntoItself
+	}
+
+	now := time.Now().Unix()
+
+	return s.UserDataDB(uid).DoTransaction(func(sess *xorm.Session) error {
+		// Get and verify source and target account
+		fromAccount := &models.Account{}
+		has, err := sess.ID(fromAccountId).Where("uid=? AND deleted=?", uid, false).Get(fromAccount)
+
+		if err != nil {
+			return err
+		} else if !has {
+			return errs.ErrSourceAccountNotFound
+		}
+
+		toAccount := &models.Account{}
+		has, err = sess.ID(toAccountId).Where("uid=? AND deleted=?", uid, false).Get(toAccount)
+
+		if err != nil {
+			return err
+		} else if !has {
+			return errs.ErrDestinationAccountNotFound
+		}
+
+		if fromAccount.Type == models.ACCOUNT_TYPE_MULTI_SUB_ACCOUNTS || toAccount.Type == models.ACCOUNT_TYPE_MULTI_SUB_ACCOUNTS {
+			return errs.ErrCannotMergeParentAccount
+		}
+
+		if fromAccount.Currency != toAccount.Currency {
+			return errs.ErrCannotMergeAccountsWithDifferentCurrency
+		}
+
+		if fromAccount.ParentAccountId != models.LevelOneAccountParentId {
+			subAccountCount, err := sess.Where("uid=? AND deleted=? AND parent_account_id=?", uid, false, fromAccount.ParentAccountId).Count(&models.Account{})
+
+			if err != nil {
+				return err
+			} else if subAccountCount <= 1 {
+				return errs.ErrAccountHaveNoSubAccount
+			}
+		}
+
+		// Each account can only have one balance modification transaction as its opening balance
+		fromBalanceModificationExists, err := sess.Cols("uid", "deleted", "account_id", "type").Where("uid=? AND deleted=? AND account_id=? AND type=?", uid, false, fromAccountId, models.TRANSACTION_DB_TYPE_MODIFY_BALANCE).Limit(1).Exist(&models.Transaction{})
+
+		if err != nil {
+			return err
+		}
+
+		toBalanceModificationExists, err := sess.Cols("uid", "deleted", "account_id", "type").Where("uid=? AND deleted=? AND account_id=? AND type=?", uid, false, toAccountId, models.TRANSACTION_DB_TYPE_MODIFY_BALANCE).Limit(1).Exist(&models.Transaction{})
+
+		if err != nil {
+			return err
+		} else if fromBalanceModificationExists && toBalanceModificationExists {
+			return errs.ErrCannotMergeAccountsWithBalanceModification
+		}
+
+		// Transfers between the two accounts would become transfers to the same account after merging, so delete them
+		var transferTransactions []*models.Transaction
+		err = sess.Cols("transaction_id").Where("uid=? AND deleted=? AND type=? AND ((account_id=? AND related_account_id=?) OR (account_id=? AND related_account_id=?))", uid, false, models.TRANSACTION_DB_TYPE_TRANSFER_OUT, fromAccountId, toAccountId, toAccountId, fromAccountId).Find(&transferTransactions)
+
+		if err != nil {
+			return err
+		}
+
+		balanceChanges := make(accountBalanceChanges)
+
+		for i := 0; i < len(transferTransactions); i++ {
+			err = s.deleteTransaction(sess, uid, transferTransactions[i].TransactionId, operator, balanceChanges)
+
+			if err != nil {
+				return err
+			}
+		}
+
+		// Deleted transactions are also moved, so that they can still be restored after source account is deleted
+		var movedTransactions []*models.Transaction
+		err = sess.Cols("transaction_id").Where("uid=? AND type<>? AND (account_id=? OR related_account_id=?)", uid, models.TRANSACTION_DB_TYPE_TRANSFER_IN, fromAccountId, fromAccountId).Find(&movedTransactions)
+
+		if err != nil {
+			return err
+		}
+
+		movedTransactionIds := make([]int64, len(movedTransactions))
+
+		for i := 0; i < len(movedTransactions); i++ {
+			movedTransactionIds[i] = movedTransactions[i].TransactionId
+		}
+
+		beforeSnapshots, err := s.getTransactionHistorySnapshots(sess, uid, movedTransactionIds)
+
+		if err != nil {
+			return err
+		}
+
+		transactionUpdateModel := &models.Transaction{
+			AccountId:       toAccountId,
+			UpdatedUnixTime: now,
+		}
+
+		_, err = sess.Cols("account_id", "updated_unix_time").Where("uid=? AND account_id=?", uid, fromAccountId).Update(transactionUpdateModel)
+
+		if err != nil {
+			return err
+		}
+
+		transactionUpdateModel = &models.Transaction{
+			RelatedAccountId: toAccountId,
+			UpdatedUnixTime:  now,
+		}
+
+		_, err = sess.Cols("related_account_id", "updated_unix_time").Where("uid=? AND related_account_id=?", uid, fromAccountId).Update(transactionUpdateModel)
+
+		if err != nil {
+			return err
+		}
+
+		err = s.insertModifiedTransactionHistories(sess, uid, movedTransactionIds, operator, beforeSnapshots)
+
+		if err != nil {
+			return err
+		}
+
+		templateUpdateModel := &models.TransactionTemplate{
+			AccountId:       toAccountId,
+			UpdatedUnixTime: now,
+		}
+
+		_, err = sess.Cols("account_id", "updated_unix_time").Where("uid=? AND deleted=? AND account_id=?", uid, false, fromAccountId).Update(templateUpdateModel)
+
+		if err != nil {
+			return err
+		}
+
+		templateUpdateModel = &models.TransactionTemplate{
+			RelatedAccountId: toAccountId,
+			UpdatedUnixTime:  now,
+		}
+
+		_, err = sess.Cols("related_account_id", "updated_unix_time").Where("uid=? AND deleted=? AND related_account_id=?", uid, false, fromAccountId).Update(templateUpdateModel)
+
+		if err != nil {
+			return err
+		}
+
+		scheduleUpdateModel := &models.TransactionSchedule{
+			AccountId:       toAccountId,
+			UpdatedUnixTime: now,
+		}
+
+		_, err = sess.Cols("account_id", "updated_unix_time").Where("uid=? AND deleted=? AND account_id=?", uid, false, fromAccountId).Update(scheduleUpdateModel)
+
+		if err != nil {
+			return err
+		}
+
+		scheduleUpdateModel = &models.TransactionSchedule{
+			RelatedAccountId: toAccountId,
+			UpdatedUnixTime:  now,
+		}
+
+		_, err = sess.Cols("related_account_id", "updated_unix_time").Where("uid=? AND deleted=? AND related_account_id=?", uid, false, fromAccountId).Update(scheduleUpdateModel)
+
+		if err != nil {
+			return err
+		}
+
+		ruleUpdateModel := &models.TransactionRule{
+			ConditionAccountId: toAccountId,
+			UpdatedUnixTime:    now,
+		}
+
+		_, err = sess.Cols("condition_account_id", "updated_unix_time").Where("uid=? AND deleted=? AND condition_account_id=?", uid, false, fromAccountId).Update(ruleUpdateModel)
+
+		if err != nil {
+			return err
+		}
+
+		// Savings goals linked to source account would be linked to target account, or unlinked if target account cannot be linked
+		var goals []*models.SavingsGoal
+		err = sess.Where("uid=? AND deleted=?", uid, false).Find(&goals)
+
+		if err != nil {
+			return err
+		}
+
+		for i := 0; i < len(goals); i++ {
+			goal := goals[i]
+			accountIds, err := goal.GetAccountIds()
+
+			if err != nil {
+				return err
+			}
+
+			newAccountIds := make([]int64, 0, len(accountIds))
+			linkedToSourceAccount := false
+
+			for j := 0; j < len(accountIds); j++ {
+				if accountIds[j] == fromAccountId {
+					linkedToSourceAccount = true
+
+					if toAccount.IsAsset() {
+						newAccountIds = append(newAccountIds, toAccountId)
+					}
+				} else {
+					newAccountIds = append(newAccountIds, accountIds[j])
+				}
+			}
+
+			if !linkedToSourceAccount {
+				continue
+			}
+
+			goal.AccountIds = strings.Join(utils.Int64ArrayToStringArray(utils.ToUniqueInt64Slice(newAccountIds)), ",")
+			goal.UpdatedUnixTime = now
+
+			_, err = sess.ID(goal.GoalId).Cols("account_ids", "updated_unix_time").Where("uid=? AND deleted=?", uid, false).Update(goal)
+
+			if err != nil {
+				return err
+			}
+		}
+
+		investmentTransactionUpdateModel := &models.InvestmentTransaction{
+			AccountId:       toAccountId,
+			UpdatedUnixTime: now,
+		}
+
+		updatedRows, err := sess.Cols("account_id", "updated_unix_time").Where("uid=? AND deleted=? AND account_id=?", uid, false, fromAccountId).Update(investmentTransactionUpdateModel)
+
+		if err != nil {
+			return err
+		} else if updatedRows > 0 && toAccount.Category != models.ACCOUNT_CATEGORY_INVESTMENT {
+			return errs.ErrInvestmentAccountInvalid
+		}
+
+		// Move the balance of source account to target account
+		balanceChanges[toAccountId] += fromAccount.Balance + balanceChanges[fromAccountId]
+		delete(balanceChanges, fromAccountId)
+
+		err = s.updateAccountBalances(sess, uid, balanceChanges)
+
+		if err != nil {
+			return err
+		}
+
+		accountUpdateModel := &models.Account{
+			Balance:         0,
+			Deleted:         true,
+			DeletedUnixTime: now,
+		}
+
+		deletedRows, err := sess.ID(fromAccountId).Cols("balance", "deleted", "deleted_unix_time").Where("uid=? AND deleted=?", uid, false).Update(accountUpdateModel)
+
+		if err != nil {
+			return err
+		} else if deletedRows < 1 {
+			return errs.ErrSourceAccountNotFound
+		}
+
+		return nil
+	})
+}
+
+// GetAllDuePendingTransactions returns pending transactions of all users whose transaction time has arrived
+func (s *TransactionService) GetAllDuePendingTransactions(count int) ([]*models.Transaction, error) {
+	if count < 1 {
+		return nil, errs.ErrPageCountInvalid
+	}
+
+	maxTransactionTime := utils.GetMaxTransactionTimeFromUnixTime(time.Now().Unix())
+
+	var transactions []*models.Transaction
+	err := s.UserDataDB(0).Where("deleted=? AND pending=? AND type<>? AND transaction_time<=?", false, true, models.TRANSACTION_DB_TYPE_TRANSFER_IN, maxTransactionTime).Limit(count, 0).OrderBy("transaction_time asc").Find(&transactions)
+
+	return transactions, err
+}
+
+// PostPendingTransaction posts a pending transaction whose transaction time has arrived to account balance
+func (s *TransactionService) PostPendingTransaction(uid int64, transactionId int64) error {
+	if uid <= 0 {
+		return errs.ErrUserIdInvalid
+	}
+
+	now := time.Now().Unix()
+
+	updateModel := &models.Transaction{
+		Pending:         false,
+		UpdatedUnixTime: now,
+	}
+
+	return s.UserDataDB(uid).DoTransaction(func(sess *xorm.Session) error {
+		// Get and verify current transaction
+		transaction := &models.Transaction{}
+		has, err := sess.ID(transactionId).Where("uid=? AND deleted=?", uid, false).Get(transaction)
+
+		if err != nil {
+			return err
+		} else if !has {
+			return errs.ErrTransactionNotFound
+		}
+
+		if transaction.Type == models.TRANSACTION_DB_TYPE_TRANSFER_IN {
+			return errs.ErrTransactionTypeInvalid
+		}
+
+		if !transaction.Pending || s.isPendingTransaction(transaction, now) {
+			return nil
+		}
+
+		beforeSnapshot, err := s.getTransactionHistorySnapshot(sess, uid, transaction.TransactionId)
+
+		if err != nil {
+			return err
+		}
+
+		// Update transaction row to posted
+		transactionIds := []int64{transaction.TransactionId}
+
+		if transaction.Type == models.TRANSACTION_DB_TYPE_TRANSFER_OUT {
+			transactionIds = append(transactionIds, transaction.RelatedId)
+		}
+
+		updatedRows, err := sess.Cols("pending", "updated_unix_time").Where("uid=? AND deleted=? AND pending=?", uid, false, true).In("transaction_id", transactionIds).Update(updateModel)
+
+		if err != nil {
+			return err
+		} else if updatedRows < int64(len(transactionIds)) {
+			return errs.ErrDatabaseOperationFailed
+		}
+
+		// Update account table
+		transaction.Pending = false
+		balanceChanges := make(accountBalanceChanges)
+		err = s.addTransactionBalanceChanges(balanceChanges, transaction, false)
+
+		if err != nil {
+			return err
+		}
+
+		err = s.updateAccountBalances(sess, uid, balanceChanges)
+
+		if err != nil {
+			return err
+		}
+
+		// Insert transaction history
+		afterSnapshot, err := s.getTransactionHistorySnapshot(sess, uid, transaction.TransactionId)
+
+		if err != nil {
+			return err
+		}
+
+		return s.insertTransactionHistory(sess, uid, transaction.TransactionId, models.TRANSACTION_HISTORY_OPERATION_MODIFY, nil, beforeSnapshot, afterSnapshot)
+	})
+}
+
+// GetAccountsPendingAmounts returns the total balance change of pending transactions of every accounts
+func (s *TransactionService) GetAccountsPendingAmounts(uid int64) (map[int64]int64, error) {
+	if uid <= 0 {
+		return nil, errs.ErrUserIdInvalid
+	}
+
+	var transactions []*models.Transaction
+	err := s.UserDataDB(uid).Where("uid=? AND deleted=? AND pending=?", uid, false, true).Find(&transactions)
+
+	if err != nil {
+		return nil, err
+	}
+
+	accountPendingAmounts := make(map[int64]int64)
+
+	for i := 0; i < len(transactions); i++ {
+		transaction := transactions[i]
+		accountPendingAmounts[transaction.AccountId] += s.getAccountBalanceChangeOfTransaction(transaction)
+	}
+
+	return accountPendingAmounts, nil
+}
+
+// GetRelatedTransferTransaction returns the related transaction for transfer transaction
+func (s *TransactionService) GetRelatedTransferTransaction(originalTransaction *models.Transaction, relatedTransactionId int64) *models.Transaction {
+	var relatedType models.TransactionDbType
+	var relatedTransactionTime int64
+
+	if originalTransaction.Type == models.TRANSACTION_DB_TYPE_TRANSFER_OUT {
+		relatedType = models.TRANSACTION_DB_TYPE_TRANSFER_IN
+		relatedTransactionTime = originalTransaction.TransactionTime + 1
+	} else if originalTransaction.Type == models.TRANSACTION_DB_TYPE_TRANSFER_IN {
+		relatedType = models.TRANSACTION_DB_TYPE_TRANSFER_OUT
+		relatedTransactionTime = originalTransaction.TransactionTime - 1
+	} else {
+		return nil
+	}
+
+	relatedTransaction := &models.Transaction{
+		TransactionId:        relatedTransactionId,
+		Uid:                  originalTransaction.Uid,
+		Deleted:              originalTransaction.Deleted,
+		Type:                 relatedType,
+		CategoryId:           originalTransaction.CategoryId,
+		TransactionTime:      relatedTransactionTime,
+		TimezoneUtcOffset:    originalTransaction.TimezoneUtcOffset,
+		AccountId:            originalTransaction.RelatedAccountId,
+		Amount:               originalTransaction.RelatedAccountAmount,
+		RelatedId:            originalTransaction.TransactionId,
+		RelatedAccountId:     originalTransaction.AccountId,
+		RelatedAccountAmount: originalTransaction.Amount,
+		Comment:              originalTransaction.Comment,
+		ReconcileState:       originalTransaction.ReconcileState,
+		Pending:              originalTransaction.Pending,
+		CreatedUnixTime:      originalTransaction.CreatedUnixTime,
+		UpdatedUnixTime:      originalTransaction.UpdatedUnixTime,
+		DeletedUnixTime:      originalTransaction.DeletedUnixTime,
+	}
+
+	return relatedTransaction
+}
+
+// GetTransferTransactionIdsWithReconciledRelatedTransaction returns the ids of transfer out transactions whose related transfer in transactions have been reconciled
+func (s *TransactionService) GetTransferTransactionIdsWithReconciledRelatedTransaction(uid int64, transactions []*models.Transaction) (map[int64]bool, error) {
+	if uid <= 0 {
+		return nil, errs.ErrUserIdInvalid
+	}
+
+	transferOutTransactionIds := make([]int64, 0, len(transactions))
+
+	for i := 0; i < len(transactions); i++ {
+		if transactions[i].Type == models.TRANSACTION_DB_TYPE_TRANSFER_OUT {
+			transferOutTransactionIds = append(transferOutTransactionIds, transactions[i].TransactionId)
+		}
+	}
+
+	transactionIds := make(map[int64]bool)
+
+	if len(transferOutTransactionIds) < 1 {
+		return transactionIds, nil
+	}
+
+	var relatedTransactions []*models.Transaction
+	err := s.UserDataDB(uid).Cols("related_id").Where("uid=? AND deleted=? AND type=? AND reconcile_state=?", uid, false, models.TRANSACTION_DB_TYPE_TRANSFER_IN, models.TRANSACTION_RECONCILE_STATE_RECONCILED).In("related_id", transferOutTransactionIds).Find(&relatedTransactions)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for i := 0; i < len(relatedTransactions); i++ {
+		transactionIds[relatedTransactions[i].RelatedId] = true
+	}
+
+	return transactionIds, nil
+}
+
+// GetAccountsTotalIncomeAndExpense returns the every accounts total income and expense amount by specific date range
+func (s *TransactionService) GetAccountsTotalIncomeAndExpense(uid int64, startUnixTime int64, endUnixTime int64) (map[int64]int64, map[int64]int64, error) {
+	if uid <= 0 {
+		return nil, nil, errs.ErrUserIdInvalid
+	}
+
+	startTransactionTime := utils.GetMinTransactionTimeFromUnixTime(startUnixTime)
+	endTransactionTime := utils.GetMaxTransactionTimeFromUnixTime(endUnixTime)
+
+	var transactionTotalAmounts []*models.Transaction
+	err := s.UserDataDB(uid).Select("type, account_id, SUM(amount) as amount").Where("uid=? AND deleted=? AND (type=? OR type=?) AND transaction_time>=? AND transaction_time<=?", uid, false, models.TRANSACTION_DB_TYPE_INCOME, models.TRANSACTION_DB_TYPE_EXPENSE, startTransactionTime, endTransactionTime).GroupBy("type, account_id").Find(&transactionTotalAmounts)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	incomeAmounts := make(map[int64]int64)
+	expenseAmounts := make(map[int64]int64)
+
+	for i := 0; i < len(transactionTotalAmounts); i++ {
+		transactionTotalAmount := transactionTotalAmounts[i]
+
+		if transactionTotalAmount.Type == models.TRANSACTION_DB_TYPE_INCOME {
+			incomeAmounts[transactionTotalAmount.AccountId] = transactionTotalAmount.Amount
+		} else if transactionTotalAmount.Type == models.TRANSACTION_DB_TYPE_EXPENSE {
+			expenseAmounts[transactionTotalAmount.AccountId] = transactionTotalAmount.Amount
+		}
+	}
+
+	return incomeAmounts, expenseAmounts, nil
+}
+
+// GetAccountsMonthTotalIncomeAndExpense returns the every accounts total income and expense amount in month by specific date range
+func (s *TransactionService) GetAccountsMonthTotalIncomeAndExpense(uid int64, startUnixTime int64, endUnixTime int64, pageCount int) (map[string]models.TransactionAccountsAmount, error) {
+	if uid <= 0 {
+		return nil, errs.ErrUserIdInvalid
+	}
+
+	startTransactionTime := utils.GetMinTransactionTimeFromUnixTime(startUnixTime)
+	endTransactionTime := utils.GetMaxTransactionTimeFromUnixTime(endUnixTime)
+
+	minTransactionTime := startTransactionTime
+	maxTransactionTime := endTransactionTime
+	var allTransactions []*models.Transaction
+
+	for maxTransactionTime > 0 {
+		var transactions []*models.Transaction
+
+		err := s.UserDataDB(uid).Select("uid, type, account_id, transaction_time, timezone_utc_offset, amount").Where("uid=? AND deleted=? AND (type=? OR type=?) AND transaction_time>=? AND transaction_time<=?", uid, false, models.TRANSACTION_DB_TYPE_INCOME, models.TRANSACTION_DB_TYPE_EXPENSE, minTransactionTime, maxTransactionTime).Limit(pageCount, 0).OrderBy("transaction_time desc").Find(&transactions)
+
+		if err != nil {
+			return nil, err
+		}
+
+		allTransactions = append(allTransactions, transactions...)
+
+		if len(transactions) < pageCount {
+			maxTransactionTime = 0
+			break
+		}
+
+		maxTransactionTime = transactions[len(transactions)-1].TransactionTime - 1
+	}
+
+	totalAmounts := make(map[string]models.TransactionAccountsAmount)
+
+	for i := 0; i < len(allTransactions); i++ {
+		transaction := allTransactions[i]
+		transactionTimeZone := time.FixedZone("Transaction Timezone", int(transaction.TimezoneUtcOffset)*60)
+		yearMonth := utils.FormatUnixTimeToYearMonth(utils.GetUnixTimeFromTransactionTime(transaction.TransactionTime), transactionTimeZone)
+
+		monthAccountsAmounts, exists := totalAmounts[yearMonth]
+
+		if !exists {
+			monthAccountsAmounts = make(models.TransactionAccountsAmount)
+			totalAmounts[yearMonth] = monthAccountsAmounts
+		}
+
+		monthAccountAmount, exists := monthAccountsAmounts[transaction.AccountId]
+
+		if !exists {
+			monthAccountAmount = &models.TransactionAccountAmount{
+				AccountId:          transaction.AccountId,
+				TotalIncomeAmount:  0,
+				TotalExpenseAmount: 0,
+			}
+			monthAccountsAmounts[transaction.AccountId] = monthAccountAmount
+		}
+
+		if transaction.Type == models.TRANSACTION_DB_TYPE_INCOME {
+			monthAccountAmount.TotalIncomeAmount += transaction.Amount
+		} else if transaction.Type == models.TRANSACTION_DB_TYPE_EXPENSE {
+			monthAccountAmount.TotalExpenseAmount += transaction.Amount
+		}
+	}
+
+	return totalAmounts, nil
+}
+
+// GetAccountsBalancesByTimes returns the balances of every account at the every specified unix time, the unix times must be in ascending order
+func (s *TransactionService) GetAccountsBalancesByTimes(uid int64, unixTimes []int64) (map[int64][]int64, error) {
+	if uid <= 0 {
+		return nil, errs.ErrUserIdInvalid
+	}
+
+	accountsBalances := make(map[int64][]int64)
+
+	if len(unixTimes) < 1 {
+		return accountsBalances, nil
+	}
+
+	var minTransactionTime int64
+
+	for i := 0; i < len(unixTimes); i++ {
+		maxTransactionTime := utils.GetMaxTransactionTimeFromUnixTime(unixTimes[i])
+		condition := "uid=? AND deleted=? AND transaction_time<=?"
+		conditionParams := []interface{}{uid, false, maxTransactionTime}
+
+		// The first period contains all the transactions before the first unix time
+		if i > 0 {
+			condition = condition + " AND transaction_time>?"
+			conditionParams = append(conditionParams, minTransactionTime)
+		}
+
+		var transactionTotalAmounts []*models.Transaction
+		err := s.UserDataDB(uid).Select("type, account_id, SUM(amount) as amount, SUM(related_account_amount) as related_account_amount").Where(condition, conditionParams...).GroupBy("type, account_id").Find(&transactionTotalAmounts)
+
+		if err != nil {
+			return nil, err
+		}
+
+		for j := 0; j < len(transactionTotalAmounts); j++ {
+			transactionTotalAmount := transactionTotalAmounts[j]
+			balances, exists := accountsBalances[transactionTotalAmount.AccountId]
+
+			if !exists {
+				balances = make([]int64, len(unixTimes))
+				accountsBalances[transactionTotalAmount.AccountId] = balances
+			}
+
+			balances[i] += s.getAccountBalanceChangeOfTransaction(transactionTotalAmount)
+		}
+
+		minTransactionTime = maxTransactionTime
+	}
+
+	for _, balances := range accountsBalances {
+		for i := 1; i < len(balances); i++ {
+			balances[i] += balances[i-1]
+		}
+	}
+
+	return accountsBalances, nil
+}
+
+// GetAccountsAndCategoriesTotalIncomeAndExpense returns the every accounts and categories (and payees if grouped by payee) total income and expense amount by specific date range
+func (s *TransactionService) GetAccountsAndCategoriesTotalIncomeAndExpense(uid int64, startUnixTime int64, endUnixTime int64, payeeIds []int64, groupByPayee bool) ([]*models.Transaction, error) {
+	if uid <= 0 {
+		return nil, errs.ErrUserIdInvalid
+	}
+
+	totalAmounts, err := s.getCategoriesTotalIncomeAndExpense(uid, startUnixTime, endUnixTime, payeeIds, groupByPayee, transactionTagGroupingNone)
+
+	if err != nil {
+		return nil, err
+	}
+
+	transactionTotalAmounts := make([]*models.Transaction, len(totalAmounts))
+
+	for i := 0; i < len(totalAmounts); i++ {
+		transactionTotalAmounts[i] = &models.Transaction{
+			Uid:        uid,
+			CategoryId: totalAmounts[i].CategoryId,
+			AccountId:  totalAmounts[i].AccountId,
+			PayeeId:    totalAmounts[i].PayeeId,
+			Amount:     totalAmounts[i].Amount,
+		}
+	}
+
+	return transactionTotalAmounts, nil
+}
+
+// GetAccountsCategoriesAndTagsTotalIncomeAndExpense returns the every tags, accounts and categories (and payees if grouped by payee) total income and expense amount by specific date range,
+// transaction with several tags is counted fully under each tag, and transactions without any tag are counted under tag id zero
+func (s *TransactionService) GetAccountsCategoriesAndTagsTotalIncomeAndExpense(uid int64, startUnixTime int64, endUnixTime int64, payeeIds []int64, groupByPayee bool) ([]*models.TransactionTagTotalAmount, error) {
+	if uid <= 0 {
+		return nil, errs.ErrUserIdInvalid
+	}
+
+	untaggedTotalAmounts, err := s.getCategoriesTotalIncomeAndExpense(uid, startUnixTime, endUnixTime, payeeIds, groupByPayee, transactionTagGroupingUntagged)
+
+	if err != nil {
+		return nil, err
+	}
+
+	taggedTotalAmounts, err := s.getCategoriesTotalIncomeAndExpense(uid, startUnixTime, endUnixTime, payeeIds, groupByPayee, transactionTagGroupingTagged)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return append(untaggedTotalAmounts, taggedTotalAmounts...), nil
+}
+
+// GetTransactionMapByList returns a transaction map by a list
+func (s *TransactionService) GetTransactionMapByList(transactions []*models.Transaction) map[int64]*models.Transaction {
+	transactionMap := make(map[int64]*models.Transaction)
+
+	for i := 0; i < len(transactions); i++ {
+		transaction := transactions[i]
+		transactionMap[transaction.TransactionId] = transaction
+	}
+
+	return transactionMap
+}
+
+func (s *TransactionService) getCategoriesTotalIncomeAndExpense(uid int64, startUnixTime int64, endUnixTime int64, payeeIds []int64, groupByPayee bool, tagGrouping transactionTagGrouping) ([]*models.TransactionTagTotalAmount, error) {
+	condition := "t.uid=? AND t.deleted=? AND (t.type=? OR t.type=?)"
+	conditionParams := make([]interface{}, 0, 8)
+	conditionParams = append(conditionParams, uid)
+	conditionParams = append(conditionParams, false)
+	conditionParams = append(conditionParams, models.TRANSACTION_DB_TYPE_INCOME)
+	conditionParams = append(conditionParams, models.TRANSACTION_DB_TYPE_EXPENSE)
+
+	if startUnixTime > 0 {
+		condition = condition + " AND t.transaction_time>=?"
+		conditionParams = append(conditionParams, utils.GetMinTransactionTimeFromUnixTime(startUnixTime))
+	}
+
+	if endUnixTime > 0 {
+		condition = condition + " AND t.transaction_time<=?"
+		conditionParams = append(conditionParams, utils.GetMaxTransactionTimeFromUnixTime(endUnixTime))
+	}
+
+	if len(payeeIds) > 0 {
+		var conditions strings.Builder
+
+		for i := 0; i < len(payeeIds); i++ {
+			if i > 0 {
+				conditions.WriteString(",")
+			}
+
+			conditions.WriteString("?")
+			conditionParams = append(conditionParams, payeeIds[i])
+		}
+
+		condition = condition + " AND t.payee_id IN (" + conditions.String() + ")"
+	}
+
+	if tagGrouping == transactionTagGroupingUntagged {
+		condition = condition + " AND t.transaction_id NOT IN (SELECT transaction_id FROM transaction_tag_index WHERE uid=? AND deleted=?)"
+		conditionParams = append(conditionParams, uid)
+		conditionParams = append(conditionParams, false)
+	}
+
+	groupByColumns := "t.account_id"
+
+	if groupByPayee {
+		groupByColumns = groupByColumns + ", t.payee_id"
+	}
+
+	if tagGrouping == transactionTagGroupingTagged {
+		groupByColumns = "ti.tag_id, " + groupByColumns
+	}
+
+	newSession := func(tableName string, alias string) *xorm.Session {
+		sess := s.UserDataDB(uid).Table(tableName).Alias(alias)
+
+		if tableName != "transaction" {
+			sess = sess.Join("INNER", []string{"transaction", "t"}, alias+".uid=t.uid AND "+alias+".transaction_id=t.transaction_id")
+		}
+
+		if tagGrouping == transactionTagGroupingTagged {
+			sess = sess.Join("INNER", []string{"transaction_tag_index", "ti"}, "ti.uid=t.uid AND ti.transaction_id=t.transaction_id AND ti.deleted=?", false)
+		}
+
+		return sess
+	}
+
+	var totalAmounts []*models.TransactionTagTotalAmount
+	err := newSession("transaction", "t").Select("t.category_id, "+groupByColumns+", SUM(t.amount) AS amount").Where(condition, conditionParams...).GroupBy("t.category_id, " + groupByColumns).Find(&totalAmounts)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// The whole amount of split transaction has been counted in its primary category, so move each split amount to its own category
+	splitCondition := condition + " AND s.deleted=?"
+	splitConditionParams := make([]interface{}, 0, len(conditionParams)+1)
+	splitConditionParams = append(splitConditionParams, conditionParams...)
+	splitConditionParams = append(splitConditionParams, false)
+
+	var primarySplitAmounts []*models.TransactionTagTotalAmount
+	err = newSession("transaction_split", "s").Select("t.category_id, "+groupByColumns+", SUM(s.amount) AS amount").Where(splitCondition, splitConditionParams...).GroupBy("t.category_id, " + groupByColumns).Find(&primarySplitAmounts)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var splitAmounts []*models.TransactionTagTotalAmount
+	err = newSession("transaction_split", "s").Select("s.category_id, "+groupByColumns+", SUM(s.amount) AS amount").Where(splitCondition, splitConditionParams...).GroupBy("s.category_id, " + groupByColumns).Find(&splitAmounts)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(primarySplitAmounts) < 1 && len(splitAmounts) < 1 {
+		return totalAmounts, nil
+	}
+
+	totalAmountMap := make(map[string]*models.TransactionTagTotalAmount, len(totalAmounts))
+	changedTotalAmounts := make(map[string]bool)
+
+	for i := 0; i < len(totalAmounts); i++ {
+		totalAmount := totalAmounts[i]
+		totalAmountMap[s.getTagTotalAmountKey(totalAmount)] = totalAmount
+	}
+
+	for i := 0; i < len(primarySplitAmounts); i++ {
+		primarySplitAmount := primarySplitAmounts[i]
+		key := s.getTagTotalAmountKey(primarySplitAmount)
+
+		if totalAmount, exists := totalAmountMap[key]; exists {
+			totalAmount.Amount -= primarySplitAmount.Amount
+			changedTotalAmounts[key] = true
+		}
+	}
+
+	for i := 0; i < len(splitAmounts); i++ {
+		splitAmount := splitAmounts[i]
+		key := s.getTagTotalAmountKey(splitAmount)
+		totalAmount, exists := totalAmountMap[key]
+
+		if !exists {
+			totalAmount = &models.TransactionTagTotalAmount{
+				TagId:      splitAmount.TagId,
+				CategoryId: splitAmount.CategoryId,
+				AccountId:  splitAmount.AccountId,
+				PayeeId:    splitAmount.PayeeId,
+			}
+
+			totalAmountMap[key] = totalAmount
+			totalAmounts = append(totalAmounts, totalAmount)
+		}
+
+		totalAmount.Amount += splitAmount.Amount
+		changedTotalAmounts[key] = true
+	}
+
+	finalTotalAmounts := make([]*models.TransactionTagTotalAmount, 0, len(totalAmounts))
+
+	for i := 0; i < len(totalAmounts); i++ {
+		totalAmount := totalAmounts[i]
+
+		if totalAmount.Amount == 0 && changedTotalAmounts[s.getTagTotalAmountKey(totalAmount)] {
+			continue
+		}
+
+		finalTotalAmounts = append(finalTotalAmounts, totalAmount)
+	}
+
+	return finalTotalAmounts, nil
+}
+
+func (s *TransactionService) getTagTotalAmountKey(totalAmount *models.TransactionTagTotalAmount) string {
+	return fmt.Sprintf("%d_%s", totalAmount.TagId, s.getTotalAmountKey(totalAmount.CategoryId, totalAmount.AccountId, totalAmount.PayeeId))
+}
+
+func (s *TransactionService) getTotalAmountKey(categoryId int64, accountId int64, payeeId int64) string {
+	return fmt.Sprintf("%d_%d_%d", categoryId, accountId, payeeId)
+}
+
+func (s *TransactionService) getTransactionHistorySnapshot(sess *xorm.Session, uid int64, transactionId int64) (*models.TransactionHistorySnapshot, error) {
+	transaction := &models.Transaction{}
+	has, err := sess.ID(transactionId).Where("uid=?", uid).Get(transaction)
+
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, errs.ErrTransactionNotFound
+	}
+
+	var tagIndexes []*models.TransactionTagIndex
+	err = sess.Where("uid=? AND deleted=? AND deleted_unix_time=? AND transaction_id=?", uid, transaction.Deleted, transaction.DeletedUnixTime, transactionId).Find(&tagIndexes)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var splits []*models.TransactionSplit
+	err = sess.Where("uid=? AND deleted=? AND deleted_unix_time=? AND transaction_id=?", uid, transaction.Deleted, transaction.DeletedUnixTime, transactionId).Find(&splits)
+
+	if err != nil {
+		return nil, err
+	}
+
+	tagIds := make([]int64, len(tagIndexes))
+
+	for i := 0; i < len(tagIndexes); i++ {
+		tagIds[i] = tagIndexes[i].TagId
+	}
+
+	var relatedReconcileState models.TransactionReconcileState
+
+	if transaction.Type == models.TRANSACTION_DB_TYPE_TRANSFER_OUT {
+		relatedTransaction := &models.Transaction{}
+		has, err = sess.ID(transaction.RelatedId).Where("uid=?", uid).Get(relatedTransaction)
+
+		if err != nil {
+			return nil, err
+		} else if has {
+			relatedReconcileState = relatedTransaction.ReconcileState
+		}
+	}
+
+	sort.Sort(models.TransactionSplitSlice(splits))
+	splitResps := make([]*models.TransactionSplitInfoResponse, len(splits))
+
+	for i := 0; i < len(splits); i++ {
+		splitResps[i] = splits[i].ToTransactionSplitInfoResponse()
+	}
+
+	snapshot := &models.TransactionHistorySnapshot{
+		Type:                  transaction.Type,
+		CategoryId:            transaction.CategoryId,
+		AccountId:             transaction.AccountId,
+		RelatedAccountId:      transaction.RelatedAccountId,
+		PayeeId:               transaction.PayeeId,
+		TransactionTime:       utils.GetUnixTimeFromTransactionTime(transaction.TransactionTime),
+		TimezoneUtcOffset:     transaction.TimezoneUtcOffset,
+		Amount:                transaction.Amount,
+		RelatedAccountAmount:  transaction.RelatedAccountAmount,
+		OriginalCurrency:      transaction.OriginalCurrency,
+		OriginalAmount:        transaction.OriginalAmount,
+		ExchangeRate:          transaction.ExchangeRate,
+		HideAmount:            transaction.HideAmount,
+		Comment:               transaction.Comment,
+		TagIds:                utils.Int64ArrayToStringArray(tagIds),
+		Splits:                splitResps,
+		ReconcileState:        transaction.ReconcileState,
+		RelatedReconcileState: relatedReconcileState,
+		Pending:               transaction.Pending,
+	}
+
+	return snapshot, nil
+}
+
+func (s *TransactionService) insertTransactionHistory(sess *xorm.Session, uid int64, transactionId int64, operation models.TransactionHistoryOperation, operator *models.TransactionOperator, beforeSnapshot *models.TransactionHistorySnapshot, afterSnapshot *models.TransactionHistorySnapshot) error {
+	history := &models.TransactionHistory{
+		HistoryId:       s.GenerateUuid(uuid.UUID_TYPE_HISTORY),
+		Uid:             uid,
+		TransactionId:   transactionId,
+		Operation:       operation,
+		CreatedUnixTime: time.Now().Unix(),
+	}
+
+	if operator != nil {
+		history.UserTokenId = operator.UserTokenId
+		history.RequestId = operator.RequestId
+	}
+
+	if beforeSnapshot != nil {
+		data, err := json.Marshal(beforeSnapshot)
+
+		if err != nil {
+			return err
+		}
+
+		history.BeforeData = string(data)
+	}
+
+	if afterSnapshot != nil {
+		data, err := json.Marshal(afterSnapshot)
+
+		if err != nil {
+			return err
+		}
+
+		history.AfterData = string(data)
+	}
+
+	_, err := sess.Insert(history)
+
+	return err
+}
+
+func (s *TransactionService) getTransactionHistorySnapshots(sess *xorm.Session, uid int64, transactionIds []int64) ([]*models.TransactionHistorySnapshot, error) {
+	snapshots := make([]*models.TransactionHistorySnapshot, len(transactionIds))
+
+	for i := 0; i < len(transactionIds); i++ {
+		snapshot, err := s.getTransactionHistorySnapshot(sess, uid, transactionIds[i])
+
+		if err != nil {
+			return nil, err
+		}
+
+		snapshots[i] = snapshot
+	}
+
+	return snapshots, nil
+}
+
+func (s *TransactionService) insertModifiedTransactionHistories(sess *xorm.Session, uid int64, transactionIds []int64, operator *models.TransactionOperator, beforeSnapshots []*models.TransactionHistorySnapshot) error {
+	for i := 0; i < len(transactionIds); i++ {
+		afterSnapshot, err := s.getTransactionHistorySnapshot(sess, uid, transactionIds[i])
+
+		if err != nil {
+			return err
+		}
+
+		err = s.insertTransactionHistory(sess, uid, transactionIds[i], models.TRANSACTION_HISTORY_OPERATION_MODIFY, operator, beforeSnapshots[i], afterSnapshot)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *TransactionService) prepareBatchTransactionTimes(sess *xorm.Session, uid int64, items []*models.TransactionBatchItem) (int, error) {
+	nextTransactionTimes := make(map[int64]int64)
+
+	// Transactions in the same second must have different transaction times, assign them after the existed transactions in the same second before inserting,
+	// so that the inserts do not conflict with each other or with the existed transactions (including the deleted ones)
+	for i := 0; i < len(items); i++ {
+		transaction := items[i].Transaction
+		unixTime := utils.GetUnixTimeFromTransactionTime(transaction.TransactionTime)
+		maxTransactionTime := utils.GetMaxTransactionTimeFromUnixTime(unixTime)
+		isTransfer := transaction.Type == models.TRANSACTION_DB_TYPE_TRANSFER_OUT || transaction.Type == models.TRANSACTION_DB_TYPE_TRANSFER_IN
+
+		transactionTime, exists := nextTransactionTimes[unixTime]
+
+		if !exists {
+			var err error
+			transactionTime, err = s.getNextAvailableTransactionTime(sess, uid, utils.GetMinTransactionTimeFromUnixTime(unixTime))
+
+			if err != nil {
+				return i, err
+			}
+		}
+
+		if transactionTime > maxTransactionTime || (isTransfer && transactionTime+1 > maxTransactionTime) {
+			return i, errs.ErrTooMuchTransactionInOneSecond
+		}
+
+		transaction.TransactionTime = transactionTime
+		nextTransactionTimes[unixTime] = transactionTime + 1
+
+		if isTransfer {
+			nextTransactionTimes[unixTime] = transactionTime + 2
+		}
+	}
+
+	return -1, nil
+}
+
+func (s *TransactionService) getAccountReconcileSummary(transactions []*models.Transaction) *models.AccountReconcileSummary {
+	summary := &models.AccountReconcileSummary{}
+
+	for i := 0; i < len(transactions); i++ {
+		transaction := transactions[i]
+
+		if transaction.ReconcileState == models.TRANSACTION_RECONCILE_STATE_UNCLEARED {
+			summary.UnclearedCount++
+			continue
+		} else if transaction.ReconcileState == models.TRANSACTION_RECONCILE_STATE_CLEARED {
+			summary.ClearedCount++
+		} else if transaction.ReconcileState == models.TRANSACTION_RECONCILE_STATE_RECONCILED {
+			summary.ReconciledCount++
+		}
+
+		summary.ClearedBalance += s.getAccountBalanceChangeOfTransaction(transaction)
+	}
+
+	return summary
+}
+
+func (s *TransactionService) getAccountBalanceChangeOfTransaction(transaction *models.Transaction) int64 {
+	if transaction.Type == models.TRANSACTION_DB_TYPE_MODIFY_BALANCE {
+		return transaction.RelatedAccountAmount
+	} else if transaction.Type == models.TRANSACTION_DB_TYPE_INCOME || transaction.Type == models.TRANSACTION_DB_TYPE_TRANSFER_IN {
+		return transaction.Amount
+	} else if transaction.Type == models.TRANSACTION_DB_TYPE_EXPENSE || transaction.Type == models.TRANSACTION_DB_TYPE_TRANSFER_OUT {
+		return -transaction.Amount
+	}
+
+	return 0
+}
+
+func (s *TransactionService) isPendingTransaction(transaction *models.Transaction, now int64) bool {
+	if transaction.Type == models.TRANSACTION_DB_TYPE_MODIFY_BALANCE {
+		return false
+	}
+
+	return utils.GetUnixTimeFromTransactionTime(transaction.TransactionTime) > now
+}
+
+func (s *TransactionService) getMinDeletedUnixTimeInTrash() int64 {
+	retentionDays := s.CurrentConfig().TransactionTrashRetentionDays
+
+	if retentionDays <= 0 {
+		return 1
+	}
+
+	return time.Now().Unix() - int64(retentionDays)*24*60*60
+}
+
+func (s *TransactionService) getTransactionQueryCondition(uid int64, maxTransactionTime int64, minTransactionTime int64, transactionType models.TransactionDbType, categoryIds []int64, accountIds []int64, payeeIds []int64, tagIds []int64, tagFilterType models.TransactionTagFilterType, keyword string, noDuplicated bool) (string, []interface{}) {
+	condition := "uid=? AND deleted=?"
+	conditionParams := make([]interface{}, 0, 16)
+	conditionParams = append(conditionParams, uid)
+	conditionParams = append(conditionParams, false)
+
+	if maxTransactionTime > 0 {
+		condition = condition + " AND transaction_time<=?"
+		conditionParams = append(conditionParams, maxTransactionTime)
+	}
+
+	if minTransactionTime > 0 {
+		condition = condition + " AND transaction_time>=?"
+		conditionParams = append(conditionParams, minTransactionTime)
+	}
+
+	if models.TRANSACTION_DB_TYPE_MODIFY_BALANCE <= transactionType && transactionType <= models.TRANSACTION_DB_TYPE_EXPENSE {
+		condition = condition + " AND type=?"
+		conditionParams = append(conditionParams, transactionType)
+	} else if transactionType == models.TRANSACTION_DB_TYPE_TRANSFER_OUT || transactionType == models.TRANSACTION_DB_TYPE_TRANSFER_IN {
+		if len(accountIds) == 0 {
+			condition = condition + " AND type=?"
+			conditionParams = append(conditionParams, models.TRANSACTION_DB_TYPE_TRANSFER_OUT)
+		} else {
+			condition = condition + " AND (type=? OR type=?)"
+			conditionParams = append(conditionParams, models.TRANSACTION_DB_TYPE_TRANSFER_OUT)
+			conditionParams = append(conditionParams, models.TRANSACTION_DB_TYPE_TRANSFER_IN)
+		}
+	} else {
+		if noDuplicated && len(accountIds) == 0 {
+			condition = condition + " AND (type=? OR type=? OR type=? OR type=?)"
+			conditionParams = append(conditionParams, models.TRANSACTION_DB_TYPE_MODIFY_BALANCE)
+			conditionParams = append(conditionParams, models.TRANSACTION_DB_TYPE_INCOME)
+			conditionParams = append(conditionParams, models.TRANSACTION_DB_TYPE_EXPENSE)
+			conditionParams = append(conditionParams, models.TRANSACTION_DB_TYPE_TRANSFER_OUT)
+		}
+	}
+
+	if len(categoryIds) > 0 {
+		var conditions strings.Builder
+
+		for i := 0; i < len(categoryIds); i++ {
+			if i > 0 {
+				conditions.WriteString(",")
+			}
+
+			conditions.WriteString("?")
+			conditionParams = append(conditionParams, categoryIds[i])
+		}
+
+		condition = condition + " AND category_id IN (" + conditions.String() + ")"
+	}
+
+	if len(accountIds) > 0 {
+		var conditions strings.Builder
+
+		for i := 0; i < len(accountIds); i++ {
+			if i > 0 {
+				conditions.WriteString(",")
+			}
+
+			conditions.WriteString("?")
+			conditionParams = append(conditionParams, accountIds[i])
+		}
+
+		condition = condition + " AND account_id IN (" + conditions.String() + ")"
+	}
+
+	if len(payeeIds) > 0 {
+		var conditions strings.Builder
+
+		for i := 0; i < len(payeeIds); i++ {
+			if i > 0 {
+				conditions.WriteString(",")
+			}
+
+			conditions.WriteString("?")
+			conditionParams = append(conditionParams, payeeIds[i])
+		}
+
+		condition = condition + " AND payee_id IN (" + conditions.String() + ")"
+	}
+
+	if len(tagIds) > 0 {
+		tagCondition, tagConditionParams := s.getTransactionTagQueryCondition(uid, tagIds, tagFilterType)
+
+		// Tags are only indexed by the transfer out transaction, so the transfer in transaction should be matched by its related id
+		if len(accountIds) > 0 {
+			condition = condition + " AND (transaction_id IN (" + tagCondition + ") OR (type=? AND related_id IN (" + tagCondition + ")))"
+			conditionParams = append(conditionParams, tagConditionParams...)
+			conditionParams = append(conditionParams, models.TRANSACTION_DB_TYPE_TRANSFER_IN)
+			conditionParams = append(conditionParams, tagConditionParams...)
+		} else {
+			condition = condition + " AND transaction_id IN (" + tagCondition + ")"
+			conditionParams = append(conditionParams, tagConditionParams...)
+		}
+	}
+
+	if keyword != "" {
+		keywordCondition, keywordConditionParams := s.getTransactionCommentQueryCondition(uid, keyword)
+		condition = condition + " AND " + keywordCondition
+		conditionParams = append(conditionParams, keywordConditionParams...)
+	}
+
+	return condition, conditionParams
+}
+
+func (s *TransactionService) getTransactionSearchCondition(uid int64, filter *models.TransactionSearchFilter) (string, []interface{}) {
+	condition, conditionParams := s.getTransactionQueryCondition(uid, filter.MaxTransactionTime, filter.MinTransactionTime, filter.Type, filter.CategoryIds, filter.AccountIds, filter.PayeeIds, filter.TagIds, filter.TagFilterType, "", true)
+
+	if len(filter.ExcludeCategoryIds) > 0 {
+		var conditions strings.Builder
+
+		for i := 0; i < len(filter.ExcludeCategoryIds); i++ {
+			if i > 0 {
+				conditions.WriteString(",")
+			}
+
+			conditions.WriteString("?")
+			conditionParams = append(conditionParams, filter.ExcludeCategoryIds[i])
+		}
+
+		condition = condition + " AND category_id NOT IN (" + conditions.String() + ")"
+	}
+
+	if len(filter.ExcludeAccountIds) > 0 {
+		var conditions strings.Builder
+
+		for i := 0; i < len(filter.ExcludeAccountIds); i++ {
+			if i > 0 {
+				conditions.WriteString(",")
+			}
+
+			conditions.WriteString("?")
+			conditionParams = append(conditionParams, filter.ExcludeAccountIds[i])
+		}
+
+		condition = condition + " AND account_id NOT IN (" + conditions.String() + ")"
+	}
+
+	if len(filter.ExcludePayeeIds) > 0 {
+		var conditions strings.Builder
+
+		for i := 0; i < len(filter.ExcludePayeeIds); i++ {
+			if i > 0 {
+				conditions.WriteString(",")
+			}
+
+			conditions.WriteString("?")
+			conditionParams = append(conditionParams, filter.ExcludePayeeIds[i])
+		}
+
+		condition = condition + " AND payee_id NOT IN (" + conditions.String() + ")"
+	}
+
+	if len(filter.ExcludeTagIds) > 0 {
+		tagCondition, tagConditionParams := s.getTransactionTagQueryCondition(uid, filter.ExcludeTagIds, models.TRANSACTION_TAG_FILTER_HAS_ANY)
+
+		condition = condition + " AND transaction_id NOT IN (" + tagCondition + ") AND (type<>? OR related_id NOT IN (" + tagCondition + "))"
+		conditionParams = append(conditionParams, tagConditionParams...)
+		conditionParams = append(conditionParams, models.TRANSACTION_DB_TYPE_TRANSFER_IN)
+		conditionParams = append(conditionParams, tagConditionParams...)
+	}
+
+	if filter.MinAmount != nil {
+		condition = condition + " AND amount>=?"
+		conditionParams = append(conditionParams, *filter.MinAmount)
+	}
+
+	if filter.MaxAmount != nil {
+		condition = condition + " AND amount<=?"
+		conditionParams = append(conditionParams, *filter.MaxAmount)
+	}
+
+	for i := 0; i < len(filter.Keywords); i++ {
+		keywordCondition, keywordConditionParams := s.getTransactionCommentQueryCondition(uid, filter.Keywords[i])
+		condition = condition + " AND " + keywordCondition
+		conditionParams = append(conditionParams, keywordConditionParams...)
+	}
+
+	for i := 0; i < len(filter.ExcludeKeywords); i++ {
+		keywordCondition, keywordConditionParams := s.getTransactionCommentQueryCondition(uid, filter.ExcludeKeywords[i])
+		condition = condition + " AND NOT (" + keywordCondition + ")"
+		conditionParams = append(conditionParams, keywordConditionParams...)
+	}
+
+	return condition, conditionParams
+}
+
+func (s *TransactionService) getTransactionCommentQueryCondition(uid int64, keyword string) (string, []interface{}) {
+	fullTextIndex := s.UserDataDB(uid).GetFullTextIndex(TransactionCommentFullTextIndex)
+
+	if fullTextIndex != nil {
+		return fullTextIndex.GetMatchCondition(keyword)
+	}
+
+	return "comment LIKE ?", []interface{}{"%%" + keyword + "%%"}
+}
+
+func (s *TransactionService) updateTransactionCommentFullTextIndex(sess *xorm.Session, transaction *models.Transaction) error {
+	fullTextIndex := s.UserDataDB(transaction.Uid).GetFullTextIndex(TransactionCommentFullTextIndex)
+
+	if fullTextIndex == nil {
+		return nil
+	}
+
+	err := fullTextIndex.UpdateDocument(sess, transaction.Uid, transaction.TransactionId, transaction.Comment)
+
+	if err != nil {
+		return err
+	}
+
+	if transaction.Type == models.TRANSACTION_DB_TYPE_TRANSFER_OUT || transaction.Type == models.TRANSACTION_DB_TYPE_TRANSFER_IN {
+		return fullTextIndex.UpdateDocument(sess, transaction.Uid, transaction.RelatedId, transaction.Comment)
+	}
+
+	return nil
+}
+
+func (s *TransactionService) deleteTransactionCommentFullTextIndex(sess *xorm.Session, transaction *models.Transaction) error {
+	fullTextIndex := s.UserDataDB(transaction.Uid).GetFullTextIndex(TransactionCommentFullTextIndex)
+
+	if fullTextIndex == nil {
+		return nil
+	}
+
+	err := fullTextIndex.DeleteDocument(sess, transaction.Uid, transaction.TransactionId)
+
+	if err != nil {
+		return err
+	}
+
+	if transaction.Type == models.TRANSACTION_DB_TYPE_TRANSFER_OUT || transaction.Type == models.TRANSACTION_DB_TYPE_TRANSFER_IN {
+		return fullTextIndex.DeleteDocument(sess, transaction.Uid, transaction.RelatedId)
+	}
+
+	return nil
+}
+
+func (s *TransactionService) getTransactionTagQueryCondition(uid int64, tagIds []int64, tagFilterType models.TransactionTagFilterType) (string, []interface{}) {
+	tagIds = utils.ToUniqueInt64Slice(tagIds)
+
+	var conditions strings.Builder
+	conditionParams := make([]interface{}, 0, len(tagIds)+3)
+	conditionParams = append(conditionParams, uid)
+	conditionParams = append(conditionParams, false)
+
+	for i := 0; i < len(tagIds); i++ {
+		if i > 0 {
+			conditions.WriteString(",")
+		}
+
+		conditions.WriteString("?")
+		conditionParams = append(conditionParams, tagIds[i])
+	}
+
+	condition := "SELECT transaction_id FROM transaction_tag_index WHERE uid=? AND deleted=? AND tag_id IN (" + conditions.String() + ")"
+
+	if tagFilterType == models.TRANSACTION_TAG_FILTER_HAS_ALL {
+		condition = condition + " GROUP BY transaction_id HAVING COUNT(DISTINCT tag_id)=?"
+		conditionParams = append(conditionParams, len(tagIds))
+	}
+
+	return condition, conditionParams
+}
+
+func (s *TransactionService) modifyTransaction(sess *xorm.Session, transaction *models.Transaction, addTagIds []int64, removeTagIds []int64, splits []*models.TransactionSplit, operator *models.TransactionOperator, balanceChanges accountBalanceChanges) error {
+	updateCols := make([]string, 0, 16)
+
+	now := time.Now().Unix()
+
+	transaction.TransactionTime = utils.GetMinTransactionTimeFromUnixTime(utils.GetUnixTimeFromTransactionTime(transaction.TransactionTime))
+	transaction.UpdatedUnixTime = now
+	updateCols = append(updateCols, "updated_unix_time")
+
+	addTagIds = utils.ToUniqueInt64Slice(addTagIds)
+	removeTagIds = utils.ToUniqueInt64Slice(removeTagIds)
+
+	transactionTagIndexs := make([]*models.TransactionTagIndex, len(addTagIds))
+
+	for i := 0; i < len(addTagIds); i++ {
+		transactionTagIndexs[i] = &models.TransactionTagIndex{
+			TagIndexId:      s.GenerateUuid(uuid.UUID_TYPE_TAG_INDEX),
+			Uid:             transaction.Uid,
+			Deleted:         false,
+			TagId:           addTagIds[i],
+			TransactionId:   transaction.TransactionId,
+			CreatedUnixTime: now,
+			UpdatedUnixTime: now,
+		}
+	}
+
+	s.prepareTransactionSplits(transaction, splits, now)
+
+	// Get and verify current transaction
+	oldTransaction := &models.Transaction{}
+	has, err := sess.ID(transaction.TransactionId).Where("uid=? AND deleted=?", transaction.Uid, false).Get(oldTransaction)
+
+	if err != nil {
+		return err
+	} else if !has {
+		return errs.ErrTransactionNotFound
+	}
+
+	beforeSnapshot, err := s.getTransactionHistorySnapshot(sess, transaction.Uid, transaction.TransactionId)
+
+	if err != nil {
+		return err
+	}
+
+	transaction.Type = oldTransaction.Type
+	transaction.Pending = s.isPendingTransaction(transaction, now)
+
+	if transaction.Type == models.TRANSACTION_DB_TYPE_TRANSFER_OUT {
+		transaction.RelatedId = oldTransaction.RelatedId
+	} else if transaction.Type == models.TRANSACTION_DB_TYPE_MODIFY_BALANCE {
+		if transaction.AccountId != oldTransaction.AccountId {
+			return errs.ErrBalanceModificationTransactionCannotChangeAccountId
+		}
+
+		transaction.RelatedAccountId = oldTransaction.RelatedAccountId
+		transaction.RelatedAccountAmount = oldTransaction.RelatedAccountAmount
+	}
+
+	// Check whether account id is valid
+	err = s.isAccountIdValid(transaction)
+
+	if err != nil {
+		return err
+	}
+
+	// Get and verify source and destination account (if necessary)
+	sourceAccount, destinationAccount, err := s.getAccountModels(sess, transaction)
+
+	if err != nil {
+		return err
+	}
+
+	if sourceAccount.Hidden || (destinationAccount != nil && destinationAccount.Hidden) {
+		return errs.ErrCannotModifyTransactionInHiddenAccount
+	}
+
+	if (transaction.Type == models.TRANSACTION_DB_TYPE_TRANSFER_OUT || transaction.Type == models.TRANSACTION_DB_TYPE_TRANSFER_IN) &&
+		sourceAccount.Currency == destinationAccount.Currency && transaction.Amount != transaction.RelatedAccountAmount {
+		return errs.ErrTransactionSourceAndDestinationAmountNotEqual
+	}
+
+	oldSourceAccount, oldDestinationAccount, err := s.getOldAccountModels(sess, transaction, oldTransaction, sourceAccount, destinationAccount)
+
+	if err != nil {
+		return err
+	}
+
+	if oldSourceAccount.Hidden || (oldDestinationAccount != nil && oldDestinationAccount.Hidden) {
+		return errs.ErrCannotAddTransactionToHiddenAccount
+	}
+
+	// Append modified columns and verify
+	if transaction.CategoryId != oldTransaction.CategoryId {
+		// Get and verify category
+		err = s.isCategoryValid(sess, transaction)
+
+		if err != nil {
+			return err
+		}
+
+		updateCols = append(updateCols, "category_id")
+	}
+
+	if transaction.PayeeId != oldTransaction.PayeeId {
+		// Get and verify payee
+		err = s.isPayeeValid(sess, transaction)
+
+		if err != nil {
+			return err
+		}
+
+		updateCols = append(updateCols, "payee_id")
+	}
+
+	if utils.GetUnixTimeFromTransactionTime(transaction.TransactionTime) != utils.GetUnixTimeFromTransactionTime(oldTransaction.TransactionTime) {
+		sameSecondLatestTransaction := &models.Transaction{}
+		minTransactionTime := utils.GetMinTransactionTimeFromUnixTime(utils.GetUnixTimeFromTransactionTime(transaction.TransactionTime))
+		maxTransactionTime := utils.GetMaxTransactionTimeFromUnixTime(utils.GetUnixTimeFromTransactionTime(transaction.TransactionTime))
+
+		has, err = sess.Where("uid=? AND deleted=? AND transaction_time>=? AND transaction_time<=?", transaction.Uid, false, minTransactionTime, maxTransactionTime).OrderBy("transaction_time desc").Limit(1).Get(sameSecondLatestTransaction)
+
+		if err != nil {
+			return err
+		}
+
+		if has && sameSecondLatestTransaction.TransactionTime < maxTransactionTime-1 {
+			transaction.TransactionTime = sameSecondLatestTransaction.TransactionTime + 1
+		} else if has && sameSecondLatestTransaction.TransactionTime == maxTransactionTime-1 {
+			return errs.ErrTooMuchTransactionInOneSecond
+		}
+
+		updateCols = append(updateCols, "transaction_time")
+	}
+
+	if transaction.TimezoneUtcOffset != oldTransaction.TimezoneUtcOffset {
+		updateCols = append(updateCols, "timezone_utc_offset")
+	}
+
+	if transaction.AccountId != oldTransaction.AccountId {
+		updateCols = append(updateCols, "account_id")
+	}
+
+	if transaction.Amount != oldTransaction.Amount {
+		if oldTransaction.Type == models.TRANSACTION_DB_TYPE_MODIFY_BALANCE {
+			originalBalance := sourceAccount.Balance + balanceChanges[sourceAccount.AccountId] - oldTransaction.RelatedAccountAmount
+			transaction.RelatedAccountAmount = transaction.Amount - originalBalance
+			updateCols = append(updateCols, "related_account_amount")
+		}
+
+		updateCols = append(updateCols, "amount")
+	}
+
+	if transaction.Type == models.TRANSACTION_DB_TYPE_TRANSFER_OUT || transaction.Type == models.TRANSACTION_DB_TYPE_TRANSFER_IN {
+		if transaction.RelatedAccountId != oldTransaction.RelatedAccountId {
+			updateCols = append(updateCols, "related_account_id")
+		}
+
+		if transaction.RelatedAccountAmount != oldTransaction.RelatedAccountAmount {
+			updateCols = append(updateCols, "related_account_amount")
+		}
+	}
+
+	if transaction.OriginalCurrency != oldTransaction.OriginalCurrency {
+		updateCols = append(updateCols, "original_currency")
+	}
+
+	if transaction.OriginalAmount != oldTransaction.OriginalAmount {
+		updateCols = append(updateCols, "original_amount")
+	}
+
+	if transaction.ExchangeRate != oldTransaction.ExchangeRate {
+		updateCols = append(updateCols, "exchange_rate")
+	}
+
+	if transaction.HideAmount != oldTransaction.HideAmount {
+		updateCols = append(updateCols, "hide_amount")
+	}
+
+	if transaction.Comment != oldTransaction.Comment {
+		updateCols = append(updateCols, "comment")
+	}
+
+	if transaction.Pending != oldTransaction.Pending {
+		updateCols = append(updateCols, "pending")
+	}
+
+	// Get and verify tags
+	err = s.isTagsValid(sess, transaction, transactionTagIndexs, addTagIds)
+
+	if err != nil {
+		return err
+	}
+
+	// Verify splits
+	err = s.isSplitsValid(sess, transaction, splits)
+
+	if err != nil {
+		return err
+	}
+
+	// Update transaction row
+	updatedRows, err := sess.ID(transaction.TransactionId).Cols(updateCols...).Where("uid=? AND deleted=?", transaction.Uid, false).Update(transaction)
+
+	if err != nil {
+		return err
+	} else if updatedRows < 1 {
+		return errs.ErrTransactionNotFound
+	}
+
+	if transaction.Type == models.TRANSACTION_DB_TYPE_TRANSFER_OUT || transaction.Type == models.TRANSACTION_DB_TYPE_TRANSFER_IN {
+		relatedTransaction := s.GetRelatedTransferTransaction(transaction, transaction.RelatedId)
+
+		if utils.GetUnixTimeFromTransactionTime(transaction.TransactionTime) != utils.GetUnixTimeFromTransactionTime(relatedTransaction.TransactionTime) {
+			return errs.ErrTooMuchTransactionInOneSecond
+		}
+
+		relatedUpdateCols := s.getRelatedUpdateColumns(updateCols)
+		updatedRows, err := sess.ID(relatedTransaction.TransactionId).Cols(relatedUpdateCols...).Where("uid=? AND deleted=?", relatedTransaction.Uid, false).Update(relatedTransaction)
+
+		if err != nil {
+			return err
+		} else if updatedRows < 1 {
+			return errs.ErrDatabaseOperationFailed
+		}
+	}
+
+	// Update transaction comment full-text index
+	if transaction.Comment != oldTransaction.Comment {
+		err = s.updateTransactionCommentFullTextIndex(sess, transaction)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	// Update transaction tag index
+	if len(removeTagIds) > 0 {
+		tagIndexUpdateModel := &models.TransactionTagIndex{
+			Deleted:         true,
+			DeletedUnixTime: now,
+		}
+
+		deletedRows, err := sess.Cols("deleted", "deleted_unix_time").Where("uid=? AND deleted=? AND transaction_id=?", transaction.Uid, false, transaction.TransactionId).In("tag_id", removeTagIds).Update(tagIndexUpdateModel)
+
+		if err != nil {
+			return err
+		} else if deletedRows < 1 {
+			return errs.ErrTransactionTagNotFound
+		}
+	}
+
+	if len(transactionTagIndexs) > 0 {
+		for i := 0; i < len(transactionTagIndexs); i++ {
+			transactionTagIndex := transactionTagIndexs[i]
+			_, err := sess.Insert(transactionTagIndex)
+
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	// Replace transaction splits, the superseded splits would never be restored so remove them directly
+	_, err = sess.Where("uid=? AND deleted=? AND transaction_id=?", transaction.Uid, false, transaction.TransactionId).Delete(&models.TransactionSplit{})
+
+	if err != nil {
+		return err
+	}
+
+	if len(splits) > 0 {
+		for i := 0; i < len(splits); i++ {
+			split := splits[i]
+			split.TransactionTime = transaction.TransactionTime
+			_, err := sess.Insert(split)
+
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	// Update account balance changes
+	err = s.addTransactionBalanceChanges(balanceChanges, oldTransaction, true)
+
+	if err != nil {
+		return err
+	}
+
+	err = s.addTransactionBalanceChanges(balanceChanges, transaction, false)
+
+	if err != nil {
+		return err
+	}
+
+	// Insert transaction history
+	afterSnapshot, err := s.getTransactionHistorySnapshot(sess, transaction.Uid, transaction.TransactionId)
+
+	if err != nil {
+		return err
+	}
+
+	return s.insertTransactionHistory(sess, transaction.Uid, transaction.TransactionId, models.TRANSACTION_HISTORY_OPERATION_MODIFY, operator, beforeSnapshot, afterSnapshot)
+}
+
+func (s *TransactionService) deleteTransaction(sess *xorm.Session, uid int64, transactionId int64, operator *models.TransactionOperator, balanceChanges accountBalanceChanges) error {
+	now := time.Now().Unix()
+
+	updateModel := &models.Transaction{
+		Deleted:         true,
+		DeletedUnixTime: now,
+	}
+
+	tagIndexUpdateModel := &models.TransactionTagIndex{
+		Deleted:         true,
+		DeletedUnixTime: now,
+	}
+
+	splitUpdateModel := &models.TransactionSplit{
+		Deleted:         true,
+		DeletedUnixTime: now,
+	}
+
+	attachmentUpdateModel := &models.TransactionAttachment{
+		Deleted:         true,
+		DeletedUnixTime: now,
+	}
+
+	// Get and verify current transaction
+	oldTransaction := &models.Transaction{}
+	has, err := sess.ID(transactionId).Where("uid=? AND deleted=?", uid, false).Get(oldTransaction)
+
+	if err != nil {
+		return err
+	} else if !has {
+		return errs.ErrTransactionNotFound
+	}
+
+	beforeSnapshot, err := s.getTransactionHistorySnapshot(sess, uid, oldTransaction.TransactionId)
+
+	if err != nil {
+		return err
+	}
+
+	// Get and verify source and destination account
+	sourceAccount, destinationAccount, err := s.getAccountModels(sess, oldTransaction)
+
+	if err != nil {
+		return err
+	}
+
+	if sourceAccount.Hidden || (destinationAccount != nil && destinationAccount.Hidden) {
+		return errs.ErrCannotDeleteTransactionInHiddenAccount
+	}
+
+	// Update transaction row to deleted
+	deletedRows, err := sess.ID(oldTransaction.TransactionId).Cols("deleted", "deleted_unix_time").Where("uid=? AND deleted=?", uid, false).Update(updateModel)
+
+	if err != nil {
+		return err
+	} else if deletedRows < 1 {
+		return errs.ErrTransactionNotFound
+	}
+
+	if oldTransaction.Type == models.TRANSACTION_DB_TYPE_TRANSFER_OUT || oldTransaction.Type == models.TRANSACTION_DB_TYPE_TRANSFER_IN {
+		deletedRows, err = sess.ID(oldTransaction.RelatedId).Cols("deleted", "deleted_unix_time").Where("uid=? AND deleted=?", uid, false).Update(updateModel)
+
+		if err != nil {
+			return err
+		} else if deletedRows < 1 {
+			return errs.ErrTransactionNotFound
+		}
+	}
+
+	// Update transaction tag index
+	_, err = sess.Cols("deleted", "deleted_unix_time").Where("uid=? AND deleted=? AND transaction_id=?", uid, false, oldTransaction.TransactionId).Update(tagIndexUpdateModel)
+
+	if err != nil {
+		return err
+	}
+
+	// Update transaction splits
+	_, err = sess.Cols("deleted", "deleted_unix_time").Where("uid=? AND deleted=? AND transaction_id=?", uid, false, oldTransaction.TransactionId).Update(splitUpdateModel)
+
+	if err != nil {
+		return err
+	}
+
+	// Update transaction attachments
+	attachmentTransactionIds := []int64{oldTransaction.TransactionId}
+
+	if oldTransaction.Type == models.TRANSACTION_DB_TYPE_TRANSFER_OUT || oldTransaction.Type == models.TRANSACTION_DB_TYPE_TRANSFER_IN {
+		attachmentTransactionIds = append(attachmentTransactionIds, oldTransaction.RelatedId)
+	}
+
+	_, err = sess.Cols("deleted", "deleted_unix_time").Where("uid=? AND deleted=?", uid, false).In("transaction_id", attachmentTransactionIds).Update(attachmentUpdateModel)
+
+	if err != nil {
+		return err
+	}
+
+	// Delete transaction comment full-text index
+	err = s.deleteTransactionCommentFullTextIndex(sess, oldTransaction)
+
+	if err != nil {
+		return err
+	}
+
+	// Update account balance changes
+	err = s.addTransactionBalanceChanges(balanceChanges, oldTransaction, true)
+
+	if err != nil {
+		return err
+	}
+
+	// Insert transaction history
+	return s.insertTransactionHistory(sess, uid, oldTransaction.TransactionId, models.TRANSACTION_HISTORY_OPERATION_DELETE, operator, beforeSnapshot, nil)
+}
+
+// createTransaction inserts the transaction with the given transaction time as is, callers must pass a transaction time whose sequence in second has been assigned,
+// e.g. the min transaction time of the second or the time returned by getNextAvailableTransactionTime
+func (s *TransactionService) createTransaction(sess *xorm.Session, transaction *models.Transaction, tagIds []int64, splits []*models.TransactionSplit, operator *models.TransactionOperator, balanceChanges accountBalanceChanges) error {
+	// Check whether account id is valid
+	err := s.isAccountIdValid(transaction)
+
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().Unix()
+
+	transaction.TransactionId = s.GenerateUuid(uuid.UUID_TYPE_TRANSACTION)
+	transaction.Pending = s.isPendingTransaction(transaction, now)
+
+	transaction.CreatedUnixTime = now
+	transaction.UpdatedUnixTime = now
+
+	tagIds = utils.ToUniqueInt64Slice(tagIds)
+	transactionTagIndexs := make([]*models.TransactionTagIndex, len(tagIds))
+
+	for i := 0; i < len(tagIds); i++ {
+		transactionTagIndexs[i] = &models.TransactionTagIndex{
+			TagIndexId:      s.GenerateUuid(uuid.UUID_TYPE_TAG_INDEX),
+			Uid:             transaction.Uid,
+			Deleted:         false,
+			TagId:           tagIds[i],
+			TransactionId:   transaction.TransactionId,
+			CreatedUnixTime: now,
+			UpdatedUnixTime: now,
+		}
+	}
+
+	s.prepareTransactionSplits(transaction, splits, now)
+
+	// Get and verify source and destination account
+	sourceAccount, destinationAccount, err := s.getAccountModels(sess, transaction)
+
+	if err != nil {
+		return err
 	}
 
-	return relatedTransaction
-}
+	if sourceAccount.Hidden || (destinationAccount != nil && destinationAccount.Hidden) {
+		return errs.ErrCannotAddTransactionToHiddenAccount
+	}
 
-// GetAccountsTotalIncomeAndExpense returns the every accounts total income and expense amount by specific date range
-func (s *TransactionService) GetAccountsTotalIncomeAndExpense(uid int64, startUnixTime int64, endUnixTime int64) (map[int64]int64, map[int64]int64, error) {
-	if uid <= 0 {
-		return nil, nil, errs.ErrUserIdInvalid
+	if (transaction.Type == models.TRANSACTION_DB_TYPE_TRANSFER_OUT || transaction.Type == models.TRANSACTION_DB_TYPE_TRANSFER_IN) &&
+		sourceAccount.Currency == destinationAccount.Currency && transaction.Amount != transaction.RelatedAccountAmount {
+		return errs.ErrTransactionSourceAndDestinationAmountNotEqual
 	}
 
-	startTransactionTime := utils.GetMinTransactionTimeFromUnixTime(startUnixTime)
-	endTransactionTime := utils.GetMaxTransactionTimeFromUnixTime(endUnixTime)
+	// Get and verify category
+	err = s.isCategoryValid(sess, transaction)
 
-	var transactionTotalAmounts []*models.Transaction
-	err := s.UserDataDB(uid).Select("type, account_id, SUM(amount) as amount").Where("uid=? AND deleted=? AND (type=? OR type=?) AND transaction_time>=? AND transaction_time<=?", uid, false, models.TRANSACTION_DB_TYPE_INCOME, models.TRANSACTION_DB_TYPE_EXPENSE, startTransactionTime, endTransactionTime).GroupBy("type, account_id").Find(&transactionTotalAmounts)
+	if err != nil {
+		return err
+	}
+
+	// Get and verify payee
+	err = s.isPayeeValid(sess, transaction)
 
 	if err != nil {
-		return nil, nil, err
+		return err
 	}
 
-	incomeAmounts := make(map[int64]int64)
-	expenseAmounts := make(map[int64]int64)
+	// Get and verify tags
+	err = s.isTagsValid(sess, transaction, transactionTagIndexs, tagIds)
 
-	for i := 0; i < len(transactionTotalAmounts); i++ {
-		transactionTotalAmount := transactionTotalAmounts[i]
+	if err != nil {
+		return err
+	}
 
-		if transactionTotalAmount.Type == models.TRANSACTION_DB_TYPE_INCOME {
-			incomeAmounts[transactionTotalAmount.AccountId] = transactionTotalAmount.Amount
-		} else if transactionTotalAmount.Type == models.TRANSACTION_DB_TYPE_EXPENSE {
-			expenseAmounts[transactionTotalAmount.AccountId] = transactionTotalAmount.Amount
-		}
+	// Verify splits
+	err = s.isSplitsValid(sess, transaction, splits)
+
+	if err != nil {
+		return err
 	}
 
-	return incomeAmounts, expenseAmounts, nil
-}
+	// Verify balance modification transaction and calculate real amount
+	if transaction.Type == models.TRANSACTION_DB_TYPE_MODIFY_BALANCE {
+		otherTransactionExists, err := sess.Cols("uid", "deleted", "account_id").Where("uid=? AND deleted=? AND account_id=?", transaction.Uid, false, sourceAccount.AccountId).Limit(1).Exist(&models.Transaction{})
 
-// GetAccountsMonthTotalIncomeAndExpense returns the every accounts total income and expense amount in month by specific date range
-func (s *TransactionService) GetAccountsMonthTotalIncomeAndExpense(uid int64, startUnixTime int64, endUnixTime int64, pageCount int) (map[string]models.TransactionAccountsAmount, error) {
-	if uid <= 0 {
-		return nil, errs.ErrUserIdInvalid
+		if err != nil {
+			return err
+		} else if otherTransactionExists {
+			return errs.ErrBalanceModificationTransactionCannotAddWhenNotEmpty
+		}
+
+		transaction.RelatedAccountId = transaction.AccountId
+		transaction.RelatedAccountAmount = transaction.Amount - (sourceAccount.Balance + balanceChanges[sourceAccount.AccountId])
 	}
 
-	startTransactionTime := utils.GetMinTransactionTimeFromUnixTime(startUnixTime)
-	endTransactionTime := utils.GetMaxTransactionTimeFromUnixTime(endUnixTime)
+	// Insert transaction row
+	var relatedTransaction *models.Transaction
 
-	minTransactionTime := startTransactionTime
-	maxTransactionTime := endTransactionTime
-	var allTransactions []*models.Transaction
+	if transaction.Type == models.TRANSACTION_DB_TYPE_TRANSFER_OUT || transaction.Type == models.TRANSACTION_DB_TYPE_TRANSFER_IN {
+		relatedTransaction = s.GetRelatedTransferTransaction(transaction, s.GenerateUuid(uuid.UUID_TYPE_TRANSACTION))
+		transaction.RelatedId = relatedTransaction.TransactionId
+	}
 
-	for maxTransactionTime > 0 {
-		var transactions []*models.Transaction
+	createdRows, err := sess.Insert(transaction)
 
-		err := s.UserDataDB(uid).Select("uid, type, account_id, transaction_time, timezone_utc_offset, amount").Where("uid=? AND deleted=? AND (type=? OR type=?) AND transaction_time>=? AND transaction_time<=?", uid, false, models.TRANSACTION_DB_TYPE_INCOME, models.TRANSACTION_DB_TYPE_EXPENSE, minTransactionTime, maxTransactionTime).Limit(pageCount, 0).OrderBy("transaction_time desc").Find(&transactions)
+	if err != nil || createdRows < 1 { // maybe another transaction has same time
+		nextTransactionTime, err := s.getNextAvailableTransactionTime(sess, transaction.Uid, transaction.TransactionTime)
 
 		if err != nil {
-			return nil, err
+			return err
+		} else if nextTransactionTime == transaction.TransactionTime {
+			return errs.ErrDatabaseOperationFailed
 		}
 
-		allTransactions = append(allTransactions, transactions...)
+		transaction.TransactionTime = nextTransactionTime
+		createdRows, err := sess.Insert(transaction)
 
-		if len(transactions) < pageCount {
-			maxTransactionTime = 0
-			break
+		if err != nil {
+			return err
+		} else if createdRows < 1 {
+			return errs.ErrDatabaseOperationFailed
 		}
-
-		maxTransactionTime = transactions[len(transactions)-1].TransactionTime - 1
 	}
 
-	totalAmounts := make(map[string]models.TransactionAccountsAmount)
+	if relatedTransaction != nil {
+		relatedTransaction.TransactionTime = transaction.TransactionTime + 1
 
-	for i := 0; i < len(allTransactions); i++ {
-		transaction := allTransactions[i]
-		transactionTimeZone := time.FixedZone("Transaction Timezone", int(transaction.TimezoneUtcOffset)*60)
-		yearMonth := utils.FormatUnixTimeToYearMonth(utils.GetUnixTimeFromTransactionTime(transaction.TransactionTime), transactionTimeZone)
+		if utils.GetUnixTimeFromTransactionTime(transaction.TransactionTime) != utils.GetUnixTimeFromTransactionTime(relatedTransaction.TransactionTime) {
+			return errs.ErrTooMuchTransactionInOneSecond
+		}
 
-		monthAccountsAmounts, exists := totalAmounts[yearMonth]
+		createdRows, err := sess.Insert(relatedTransaction)
 
-		if !exists {
-			monthAccountsAmounts = make(models.TransactionAccountsAmount)
-			totalAmounts[yearMonth] = monthAccountsAmounts
+		if err != nil {
+			return err
+		} else if createdRows < 1 {
+			return errs.ErrDatabaseOperationFailed
 		}
+	}
 
-		monthAccountAmount, exists := monthAccountsAmounts[transaction.AccountId]
+	// Update transaction comment full-text index
+	err = s.updateTransactionCommentFullTextIndex(sess, transaction)
 
-		if !exists {
-			monthAccountAmount = &models.TransactionAccountAmount{
-				AccountId:          transaction.AccountId,
-				TotalIncomeAmount:  0,
-				TotalExpenseAmount: 0,
+	if err != nil {
+		return err
+	}
+
+	// Insert transaction tag index
+	if len(transactionTagIndexs) > 0 {
+		for i := 0; i < len(transactionTagIndexs); i++ {
+			transactionTagIndex := transactionTagIndexs[i]
+			_, err := sess.Insert(transactionTagIndex)
+
+			if err != nil {
+				return err
 			}
-			monthAccountsAmounts[transaction.AccountId] = monthAccountAmount
 		}
+	}
 
-		if transaction.Type == models.TRANSACTION_DB_TYPE_INCOME {
-			monthAccountAmount.TotalIncomeAmount += transaction.Amount
-		} else if transaction.Type == models.TRANSACTION_DB_TYPE_EXPENSE {
-			monthAccountAmount.TotalExpenseAmount += transaction.Amount
+	// Insert transaction splits
+	if len(splits) > 0 {
+		for i := 0; i < len(splits); i++ {
+			split := splits[i]
+			split.TransactionTime = transaction.TransactionTime
+			_, err := sess.Insert(split)
+
+			if err != nil {
+				return err
+			}
 		}
 	}
 
-	return totalAmounts, nil
-}
+	// Update account balance changes, pending transaction would be posted to account balance when its time arrives
+	err = s.addTransactionBalanceChanges(balanceChanges, transaction, false)
 
-// GetAccountsAndCategoriesTotalIncomeAndExpense returns the every accounts and categories total income and expense amount by specific date range
-func (s *TransactionService) GetAccountsAndCategoriesTotalIncomeAndExpense(uid int64, startUnixTime int64, endUnixTime int64) ([]*models.Transaction, error) {
-	if uid <= 0 {
-		return nil, errs.ErrUserIdInvalid
+	if err != nil {
+		return err
 	}
 
-	condition := "uid=? AND deleted=? AND (type=? OR type=?)"
-	conditionParams := make([]interface{}, 0, 8)
-	conditionParams = append(conditionParams, uid)
-	conditionParams = append(conditionParams, false)
-	conditionParams = append(conditionParams, models.TRANSACTION_DB_TYPE_INCOME)
-	conditionParams = append(conditionParams, models.TRANSACTION_DB_TYPE_EXPENSE)
+	// Insert transaction history
+	afterSnapshot, err := s.getTransactionHistorySnapshot(sess, transaction.Uid, transaction.TransactionId)
 
-	if startUnixTime > 0 {
-		condition = condition + " AND transaction_time>=?"
-		conditionParams = append(conditionParams, utils.GetMinTransactionTimeFromUnixTime(startUnixTime))
+	if err != nil {
+		return err
 	}
 
-	if endUnixTime > 0 {
-		condition = condition + " AND transaction_time<=?"
-		conditionParams = append(conditionParams, utils.GetMaxTransactionTimeFromUnixTime(endUnixTime))
-	}
+	return s.insertTransactionHistory(sess, transaction.Uid, transaction.TransactionId, models.TRANSACTION_HISTORY_OPERATION_CREATE, operator, nil, afterSnapshot)
+}
 
-	var transactionTotalAmounts []*models.Transaction
-	err := s.UserDataDB(uid).Select("uid, category_id, account_id, SUM(amount) as amount").Where(condition, conditionParams...).GroupBy("category_id, account_id").Find(&transactionTotalAmounts)
+// getNextAvailableTransactionTime returns the given transaction time if it is not taken by any transaction (including deleted ones), or returns the time after the latest transaction in the same second
+func (s *TransactionService) getNextAvailableTransactionTime(sess *xorm.Session, uid int64, transactionTime int64) (int64, error) {
+	sameSecondLatestTransaction := &models.Transaction{}
+	minTransactionTime := utils.GetMinTransactionTimeFromUnixTime(utils.GetUnixTimeFromTransactionTime(transactionTime))
+	maxTransactionTime := utils.GetMaxTransactionTimeFromUnixTime(utils.GetUnixTimeFromTransactionTime(transactionTime))
+
+	has, err := sess.Cols("transaction_time").Where("uid=? AND transaction_time>=? AND transaction_time<=?", uid, minTransactionTime, maxTransactionTime).OrderBy("transaction_time desc").Limit(1).Get(sameSecondLatestTransaction)
 
 	if err != nil {
-		return nil, err
+		return 0, err
+	} else if !has {
+		return transactionTime, nil
+	} else if sameSecondLatestTransaction.TransactionTime >= maxTransactionTime-1 {
+		return 0, errs.ErrTooMuchTransactionInOneSecond
 	}
 
-	return transactionTotalAmounts, nil
+	return sameSecondLatestTransaction.TransactionTime + 1, nil
 }
 
-// GetTransactionMapByList returns a transaction map by a list
-func (s *TransactionService) GetTransactionMapByList(transactions []*models.Transaction) map[int64]*models.Transaction {
-	transactionMap := make(map[int64]*models.Transaction)
+func (s *TransactionService) addTransactionBalanceChanges(balanceChanges accountBalanceChanges, transaction *models.Transaction, revert bool) error {
+	if transaction.Type == models.TRANSACTION_DB_TYPE_TRANSFER_IN {
+		return errs.ErrTransactionTypeInvalid
+	}
 
-	for i := 0; i < len(transactions); i++ {
-		transaction := transactions[i]
-		transactionMap[transaction.TransactionId] = transaction
+	if transaction.Pending {
+		return nil
 	}
 
-	return transactionMap
-}
+	sign := int64(1)
 
-func (s *TransactionService) getTransactionQueryCondition(uid int64, maxTransactionTime int64, minTransactionTime int64, transactionType models.TransactionDbType, categoryIds []int64, accountId int64, keyword string, noDuplicated bool) (string, []interface{}) {
-	condition := "uid=? AND deleted=?"
-	conditionParams := make([]interface{}, 0, 16)
-	conditionParams = append(conditionParams, uid)
-	conditionParams = append(conditionParams, false)
+	if revert {
+		sign = -1
+	}
 
-	if maxTransactionTime > 0 {
-		condition = condition + " AND transaction_time<=?"
-		conditionParams = append(conditionParams, maxTransactionTime)
+	balanceChanges[transaction.AccountId] += sign * s.getAccountBalanceChangeOfTransaction(transaction)
+
+	if transaction.Type == models.TRANSACTION_DB_TYPE_TRANSFER_OUT {
+		balanceChanges[transaction.RelatedAccountId] += sign * transaction.RelatedAccountAmount
 	}
 
-	if minTransactionTime > 0 {
-		condition = condition + " AND transaction_time>=?"
-		conditionParams = append(conditionParams, minTransactionTime)
+	return nil
+}
+
+func (s *TransactionService) updateAccountBalances(sess *xorm.Session, uid int64, balanceChanges accountBalanceChanges) error {
+	accountIds := make([]int64, 0, len(balanceChanges))
+
+	for accountId, amount := range balanceChanges {
+		if amount != 0 {
+			accountIds = append(accountIds, accountId)
+		}
 	}
 
-	if models.TRANSACTION_DB_TYPE_MODIFY_BALANCE <= transactionType && transactionType <= models.TRANSACTION_DB_TYPE_EXPENSE {
-		condition = condition + " AND type=?"
-		conditionParams = append(conditionParams, transactionType)
-	} else if transactionType == models.TRANSACTION_DB_TYPE_TRANSFER_OUT || transactionType == models.TRANSACTION_DB_TYPE_TRANSFER_IN {
-		if accountId == 0 {
-			condition = condition + " AND type=?"
-			conditionParams = append(conditionParams, models.TRANSACTION_DB_TYPE_TRANSFER_OUT)
-		} else {
-			condition = condition + " AND (type=? OR type=?)"
-			conditionParams = append(conditionParams, models.TRANSACTION_DB_TYPE_TRANSFER_OUT)
-			conditionParams = append(conditionParams, models.TRANSACTION_DB_TYPE_TRANSFER_IN)
+	sort.Slice(accountIds, func(i, j int) bool {
+		return accountIds[i] < accountIds[j]
+	})
+
+	for i := 0; i < len(accountIds); i++ {
+		accountId := accountIds[i]
+		updateModel := &models.Account{
+			UpdatedUnixTime: time.Now().Unix(),
 		}
-	} else {
-		if noDuplicated && accountId == 0 {
-			condition = condition + " AND (type=? OR type=? OR type=? OR type=?)"
-			conditionParams = append(conditionParams, models.TRANSACTION_DB_TYPE_MODIFY_BALANCE)
-			conditionParams = append(conditionParams, models.TRANSACTION_DB_TYPE_INCOME)
-			conditionParams = append(conditionParams, models.TRANSACTION_DB_TYPE_EXPENSE)
-			conditionParams = append(conditionParams, models.TRANSACTION_DB_TYPE_TRANSFER_OUT)
+
+		updatedRows, err := sess.ID(accountId).SetExpr("balance", fmt.Sprintf("balance+(%d)", balanceChanges[accountId])).Cols("updated_unix_time").Where("uid=? AND deleted=?", uid, false).Update(updateModel)
+
+		if err != nil {
+			return err
+		} else if updatedRows < 1 {
+			return errs.ErrDatabaseOperationFailed
 		}
 	}
 
-	if len(categoryIds) > 0 {
-		var conditions strings.Builder
+	return nil
+}
 
-		for i := 0; i < len(categoryIds); i++ {
-			if i > 0 {
-				conditions.WriteString(",")
-			}
+func (s *TransactionService) isNewTransactionValid(sess *xorm.Session, transaction *models.Transaction, tagIds []int64) error {
+	err := s.isAccountIdValid(transaction)
 
-			conditions.WriteString("?")
-			conditionParams = append(conditionParams, categoryIds[i])
-		}
+	if err != nil {
+		return err
+	}
 
-		condition = condition + " AND category_id IN (" + conditions.String() + ")"
+	sourceAccount, destinationAccount, err := s.getAccountModels(sess, transaction)
+
+	if err != nil {
+		return err
 	}
 
-	if accountId > 0 {
-		condition = condition + " AND account_id=?"
-		conditionParams = append(conditionParams, accountId)
+	if sourceAccount.Hidden || (destinationAccount != nil && destinationAccount.Hidden) {
+		return errs.ErrCannotAddTransactionToHiddenAccount
 	}
 
-	if keyword != "" {
-		condition = condition + " AND comment LIKE ?"
-		conditionParams = append(conditionParams, "%%"+keyword+"%%")
+	if transaction.Type == models.TRANSACTION_DB_TYPE_TRANSFER_OUT &&
+		sourceAccount.Currency == destinationAccount.Currency && transaction.Amount != transaction.RelatedAccountAmount {
+		return errs.ErrTransactionSourceAndDestinationAmountNotEqual
 	}
 
-	return condition, conditionParams
+	err = s.isCategoryValid(sess, transaction)
+
+	if err != nil {
+		return err
+	}
+
+	err = s.isPayeeValid(sess, transaction)
+
+	if err != nil {
+		return err
+	}
+
+	transactionTagIndexs := make([]*models.TransactionTagIndex, len(tagIds))
+
+	for i := 0; i < len(tagIds); i++ {
+		transactionTagIndexs[i] = &models.TransactionTagIndex{
+			Uid:   transaction.Uid,
+			TagId: tagIds[i],
+		}
+	}
+
+	return s.isTagsValid(sess, transaction, transactionTagIndexs, tagIds)
 }
 
 func (s *TransactionService) isAccountIdValid(transaction *models.Transaction) error {
@@ -1296,6 +3571,85 @@ func (s *TransactionService) isCategoryValid(sess *xorm.Session, transaction *mo
 	return nil
 }
 
+func (s *TransactionService) isPayeeValid(sess *xorm.Session, transaction *models.Transaction) error {
+	if transaction.PayeeId == 0 {
+		return nil
+	}
+
+	if transaction.Type != models.TRANSACTION_DB_TYPE_INCOME && transaction.Type != models.TRANSACTION_DB_TYPE_EXPENSE {
+		return errs.ErrTransactionCannotHavePayee
+	}
+
+	exists, err := sess.Cols("uid", "deleted", "payee_id").Where("uid=? AND deleted=? AND payee_id=?", transaction.Uid, false, transaction.PayeeId).Exist(&models.TransactionPayee{})
+
+	if err != nil {
+		return err
+	} else if !exists {
+		return errs.ErrTransactionPayeeNotFound
+	}
+
+	return nil
+}
+
+func (s *TransactionService) prepareTransactionSplits(transaction *models.Transaction, splits []*models.TransactionSplit, now int64) {
+	if len(splits) < 1 {
+		return
+	}
+
+	// The first split is regarded as the primary category of split transaction
+	transaction.CategoryId = splits[0].CategoryId
+
+	for i := 0; i < len(splits); i++ {
+		split := splits[i]
+		split.SplitId = s.GenerateUuid(uuid.UUID_TYPE_SPLIT)
+		split.Uid = transaction.Uid
+		split.Deleted = false
+		split.TransactionId = transaction.TransactionId
+		split.TransactionTime = transaction.TransactionTime
+		split.DisplayOrder = i
+		split.CreatedUnixTime = now
+		split.UpdatedUnixTime = now
+	}
+}
+
+func (s *TransactionService) isSplitsValid(sess *xorm.Session, transaction *models.Transaction, splits []*models.TransactionSplit) error {
+	if len(splits) < 1 {
+		return nil
+	}
+
+	if transaction.Type != models.TRANSACTION_DB_TYPE_INCOME && transaction.Type != models.TRANSACTION_DB_TYPE_EXPENSE {
+		return errs.ErrTransactionCannotBeSplit
+	}
+
+	if len(splits) < 2 {
+		return errs.ErrTransactionSplitsTooFew
+	}
+
+	var totalAmount int64 = 0
+
+	for i := 0; i < len(splits); i++ {
+		totalAmount += splits[i].Amount
+	}
+
+	if totalAmount != transaction.Amount {
+		return errs.ErrTransactionSplitsAmountNotEqual
+	}
+
+	for i := 0; i < len(splits); i++ {
+		err := s.isCategoryValid(sess, &models.Transaction{
+			Uid:        transaction.Uid,
+			Type:       transaction.Type,
+			CategoryId: splits[i].CategoryId,
+		})
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (s *TransactionService) isTagsValid(sess *xorm.Session, transaction *models.Transaction, transactionTagIndexs []*models.TransactionTagIndex, tagIds []int64) error {
 	if len(transactionTagIndexs) > 0 {
 		var tags []*models.TransactionTag
@@ -0,0 +1,366 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mayswind/ezbookkeeping/pkg/datastore"
+	"github.com/mayswind/ezbookkeeping/pkg/errs"
+	"github.com/mayswind/ezbookkeeping/pkg/models"
+	"github.com/mayswind/ezbookkeeping/pkg/utils"
+)
+
+func TestTransactionServiceCreateTransaction_Splits(t *testing.T) {
+	initializeTestDataStore(t)
+
+	account := createTestAccount(t, "cash", 0)
+	food := createTestCategory(t, models.CATEGORY_TYPE_EXPENSE, "food")
+	grocery := createTestCategory(t, models.CATEGORY_TYPE_EXPENSE, "grocery")
+	transfer := createTestCategory(t, models.CATEGORY_TYPE_TRANSFER, "transfer")
+	transactionTime := utils.GetMinTransactionTimeFromUnixTime(time.Now().Unix() - 3600)
+
+	testCases := []struct {
+		name          string
+		categoryId    int64
+		amount        int64
+		splits        []*models.TransactionSplit
+		expectedError error
+	}{
+		{
+			name:       "too few splits",
+			categoryId: food.CategoryId,
+			amount:     1000,
+			splits: []*models.TransactionSplit{
+				{CategoryId: food.CategoryId, Amount: 1000},
+			},
+			expectedError: errs.ErrTransactionSplitsTooFew,
+		},
+		{
+			name:       "amount not equal",
+			categoryId: food.CategoryId,
+			amount:     1000,
+			splits: []*models.TransactionSplit{
+				{CategoryId: food.CategoryId, Amount: 600},
+				{CategoryId: grocery.CategoryId, Amount: 300},
+			},
+			expectedError: errs.ErrTransactionSplitsAmountNotEqual,
+		},
+		{
+			name:       "category type invalid",
+			categoryId: food.CategoryId,
+			amount:     1000,
+			splits: []*models.TransactionSplit{
+				{CategoryId: food.CategoryId, Amount: 600},
+				{CategoryId: transfer.CategoryId, Amount: 400},
+			},
+			expectedError: errs.ErrTransactionCategoryTypeInvalid,
+		},
+	}
+
+	for _, testCase := range testCases {
+		transaction := &models.Transaction{
+			Uid:             testUid,
+			Type:            models.TRANSACTION_DB_TYPE_EXPENSE,
+			CategoryId:      testCase.categoryId,
+			TransactionTime: transactionTime,
+			AccountId:       account.AccountId,
+			Amount:          testCase.amount,
+		}
+
+		err := Transactions.CreateTransaction(transaction, nil, testCase.splits, nil)
+		assert.Equal(t, testCase.expectedError, err, testCase.name)
+	}
+
+	assert.Equal(t, int64(0), getTestAccountBalance(t, account.AccountId))
+
+	// The first split is the primary category of transaction
+	transaction := &models.Transaction{
+		Uid:             testUid,
+		Type:            models.TRANSACTION_DB_TYPE_EXPENSE,
+		CategoryId:      food.CategoryId,
+		TransactionTime: transactionTime,
+		AccountId:       account.AccountId,
+		Amount:          1000,
+	}
+	splits := []*models.TransactionSplit{
+		{CategoryId: grocery.CategoryId, Amount: 600},
+		{CategoryId: food.CategoryId, Amount: 400},
+	}
+
+	err := Transactions.CreateTransaction(transaction, nil, splits, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, grocery.CategoryId, transaction.CategoryId)
+	assert.Equal(t, int64(-1000), getTestAccountBalance(t, account.AccountId))
+
+	actualSplits, err := TransactionSplits.GetAllSplitsOfTransactions(testUid, []int64{transaction.TransactionId})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(actualSplits[transaction.TransactionId]))
+
+	// Transfer transaction cannot be split
+	transferTransaction := &models.Transaction{
+		Uid:                  testUid,
+		Type:                 models.TRANSACTION_DB_TYPE_TRANSFER_OUT,
+		CategoryId:           transfer.CategoryId,
+		TransactionTime:      transactionTime + 1,
+		AccountId:            account.AccountId,
+		Amount:               1000,
+		RelatedAccountId:     createTestAccount(t, "bank", 0).AccountId,
+		RelatedAccountAmount: 1000,
+	}
+
+	transferSplits := []*models.TransactionSplit{
+		{CategoryId: transfer.CategoryId, Amount: 600},
+		{CategoryId: transfer.CategoryId, Amount: 400},
+	}
+
+	err = Transactions.CreateTransaction(transferTransaction, nil, transferSplits, nil)
+	assert.Equal(t, errs.ErrTransactionCannotBeSplit, err)
+
+	splitCount, err := datastore.Container.UserDataStore.Choose(testUid).Where("uid=? AND deleted=?", testUid, false).Count(&models.TransactionSplit{})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), splitCount)
+}
+
+func TestTransactionServicePostPendingTransaction(t *testing.T) {
+	initializeTestDataStore(t)
+
+	cash := createTestAccount(t, "cash", 10000)
+	bank := createTestAccount(t, "bank", 0)
+	transfer := createTestCategory(t, models.CATEGORY_TYPE_TRANSFER, "transfer")
+
+	transaction := &models.Transaction{
+		Uid:                  testUid,
+		Type:                 models.TRANSACTION_DB_TYPE_TRANSFER_OUT,
+		CategoryId:           transfer.CategoryId,
+		TransactionTime:      utils.GetMinTransactionTimeFromUnixTime(time.Now().Unix() + 3600),
+		AccountId:            cash.AccountId,
+		Amount:               1000,
+		RelatedAccountId:     bank.AccountId,
+		RelatedAccountAmount: 1000,
+	}
+
+	err := Transactions.CreateTransaction(transaction, nil, nil, nil)
+	assert.Nil(t, err)
+	assert.True(t, transaction.Pending)
+
+	// Future-dated transaction does not affect account balance until its time arrives
+	assert.Equal(t, int64(10000), getTestAccountBalance(t, cash.AccountId))
+	assert.Equal(t, int64(0), getTestAccountBalance(t, bank.AccountId))
+
+	pendingAmounts, err := Transactions.GetAccountsPendingAmounts(testUid)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(-1000), pendingAmounts[cash.AccountId])
+	assert.Equal(t, int64(1000), pendingAmounts[bank.AccountId])
+
+	dueTransactions, err := Transactions.GetAllDuePendingTransactions(10)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(dueTransactions))
+
+	// Posting a transaction whose time has not arrived does nothing
+	err = Transactions.PostPendingTransaction(testUid, transaction.TransactionId)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(10000), getTestAccountBalance(t, cash.AccountId))
+
+	// Move the transaction to the past, so that it becomes due
+	pastTransactionTime := utils.GetMinTransactionTimeFromUnixTime(time.Now().Unix() - 60)
+	_, err = datastore.Container.UserDataStore.Choose(testUid).ID(transaction.TransactionId).Cols("transaction_time").Update(&models.Transaction{TransactionTime: pastTransactionTime})
+	assert.Nil(t, err)
+	_, err = datastore.Container.UserDataStore.Choose(testUid).ID(transaction.RelatedId).Cols("transaction_time").Update(&models.Transaction{TransactionTime: pastTransactionTime + 1})
+	assert.Nil(t, err)
+
+	dueTransactions, err = Transactions.GetAllDuePendingTransactions(10)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(dueTransactions))
+	assert.Equal(t, transaction.TransactionId, dueTransactions[0].TransactionId)
+
+	err = Transactions.PostPendingTransaction(testUid, transaction.TransactionId)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(9000), getTestAccountBalance(t, cash.AccountId))
+	assert.Equal(t, int64(1000), getTestAccountBalance(t, bank.AccountId))
+
+	pendingAmounts, err = Transactions.GetAccountsPendingAmounts(testUid)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(pendingAmounts))
+
+	// Posting the same transaction again does not change account balance twice
+	err = Transactions.PostPendingTransaction(testUid, transaction.TransactionId)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(9000), getTestAccountBalance(t, cash.AccountId))
+
+	err = Transactions.PostPendingTransaction(testUid, transaction.RelatedId)
+	assert.Equal(t, errs.ErrTransactionTypeInvalid, err)
+}
+
+func TestTransactionServiceBatchCreateTransactions_TransactionTimes(t *testing.T) {
+	initializeTestDataStore(t)
+
+	cash := createTestAccount(t, "cash", 0)
+	bank := createTestAccount(t, "bank", 0)
+	food := createTestCategory(t, models.CATEGORY_TYPE_EXPENSE, "food")
+	transfer := createTestCategory(t, models.CATEGORY_TYPE_TRANSFER, "transfer")
+	unixTime := time.Now().Unix() - 3600
+	transactionTime := utils.GetMinTransactionTimeFromUnixTime(unixTime)
+
+	newExpense := func(unixTime int64) *models.Transaction {
+		return &models.Transaction{
+			Uid:             testUid,
+			Type:            models.TRANSACTION_DB_TYPE_EXPENSE,
+			CategoryId:      food.CategoryId,
+			TransactionTime: utils.GetMinTransactionTimeFromUnixTime(unixTime),
+			AccountId:       cash.AccountId,
+			Amount:          100,
+		}
+	}
+
+	// The existed transactions take the first two transaction times of the second, and the latest one is deleted
+	existedTransaction := newExpense(unixTime)
+	err := Transactions.CreateTransaction(existedTransaction, nil, nil, nil)
+	assert.Nil(t, err)
+
+	deletedTransaction := newExpense(unixTime)
+	err = Transactions.CreateTransaction(deletedTransaction, nil, nil, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, transactionTime+1, deletedTransaction.TransactionTime)
+
+	err = Transactions.DeleteTransaction(testUid, deletedTransaction.TransactionId, nil)
+	assert.Nil(t, err)
+
+	items := []*models.TransactionBatchItem{
+		{Transaction: newExpense(unixTime)},
+		{Transaction: &models.Transaction{
+			Uid:                  testUid,
+			Type:                 models.TRANSACTION_DB_TYPE_TRANSFER_OUT,
+			CategoryId:           transfer.CategoryId,
+			TransactionTime:      transactionTime,
+			AccountId:            cash.AccountId,
+			Amount:               1000,
+			RelatedAccountId:     bank.AccountId,
+			RelatedAccountAmount: 1000,
+		}},
+		{Transaction: newExpense(unixTime)},
+		{Transaction: newExpense(unixTime + 1)},
+	}
+
+	failedIndex, err := Transactions.BatchCreateTransactions(testUid, items, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, -1, failedIndex)
+
+	// The transfer takes two transaction times for both sides
+	assert.Equal(t, transactionTime+2, items[0].Transaction.TransactionTime)
+	assert.Equal(t, transactionTime+3, items[1].Transaction.TransactionTime)
+	assert.Equal(t, transactionTime+5, items[2].Transaction.TransactionTime)
+	assert.Equal(t, utils.GetMinTransactionTimeFromUnixTime(unixTime+1), items[3].Transaction.TransactionTime)
+
+	relatedTransaction := &models.Transaction{}
+	has, err := datastore.Container.UserDataStore.Choose(testUid).ID(items[1].Transaction.RelatedId).Get(relatedTransaction)
+	assert.Nil(t, err)
+	assert.True(t, has)
+	assert.Equal(t, transactionTime+4, relatedTransaction.TransactionTime)
+
+	assert.Equal(t, int64(-1400), getTestAccountBalance(t, cash.AccountId))
+	assert.Equal(t, int64(1000), getTestAccountBalance(t, bank.AccountId))
+}
+
+func TestTransactionServiceMergeAccounts(t *testing.T) {
+	initializeTestDataStore(t)
+
+	fromAccount := createTestAccount(t, "from", 0)
+	toAccount := createTestAccount(t, "to", 0)
+	otherAccount := createTestAccount(t, "other", 0)
+	food := createTestCategory(t, models.CATEGORY_TYPE_EXPENSE, "food")
+	transfer := createTestCategory(t, models.CATEGORY_TYPE_TRANSFER, "transfer")
+	unixTime := time.Now().Unix() - 3600
+
+	newTransaction := func(transactionType models.TransactionDbType, accountId int64, relatedAccountId int64, amount int64) *models.Transaction {
+		transaction := &models.Transaction{
+			Uid:             testUid,
+			Type:            transactionType,
+			CategoryId:      food.CategoryId,
+			TransactionTime: utils.GetMinTransactionTimeFromUnixTime(unixTime),
+			AccountId:       accountId,
+			Amount:          amount,
+		}
+
+		if transactionType == models.TRANSACTION_DB_TYPE_TRANSFER_OUT {
+			transaction.CategoryId = transfer.CategoryId
+			transaction.RelatedAccountId = relatedAccountId
+			transaction.RelatedAccountAmount = amount
+		}
+
+		err := Transactions.CreateTransaction(transaction, nil, nil, nil)
+		assert.Nil(t, err)
+		unixTime++
+
+		return transaction
+	}
+
+	expense := newTransaction(models.TRANSACTION_DB_TYPE_EXPENSE, fromAccount.AccountId, 0, 100)
+	transferToOther := newTransaction(models.TRANSACTION_DB_TYPE_TRANSFER_OUT, fromAccount.AccountId, otherAccount.AccountId, 500)
+	transferFromOther := newTransaction(models.TRANSACTION_DB_TYPE_TRANSFER_OUT, otherAccount.AccountId, fromAccount.AccountId, 200)
+	transferToTarget := newTransaction(models.TRANSACTION_DB_TYPE_TRANSFER_OUT, fromAccount.AccountId, toAccount.AccountId, 300)
+	deletedExpense := newTransaction(models.TRANSACTION_DB_TYPE_EXPENSE, fromAccount.AccountId, 0, 50)
+
+	err := Transactions.DeleteTransaction(testUid, deletedExpense.TransactionId, nil)
+	assert.Nil(t, err)
+
+	assert.Equal(t, int64(-700), getTestAccountBalance(t, fromAccount.AccountId))
+	assert.Equal(t, int64(300), getTestAccountBalance(t, toAccount.AccountId))
+	assert.Equal(t, int64(300), getTestAccountBalance(t, otherAccount.AccountId))
+
+	err = Transactions.MergeAccounts(testUid, fromAccount.AccountId, fromAccount.AccountId, nil)
+	assert.Equal(t, errs.ErrCannotMergeAccountIntoItself, err)
+
+	err = Transactions.MergeAccounts(testUid, fromAccount.AccountId, toAccount.AccountId, nil)
+	assert.Nil(t, err)
+
+	// The transfer between the two accounts is deleted, and the remaining balance of source account is moved to target account
+	assert.Equal(t, int64(-400), getTestAccountBalance(t, toAccount.AccountId))
+	assert.Equal(t, int64(300), getTestAccountBalance(t, otherAccount.AccountId))
+
+	deletedAccount := &models.Account{}
+	has, err := datastore.Container.UserDataStore.Choose(testUid).ID(fromAccount.AccountId).Get(deletedAccount)
+	assert.Nil(t, err)
+	assert.True(t, has)
+	assert.True(t, deletedAccount.Deleted)
+	assert.Equal(t, int64(0), deletedAccount.Balance)
+
+	deletedTransfer := &models.Transaction{}
+	has, err = datastore.Container.UserDataStore.Choose(testUid).ID(transferToTarget.TransactionId).Get(deletedTransfer)
+	assert.Nil(t, err)
+	assert.True(t, has)
+	assert.True(t, deletedTransfer.Deleted)
+
+	// All the other transactions (including the deleted ones and both sides of transfers) are rewired to target account
+	sourceAccountTransactionCount, err := datastore.Container.UserDataStore.Choose(testUid).Where("uid=? AND (account_id=? OR related_account_id=?)", testUid, fromAccount.AccountId, fromAccount.AccountId).Count(&models.Transaction{})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), sourceAccountTransactionCount)
+
+	testCases := []struct {
+		name                     string
+		transactionId            int64
+		expectedAccountId        int64
+		expectedRelatedAccountId int64
+	}{
+		{"expense", expense.TransactionId, toAccount.AccountId, 0},
+		{"deleted expense", deletedExpense.TransactionId, toAccount.AccountId, 0},
+		{"transfer out", transferToOther.TransactionId, toAccount.AccountId, otherAccount.AccountId},
+		{"transfer in of transfer out", transferToOther.RelatedId, otherAccount.AccountId, toAccount.AccountId},
+		{"transfer in", transferFromOther.RelatedId, toAccount.AccountId, otherAccount.AccountId},
+	}
+
+	for _, testCase := range testCases {
+		transaction := &models.Transaction{}
+		has, err = datastore.Container.UserDataStore.Choose(testUid).ID(testCase.transactionId).Get(transaction)
+		assert.Nil(t, err, testCase.name)
+		assert.True(t, has, testCase.name)
+		assert.Equal(t, testCase.expectedAccountId, transaction.AccountId, testCase.name)
+		assert.Equal(t, testCase.expectedRelatedAccountId, transaction.RelatedAccountId, testCase.name)
+	}
+
+	// The rewired transactions have modification histories
+	historyCount, err := datastore.Container.UserDataStore.Choose(testUid).Where("uid=? AND transaction_id=? AND operation=?", testUid, expense.TransactionId, models.TRANSACTION_HISTORY_OPERATION_MODIFY).Count(&models.TransactionHistory{})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), historyCount)
+}
@@ -62,6 +62,11 @@ const (
 	InternalUuidGeneratorType string = "internal"
 )
 
+// Object storage types
+const (
+	LocalFileSystemObjectStorageType string = "local_filesystem"
+)
+
 // Exchange rates data source types
 const (
 	EuroCentralBankDataSource        string = "euro_central_bank"
@@ -91,6 +96,11 @@ const (
 	defaultTokenExpiredTime          int    = 604800 // 7 days
 	defaultTemporaryTokenExpiredTime int    = 300    // 5 minutes
 
+	defaultTransactionTrashRetentionDays int = 30
+
+	defaultLocalFileSystemPath   string = "storage"
+	defaultMaxAttachmentFileSize int    = 10485760 // 10MB
+
 	defaultExchangeRatesDataRequestTimeout int = 10000 // 10 seconds
 )
 
@@ -166,7 +176,13 @@ type Config struct {
 	EnableUserRegister bool
 
 	// Data
-	EnableDataExport bool
+	EnableDataExport              bool
+	TransactionTrashRetentionDays int
+
+	// Storage
+	StorageType           string
+	LocalFileSystemPath   string
+	MaxAttachmentFileSize int
 
 	// Exchange Rates
 	ExchangeRatesDataSource     string
@@ -238,6 +254,12 @@ func LoadConfiguration(configFilePath string) (*Config, error) {
 		return nil, err
 	}
 
+	err = loadStorageConfiguration(config, cfgFile, "storage")
+
+	if err != nil {
+		return nil, err
+	}
+
 	err = loadExchangeRatesConfiguration(config, cfgFile, "exchange_rates")
 
 	if err != nil {
@@ -411,6 +433,25 @@ func loadUserConfiguration(config *Config, configFile *ini.File, sectionName str
 
 func loadDataConfiguration(config *Config, configFile *ini.File, sectionName string) error {
 	config.EnableDataExport = getConfigItemBoolValue(configFile, sectionName, "enable_export", false)
+	config.TransactionTrashRetentionDays = getConfigItemIntValue(configFile, sectionName, "transaction_trash_retention_days", defaultTransactionTrashRetentionDays)
+
+	return nil
+}
+
+func loadStorageConfiguration(config *Config, configFile *ini.File, sectionName string) error {
+	if getConfigItemStringValue(configFile, sectionName, "type", LocalFileSystemObjectStorageType) == LocalFileSystemObjectStorageType {
+		config.StorageType = LocalFileSystemObjectStorageType
+	} else {
+		return errs.ErrInvalidStorageType
+	}
+
+	if config.StorageType == LocalFileSystemObjectStorageType {
+		localFileSystemPath := getConfigItemStringValue(configFile, sectionName, "local_filesystem_path", defaultLocalFileSystemPath)
+		finalLocalFileSystemPath, _ := getFinalPath(config.WorkingPath, localFileSystemPath)
+		config.LocalFileSystemPath = finalLocalFileSystemPath
+	}
+
+	config.MaxAttachmentFileSize = getConfigItemIntValue(configFile, sectionName, "max_attachment_size", defaultMaxAttachmentFileSize)
 
 	return nil
 }
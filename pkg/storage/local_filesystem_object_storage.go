@@ -0,0 +1,117 @@
+package storage
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mayswind/ezbookkeeping/pkg/errs"
+	"github.com/mayswind/ezbookkeeping/pkg/settings"
+)
+
+// LocalFileSystemObjectStorage represents local file system object storage
+type LocalFileSystemObjectStorage struct {
+	rootPath string
+}
+
+// NewLocalFileSystemObjectStorage returns a local file system object storage
+func NewLocalFileSystemObjectStorage(config *settings.Config) (*LocalFileSystemObjectStorage, error) {
+	rootPath, err := filepath.Abs(config.LocalFileSystemPath)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = os.MkdirAll(rootPath, 0700)
+
+	if err != nil {
+		return nil, err
+	}
+
+	storage := &LocalFileSystemObjectStorage{
+		rootPath: rootPath,
+	}
+
+	return storage, nil
+}
+
+// Save stores the content read from given reader to the specified path
+func (s *LocalFileSystemObjectStorage) Save(path string, reader io.Reader) error {
+	finalPath, err := s.getFinalPath(path)
+
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(finalPath), 0700)
+
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(finalPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(file, reader)
+
+	if err != nil {
+		file.Close()
+		os.Remove(finalPath)
+		return err
+	}
+
+	return file.Close()
+}
+
+// Read returns a reader of the object in the specified path
+func (s *LocalFileSystemObjectStorage) Read(path string) (io.ReadCloser, error) {
+	finalPath, err := s.getFinalPath(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return os.Open(finalPath)
+}
+
+// Delete removes the object in the specified path
+func (s *LocalFileSystemObjectStorage) Delete(path string) error {
+	finalPath, err := s.getFinalPath(path)
+
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(finalPath)
+
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteAll removes all the objects under the specified path prefix
+func (s *LocalFileSystemObjectStorage) DeleteAll(prefix string) error {
+	finalPath, err := s.getFinalPath(prefix)
+
+	if err != nil {
+		return err
+	}
+
+	return os.RemoveAll(finalPath)
+}
+
+func (s *LocalFileSystemObjectStorage) getFinalPath(path string) (string, error) {
+	finalPath := filepath.Join(s.rootPath, filepath.FromSlash(path))
+
+	if finalPath == s.rootPath || !strings.HasPrefix(finalPath, s.rootPath+string(filepath.Separator)) {
+		return "", errs.ErrObjectStoragePathInvalid
+	}
+
+	return finalPath, nil
+}
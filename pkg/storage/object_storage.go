@@ -0,0 +1,18 @@
+package storage
+
+import "io"
+
+// ObjectStorage is common object storage interface
+type ObjectStorage interface {
+	// Save stores the content read from given reader to the specified path
+	Save(path string, reader io.Reader) error
+
+	// Read returns a reader of the object in the specified path
+	Read(path string) (io.ReadCloser, error)
+
+	// Delete removes the object in the specified path
+	Delete(path string) error
+
+	// DeleteAll removes all the objects under the specified path prefix
+	DeleteAll(prefix string) error
+}
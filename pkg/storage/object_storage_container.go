@@ -0,0 +1,50 @@
+package storage
+
+import (
+	"io"
+
+	"github.com/mayswind/ezbookkeeping/pkg/errs"
+	"github.com/mayswind/ezbookkeeping/pkg/settings"
+)
+
+// ObjectStorageContainer contains the current object storage
+type ObjectStorageContainer struct {
+	Current ObjectStorage
+}
+
+// Initialize a object storage container singleton instance
+var (
+	Container = &ObjectStorageContainer{}
+)
+
+// InitializeObjectStorage initializes the current object storage according to the config
+func InitializeObjectStorage(config *settings.Config) error {
+	if config.StorageType == settings.LocalFileSystemObjectStorageType {
+		objectStorage, err := NewLocalFileSystemObjectStorage(config)
+		Container.Current = objectStorage
+
+		return err
+	}
+
+	return errs.ErrInvalidStorageType
+}
+
+// Save stores the content read from given reader to the specified path by the current object storage
+func (s *ObjectStorageContainer) Save(path string, reader io.Reader) error {
+	return s.Current.Save(path, reader)
+}
+
+// Read returns a reader of the object in the specified path by the current object storage
+func (s *ObjectStorageContainer) Read(path string) (io.ReadCloser, error) {
+	return s.Current.Read(path)
+}
+
+// Delete removes the object in the specified path by the current object storage
+func (s *ObjectStorageContainer) Delete(path string) error {
+	return s.Current.Delete(path)
+}
+
+// DeleteAll removes all the objects under the specified path prefix by the current object storage
+func (s *ObjectStorageContainer) DeleteAll(prefix string) error {
+	return s.Current.DeleteAll(prefix)
+}
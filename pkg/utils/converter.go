@@ -1,6 +1,11 @@
 package utils
 
-import "strconv"
+import (
+	"strconv"
+	"strings"
+
+	"github.com/mayswind/ezbookkeeping/pkg/errs"
+)
 
 // Int32ToString returns the textual representation of this number
 func Int32ToString(num int) string {
@@ -83,3 +88,57 @@ func Float64ToString(num float64) string {
 func StringToFloat64(str string) (float64, error) {
 	return strconv.ParseFloat(str, 64)
 }
+
+// StringToAmount parses a textual representation of the decimal amount (e.g. "-123.45") to int64 amount in hundredths
+func StringToAmount(str string) (int64, error) {
+	if str == "" {
+		return 0, errs.ErrFormatInvalid
+	}
+
+	negative := false
+
+	if str[0] == '-' || str[0] == '+' {
+		negative = str[0] == '-'
+		str = str[1:]
+	}
+
+	integer := str
+	decimals := ""
+
+	if index := strings.Index(str, "."); index >= 0 {
+		integer = str[:index]
+		decimals = str[index+1:]
+	}
+
+	if integer == "" || len(decimals) > 2 || strings.Contains(decimals, ".") {
+		return 0, errs.ErrFormatInvalid
+	}
+
+	for len(decimals) < 2 {
+		decimals = decimals + "0"
+	}
+
+	for i := 0; i < len(integer); i++ {
+		if integer[i] < '0' || integer[i] > '9' {
+			return 0, errs.ErrFormatInvalid
+		}
+	}
+
+	for i := 0; i < len(decimals); i++ {
+		if decimals[i] < '0' || decimals[i] > '9' {
+			return 0, errs.ErrFormatInvalid
+		}
+	}
+
+	amount, err := StringToInt64(integer + decimals)
+
+	if err != nil {
+		return 0, err
+	}
+
+	if negative {
+		amount = -amount
+	}
+
+	return amount, nil
+}
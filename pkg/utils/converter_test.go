@@ -99,3 +99,45 @@ func TestStringToFloat64(t *testing.T) {
 	assert.Equal(t, nil, err)
 	assert.Equal(t, expectedValue, actualValue)
 }
+
+func TestStringToAmount(t *testing.T) {
+	expectedValue := int64(12345)
+	actualValue, err := StringToAmount("123.45")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, expectedValue, actualValue)
+
+	expectedValue = int64(-12340)
+	actualValue, err = StringToAmount("-123.4")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, expectedValue, actualValue)
+
+	expectedValue = int64(10000)
+	actualValue, err = StringToAmount("100")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, expectedValue, actualValue)
+
+	expectedValue = int64(5)
+	actualValue, err = StringToAmount("0.05")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, expectedValue, actualValue)
+}
+
+func TestStringToAmount_InvalidAmount(t *testing.T) {
+	_, err := StringToAmount("")
+	assert.NotEqual(t, nil, err)
+
+	_, err = StringToAmount("-")
+	assert.NotEqual(t, nil, err)
+
+	_, err = StringToAmount(".5")
+	assert.NotEqual(t, nil, err)
+
+	_, err = StringToAmount("1.234")
+	assert.NotEqual(t, nil, err)
+
+	_, err = StringToAmount("1.2.3")
+	assert.NotEqual(t, nil, err)
+
+	_, err = StringToAmount("1e5")
+	assert.NotEqual(t, nil, err)
+}
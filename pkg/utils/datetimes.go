@@ -124,6 +124,31 @@ func ParseFromTimezoneOffset(tzOffset string) (*time.Location, error) {
 	return time.FixedZone("Timezone", totalOffset), nil
 }
 
+// AddMonthsWithoutOverflow returns the time after adding specified months, the day will be the last day of target month if target month does not have that day
+func AddMonthsWithoutOverflow(t time.Time, months int) time.Time {
+	firstDayOfTargetMonth := time.Date(t.Year(), t.Month(), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location()).AddDate(0, months, 0)
+	lastDayOfTargetMonth := firstDayOfTargetMonth.AddDate(0, 1, -1).Day()
+	day := t.Day()
+
+	if day > lastDayOfTargetMonth {
+		day = lastDayOfTargetMonth
+	}
+
+	return firstDayOfTargetMonth.AddDate(0, 0, day-1)
+}
+
+// GetDateOfMonthWithoutOverflow returns the beginning time of specified day in specified month, the day will be the last day of that month if that month does not have that day
+func GetDateOfMonthWithoutOverflow(year int, month time.Month, day int, timezone *time.Location) time.Time {
+	firstDayOfMonth := time.Date(year, month, 1, 0, 0, 0, 0, timezone)
+	lastDayOfMonth := firstDayOfMonth.AddDate(0, 1, -1).Day()
+
+	if day > lastDayOfMonth {
+		day = lastDayOfMonth
+	}
+
+	return firstDayOfMonth.AddDate(0, 0, day-1)
+}
+
 // GetMinTransactionTimeFromUnixTime returns the minimum transaction time from unix time
 func GetMinTransactionTimeFromUnixTime(unixTime int64) int64 {
 	return unixTime * 1000
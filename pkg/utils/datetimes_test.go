@@ -128,6 +128,50 @@ func TestParseFromTimezoneOffset(t *testing.T) {
 	assert.NotEqual(t, nil, err)
 }
 
+func TestAddMonthsWithoutOverflow(t *testing.T) {
+	timezone := time.FixedZone("Test Timezone", 28800) // UTC+8
+
+	expectedValue := time.Date(2021, 2, 15, 10, 30, 0, 0, timezone)
+	actualValue := AddMonthsWithoutOverflow(time.Date(2021, 1, 15, 10, 30, 0, 0, timezone), 1)
+	assert.Equal(t, expectedValue, actualValue)
+
+	expectedValue = time.Date(2021, 2, 28, 10, 30, 0, 0, timezone)
+	actualValue = AddMonthsWithoutOverflow(time.Date(2021, 1, 31, 10, 30, 0, 0, timezone), 1)
+	assert.Equal(t, expectedValue, actualValue)
+
+	expectedValue = time.Date(2025, 2, 28, 0, 0, 0, 0, timezone)
+	actualValue = AddMonthsWithoutOverflow(time.Date(2024, 2, 29, 0, 0, 0, 0, timezone), 12)
+	assert.Equal(t, expectedValue, actualValue)
+
+	expectedValue = time.Date(2020, 11, 30, 0, 0, 0, 0, timezone)
+	actualValue = AddMonthsWithoutOverflow(time.Date(2021, 3, 31, 0, 0, 0, 0, timezone), -4)
+	assert.Equal(t, expectedValue, actualValue)
+}
+
+func TestGetDateOfMonthWithoutOverflow(t *testing.T) {
+	timezone := time.FixedZone("Test Timezone", 28800) // UTC+8
+
+	expectedValue := time.Date(2021, 1, 15, 0, 0, 0, 0, timezone)
+	actualValue := GetDateOfMonthWithoutOverflow(2021, 1, 15, timezone)
+	assert.Equal(t, expectedValue, actualValue)
+
+	expectedValue = time.Date(2021, 2, 28, 0, 0, 0, 0, timezone)
+	actualValue = GetDateOfMonthWithoutOverflow(2021, 2, 31, timezone)
+	assert.Equal(t, expectedValue, actualValue)
+
+	expectedValue = time.Date(2024, 2, 29, 0, 0, 0, 0, timezone)
+	actualValue = GetDateOfMonthWithoutOverflow(2024, 2, 30, timezone)
+	assert.Equal(t, expectedValue, actualValue)
+
+	expectedValue = time.Date(2022, 1, 31, 0, 0, 0, 0, timezone)
+	actualValue = GetDateOfMonthWithoutOverflow(2021, 13, 31, timezone)
+	assert.Equal(t, expectedValue, actualValue)
+
+	expectedValue = time.Date(2020, 12, 31, 0, 0, 0, 0, timezone)
+	actualValue = GetDateOfMonthWithoutOverflow(2021, 0, 31, timezone)
+	assert.Equal(t, expectedValue, actualValue)
+}
+
 func TestGetMinTransactionTimeFromUnixTime(t *testing.T) {
 	expectedValue := int64(1617228083000)
 	actualValue := GetMinTransactionTimeFromUnixTime(1617228083)
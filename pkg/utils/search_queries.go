@@ -0,0 +1,126 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/mayswind/ezbookkeeping/pkg/errs"
+)
+
+var searchQueryOperators = []string{">=", "<=", "!=", ":", "=", ">", "<"}
+
+// SearchQueryTerm represents a term of search query, the field of free text term is empty
+type SearchQueryTerm struct {
+	Field    string
+	Operator string
+	Value    string
+	Negated  bool
+}
+
+// ParseSearchQuery parses a search query string (e.g. `amount>100 tag:travel account:"Visa" -category:Fees`) to search terms
+func ParseSearchQuery(query string) ([]*SearchQueryTerm, error) {
+	terms := make([]*SearchQueryTerm, 0, 8)
+	runes := []rune(query)
+	i := 0
+
+	for i < len(runes) {
+		if unicode.IsSpace(runes[i]) {
+			i++
+			continue
+		}
+
+		term := &SearchQueryTerm{}
+
+		if runes[i] == '-' {
+			term.Negated = true
+			i++
+		}
+
+		if i < len(runes) && runes[i] != '"' {
+			fieldStart := i
+
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || runes[i] == '_') {
+				i++
+			}
+
+			operator := getSearchQueryOperator(runes[i:])
+
+			if i > fieldStart && operator != "" {
+				term.Field = strings.ToLower(string(runes[fieldStart:i]))
+				term.Operator = operator
+				i += len(operator)
+			} else {
+				i = fieldStart
+			}
+		}
+
+		value, nextIndex, err := parseSearchQueryValue(runes, i)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if value == "" {
+			return nil, errs.ErrFormatInvalid
+		}
+
+		term.Value = value
+		i = nextIndex
+		terms = append(terms, term)
+	}
+
+	return terms, nil
+}
+
+func getSearchQueryOperator(runes []rune) string {
+	for i := 0; i < len(searchQueryOperators); i++ {
+		operator := searchQueryOperators[i]
+
+		if len(runes) >= len(operator) && string(runes[:len(operator)]) == operator {
+			return operator
+		}
+	}
+
+	return ""
+}
+
+func parseSearchQueryValue(runes []rune, start int) (string, int, error) {
+	if start >= len(runes) || unicode.IsSpace(runes[start]) {
+		return "", start, nil
+	}
+
+	var value strings.Builder
+	i := start
+
+	if runes[i] == '"' {
+		i++
+
+		for i < len(runes) && runes[i] != '"' {
+			if runes[i] == '\\' && i+1 < len(runes) {
+				i++
+			}
+
+			value.WriteRune(runes[i])
+			i++
+		}
+
+		if i >= len(runes) {
+			return "", i, errs.ErrFormatInvalid
+		}
+
+		i++
+
+		if i < len(runes) && !unicode.IsSpace(runes[i]) {
+			return "", i, errs.ErrFormatInvalid
+		}
+
+		return value.String(), i, nil
+	}
+
+	for i < len(runes) && !unicode.IsSpace(runes[i]) {
+		value.WriteRune(runes[i])
+		i++
+	}
+
+	return value.String(), i, nil
+}
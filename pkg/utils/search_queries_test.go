@@ -0,0 +1,53 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSearchQuery(t *testing.T) {
+	expectedValue := []*SearchQueryTerm{
+		{Field: "amount", Operator: ">", Value: "100"},
+		{Field: "tag", Operator: ":", Value: "travel"},
+		{Field: "account", Operator: ":", Value: "Visa Card"},
+		{Field: "category", Operator: ":", Value: "Fees", Negated: true},
+		{Field: "", Operator: "", Value: "lunch"},
+		{Field: "", Operator: "", Value: "with \"friends\"", Negated: true},
+		{Field: "date", Operator: ">=", Value: "2024-01-01"},
+	}
+	actualValue, err := ParseSearchQuery(`amount>100 tag:travel  account:"Visa Card" -category:Fees lunch -"with \"friends\"" DATE>=2024-01-01`)
+	assert.Equal(t, nil, err)
+	assert.EqualValues(t, expectedValue, actualValue)
+}
+
+func TestParseSearchQuery_TextWithoutField(t *testing.T) {
+	expectedValue := []*SearchQueryTerm{
+		{Field: "", Operator: "", Value: "1:2"},
+		{Field: "", Operator: "", Value: "#food"},
+		{Field: "", Operator: "", Value: "-", Negated: true},
+	}
+	actualValue, err := ParseSearchQuery(`1:2 #food --`)
+	assert.Equal(t, nil, err)
+	assert.EqualValues(t, expectedValue, actualValue)
+}
+
+func TestParseSearchQuery_EmptyQuery(t *testing.T) {
+	actualValue, err := ParseSearchQuery("   ")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 0, len(actualValue))
+}
+
+func TestParseSearchQuery_InvalidQuery(t *testing.T) {
+	_, err := ParseSearchQuery(`account:"Visa`)
+	assert.NotEqual(t, nil, err)
+
+	_, err = ParseSearchQuery(`amount> 100`)
+	assert.NotEqual(t, nil, err)
+
+	_, err = ParseSearchQuery(`tag:"a"b`)
+	assert.NotEqual(t, nil, err)
+
+	_, err = ParseSearchQuery(`- foo`)
+	assert.NotEqual(t, nil, err)
+}
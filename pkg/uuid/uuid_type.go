@@ -12,4 +12,13 @@ const (
 	UUID_TYPE_CATEGORY    UuidType = 4
 	UUID_TYPE_TAG         UuidType = 5
 	UUID_TYPE_TAG_INDEX   UuidType = 6
+	UUID_TYPE_SCHEDULE    UuidType = 7
+	UUID_TYPE_TEMPLATE    UuidType = 8
+	UUID_TYPE_SPLIT       UuidType = 9
+	UUID_TYPE_ATTACHMENT  UuidType = 10
+	UUID_TYPE_HISTORY     UuidType = 11
+	UUID_TYPE_PAYEE       UuidType = 12
+	UUID_TYPE_RULE        UuidType = 13
+	UUID_TYPE_INVESTMENT  UuidType = 14
+	UUID_TYPE_BUDGET      UuidType = 15 // also used by savings goals, since uuid type only has 4 bits
 )